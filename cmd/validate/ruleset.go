@@ -12,6 +12,7 @@ import (
 	"github.com/dadrus/heimdall/internal/rules/event"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms"
 	"github.com/dadrus/heimdall/internal/rules/provider/filesystem"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 )
 
 // NewValidateRulesCommand represents the "validate rules" command.
@@ -78,7 +79,13 @@ func validateRuleSet(cmd *cobra.Command, args []string) error {
 
 	defer close(queue)
 
-	provider, err := filesystem.NewProvider(conf, rules.NewRuleSetProcessor(queue, rFactory, logger), logger)
+	verifier, err := signature.NewVerifier(conf, logger)
+	if err != nil {
+		return err
+	}
+
+	provider, err := filesystem.NewProvider(
+		conf, rules.NewRuleSetProcessor(queue, rFactory, logger), verifier, nil, logger)
 	if err != nil {
 		return err
 	}
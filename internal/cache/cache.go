@@ -27,5 +27,8 @@ type Cache interface {
 
 	Get(key string) any
 	Set(key string, value any, ttl time.Duration)
+	// SetIfAbsent atomically sets the value only if there is no (not expired) entry for the given key.
+	// Returns true if the value has been set.
+	SetIfAbsent(key string, value any, ttl time.Duration) bool
 	Delete(key string)
 }
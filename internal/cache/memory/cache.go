@@ -54,4 +54,10 @@ func (c *InMemoryCache) Get(key string) any {
 
 func (c *InMemoryCache) Set(key string, value any, ttl time.Duration) { c.c.Set(key, value, ttl) }
 
+func (c *InMemoryCache) SetIfAbsent(key string, value any, ttl time.Duration) bool {
+	_, found := c.c.GetOrSet(key, value, ttlcache.WithTTL[string, any](ttl))
+
+	return !found
+}
+
 func (c *InMemoryCache) Delete(key string) { c.c.Delete(key) }
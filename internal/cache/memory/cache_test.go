@@ -124,3 +124,19 @@ func TestCacheExpiration(t *testing.T) {
 
 	assert.LessOrEqual(t, hits, 4)
 }
+
+func TestCacheSetIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	cache := New()
+
+	assert.True(t, cache.SetIfAbsent("foo", "bar", 10*time.Minute))
+	assert.False(t, cache.SetIfAbsent("foo", "baz", 10*time.Minute))
+	assert.Equal(t, "bar", cache.Get("foo"))
+
+	cache.Set("baz", "bar", 1*time.Microsecond)
+	time.Sleep(200 * time.Millisecond)
+
+	assert.True(t, cache.SetIfAbsent("baz", "foo", 10*time.Minute))
+	assert.Equal(t, "foo", cache.Get("baz"))
+}
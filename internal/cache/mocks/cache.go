@@ -134,6 +134,50 @@ func (_c *CacheMock_Set_Call) RunAndReturn(run func(string, interface{}, time.Du
 	return _c
 }
 
+// SetIfAbsent provides a mock function with given fields: key, value, ttl
+func (_m *CacheMock) SetIfAbsent(key string, value interface{}, ttl time.Duration) bool {
+	ret := _m.Called(key, value, ttl)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, interface{}, time.Duration) bool); ok {
+		r0 = rf(key, value, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// CacheMock_SetIfAbsent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetIfAbsent'
+type CacheMock_SetIfAbsent_Call struct {
+	*mock.Call
+}
+
+// SetIfAbsent is a helper method to define mock.On call
+//   - key string
+//   - value interface{}
+//   - ttl time.Duration
+func (_e *CacheMock_Expecter) SetIfAbsent(key interface{}, value interface{}, ttl interface{}) *CacheMock_SetIfAbsent_Call {
+	return &CacheMock_SetIfAbsent_Call{Call: _e.mock.On("SetIfAbsent", key, value, ttl)}
+}
+
+func (_c *CacheMock_SetIfAbsent_Call) Run(run func(key string, value interface{}, ttl time.Duration)) *CacheMock_SetIfAbsent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(interface{}), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *CacheMock_SetIfAbsent_Call) Return(_a0 bool) *CacheMock_SetIfAbsent_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *CacheMock_SetIfAbsent_Call) RunAndReturn(run func(string, interface{}, time.Duration) bool) *CacheMock_SetIfAbsent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Start provides a mock function with given fields: ctx
 func (_m *CacheMock) Start(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -42,7 +42,8 @@ func newCache(conf *config.Configuration, logger zerolog.Logger) Cache {
 		return memory.New()
 	}
 
-	logger.Info().Msg("Cache is disabled")
+	logger.Warn().Msg("Cache is disabled. Mechanisms keeping their state in the cache, like the " +
+		"rate_limit authorizer, will reject all requests")
 
 	return noopCache{}
 }
@@ -41,6 +41,7 @@ func TestNewCache(t *testing.T) {
 				t.Helper()
 
 				assert.IsType(t, &memory.InMemoryCache{}, cch)
+				assert.False(t, IsDisabled(cch))
 			},
 		},
 		{
@@ -50,6 +51,7 @@ func TestNewCache(t *testing.T) {
 				t.Helper()
 
 				assert.IsType(t, noopCache{}, cch)
+				assert.True(t, IsDisabled(cch))
 			},
 		},
 	} {
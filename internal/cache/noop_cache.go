@@ -23,10 +23,21 @@ import (
 
 type noopCache struct{}
 
+// IsDisabled returns true if the given cache does not keep any entries. Mechanisms relying on the
+// cache to keep security relevant state, like consumed rate limits, must not silently skip their
+// checks in that case.
+func IsDisabled(cch Cache) bool {
+	_, ok := cch.(noopCache)
+
+	return ok
+}
+
 func (noopCache) Get(_ string) any { return nil }
 
 func (noopCache) Set(_ string, _ any, _ time.Duration) {}
 
+func (noopCache) SetIfAbsent(_ string, _ any, _ time.Duration) bool { return true }
+
 func (noopCache) Delete(_ string) {}
 
 func (noopCache) Start(_ context.Context) error { return nil }
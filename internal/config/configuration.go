@@ -36,8 +36,14 @@ type Configuration struct { //nolint:musttag
 	Prototypes *MechanismPrototypes `koanf:"mechanisms,omitempty"`
 	Default    *DefaultRule         `koanf:"default_rule,omitempty"`
 	Providers  RuleProviders        `koanf:"providers,omitempty"`
+
+	configFile string
 }
 
+// ConfigFile returns the path to the config file the configuration has been loaded from. If
+// the configuration has been loaded from environment variables only, an empty string is returned.
+func (c *Configuration) ConfigFile() string { return c.configFile }
+
 func NewConfiguration(envPrefix EnvVarPrefix, configFile ConfigurationPath) (*Configuration, error) {
 	// copy defaults
 	result := defaultConfig()
@@ -53,7 +59,11 @@ func NewConfiguration(envPrefix EnvVarPrefix, configFile ConfigurationPath) (*Co
 		parser.WithEnvPrefix(string(envPrefix)),
 		parser.WithDefaultConfigFilename("heimdall.yaml"),
 		parser.WithConfigFile(string(configFile)),
-		parser.WithConfigValidator(ValidateConfig),
+		parser.WithConfigValidator(func(configFile string) error {
+			result.configFile = configFile
+
+			return ValidateConfig(configFile)
+		}),
 	}
 
 	// if no config file provided, the lookup order for the heimdall.yaml file is:
@@ -40,6 +40,7 @@ func TestNewConfigurationFromStructWithDefaultsOnly(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Equal(t, string(rawExp), string(rawConf))
+	require.Empty(t, config.ConfigFile())
 }
 
 func TestNewConfigurationWithConfigFile(t *testing.T) {
@@ -59,4 +60,5 @@ func TestNewConfigurationWithConfigFile(t *testing.T) {
 	require.NoError(t, err)
 
 	require.NotEqual(t, string(rawExp), string(rawConf))
+	require.Equal(t, "./test_data/test_config.yaml", config.ConfigFile())
 }
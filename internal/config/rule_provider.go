@@ -21,4 +21,19 @@ type RuleProviders struct {
 	HTTPEndpoint map[string]any `koanf:"http_endpoint,omitempty"`
 	CloudBlob    map[string]any `koanf:"cloud_blob,omitempty"`
 	Kubernetes   map[string]any `koanf:"kubernetes,omitempty"`
+	Git          map[string]any `koanf:"git,omitempty"`
+	Etcd         map[string]any `koanf:"etcd,omitempty"`
+	OCI          map[string]any `koanf:"oci,omitempty"`
+
+	Signature *RuleSetSignature `koanf:"signature,omitempty"`
+
+	// ReadinessCheck lets the readiness endpoint report not ready until all configured providers
+	// completed their first successful synchronization.
+	ReadinessCheck bool `koanf:"readiness_check"`
+}
+
+// RuleSetSignature configures the verification of signed rule sets.
+type RuleSetSignature struct {
+	TrustStore string `koanf:"trust_store"`
+	Required   bool   `koanf:"required"`
 }
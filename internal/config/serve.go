@@ -50,19 +50,29 @@ type CORS struct {
 }
 
 type ServiceConfig struct {
-	Host             string           `koanf:"host"`
-	Port             int              `koanf:"port"`
-	Timeout          Timeout          `koanf:"timeout"`
-	BufferLimit      BufferLimit      `koanf:"buffer_limit"`
-	ConnectionsLimit ConnectionsLimit `koanf:"connections_limit"`
-	CORS             *CORS            `koanf:"cors,omitempty"`
-	TLS              *TLS             `koanf:"tls,omitempty"`
-	TrustedProxies   *[]string        `koanf:"trusted_proxies,omitempty"`
-	Respond          RespondConfig    `koanf:"respond"`
+	Host             string             `koanf:"host"`
+	Port             int                `koanf:"port"`
+	Timeout          Timeout            `koanf:"timeout"`
+	BufferLimit      BufferLimit        `koanf:"buffer_limit"`
+	ConnectionsLimit ConnectionsLimit   `koanf:"connections_limit"`
+	CORS             *CORS              `koanf:"cors,omitempty"`
+	TLS              *TLS               `koanf:"tls,omitempty"`
+	TrustedProxies   *[]string          `koanf:"trusted_proxies,omitempty"`
+	Respond          RespondConfig      `koanf:"respond"`
+	Explain          *ProtectedEndpoint `koanf:"explain,omitempty"`
+	Inventory        *ProtectedEndpoint `koanf:"inventory,omitempty"`
+	Providers        *ProtectedEndpoint `koanf:"providers,omitempty"`
 }
 
 func (c ServiceConfig) Address() string { return fmt.Sprintf("%s:%d", c.Host, c.Port) }
 
+// ProtectedEndpoint configures an optional endpoint of the management service. As such endpoints
+// reveal details about the configured rules and rule providers, these require one of the configured bearer tokens.
+type ProtectedEndpoint struct {
+	Enabled bool     `koanf:"enabled"`
+	Tokens  []string `koanf:"tokens"`
+}
+
 type ServeConfig struct {
 	Proxy      ServiceConfig `koanf:"proxy"`
 	Decision   ServiceConfig `koanf:"decision"`
@@ -34,4 +34,11 @@ type TLS struct {
 	KeyID        string          `koanf:"key_id"        mapstructure:"key_id"`
 	CipherSuites TLSCipherSuites `koanf:"cipher_suites" mapstructure:"cipher_suites"`
 	MinVersion   TLSMinVersion   `koanf:"min_version"   mapstructure:"min_version"`
+	ClientAuth   *TLSClientAuth  `koanf:"client_auth"   mapstructure:"client_auth"`
+}
+
+// TLSClientAuth configures the verification of client certificates presented during the TLS handshake.
+type TLSClientAuth struct {
+	TrustStore string `koanf:"trust_store" mapstructure:"trust_store"`
+	Required   bool   `koanf:"required"    mapstructure:"required"`
 }
@@ -0,0 +1,89 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package explain
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeSkipped = "skipped"
+)
+
+type ctxKey struct{}
+
+// Step describes the execution of a single pipeline mechanism.
+type Step struct {
+	Rule    string            `json:"rule,omitempty"`
+	Type    string            `json:"type"`
+	ID      string            `json:"id"`
+	Shadow  bool              `json:"shadow,omitempty"`
+	Outcome string            `json:"outcome"`
+	Error   string            `json:"error,omitempty"`
+	Headers http.Header       `json:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
+}
+
+type trace struct {
+	mut   sync.Mutex
+	rule  string
+	steps []*Step
+}
+
+// New returns a context, which enables the recording of the pipeline steps executed for a request.
+func New(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &trace{})
+}
+
+func Enabled(ctx context.Context) bool {
+	_, ok := ctx.Value(ctxKey{}).(*trace)
+
+	return ok
+}
+
+// SetRule sets the id of the rule, the steps recorded afterwards belong to.
+func SetRule(ctx context.Context, ruleID string) {
+	if t, ok := ctx.Value(ctxKey{}).(*trace); ok {
+		t.mut.Lock()
+		t.rule = ruleID
+		t.mut.Unlock()
+	}
+}
+
+func Record(ctx context.Context, step *Step) {
+	if t, ok := ctx.Value(ctxKey{}).(*trace); ok {
+		t.mut.Lock()
+		step.Rule = t.rule
+		t.steps = append(t.steps, step)
+		t.mut.Unlock()
+	}
+}
+
+func Steps(ctx context.Context) []*Step {
+	if t, ok := ctx.Value(ctxKey{}).(*trace); ok {
+		t.mut.Lock()
+		defer t.mut.Unlock()
+
+		return t.steps
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package explain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingWithoutTrace(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := context.Background()
+
+	// WHEN
+	SetRule(ctx, "foo")
+	Record(ctx, &Step{Type: "authenticator", ID: "bar"})
+
+	// THEN
+	assert.False(t, Enabled(ctx))
+	assert.Empty(t, Steps(ctx))
+}
+
+func TestRecordingWithTrace(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := New(context.Background())
+
+	// WHEN
+	Record(ctx, &Step{Type: "authenticator", ID: "foo", Outcome: OutcomeSuccess})
+	SetRule(ctx, "bar")
+	Record(ctx, &Step{Type: "authorizer", ID: "baz", Outcome: OutcomeFailure, Error: "test"})
+
+	// THEN
+	assert.True(t, Enabled(ctx))
+
+	steps := Steps(ctx)
+	require.Len(t, steps, 2)
+	assert.Equal(t, &Step{Type: "authenticator", ID: "foo", Outcome: OutcomeSuccess}, steps[0])
+	assert.Equal(t, &Step{Rule: "bar", Type: "authorizer", ID: "baz", Outcome: OutcomeFailure, Error: "test"},
+		steps[1])
+}
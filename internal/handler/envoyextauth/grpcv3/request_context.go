@@ -18,6 +18,7 @@ package grpcv3
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -31,15 +32,18 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/truststore"
 	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/stringx"
 )
 
 type RequestContext struct {
 	ctx             context.Context // nolint: containedctx
 	ips             []string
+	clientCerts     []*x509.Certificate
 	reqMethod       string
 	reqHeaders      map[string]string
-	reqURL          *url.URL
+	reqURL          *heimdall.URL
 	reqBody         string
 	reqRawBody      []byte
 	upstreamHeaders http.Header
@@ -59,16 +63,19 @@ func NewRequestContext(ctx context.Context, req *envoy_auth.CheckRequest, signer
 	}
 
 	return &RequestContext{
-		ctx:        ctx,
-		ips:        clientIPs,
-		reqMethod:  req.GetAttributes().GetRequest().GetHttp().GetMethod(),
-		reqHeaders: canonicalizeHeaders(req.GetAttributes().GetRequest().GetHttp().GetHeaders()),
-		reqURL: &url.URL{
-			Scheme:   req.GetAttributes().GetRequest().GetHttp().GetScheme(),
-			Host:     req.GetAttributes().GetRequest().GetHttp().GetHost(),
-			Path:     req.GetAttributes().GetRequest().GetHttp().GetPath(),
-			RawQuery: req.GetAttributes().GetRequest().GetHttp().GetQuery(),
-			Fragment: req.GetAttributes().GetRequest().GetHttp().GetFragment(),
+		ctx:         ctx,
+		ips:         clientIPs,
+		clientCerts: peerCertificates(req),
+		reqMethod:   req.GetAttributes().GetRequest().GetHttp().GetMethod(),
+		reqHeaders:  canonicalizeHeaders(req.GetAttributes().GetRequest().GetHttp().GetHeaders()),
+		reqURL: &heimdall.URL{
+			URL: url.URL{
+				Scheme:   req.GetAttributes().GetRequest().GetHttp().GetScheme(),
+				Host:     req.GetAttributes().GetRequest().GetHttp().GetHost(),
+				Path:     req.GetAttributes().GetRequest().GetHttp().GetPath(),
+				RawQuery: req.GetAttributes().GetRequest().GetHttp().GetQuery(),
+				Fragment: req.GetAttributes().GetRequest().GetHttp().GetFragment(),
+			},
 		},
 		reqBody:         req.GetAttributes().GetRequest().GetHttp().GetBody(),
 		reqRawBody:      req.GetAttributes().GetRequest().GetHttp().GetRawBody(),
@@ -78,6 +85,27 @@ func NewRequestContext(ctx context.Context, req *envoy_auth.CheckRequest, signer
 	}
 }
 
+// peerCertificates returns the certificate of the downstream peer, if envoy has been configured
+// to terminate mTLS. The certificate is URL encoded PEM.
+func peerCertificates(req *envoy_auth.CheckRequest) []*x509.Certificate {
+	encoded := req.GetAttributes().GetSource().GetCertificate()
+	if len(encoded) == 0 {
+		return nil
+	}
+
+	decoded, err := url.PathUnescape(encoded)
+	if err != nil {
+		return nil
+	}
+
+	certs, err := truststore.NewTrustStoreFromPEMBytes(stringx.ToBytes(decoded), false)
+	if err != nil {
+		return nil
+	}
+
+	return certs
+}
+
 func canonicalizeHeaders(headers map[string]string) map[string]string {
 	result := make(map[string]string, len(headers))
 
@@ -90,10 +118,11 @@ func canonicalizeHeaders(headers map[string]string) map[string]string {
 
 func (s *RequestContext) Request() *heimdall.Request {
 	return &heimdall.Request{
-		RequestFunctions: s,
-		Method:           s.reqMethod,
-		URL:              s.reqURL,
-		ClientIP:         s.ips,
+		RequestFunctions:   s,
+		Method:             s.reqMethod,
+		URL:                s.reqURL,
+		ClientIP:           s.ips,
+		ClientCertificates: s.clientCerts,
 	}
 }
 
@@ -18,6 +18,7 @@ package listener
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"net"
 	"sync/atomic"
 	"time"
@@ -25,6 +26,8 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/keystore"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -145,5 +148,33 @@ func newTLSListener(tlsConf *config.TLS, listener net.Listener) (net.Listener, e
 		cfg.CipherSuites = tlsConf.CipherSuites.OrDefault()
 	}
 
+	if tlsConf.ClientAuth != nil {
+		if err = configureClientAuth(cfg, tlsConf.ClientAuth); err != nil {
+			return nil, err
+		}
+	}
+
 	return tls.NewListener(listener, cfg), nil
 }
+
+func configureClientAuth(cfg *tls.Config, clientAuth *config.TLSClientAuth) error {
+	ts, err := truststore.NewTrustStoreFromPEMFile(clientAuth.TrustStore, false)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed loading trust store for client certificate verification").CausedBy(err)
+	}
+
+	if len(ts) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"trust store for client certificate verification does not contain any certificates")
+	}
+
+	cfg.ClientCAs = x509.NewCertPool()
+	for _, cert := range ts {
+		cfg.ClientCAs.AddCert(cert)
+	}
+
+	cfg.ClientAuth = x.IfThenElse(clientAuth.Required, tls.RequireAndVerifyClientCert, tls.VerifyClientCertIfGiven)
+
+	return nil
+}
@@ -193,6 +193,69 @@ func TestNewListener(t *testing.T) {
 				assert.Contains(t, ln.Addr().String(), port)
 			},
 		},
+		{
+			uc:      "fails due to not existent trust store for client certificate verification",
+			network: "tcp",
+			serviceConf: config.ServiceConfig{
+				TLS: &config.TLS{
+					KeyStore:   config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.TLSClientAuth{TrustStore: "/no/such/file"},
+				},
+			},
+			assert: func(t *testing.T, err error, ln net.Listener, port string) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed loading trust store")
+			},
+		},
+		{
+			uc:      "successful with required client certificate verification",
+			network: "tcp",
+			serviceConf: config.ServiceConfig{
+				Host: "127.0.0.1",
+				TLS: &config.TLS{
+					KeyStore:   config.KeyStore{Path: pemFile.Name()},
+					ClientAuth: &config.TLSClientAuth{TrustStore: pemFile.Name(), Required: true},
+				},
+			},
+			assert: func(t *testing.T, err error, ln net.Listener, port string) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, ln)
+
+				handshakeErr := make(chan error, 1)
+
+				go func() {
+					con, err := ln.Accept()
+					if err != nil {
+						handshakeErr <- err
+
+						return
+					}
+
+					defer con.Close()
+
+					handshakeErr <- con.(*tls.Conn).Handshake() // nolint: forcetypeassert
+				}()
+
+				// client does not present any certificate
+				con, err := tls.Dial("tcp", "127.0.0.1:"+port, &tls.Config{
+					InsecureSkipVerify: true, // nolint: gosec
+					MinVersion:         tls.VersionTLS13,
+				})
+				if err == nil {
+					// with TLS 1.3 the client learns about the failure on first read
+					_, err = con.Read(make([]byte, 1))
+					con.Close()
+				}
+
+				require.Error(t, err)
+				require.Error(t, <-handshakeErr)
+			},
+		},
 		{
 			uc:      "successful with specified key id",
 			network: "tcp",
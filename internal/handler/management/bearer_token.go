@@ -0,0 +1,86 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/justinas/alice"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// newBearerTokenAuth returns a middleware, which lets only requests through, which carry one of the
+// tokens configured for the given endpoint as bearer token. If the endpoint is disabled, or no tokens
+// are configured, nil is returned and the endpoint must not be exposed.
+func newBearerTokenAuth(
+	logger zerolog.Logger,
+	name string,
+	conf *config.ProtectedEndpoint,
+	eh errorhandler.ErrorHandler,
+) alice.Constructor {
+	switch {
+	case conf == nil || !conf.Enabled:
+		return nil
+	case len(conf.Tokens) == 0:
+		logger.Warn().Str("_endpoint", name).
+			Msg("Endpoint is enabled, but no tokens are configured. Endpoint is disabled.")
+
+		return nil
+	}
+
+	tokens := make([][]byte, len(conf.Tokens))
+	for idx, token := range conf.Tokens {
+		tokens[idx] = []byte(token)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if !authorized(req, tokens) {
+				rw.Header().Set("WWW-Authenticate", "Bearer")
+				eh.HandleError(rw, req, errorchain.NewWithMessage(heimdall.ErrAuthentication,
+					"missing or invalid bearer token"))
+
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+func authorized(req *http.Request, tokens [][]byte) bool {
+	token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !found || len(token) == 0 {
+		return false
+	}
+
+	for _, expected := range tokens {
+		if subtle.ConstantTimeCompare(expected, stringx.ToBytes(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
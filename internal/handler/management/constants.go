@@ -18,5 +18,11 @@ package management
 
 const (
 	EndpointHealth = "/.well-known/health"
+	EndpointReady  = "/.well-known/ready"
 	EndpointJWKS   = "/.well-known/jwks"
+
+	EndpointExplain   = "/explain"
+	EndpointRuleSets  = "/rulesets"
+	EndpointRules     = "/rules"
+	EndpointProviders = "/providers"
 )
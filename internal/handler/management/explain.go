@@ -0,0 +1,161 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/explain"
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	decisionAllow = "allow"
+	decisionDeny  = "deny"
+)
+
+type explainRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Cookies map[string]string `json:"cookies"`
+	Body    string            `json:"body"`
+}
+
+type explainedRule struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+}
+
+type explanation struct {
+	Rule     *explainedRule  `json:"rule,omitempty"`
+	Decision string          `json:"decision"`
+	Error    string          `json:"error,omitempty"`
+	Steps    []*explain.Step `json:"steps"`
+}
+
+// explainHandler executes the rule matching a synthetic request and responds with the trace of all
+// executed pipeline steps. The steps are executed for real, so e.g. contextualizers and remote
+// authorizers call their endpoints and rate limits are consumed. In proxy mode, the request is
+// however never forwarded to the upstream service.
+type explainHandler struct {
+	r  rule.Repository
+	s  heimdall.JWTSigner
+	eh errorhandler.ErrorHandler
+}
+
+func (h *explainHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var expReq explainRequest
+	if err := json.NewDecoder(req.Body).Decode(&expReq); err != nil {
+		h.eh.HandleError(rw, req, errorchain.NewWithMessage(heimdall.ErrArgument,
+			"failed to decode request").CausedBy(err))
+
+		return
+	}
+
+	synthetic, err := newSyntheticRequest(req, &expReq)
+	if err != nil {
+		h.eh.HandleError(rw, req, err)
+
+		return
+	}
+
+	res, err := json.Marshal(h.explain(synthetic))
+	if err != nil {
+		zerolog.Ctx(req.Context()).Error().Err(err).Msg("Failed to marshal explanation object")
+		h.eh.HandleError(rw, req, err)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_, _ = rw.Write(res)
+}
+
+func (h *explainHandler) explain(req *http.Request) *explanation {
+	rc := requestcontext.New(h.s, req)
+	result := &explanation{Decision: decisionDeny}
+
+	rul, err := h.r.FindRule(rc.Request())
+	if err == nil {
+		result.Rule = &explainedRule{ID: rul.ID(), Source: rul.SrcID()}
+
+		// the returned backend is not used. So no upstream is selected and nothing has to be released
+		if _, err = rul.Execute(rc); err == nil {
+			err = rc.PipelineError()
+		}
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Decision = decisionAllow
+	}
+
+	result.Steps = explain.Steps(req.Context())
+	if result.Steps == nil {
+		result.Steps = []*explain.Step{}
+	}
+
+	return result
+}
+
+func newSyntheticRequest(req *http.Request, expReq *explainRequest) (*http.Request, error) {
+	if len(expReq.Method) == 0 || len(expReq.URL) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrArgument, "method and url are required")
+	}
+
+	synthetic, err := http.NewRequestWithContext(
+		explain.New(req.Context()), expReq.Method, expReq.URL, strings.NewReader(expReq.Body))
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrArgument, "failed to create request").CausedBy(err)
+	}
+
+	if !synthetic.URL.IsAbs() {
+		return nil, errorchain.NewWithMessage(heimdall.ErrArgument, "url must be absolute")
+	}
+
+	for name, value := range expReq.Headers {
+		synthetic.Header.Set(name, value)
+	}
+
+	if host := synthetic.Header.Get("Host"); len(host) != 0 {
+		synthetic.Host = host
+		synthetic.Header.Del("Host")
+	}
+
+	// the scheme of the synthetic request is not available otherwise
+	if len(synthetic.Header.Get("X-Forwarded-Proto")) == 0 {
+		synthetic.Header.Set("X-Forwarded-Proto", synthetic.URL.Scheme)
+	}
+
+	for name, value := range expReq.Cookies {
+		synthetic.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+
+	synthetic.RemoteAddr = req.RemoteAddr
+
+	return synthetic, nil
+}
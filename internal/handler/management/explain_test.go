@@ -0,0 +1,310 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/explain"
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	rulemocks "github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+func TestExplainEndpoint(t *testing.T) {
+	t.Parallel()
+
+	enabled := &config.ProtectedEndpoint{Enabled: true, Tokens: []string{"foo", "bar"}}
+	validBody := `{
+		"method": "POST",
+		"url": "https://foo.bar/baz?foo=bar",
+		"headers": {"Authorization": "Bearer baz", "Host": "bar.foo"},
+		"cookies": {"session": "zab"},
+		"body": "hello"
+	}`
+
+	for _, tc := range []struct {
+		uc             string
+		conf           *config.ProtectedEndpoint
+		method         string
+		token          string
+		body           string
+		configureMocks func(t *testing.T, repo *rulemocks.RepositoryMock)
+		assert         func(t *testing.T, resp *http.Response, result *explanation)
+	}{
+		{
+			uc:     "endpoint disabled",
+			method: http.MethodPost,
+			token:  "foo",
+			body:   validBody,
+			assert: func(t *testing.T, resp *http.Response, _ *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "endpoint enabled without tokens",
+			conf:   &config.ProtectedEndpoint{Enabled: true},
+			method: http.MethodPost,
+			body:   validBody,
+			assert: func(t *testing.T, resp *http.Response, _ *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "unsupported method",
+			conf:   enabled,
+			method: http.MethodGet,
+			token:  "foo",
+			assert: func(t *testing.T, resp *http.Response, _ *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "without token",
+			conf:   enabled,
+			method: http.MethodPost,
+			body:   validBody,
+			assert: func(t *testing.T, resp *http.Response, _ *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+				assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+			},
+		},
+		{
+			uc:     "with wrong token",
+			conf:   enabled,
+			method: http.MethodPost,
+			token:  "baz",
+			body:   validBody,
+			assert: func(t *testing.T, resp *http.Response, _ *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "with malformed body",
+			conf:   enabled,
+			method: http.MethodPost,
+			token:  "bar",
+			body:   `{"method":`,
+			assert: func(t *testing.T, resp *http.Response, _ *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "without url",
+			conf:   enabled,
+			method: http.MethodPost,
+			token:  "bar",
+			body:   `{"method": "GET"}`,
+			assert: func(t *testing.T, resp *http.Response, _ *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "with relative url",
+			conf:   enabled,
+			method: http.MethodPost,
+			token:  "bar",
+			body:   `{"method": "GET", "url": "/foo"}`,
+			assert: func(t *testing.T, resp *http.Response, _ *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			},
+		},
+		{
+			uc:     "no rule matches",
+			conf:   enabled,
+			method: http.MethodPost,
+			token:  "foo",
+			body:   validBody,
+			configureMocks: func(t *testing.T, repo *rulemocks.RepositoryMock) {
+				t.Helper()
+
+				repo.EXPECT().FindRule(mock.Anything).Return(nil, heimdall.ErrNoRuleFound)
+			},
+			assert: func(t *testing.T, resp *http.Response, result *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				require.NotNil(t, result)
+				assert.Nil(t, result.Rule)
+				assert.Equal(t, decisionDeny, result.Decision)
+				assert.Equal(t, heimdall.ErrNoRuleFound.Error(), result.Error)
+				assert.Empty(t, result.Steps)
+			},
+		},
+		{
+			uc:     "rule denies the request",
+			conf:   enabled,
+			method: http.MethodPost,
+			token:  "foo",
+			body:   validBody,
+			configureMocks: func(t *testing.T, repo *rulemocks.RepositoryMock) {
+				t.Helper()
+
+				rul := rulemocks.NewRuleMock(t)
+				rul.EXPECT().ID().Return("test")
+				rul.EXPECT().SrcID().Return("test-src")
+				rul.EXPECT().Execute(mock.Anything).
+					Run(func(ctx heimdall.Context) {
+						explain.Record(ctx.AppContext(), &explain.Step{
+							Type: "authenticator", ID: "jwt", Outcome: explain.OutcomeFailure, Error: "bad token",
+						})
+					}).
+					Return(nil, errorchain.NewWithMessage(heimdall.ErrAuthentication, "bad token"))
+
+				repo.EXPECT().FindRule(mock.Anything).Return(rul, nil)
+			},
+			assert: func(t *testing.T, resp *http.Response, result *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				require.NotNil(t, result)
+				assert.Equal(t, &explainedRule{ID: "test", Source: "test-src"}, result.Rule)
+				assert.Equal(t, decisionDeny, result.Decision)
+				assert.Contains(t, result.Error, "bad token")
+				require.Len(t, result.Steps, 1)
+				assert.Equal(t, "jwt", result.Steps[0].ID)
+				assert.Equal(t, explain.OutcomeFailure, result.Steps[0].Outcome)
+			},
+		},
+		{
+			uc:     "rule allows the request",
+			conf:   enabled,
+			method: http.MethodPost,
+			token:  "bar",
+			body:   validBody,
+			configureMocks: func(t *testing.T, repo *rulemocks.RepositoryMock) {
+				t.Helper()
+
+				rul := rulemocks.NewRuleMock(t)
+				rul.EXPECT().ID().Return("test")
+				rul.EXPECT().SrcID().Return("test-src")
+				rul.EXPECT().Execute(mock.Anything).
+					Run(func(ctx heimdall.Context) {
+						// mechanisms have access to the cache
+						assert.NotNil(t, cache.Ctx(ctx.AppContext()))
+
+						explain.Record(ctx.AppContext(), &explain.Step{
+							Type:    "finalizer",
+							ID:      "header",
+							Outcome: explain.OutcomeSuccess,
+							Headers: http.Header{"X-User": []string{"foo"}},
+						})
+					}).
+					Return(nil, nil)
+
+				repo.EXPECT().FindRule(mock.MatchedBy(func(req *heimdall.Request) bool {
+					return req.Method == http.MethodPost &&
+						req.URL.String() == "https://bar.foo/baz?foo=bar" &&
+						req.Header("Authorization") == "Bearer baz" &&
+						req.Cookie("session") == "zab" &&
+						string(req.Body()) == "hello"
+				})).Return(rul, nil)
+			},
+			assert: func(t *testing.T, resp *http.Response, result *explanation) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+				require.NotNil(t, result)
+				assert.Equal(t, &explainedRule{ID: "test", Source: "test-src"}, result.Rule)
+				assert.Equal(t, decisionAllow, result.Decision)
+				assert.Empty(t, result.Error)
+				require.Len(t, result.Steps, 1)
+				assert.Equal(t, &explain.Step{
+					Type:    "finalizer",
+					ID:      "header",
+					Outcome: explain.OutcomeSuccess,
+					Headers: http.Header{"X-User": []string{"foo"}},
+				}, result.Steps[0])
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureMocks := func(t *testing.T, _ *rulemocks.RepositoryMock) { t.Helper() }
+			if tc.configureMocks != nil {
+				configureMocks = tc.configureMocks
+			}
+
+			repo := rulemocks.NewRepositoryMock(t)
+			configureMocks(t, repo)
+
+			handler := newManagementHandler(
+				config.ServiceConfig{Explain: tc.conf},
+				log.Logger,
+				memory.New(),
+				repo,
+				nil,
+				nil,
+				false,
+				mocks.NewJWTSignerMock(t),
+				errorhandler.New(),
+			)
+
+			req := httptest.NewRequest(tc.method, "http://heimdall.local"+EndpointExplain, strings.NewReader(tc.body))
+			if len(tc.token) != 0 {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			rec := httptest.NewRecorder()
+
+			// WHEN
+			handler.ServeHTTP(rec, req)
+
+			// THEN
+			resp := rec.Result()
+			defer resp.Body.Close()
+
+			var result *explanation
+			if resp.StatusCode == http.StatusOK {
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			}
+
+			tc.assert(t, resp, result)
+		})
+	}
+}
@@ -9,12 +9,27 @@ import (
 	"github.com/rs/zerolog"
 	"gopkg.in/square/go-jose.v2"
 
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/config"
+	cachemiddleware "github.com/dadrus/heimdall/internal/handler/middleware/http/cache"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/methodfilter"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
+	"github.com/dadrus/heimdall/internal/rules/rule"
 )
 
-func newManagementHandler(signer heimdall.JWTSigner, eh errorhandler.ErrorHandler) http.Handler {
+func newManagementHandler(
+	conf config.ServiceConfig,
+	logger zerolog.Logger,
+	cch cache.Cache,
+	repository rule.Repository,
+	inventory rule.Inventory,
+	registry *syncstatus.Registry,
+	readinessCheck bool,
+	signer heimdall.JWTSigner,
+	eh errorhandler.ErrorHandler,
+) http.Handler {
 	mh := &handler{
 		s:  signer,
 		eh: eh,
@@ -25,10 +40,38 @@ func newManagementHandler(signer heimdall.JWTSigner, eh errorhandler.ErrorHandle
 	mux.Handle(EndpointHealth,
 		alice.New(methodfilter.New(http.MethodGet)).
 			Then(http.HandlerFunc(mh.health)))
+	ph := &providersHandler{r: registry, gate: readinessCheck, eh: eh}
+
+	mux.Handle(EndpointReady,
+		alice.New(methodfilter.New(http.MethodGet)).
+			Then(http.HandlerFunc(ph.ready)))
 	mux.Handle(EndpointJWKS,
 		alice.New(methodfilter.New(http.MethodGet)).
 			Then(etag.Handler(http.HandlerFunc(mh.jwks), false)))
 
+	if auth := newBearerTokenAuth(logger, "inventory", conf.Inventory, eh); auth != nil && inventory != nil {
+		ih := &inventoryHandler{i: inventory, eh: eh}
+
+		mux.Handle(EndpointRuleSets,
+			alice.New(methodfilter.New(http.MethodGet), auth).
+				Then(http.HandlerFunc(ih.ruleSets)))
+		mux.Handle(EndpointRules,
+			alice.New(methodfilter.New(http.MethodGet), auth).
+				Then(http.HandlerFunc(ih.rules)))
+	}
+
+	if auth := newBearerTokenAuth(logger, "providers", conf.Providers, eh); auth != nil && registry != nil {
+		mux.Handle(EndpointProviders,
+			alice.New(methodfilter.New(http.MethodGet), auth).
+				Then(http.HandlerFunc(ph.providers)))
+	}
+
+	if auth := newBearerTokenAuth(logger, "explain", conf.Explain, eh); auth != nil {
+		mux.Handle(EndpointExplain,
+			alice.New(methodfilter.New(http.MethodPost), auth, cachemiddleware.New(cch)).
+				Then(&explainHandler{r: repository, s: signer, eh: eh}))
+	}
+
 	return mux
 }
 
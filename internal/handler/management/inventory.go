@@ -0,0 +1,61 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"net/http"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/x/slicex"
+)
+
+// inventoryHandler exposes the currently loaded rule sets and rules in a read-only manner.
+type inventoryHandler struct {
+	i  rule.Inventory
+	eh errorhandler.ErrorHandler
+}
+
+func (h *inventoryHandler) ruleSets(rw http.ResponseWriter, req *http.Request) {
+	h.respond(rw, req, h.i.RuleSets())
+}
+
+func (h *inventoryHandler) rules(rw http.ResponseWriter, req *http.Request) {
+	rules := h.i.Rules()
+
+	if source := req.URL.Query().Get("source"); len(source) != 0 {
+		rules = slicex.Filter(rules, func(desc rule.Description) bool { return desc.Source == source })
+	}
+
+	h.respond(rw, req, rules)
+}
+
+func (h *inventoryHandler) respond(rw http.ResponseWriter, req *http.Request, value any) {
+	res, err := json.Marshal(value)
+	if err != nil {
+		zerolog.Ctx(req.Context()).Error().Err(err).Msg("Failed to marshal inventory object")
+		h.eh.HandleError(rw, req, err)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	_, _ = rw.Write(res)
+}
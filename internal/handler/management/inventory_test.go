@@ -0,0 +1,239 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	rulecfg "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	rulemocks "github.com/dadrus/heimdall/internal/rules/rule/mocks"
+)
+
+func TestInventoryEndpoints(t *testing.T) {
+	t.Parallel()
+
+	loadedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	rules := []rule.Description{
+		{
+			ID:      "rule:foo",
+			Source:  "foo",
+			Match:   rulecfg.Matcher{URL: "http://foo.bar/<**>", Strategy: "glob"},
+			Methods: []string{http.MethodGet},
+			Execute: []rule.MechanismDescription{{Type: "authenticator", ID: "anon"}},
+		},
+		{
+			ID:      "rule:bar",
+			Source:  "bar",
+			Match:   rulecfg.Matcher{URL: "http://bar.foo/<**>", Strategy: "glob"},
+			Methods: []string{http.MethodPost},
+			Execute: []rule.MechanismDescription{{Type: "authenticator", ID: "jwt"}},
+		},
+	}
+
+	enabled := &config.ProtectedEndpoint{Enabled: true, Tokens: []string{"foo", "bar"}}
+
+	for _, tc := range []struct {
+		uc             string
+		conf           *config.ProtectedEndpoint
+		token          string
+		method         string
+		endpoint       string
+		configureMocks func(t *testing.T, inv *rulemocks.InventoryMock)
+		assert         func(t *testing.T, resp *http.Response)
+	}{
+		{
+			uc:       "endpoints disabled",
+			token:    "foo",
+			method:   http.MethodGet,
+			endpoint: EndpointRules,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			uc:       "endpoints enabled without tokens",
+			conf:     &config.ProtectedEndpoint{Enabled: true},
+			method:   http.MethodGet,
+			endpoint: EndpointRuleSets,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			uc:       "without token",
+			conf:     enabled,
+			method:   http.MethodGet,
+			endpoint: EndpointRuleSets,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+				assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+			},
+		},
+		{
+			uc:       "with wrong token",
+			conf:     enabled,
+			token:    "baz",
+			method:   http.MethodGet,
+			endpoint: EndpointRules,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
+		{
+			uc:       "unsupported method",
+			conf:     enabled,
+			token:    "foo",
+			method:   http.MethodPost,
+			endpoint: EndpointRules,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+			},
+		},
+		{
+			uc:       "list rule sets",
+			conf:     enabled,
+			token:    "bar",
+			method:   http.MethodGet,
+			endpoint: EndpointRuleSets,
+			configureMocks: func(t *testing.T, inv *rulemocks.InventoryMock) {
+				t.Helper()
+
+				inv.EXPECT().RuleSets().Return([]rule.SetInfo{
+					{Source: "foo", Name: "test", Hash: "abcd", LoadedAt: loadedAt, Rules: []string{"rule:foo"}},
+				})
+			},
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+				var sets []rule.SetInfo
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&sets))
+				require.Len(t, sets, 1)
+				assert.Equal(t, "foo", sets[0].Source)
+				assert.Equal(t, "test", sets[0].Name)
+				assert.Equal(t, "abcd", sets[0].Hash)
+				assert.Equal(t, loadedAt, sets[0].LoadedAt)
+				assert.Equal(t, []string{"rule:foo"}, sets[0].Rules)
+			},
+		},
+		{
+			uc:       "list all rules",
+			conf:     enabled,
+			token:    "bar",
+			method:   http.MethodGet,
+			endpoint: EndpointRules,
+			configureMocks: func(t *testing.T, inv *rulemocks.InventoryMock) {
+				t.Helper()
+
+				inv.EXPECT().Rules().Return(rules)
+			},
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+				var result []rule.Description
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, rules, result)
+			},
+		},
+		{
+			uc:       "list rules of a particular source",
+			conf:     enabled,
+			token:    "bar",
+			method:   http.MethodGet,
+			endpoint: EndpointRules + "?source=bar",
+			configureMocks: func(t *testing.T, inv *rulemocks.InventoryMock) {
+				t.Helper()
+
+				inv.EXPECT().Rules().Return(rules)
+			},
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+				var result []rule.Description
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, rules[1:], result)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			configureMocks := func(t *testing.T, _ *rulemocks.InventoryMock) { t.Helper() }
+			if tc.configureMocks != nil {
+				configureMocks = tc.configureMocks
+			}
+
+			inv := rulemocks.NewInventoryMock(t)
+			configureMocks(t, inv)
+
+			handler := newManagementHandler(
+				config.ServiceConfig{Inventory: tc.conf},
+				log.Logger,
+				nil,
+				nil,
+				inv,
+				nil,
+				false,
+				mocks.NewJWTSignerMock(t),
+				errorhandler.New(),
+			)
+
+			req := httptest.NewRequest(tc.method, "http://heimdall.local"+tc.endpoint, nil)
+			if len(tc.token) != 0 {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			rec := httptest.NewRecorder()
+
+			// WHEN
+			handler.ServeHTTP(rec, req)
+
+			// THEN
+			resp := rec.Result()
+			defer resp.Body.Close()
+
+			tc.assert(t, resp)
+		})
+	}
+}
@@ -22,9 +22,12 @@ import (
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 
+	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/fxlcm"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
+	"github.com/dadrus/heimdall/internal/rules/rule"
 )
 
 var Module = fx.Invoke( // nolint: gochecknoglobals
@@ -38,6 +41,10 @@ var Module = fx.Invoke( // nolint: gochecknoglobals
 func newLifecycleManager(
 	conf *config.Configuration,
 	logger zerolog.Logger,
+	cch cache.Cache,
+	repository rule.Repository,
+	inventory rule.Inventory,
+	registry *syncstatus.Registry,
 	signer heimdall.JWTSigner,
 ) *fxlcm.LifecycleManager {
 	cfg := conf.Serve.Management
@@ -45,7 +52,7 @@ func newLifecycleManager(
 	return &fxlcm.LifecycleManager{
 		ServiceName:    "Management",
 		ServiceAddress: cfg.Address(),
-		Server:         newService(conf, logger, signer),
+		Server:         newService(conf, logger, cch, repository, inventory, registry, signer),
 		Logger:         logger,
 		TLSConf:        cfg.TLS,
 	}
@@ -0,0 +1,67 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"net/http"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
+)
+
+// providersHandler exposes the synchronization status of the configured rule providers and
+// gates the readiness of heimdall on it, if configured.
+type providersHandler struct {
+	r    *syncstatus.Registry
+	gate bool
+	eh   errorhandler.ErrorHandler
+}
+
+func (h *providersHandler) providers(rw http.ResponseWriter, req *http.Request) {
+	h.respond(rw, req, http.StatusOK, h.r.Status())
+}
+
+func (h *providersHandler) ready(rw http.ResponseWriter, req *http.Request) {
+	type status struct {
+		Status string `json:"status"`
+	}
+
+	if h.gate && h.r != nil && !h.r.Ready() {
+		h.respond(rw, req, http.StatusServiceUnavailable, status{Status: "not ready"})
+
+		return
+	}
+
+	h.respond(rw, req, http.StatusOK, status{Status: "ok"})
+}
+
+func (h *providersHandler) respond(rw http.ResponseWriter, req *http.Request, code int, value any) {
+	res, err := json.Marshal(value)
+	if err != nil {
+		zerolog.Ctx(req.Context()).Error().Err(err).Msg("Failed to marshal status object")
+		h.eh.HandleError(rw, req, err)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	_, _ = rw.Write(res)
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/handler/middleware/http/errorhandler"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
+)
+
+func TestProvidersEndpoints(t *testing.T) {
+	t.Parallel()
+
+	enabled := &config.ProtectedEndpoint{Enabled: true, Tokens: []string{"foo"}}
+
+	for _, tc := range []struct {
+		uc             string
+		conf           *config.ProtectedEndpoint
+		token          string
+		endpoint       string
+		readinessCheck bool
+		noRegistry     bool
+		synced         bool
+		assert         func(t *testing.T, resp *http.Response)
+	}{
+		{
+			uc:         "providers endpoint not available without registry",
+			conf:       enabled,
+			token:      "foo",
+			endpoint:   EndpointProviders,
+			noRegistry: true,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			uc:       "providers endpoint disabled",
+			token:    "foo",
+			endpoint: EndpointProviders,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			uc:       "list providers without token",
+			conf:     enabled,
+			endpoint: EndpointProviders,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+				assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+			},
+		},
+		{
+			uc:       "list providers with wrong token",
+			conf:     enabled,
+			token:    "bar",
+			endpoint: EndpointProviders,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
+		{
+			uc:       "list providers",
+			conf:     enabled,
+			token:    "foo",
+			endpoint: EndpointProviders,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				require.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+				var status []syncstatus.Status
+
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+				require.Len(t, status, 1)
+				assert.Equal(t, "test", status[0].Provider)
+				assert.False(t, status[0].Ready)
+			},
+		},
+		{
+			uc:       "ready without readiness check even if not synced",
+			conf:     enabled,
+			endpoint: EndpointReady,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			uc:             "not ready with readiness check if not synced",
+			endpoint:       EndpointReady,
+			readinessCheck: true,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"status":"not ready"}`, string(body))
+			},
+		},
+		{
+			uc:             "ready with readiness check if synced",
+			endpoint:       EndpointReady,
+			readinessCheck: true,
+			synced:         true,
+			assert: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"status":"ok"}`, string(body))
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			var registry *syncstatus.Registry
+
+			if !tc.noRegistry {
+				registry = syncstatus.NewRegistry(zerolog.Nop())
+				tracker := registry.Register("test")
+
+				if tc.synced {
+					tracker.Synced("test")
+				}
+			}
+
+			handler := newManagementHandler(
+				config.ServiceConfig{Providers: tc.conf},
+				log.Logger,
+				nil,
+				nil,
+				nil,
+				registry,
+				tc.readinessCheck,
+				mocks.NewJWTSignerMock(t),
+				errorhandler.New(),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "http://heimdall.local"+tc.endpoint, nil)
+			if len(tc.token) != 0 {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			rec := httptest.NewRecorder()
+
+			// WHEN
+			handler.ServeHTTP(rec, req)
+
+			// THEN
+			resp := rec.Result()
+			defer resp.Body.Close()
+
+			tc.assert(t, resp)
+		})
+	}
+}
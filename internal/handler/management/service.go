@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
+	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/accesslog"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/dump"
@@ -19,6 +20,8 @@ import (
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/passthrough"
 	"github.com/dadrus/heimdall/internal/handler/middleware/http/recovery"
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
+	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/httpx"
 	"github.com/dadrus/heimdall/internal/x/loggeradapter"
@@ -27,11 +30,17 @@ import (
 func newService(
 	conf *config.Configuration,
 	log zerolog.Logger,
+	cch cache.Cache,
+	repository rule.Repository,
+	inventory rule.Inventory,
+	registry *syncstatus.Registry,
 	signer heimdall.JWTSigner,
 ) *http.Server {
 	cfg := conf.Serve.Management
 	eh := errorhandler2.New()
-	opFilter := func(req *http.Request) bool { return req.URL.Path != EndpointHealth }
+	opFilter := func(req *http.Request) bool {
+		return req.URL.Path != EndpointHealth && req.URL.Path != EndpointReady
+	}
 
 	hc := alice.New(
 		accesslog.New(log),
@@ -66,7 +75,8 @@ func newService(
 			},
 			func() func(http.Handler) http.Handler { return passthrough.New },
 		),
-	).Then(newManagementHandler(signer, eh))
+	).Then(newManagementHandler(
+		cfg, log, cch, repository, inventory, registry, conf.Providers.ReadinessCheck, signer, eh))
 
 	return &http.Server{
 		Handler:        hc,
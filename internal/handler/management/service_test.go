@@ -128,7 +128,7 @@ func (suite *ServiceTestSuite) SetupTest() {
 	suite.addr = "http://" + listener.Addr().String()
 
 	suite.signer = mocks.NewJWTSignerMock(suite.T())
-	suite.srv = newService(conf, log.Logger, suite.signer)
+	suite.srv = newService(conf, log.Logger, nil, nil, nil, nil, suite.signer)
 
 	go func() {
 		err = suite.srv.Serve(listener)
@@ -19,6 +19,8 @@ package errorhandler
 import (
 	"context"
 	"errors"
+	"math"
+	"strconv"
 
 	envoy_core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
@@ -92,6 +94,30 @@ func (h *interceptor) intercept(
 				},
 			},
 		}, nil
+	case errors.Is(err, &heimdall.TooManyRequestsError{}):
+		var tooManyRequestsError *heimdall.TooManyRequestsError
+
+		errors.As(err, &tooManyRequestsError)
+
+		var headers []*envoy_core.HeaderValueOption
+		if tooManyRequestsError.RetryAfter > 0 {
+			headers = append(headers, &envoy_core.HeaderValueOption{
+				Header: &envoy_core.HeaderValue{
+					Key:   "Retry-After",
+					Value: strconv.FormatInt(int64(math.Ceil(tooManyRequestsError.RetryAfter.Seconds())), 10),
+				},
+			})
+		}
+
+		return &envoy_auth.CheckResponse{
+			Status: &status.Status{Code: int32(codes.ResourceExhausted)},
+			HttpResponse: &envoy_auth.CheckResponse_DeniedResponse{
+				DeniedResponse: &envoy_auth.DeniedHttpResponse{
+					Status:  &envoy_type.HttpStatus{Code: envoy_type.StatusCode_TooManyRequests},
+					Headers: headers,
+				},
+			},
+		}, nil
 
 	default:
 		logger := zerolog.Ctx(ctx)
@@ -21,6 +21,7 @@ import (
 	"net"
 	"net/http"
 	"testing"
+	"time"
 
 	envoy_auth "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type/v3"
@@ -222,6 +223,13 @@ func TestErrorInterceptor(t *testing.T) {
 			expGRPCCode: codes.FailedPrecondition,
 			expHTTPCode: http.StatusFound,
 		},
+		{
+			uc:          "too many requests error",
+			interceptor: New(),
+			err:         &heimdall.TooManyRequestsError{RetryAfter: 10 * time.Second},
+			expGRPCCode: codes.ResourceExhausted,
+			expHTTPCode: http.StatusTooManyRequests,
+		},
 		{
 			uc:          "internal error default",
 			interceptor: New(),
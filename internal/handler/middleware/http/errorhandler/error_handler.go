@@ -18,7 +18,10 @@ package errorhandler
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -71,6 +74,16 @@ func (h *errorHandler) HandleError(rw http.ResponseWriter, req *http.Request, er
 		rw.WriteHeader(redirectError.Code)
 
 		return
+	case errors.Is(err, &heimdall.TooManyRequestsError{}):
+		var tooManyRequestsError *heimdall.TooManyRequestsError
+
+		errors.As(err, &tooManyRequestsError)
+
+		if retryAfter := retryAfterSeconds(tooManyRequestsError.RetryAfter); len(retryAfter) != 0 {
+			rw.Header().Set("Retry-After", retryAfter)
+		}
+
+		rw.WriteHeader(http.StatusTooManyRequests)
 	default:
 		logger := zerolog.Ctx(ctx)
 		logger.Error().Err(err).Msg("Internal error occurred")
@@ -80,3 +93,11 @@ func (h *errorHandler) HandleError(rw http.ResponseWriter, req *http.Request, er
 
 	accesscontext.SetError(ctx, err)
 }
+
+func retryAfterSeconds(duration time.Duration) string {
+	if duration <= 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(int64(math.Ceil(duration.Seconds())), 10)
+}
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -186,6 +187,14 @@ func TestHandlerHandle(t *testing.T) {
 			err:     &heimdall.RedirectError{RedirectTo: "http://foo.local", Code: http.StatusFound},
 			expCode: http.StatusFound,
 		},
+		{
+			uc:      "too many requests error",
+			handler: New(),
+			err: errorchain.New(&heimdall.TooManyRequestsError{
+				Message: "rate limit exceeded", RetryAfter: 1500 * time.Millisecond,
+			}),
+			expCode: http.StatusTooManyRequests,
+		},
 		{
 			uc:      "internal error default",
 			handler: New(),
@@ -222,3 +231,30 @@ func TestHandlerHandle(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerHandleTooManyRequestsErrorSetsRetryAfterHeader(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc            string
+		retryAfter    time.Duration
+		expRetryAfter string
+	}{
+		{uc: "without retry after", retryAfter: 0, expRetryAfter: ""},
+		{uc: "with retry after rounded up", retryAfter: 1500 * time.Millisecond, expRetryAfter: "2"},
+		{uc: "with retry after of full seconds", retryAfter: 30 * time.Second, expRetryAfter: "30"},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+
+			// WHEN
+			New().HandleError(recorder, req, &heimdall.TooManyRequestsError{RetryAfter: tc.retryAfter})
+
+			// THEN
+			assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			assert.Equal(t, tc.expRetryAfter, recorder.Header().Get("Retry-After"))
+		})
+	}
+}
@@ -34,6 +34,7 @@ var untrustedHeader = []string{ //nolint:gochecknoglobals
 	"X-Forwarded-Uri",
 	"X-Forwarded-Path",
 	"X-Forwarded-Method",
+	"X-Forwarded-Client-Cert",
 }
 
 type ipHolder interface {
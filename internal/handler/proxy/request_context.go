@@ -75,9 +75,11 @@ func (r *requestContext) Finalize(upstream rule.Backend) error {
 		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "No upstream reference defined")
 	}
 
+	targetURL := upstream.URL()
+
 	logger.Info().
 		Str("_method", r.Request().Method).
-		Str("_upstream", upstream.URL().String()).
+		Str("_upstream", targetURL.String()).
 		Msg("Forwarding request")
 
 	errHolder := struct{ err error }{}
@@ -89,7 +91,12 @@ func (r *requestContext) Finalize(upstream rule.Backend) error {
 			errHolder.err = errorchain.NewWithMessage(heimdall.ErrCommunication, "Failed to proxy request").
 				CausedBy(err)
 		},
-		Rewrite: r.rewriteRequest(upstream.URL()),
+		ModifyResponse: func(resp *http.Response) error {
+			upstream.Done(upstreamResponseError(resp))
+
+			return nil
+		},
+		Rewrite: r.rewriteRequest(targetURL, upstream.HostHeader()),
 		Transport: otelhttp.NewTransport(
 			httpx.NewTraceRoundTripper(r.transport),
 			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
@@ -99,15 +106,31 @@ func (r *requestContext) Finalize(upstream rule.Backend) error {
 
 	proxy.ServeHTTP(r.rw, r.req)
 
-	// set in the proxy error handler above
+	if errHolder.err != nil {
+		// set in the proxy error handler above
+		upstream.Done(errHolder.err)
+	}
+
 	return errHolder.err
 }
 
-func (r *requestContext) rewriteRequest(targetURL *url.URL) func(req *httputil.ProxyRequest) {
+// upstreamResponseError returns an error if the response indicates the upstream to be unable
+// to serve requests, so that it is accounted as failed by the passive health checking.
+func upstreamResponseError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"upstream responded with %d", resp.StatusCode)
+	default:
+		return nil
+	}
+}
+
+func (r *requestContext) rewriteRequest(targetURL *url.URL, hostHeader string) func(req *httputil.ProxyRequest) {
 	return func(proxyReq *httputil.ProxyRequest) {
 		proxyReq.Out.Method = r.Request().Method
 		proxyReq.Out.URL = targetURL
-		proxyReq.Out.Host = targetURL.Host
+		proxyReq.Out.Host = x.IfThenElse(len(hostHeader) != 0, hostHeader, targetURL.Host)
 
 		// delete headers, which are useless for the upstream service, before forwarding the request
 		proxyReq.Out.Header.Del("X-Forwarded-Method")
@@ -26,10 +26,12 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/handler/requestcontext"
+	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	mocks2 "github.com/dadrus/heimdall/internal/rules/rule/mocks"
 )
@@ -63,6 +65,8 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				return backend
 			},
@@ -95,6 +99,8 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				return backend
 			},
@@ -124,6 +130,8 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				return backend
 			},
@@ -156,6 +164,8 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				return backend
 			},
@@ -187,6 +197,8 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				return backend
 			},
@@ -202,6 +214,27 @@ func TestRequestContextFinalize(t *testing.T) {
 				assert.Equal(t, "for=192.0.2.1;host=foo.bar;proto=https", req.Header.Get("Forwarded"))
 			},
 		},
+		{
+			uc:             "Host header is overridden by the backend",
+			upstreamCalled: true,
+			setup: func(t *testing.T, _ requestcontext.Context, upstreamURL *url.URL) rule.Backend {
+				t.Helper()
+
+				backend := mocks2.NewBackendMock(t)
+				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("baz.bar")
+				backend.EXPECT().Done(nil)
+
+				return backend
+			},
+			assertRequest: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				assert.Equal(t, "baz.bar", req.Host)
+				assert.Equal(t, http.MethodGet, req.Method)
+				assert.Equal(t, "for=192.0.2.1;host=foo.bar;proto=https", req.Header.Get("Forwarded"))
+			},
+		},
 		{
 			uc:             "Only X-Forwarded-Proto header is present",
 			upstreamCalled: true,
@@ -213,6 +246,8 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				return backend
 			},
@@ -241,6 +276,8 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				return backend
 			},
@@ -269,6 +306,8 @@ func TestRequestContextFinalize(t *testing.T) {
 
 				backend := mocks2.NewBackendMock(t)
 				backend.EXPECT().URL().Return(upstreamURL)
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				return backend
 			},
@@ -323,3 +362,95 @@ func TestRequestContextFinalize(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestContextFinalizeReportsUpstreamOutcome(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc           string
+		responseCode int
+		unreachable  bool
+		assert       func(t *testing.T, err error, reported error)
+	}{
+		{
+			uc:           "upstream responded successfully",
+			responseCode: http.StatusOK,
+			assert: func(t *testing.T, err error, reported error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NoError(t, reported)
+			},
+		},
+		{
+			uc:           "upstream responded with client error",
+			responseCode: http.StatusNotFound,
+			assert: func(t *testing.T, err error, reported error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NoError(t, reported)
+			},
+		},
+		{
+			uc:           "upstream responded as unavailable",
+			responseCode: http.StatusServiceUnavailable,
+			assert: func(t *testing.T, err error, reported error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Error(t, reported)
+				require.ErrorIs(t, reported, heimdall.ErrCommunication)
+				assert.Contains(t, reported.Error(), "503")
+			},
+		},
+		{
+			uc:          "upstream not reachable",
+			unreachable: true,
+			assert: func(t *testing.T, err error, reported error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Equal(t, err, reported)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			var reported error
+
+			req := httptest.NewRequest(http.MethodGet, "https://foo.bar/test", nil)
+			rw := httptest.NewRecorder()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(tc.responseCode)
+			}))
+
+			targetURL, err := url.Parse(srv.URL)
+			require.NoError(t, err)
+
+			if tc.unreachable {
+				srv.Close()
+			} else {
+				defer srv.Close()
+			}
+
+			ctx := newContextFactory(nil, config.ServiceConfig{Timeout: config.Timeout{Read: time.Second}}, nil).
+				Create(rw, req)
+
+			backend := mocks2.NewBackendMock(t)
+			backend.EXPECT().URL().Return(targetURL)
+			backend.EXPECT().HostHeader().Return("")
+			backend.EXPECT().Done(mock.Anything).Call.Run(func(args mock.Arguments) {
+				reported, _ = args.Get(0).(error)
+			})
+
+			// WHEN
+			err = ctx.Finalize(backend)
+
+			// THEN
+			tc.assert(t, err, reported)
+		})
+	}
+}
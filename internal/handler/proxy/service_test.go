@@ -304,6 +304,8 @@ func TestProxyService(t *testing.T) {
 					Host:   upstreamURL.Host,
 					Path:   "/foobar",
 				})
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.Context) bool {
@@ -387,6 +389,8 @@ func TestProxyService(t *testing.T) {
 					Host:   upstreamURL.Host,
 					Path:   "/[id]/foobar",
 				})
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.Context) bool {
@@ -469,6 +473,8 @@ func TestProxyService(t *testing.T) {
 					Host:   upstreamURL.Host,
 					Path:   "/[barfoo]",
 				})
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.Context) bool {
@@ -557,6 +563,8 @@ func TestProxyService(t *testing.T) {
 					Host:   upstreamURL.Host,
 					Path:   "/bar",
 				})
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.Context) bool {
@@ -733,6 +741,8 @@ func TestProxyService(t *testing.T) {
 					Host:   upstreamURL.Host,
 					Path:   "/bar",
 				})
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.Context) bool {
@@ -828,6 +838,8 @@ func TestProxyService(t *testing.T) {
 					Host:   upstreamURL.Host,
 					Path:   "/bar",
 				})
+				backend.EXPECT().HostHeader().Return("")
+				backend.EXPECT().Done(nil)
 
 				exec.EXPECT().Execute(
 					mock.MatchedBy(func(ctx heimdall.Context) bool {
@@ -1022,6 +1034,8 @@ func TestWebSocketSupport(t *testing.T) {
 		Host:   upstreamURL.Host,
 		Path:   "/bar",
 	})
+	backend.EXPECT().HostHeader().Return("")
+	backend.EXPECT().Done(nil)
 
 	exec.EXPECT().Execute(
 		mock.MatchedBy(func(ctx heimdall.Context) bool {
@@ -1122,6 +1136,8 @@ func TestServerSentEventsSupport(t *testing.T) {
 		Host:   upstreamURL.Host,
 		Path:   "/bar",
 	})
+	backend.EXPECT().HostHeader().Return("")
+	backend.EXPECT().Done(nil)
 
 	exec.EXPECT().Execute(
 		mock.MatchedBy(func(ctx heimdall.Context) bool {
@@ -0,0 +1,119 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package requestcontext
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const headerForwardedClientCert = "X-Forwarded-Client-Cert"
+
+// extractClientCertificates returns the client certificate chain from the X-Forwarded-Client-Cert header,
+// or, if not present, from the TLS connection. The header is only present if the request has been received
+// from a trusted proxy. Such a proxy may authenticate itself to heimdall with its own certificate, so the
+// header, which holds the certificate of the actual client, takes precedence.
+func extractClientCertificates(req *http.Request) []*x509.Certificate {
+	if val := req.Header.Get(headerForwardedClientCert); len(val) != 0 {
+		return parseForwardedClientCert(val)
+	}
+
+	if req.TLS != nil && len(req.TLS.PeerCertificates) != 0 {
+		return req.TLS.PeerCertificates
+	}
+
+	return nil
+}
+
+// parseForwardedClientCert supports the format used by envoy, which is a list of elements with
+// the Cert and Chain keys holding URL encoded PEM values, as well as a plain URL encoded PEM value
+// (as e.g. set by nginx). If there are multiple elements, the last one, which has been added by the
+// proxy in front of heimdall, is used.
+func parseForwardedClientCert(value string) []*x509.Certificate {
+	if strings.HasPrefix(value, "-----BEGIN") || strings.HasPrefix(strings.ToUpper(value), "%2D%2D%2D%2D%2DBEGIN") {
+		return decodeCertificates(value)
+	}
+
+	elements := splitQuoted(value, ',')
+
+	var cert, chain string
+
+	for _, pair := range splitQuoted(elements[len(elements)-1], ';') {
+		key, val, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "cert":
+			cert = strings.Trim(strings.TrimSpace(val), `"`)
+		case "chain":
+			chain = strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+
+	// chain includes the leaf certificate as well
+	if len(chain) != 0 {
+		return decodeCertificates(chain)
+	}
+
+	if len(cert) != 0 {
+		return decodeCertificates(cert)
+	}
+
+	return nil
+}
+
+func decodeCertificates(value string) []*x509.Certificate {
+	// PathUnescape is used by intention, as "+" is a valid character in base64 encoded values
+	decoded, err := url.PathUnescape(value)
+	if err != nil {
+		return nil
+	}
+
+	certs, err := truststore.NewTrustStoreFromPEMBytes(stringx.ToBytes(decoded), false)
+	if err != nil || len(certs) == 0 {
+		return nil
+	}
+
+	return certs
+}
+
+func splitQuoted(value string, sep rune) []string {
+	var (
+		parts    []string
+		inQuotes bool
+		start    int
+	)
+
+	for idx, char := range value {
+		switch {
+		case char == '"':
+			inQuotes = !inQuotes
+		case char == sep && !inQuotes:
+			parts = append(parts, value[start:idx])
+			start = idx + 1
+		}
+	}
+
+	return append(parts, value[start:])
+}
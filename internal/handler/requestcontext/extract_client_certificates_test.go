@@ -0,0 +1,181 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package requestcontext
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestExtractClientCertificates(t *testing.T) {
+	t.Parallel()
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour)
+	require.NoError(t, err)
+
+	privKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	eeCert, err := rootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "Test Client"}),
+		testsupport.WithValidity(time.Now(), time.Hour),
+		testsupport.WithSubjectPubKey(&privKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature))
+	require.NoError(t, err)
+
+	certPEM, err := pemx.BuildPEM(pemx.WithX509Certificate(eeCert))
+	require.NoError(t, err)
+
+	chainPEM, err := pemx.BuildPEM(pemx.WithX509Certificate(eeCert), pemx.WithX509Certificate(rootCA.Certificate))
+	require.NoError(t, err)
+
+	encodedCert := url.PathEscape(stringx.ToString(certPEM))
+	encodedChain := url.PathEscape(stringx.ToString(chainPEM))
+
+	for _, tc := range []struct {
+		uc     string
+		modify func(t *testing.T, req *http.Request)
+		assert func(t *testing.T, certs []*x509.Certificate)
+	}{
+		{
+			uc:     "no client certificate present",
+			modify: func(t *testing.T, _ *http.Request) { t.Helper() },
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				assert.Empty(t, certs)
+			},
+		},
+		{
+			uc: "from tls connection state",
+			modify: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{eeCert}}
+			},
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.Len(t, certs, 1)
+				assert.Equal(t, eeCert, certs[0])
+			},
+		},
+		{
+			uc: "header set by trusted proxy takes precedence over tls connection state",
+			modify: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				// the proxy authenticates itself to heimdall with its own certificate
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{rootCA.Certificate}}
+				req.Header.Set("X-Forwarded-Client-Cert", encodedCert)
+			},
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.Len(t, certs, 1)
+				assert.Equal(t, eeCert.Raw, certs[0].Raw)
+			},
+		},
+		{
+			uc: "from header with url encoded pem",
+			modify: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				req.Header.Set("X-Forwarded-Client-Cert", encodedChain)
+			},
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.Len(t, certs, 2)
+				assert.Equal(t, eeCert.Raw, certs[0].Raw)
+				assert.Equal(t, rootCA.Certificate.Raw, certs[1].Raw)
+			},
+		},
+		{
+			uc: "from header in envoy format with cert only",
+			modify: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				req.Header.Set("X-Forwarded-Client-Cert",
+					`By=spiffe://foo;Hash=abc;Subject="CN=Test Client,O=Test";Cert="`+encodedCert+`"`)
+			},
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.Len(t, certs, 1)
+				assert.Equal(t, eeCert.Raw, certs[0].Raw)
+			},
+		},
+		{
+			uc: "from header in envoy format with multiple elements, cert and chain",
+			modify: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				req.Header.Set("X-Forwarded-Client-Cert",
+					`By=spiffe://bar;Cert="foo",By=spiffe://foo;Cert="`+encodedCert+`";Chain="`+encodedChain+`"`)
+			},
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				require.Len(t, certs, 2)
+				assert.Equal(t, eeCert.Raw, certs[0].Raw)
+				assert.Equal(t, rootCA.Certificate.Raw, certs[1].Raw)
+			},
+		},
+		{
+			uc: "from header with invalid value",
+			modify: func(t *testing.T, req *http.Request) {
+				t.Helper()
+
+				req.Header.Set("X-Forwarded-Client-Cert", `By=spiffe://foo;Cert="foo%ZZ"`)
+			},
+			assert: func(t *testing.T, certs []*x509.Certificate) {
+				t.Helper()
+
+				assert.Empty(t, certs)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			tc.modify(t, req)
+
+			// WHEN
+			certs := extractClientCertificates(req)
+
+			// THEN
+			tc.assert(t, certs)
+		})
+	}
+}
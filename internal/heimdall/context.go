@@ -18,6 +18,7 @@ package heimdall
 
 import (
 	"context"
+	"crypto/x509"
 	"net/url"
 )
 
@@ -49,6 +50,19 @@ type Request struct {
 	RequestFunctions
 
 	Method   string
-	URL      *url.URL
+	URL      *URL
 	ClientIP []string
+
+	// ClientCertificates holds the certificate chain presented by the client, starting with
+	// the leaf certificate. It is either taken from the TLS connection, or from the
+	// X-Forwarded-Client-Cert header set by a trusted proxy.
+	ClientCertificates []*x509.Certificate
+}
+
+// URL is the url of the request to be processed. In addition to the url itself, it holds
+// the values captured by the url pattern of the matched rule.
+type URL struct {
+	url.URL
+
+	Captures map[string]string
 }
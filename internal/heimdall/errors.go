@@ -19,6 +19,7 @@ package heimdall
 import (
 	"errors"
 	"reflect"
+	"time"
 )
 
 var (
@@ -42,3 +43,14 @@ type RedirectError struct {
 func (e *RedirectError) Error() string { return e.Message }
 
 func (e *RedirectError) Is(target error) bool { return reflect.TypeOf(e) == reflect.TypeOf(target) }
+
+type TooManyRequestsError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string { return e.Message }
+
+func (e *TooManyRequestsError) Is(target error) bool {
+	return reflect.TypeOf(e) == reflect.TypeOf(target)
+}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/pquerna/cachecontrol"
+	"github.com/pquerna/cachecontrol/cacheobject"
 
 	"github.com/dadrus/heimdall/internal/cache"
 	"github.com/dadrus/heimdall/internal/x/stringx"
@@ -43,12 +44,15 @@ type RoundTripper struct {
 }
 
 func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	resp, err := rt.cachedResponse(req)
-	if err == nil {
-		return resp, nil
+	// a request with a no-cache directive must be served by the origin server. The
+	// received response still replaces the cached one.
+	if !noCache(req) {
+		if resp, err := rt.cachedResponse(req); err == nil {
+			return resp, nil
+		}
 	}
 
-	resp, err = rt.Transport.RoundTrip(req)
+	resp, err := rt.Transport.RoundTrip(req)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +95,12 @@ func (rt *RoundTripper) cacheResponse(req *http.Request, resp *http.Response) {
 	cch.Set(cacheKey(req), respDump, time.Until(expires))
 }
 
+func noCache(req *http.Request) bool {
+	directives, err := cacheobject.ParseRequestCacheControl(req.Header.Get("Cache-Control"))
+
+	return err == nil && directives.NoCache
+}
+
 func cacheKey(req *http.Request) string {
 	hash := sha256.New()
 
@@ -54,10 +54,12 @@ func TestRoundTripperRoundTrip(t *testing.T) {
 	for _, tc := range []struct {
 		uc               string
 		setExpiresHeader bool
+		noCache          bool
 		requestCounts    int
 	}{
 		{uc: "should cache response", setExpiresHeader: true, requestCounts: 1},
 		{uc: "should not cache response", setExpiresHeader: false, requestCounts: 4},
+		{uc: "should bypass cache for no-cache requests", setExpiresHeader: true, noCache: true, requestCounts: 4},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -72,6 +74,10 @@ func TestRoundTripperRoundTrip(t *testing.T) {
 			req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
 			require.NoError(t, err)
 
+			if tc.noCache {
+				req.Header.Set("Cache-Control", "no-cache")
+			}
+
 			for c := 0; c < 4; c++ {
 				resp, err := client.Do(req)
 				require.NoError(t, err)
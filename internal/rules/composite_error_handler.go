@@ -19,6 +19,7 @@ package rules
 import (
 	"github.com/rs/zerolog"
 
+	"github.com/dadrus/heimdall/internal/explain"
 	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
@@ -34,7 +35,14 @@ func (eh compositeErrorHandler) Execute(ctx heimdall.Context, exErr error) (bool
 	)
 
 	for _, eh := range eh {
-		ok, err = eh.Execute(ctx, exErr)
+		err = executeStep(ctx, "error_handler", eh, func(ctx heimdall.Context) error {
+			ok, err = eh.Execute(ctx, exErr)
+			if step := explainStep(ctx); step != nil && err == nil && !ok {
+				step.Outcome = explain.OutcomeSkipped
+			}
+
+			return err
+		})
 		if err != nil {
 			logger.Error().Err(err).
 				Msg("Failed to execute error handler. Falling back to the next or the default one")
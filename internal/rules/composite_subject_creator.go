@@ -37,7 +37,11 @@ func (ca compositeSubjectCreator) Execute(ctx heimdall.Context) (*subject.Subjec
 	)
 
 	for idx, a := range ca {
-		sub, err = a.Execute(ctx)
+		err = executeStep(ctx, "authenticator", a, func(ctx heimdall.Context) error {
+			sub, err = a.Execute(ctx)
+
+			return err
+		})
 		if err != nil {
 			logger.Info().Err(err).Msg("Pipeline step execution failed")
 
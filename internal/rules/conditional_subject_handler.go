@@ -20,14 +20,16 @@ import (
 	"github.com/goccy/go-json"
 	"github.com/rs/zerolog"
 
+	"github.com/dadrus/heimdall/internal/explain"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	"github.com/dadrus/heimdall/internal/x/stringx"
 )
 
 type conditionalSubjectHandler struct {
-	h subjectHandler
-	c executionCondition
+	typ string
+	h   subjectHandler
+	c   executionCondition
 }
 
 func (h *conditionalSubjectHandler) Execute(ctx heimdall.Context, sub *subject.Subject) error {
@@ -44,15 +46,21 @@ func (h *conditionalSubjectHandler) Execute(ctx heimdall.Context, sub *subject.S
 		}
 	}
 
-	if canExecute, err := h.c.CanExecute(ctx, sub); err != nil {
-		return err
-	} else if canExecute {
-		return h.h.Execute(ctx, sub)
-	}
+	return executeStep(ctx, h.typ, h.h, func(ctx heimdall.Context) error {
+		if canExecute, err := h.c.CanExecute(ctx, sub); err != nil {
+			return err
+		} else if canExecute {
+			return h.h.Execute(ctx, sub)
+		}
 
-	logger.Debug().Str("_id", h.h.ID()).Msg("Execution skipped")
+		logger.Debug().Str("_id", h.h.ID()).Msg("Execution skipped")
+
+		if step := explainStep(ctx); step != nil {
+			step.Outcome = explain.OutcomeSkipped
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (h *conditionalSubjectHandler) ID() string { return h.h.ID() }
@@ -18,25 +18,63 @@ package config
 
 import (
 	"net/url"
+	"time"
 
 	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
 type Backend struct {
-	Host        string       `json:"host"    yaml:"host"`
-	URLRewriter *URLRewriter `json:"rewrite" yaml:"rewrite"`
+	Host          string         `json:"host"           yaml:"host"`
+	Upstreams     []Upstream     `json:"upstreams"      yaml:"upstreams"`
+	LoadBalancing *LoadBalancing `json:"load_balancing" yaml:"load_balancing"`
+	URLRewriter   *URLRewriter   `json:"rewrite"        yaml:"rewrite"`
+}
+
+type Upstream struct {
+	Host string `json:"host"   yaml:"host"`
+	// Weight defaults to 1 if not set. An explicit 0 means the upstream receives no traffic.
+	Weight *int `json:"weight" yaml:"weight"`
+}
+
+type LoadBalancing struct {
+	Policy      string              `json:"policy"               yaml:"policy"`
+	HealthCheck *PassiveHealthCheck `json:"passive_health_check" yaml:"passive_health_check"`
+}
+
+type PassiveHealthCheck struct {
+	MaxFailures  int           `json:"max_failures"  yaml:"max_failures"`
+	EjectionTime time.Duration `json:"ejection_time" yaml:"ejection_time"`
+}
+
+func (c *PassiveHealthCheck) UnmarshalJSON(data []byte) error {
+	var rawData map[string]any
+
+	if err := json.Unmarshal(data, &rawData); err != nil {
+		return err
+	}
+
+	return DecodeConfig(rawData, c)
+}
+
+// CreateURL creates the url to forward the request to by using the configured host.
+func (f *Backend) CreateURL(value *heimdall.URL) *url.URL {
+	return f.CreateURLFor(f.Host, value)
 }
 
-func (f *Backend) CreateURL(value *url.URL) *url.URL {
+// CreateURLFor creates the url to forward the request to by using the given host, which
+// is expected to be either the configured host, or one of the configured upstreams.
+func (f *Backend) CreateURLFor(host string, value *heimdall.URL) *url.URL {
 	upstreamURL := &url.URL{
 		Scheme:   value.Scheme,
-		Host:     f.Host,
+		Host:     host,
 		Path:     value.Path,
 		RawQuery: value.RawQuery,
 	}
 
 	if f.URLRewriter != nil {
-		f.URLRewriter.Rewrite(upstreamURL)
+		f.URLRewriter.Rewrite(upstreamURL, value.Captures)
 	}
 
 	return upstreamURL
@@ -19,9 +19,13 @@ package config
 import (
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
 func TestUpstreamURLFactoryCreateURL(t *testing.T) {
@@ -58,7 +62,7 @@ func TestUpstreamURLFactoryCreateURL(t *testing.T) {
 			require.NoError(t, err)
 
 			// WHEN
-			result := tc.factory.CreateURL(requestURL)
+			result := tc.factory.CreateURL(&heimdall.URL{URL: *requestURL})
 
 			// THEN
 			assert.Equal(t, tc.expected, result.String())
@@ -66,14 +70,101 @@ func TestUpstreamURLFactoryCreateURL(t *testing.T) {
 	}
 }
 
+func TestUpstreamURLFactoryCreateURLFor(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	factory := &Backend{
+		Upstreams:   []Upstream{{Host: "bar.foo"}, {Host: "baz.foo"}},
+		URLRewriter: &URLRewriter{Scheme: "https"},
+	}
+
+	requestURL, err := url.Parse("http://foo.bar/foo/bar?baz=bar")
+	require.NoError(t, err)
+
+	// WHEN
+	result := factory.CreateURLFor("baz.foo", &heimdall.URL{URL: *requestURL})
+
+	// THEN
+	assert.Equal(t, "https://baz.foo/foo/bar?baz=bar", result.String())
+}
+
+func TestPassiveHealthCheckUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, hc *PassiveHealthCheck)
+	}{
+		{
+			uc:     "with duration given as string",
+			config: []byte(`{ "max_failures": 3, "ejection_time": "1m" }`),
+			assert: func(t *testing.T, err error, hc *PassiveHealthCheck) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 3, hc.MaxFailures)
+				assert.Equal(t, time.Minute, hc.EjectionTime)
+			},
+		},
+		{
+			uc:     "with duration given in nanoseconds",
+			config: []byte(`{ "ejection_time": 1000000000 }`),
+			assert: func(t *testing.T, err error, hc *PassiveHealthCheck) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, 0, hc.MaxFailures)
+				assert.Equal(t, time.Second, hc.EjectionTime)
+			},
+		},
+		{
+			uc:     "with unknown property",
+			config: []byte(`{ "foo": "bar" }`),
+			assert: func(t *testing.T, err error, _ *PassiveHealthCheck) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		{
+			uc:     "with malformed json",
+			config: []byte(`{ "max_failures": `),
+			assert: func(t *testing.T, err error, _ *PassiveHealthCheck) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			var hc PassiveHealthCheck
+
+			// WHEN
+			err := json.Unmarshal(tc.config, &hc)
+
+			// THEN
+			tc.assert(t, err, &hc)
+		})
+	}
+}
+
 func TestUpstreamURLFactoryDeepCopyInto(t *testing.T) {
 	t.Parallel()
 
 	// GIVEN
 	var out Backend
 
+	weight := 0
+
 	in := Backend{
-		Host: "bar.foo",
+		Host:      "bar.foo",
+		Upstreams: []Upstream{{Host: "foo.bar", Weight: &weight}, {Host: "baz.bar"}},
+		LoadBalancing: &LoadBalancing{
+			Policy:      "consistent_hash",
+			HealthCheck: &PassiveHealthCheck{MaxFailures: 3, EjectionTime: 10 * time.Second},
+		},
 		URLRewriter: &URLRewriter{
 			Scheme:              "https",
 			PathPrefixToCut:     "/foo",
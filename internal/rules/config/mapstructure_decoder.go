@@ -19,6 +19,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 
 	"github.com/dadrus/heimdall/internal/x"
@@ -69,13 +70,31 @@ func matcherDecodeHookFunc(from reflect.Type, to reflect.Type, data any) (any, e
 			return nil, ErrStrategyType
 		}
 
-		if strategyValue != "glob" && strategyValue != "regex" {
+		if strategyValue != "glob" && strategyValue != "regex" && strategyValue != "template" {
 			return nil, fmt.Errorf("%w: %s", ErrUnsupportedStrategy, strategyValue)
 		}
 	}
 
+	// all other properties are optional conditions, the request must satisfy in addition
+	var conditions struct {
+		Headers     map[string][]string `json:"headers"`
+		QueryParams map[string][]string `json:"query_params"`
+		ClientIPs   []string            `json:"client_ips"`
+	}
+
+	others := maps.Clone(values)
+	delete(others, "url")
+	delete(others, "strategy")
+
+	if err := DecodeConfig(others, &conditions); err != nil {
+		return nil, err
+	}
+
 	return Matcher{
-		URL:      urlValue,
-		Strategy: x.IfThenElse(strategyPresent, strategyValue, "glob"),
+		URL:         urlValue,
+		Strategy:    x.IfThenElse(strategyPresent, strategyValue, "glob"),
+		Headers:     conditions.Headers,
+		QueryParams: conditions.QueryParams,
+		ClientIPs:   conditions.ClientIPs,
 	}, nil
 }
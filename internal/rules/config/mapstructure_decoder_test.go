@@ -146,6 +146,58 @@ match:
 				assert.Equal(t, "regex", matcher.Strategy)
 			},
 		},
+		{
+			uc: "specified as structured type with template strategy specified",
+			config: []byte(`
+match: 
+  url: foo.bar/:id
+  strategy: template
+`),
+			assert: func(t *testing.T, err error, matcher *Matcher) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo.bar/:id", matcher.URL)
+				assert.Equal(t, "template", matcher.Strategy)
+			},
+		},
+		{
+			uc: "specified as structured type with request conditions",
+			config: []byte(`
+match: 
+  url: foo.bar
+  headers:
+    X-Api-Version: [ "2", "3" ]
+  query_params:
+    format: [ full ]
+  client_ips: [ 10.0.0.0/8 ]
+`),
+			assert: func(t *testing.T, err error, matcher *Matcher) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo.bar", matcher.URL)
+				assert.Equal(t, "glob", matcher.Strategy)
+				assert.Equal(t, map[string][]string{"X-Api-Version": {"2", "3"}}, matcher.Headers)
+				assert.Equal(t, map[string][]string{"format": {"full"}}, matcher.QueryParams)
+				assert.Equal(t, []string{"10.0.0.0/8"}, matcher.ClientIPs)
+			},
+		},
+		{
+			uc: "specified as structured type with unsupported property",
+			config: []byte(`
+match: 
+  url: foo.bar
+  cookies:
+    foo: [ bar ]
+`),
+			assert: func(t *testing.T, err error, _ *Matcher) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "cookies")
+			},
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
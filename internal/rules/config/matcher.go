@@ -23,8 +23,11 @@ import (
 )
 
 type Matcher struct {
-	URL      string `json:"url"      yaml:"url"`
-	Strategy string `json:"strategy" yaml:"strategy"`
+	URL         string              `json:"url"          yaml:"url"`
+	Strategy    string              `json:"strategy"     yaml:"strategy"`
+	Headers     map[string][]string `json:"headers"      yaml:"headers"`
+	QueryParams map[string][]string `json:"query_params" yaml:"query_params"`
+	ClientIPs   []string            `json:"client_ips"   yaml:"client_ips"`
 }
 
 func (m *Matcher) UnmarshalJSON(data []byte) error {
@@ -44,3 +47,36 @@ func (m *Matcher) UnmarshalJSON(data []byte) error {
 
 	return DecodeConfig(rawData, m)
 }
+
+func (m *Matcher) DeepCopyInto(out *Matcher) {
+	*out = *m
+
+	out.Headers = deepCopyValues(m.Headers)
+	out.QueryParams = deepCopyValues(m.QueryParams)
+
+	if m.ClientIPs != nil {
+		out.ClientIPs = make([]string, len(m.ClientIPs))
+		copy(out.ClientIPs, m.ClientIPs)
+	}
+}
+
+func deepCopyValues(in map[string][]string) map[string][]string {
+	if in == nil {
+		return nil
+	}
+
+	out := make(map[string][]string, len(in))
+
+	for key, values := range in {
+		if values == nil {
+			out[key] = nil
+
+			continue
+		}
+
+		out[key] = make([]string, len(values))
+		copy(out[key], values)
+	}
+
+	return out
+}
@@ -22,16 +22,18 @@ import (
 
 type Rule struct {
 	ID           string                   `json:"id"         yaml:"id"`
+	Priority     int                      `json:"priority"   yaml:"priority"`
 	RuleMatcher  Matcher                  `json:"match"      yaml:"match"`
 	Backend      *Backend                 `json:"forward_to" yaml:"forward_to"`
 	Methods      []string                 `json:"methods"    yaml:"methods"`
+	Mode         string                   `json:"mode"       yaml:"mode"`
 	Execute      []config.MechanismConfig `json:"execute"    yaml:"execute"`
 	ErrorHandler []config.MechanismConfig `json:"on_error"   yaml:"on_error"`
 }
 
 func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
-	out.RuleMatcher = in.RuleMatcher
+	in.RuleMatcher.DeepCopyInto(&out.RuleMatcher)
 
 	if in.Backend != nil {
 		in, out := in.Backend, out.Backend
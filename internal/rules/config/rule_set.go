@@ -28,6 +28,9 @@ type MetaData struct {
 	Hash    []byte    `json:"-" yaml:"-"`
 	Source  string    `json:"-" yaml:"-"`
 	ModTime time.Time `json:"-" yaml:"-"`
+	// Revision is the revision of the source the rule set has been loaded from, like
+	// a commit SHA. Empty if the source has no notion of revisions.
+	Revision string `json:"-" yaml:"-"`
 }
 
 type RuleSet struct {
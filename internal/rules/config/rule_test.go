@@ -34,8 +34,11 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 	in := Rule{
 		ID: "foo",
 		RuleMatcher: Matcher{
-			URL:      "bar",
-			Strategy: "glob",
+			URL:         "bar",
+			Strategy:    "glob",
+			Headers:     map[string][]string{"X-Foo": {"bar"}},
+			QueryParams: map[string][]string{"foo": {"bar", "baz"}},
+			ClientIPs:   []string{"10.0.0.0/8"},
 		},
 		Backend: &Backend{
 			Host: "baz",
@@ -59,9 +62,20 @@ func TestRuleConfigDeepCopyInto(t *testing.T) {
 	assert.Equal(t, in.RuleMatcher.URL, out.RuleMatcher.URL)
 	assert.Equal(t, in.Backend, out.Backend)
 	assert.Equal(t, in.RuleMatcher.Strategy, out.RuleMatcher.Strategy)
+	assert.Equal(t, in.RuleMatcher.Headers, out.RuleMatcher.Headers)
+	assert.Equal(t, in.RuleMatcher.QueryParams, out.RuleMatcher.QueryParams)
+	assert.Equal(t, in.RuleMatcher.ClientIPs, out.RuleMatcher.ClientIPs)
 	assert.Equal(t, in.Methods, out.Methods)
 	assert.Equal(t, in.Execute, out.Execute)
 	assert.Equal(t, in.ErrorHandler, out.ErrorHandler)
+
+	// WHEN
+	out.RuleMatcher.Headers["X-Foo"][0] = "baz"
+	out.RuleMatcher.ClientIPs[0] = "192.168.0.0/16"
+
+	// THEN
+	assert.Equal(t, []string{"bar"}, in.RuleMatcher.Headers["X-Foo"])
+	assert.Equal(t, []string{"10.0.0.0/8"}, in.RuleMatcher.ClientIPs)
 }
 
 func TestRuleConfigDeepCopy(t *testing.T) {
@@ -65,27 +65,71 @@ func (r QueryParamsRemover) RemoveFrom(value string) string {
 	return query.Encode()
 }
 
+type RegexSubstitution struct {
+	Pattern     string `json:"pattern"     yaml:"pattern"`
+	Replacement string `json:"replacement" yaml:"replacement"`
+}
+
 type URLRewriter struct {
-	Scheme              string             `json:"scheme"                 yaml:"scheme"`
-	PathPrefixToCut     PrefixCutter       `json:"strip_path_prefix"      yaml:"strip_path_prefix"`
-	PathPrefixToAdd     PrefixAdder        `json:"add_path_prefix"        yaml:"add_path_prefix"`
-	QueryParamsToRemove QueryParamsRemover `json:"strip_query_parameters" yaml:"strip_query_parameters"`
+	Scheme              string             `json:"scheme"                  yaml:"scheme"`
+	PathPrefixToCut     PrefixCutter       `json:"strip_path_prefix"       yaml:"strip_path_prefix"`
+	PathPrefixToAdd     PrefixAdder        `json:"add_path_prefix"         yaml:"add_path_prefix"`
+	PathRegex           *RegexSubstitution `json:"path_regex"              yaml:"path_regex"`
+	PathTemplate        string             `json:"path_template"           yaml:"path_template"`
+	QueryParamsToRemove QueryParamsRemover `json:"strip_query_parameters"  yaml:"strip_query_parameters"`
+	QueryParamsToRename map[string]string  `json:"rename_query_parameters" yaml:"rename_query_parameters"`
+	QueryParamsToAdd    map[string]string  `json:"add_query_parameters"    yaml:"add_query_parameters"`
+	HostHeader          string             `json:"host_header"             yaml:"host_header"`
+}
+
+// IsEmpty returns true if none of the supported rewrite middlewares is configured.
+func (r *URLRewriter) IsEmpty() bool {
+	return len(r.Scheme) == 0 &&
+		len(r.PathPrefixToAdd) == 0 &&
+		len(r.PathPrefixToCut) == 0 &&
+		r.PathRegex == nil &&
+		len(r.PathTemplate) == 0 &&
+		len(r.QueryParamsToRemove) == 0 &&
+		len(r.QueryParamsToRename) == 0 &&
+		len(r.QueryParamsToAdd) == 0 &&
+		len(r.HostHeader) == 0
 }
 
-func (r *URLRewriter) Rewrite(value *url.URL) {
+// Rewrite rewrites the given url by applying the scheme, the path prefix and the query parameter
+// removal middlewares. The path prefixes to cut and to add can reference the given captures of the
+// rule's url pattern by their names in braces, like {id}. The remaining middlewares require access
+// to the request and the subject and are applied while the rule is executed.
+func (r *URLRewriter) Rewrite(value *url.URL, captures map[string]string) {
 	value.Scheme = x.IfThenElseExec(
 		len(r.Scheme) != 0,
 		func() string { return r.Scheme },
 		func() string { return value.Scheme },
 	)
-	value.Path = r.transformPath(value.Path)
+	value.Path = r.transformPath(value.Path, captures)
 	value.RawQuery = r.transformQuery(value.RawQuery)
 }
 
-func (r *URLRewriter) transformPath(value string) string {
-	return r.PathPrefixToAdd.AddTo(r.PathPrefixToCut.CutFrom(value))
+func (r *URLRewriter) transformPath(value string, captures map[string]string) string {
+	cutter := PrefixCutter(expandCaptures(string(r.PathPrefixToCut), captures))
+	adder := PrefixAdder(expandCaptures(string(r.PathPrefixToAdd), captures))
+
+	return adder.AddTo(cutter.CutFrom(value))
 }
 
 func (r *URLRewriter) transformQuery(value string) string {
 	return r.QueryParamsToRemove.RemoveFrom(value)
 }
+
+func expandCaptures(value string, captures map[string]string) string {
+	if len(captures) == 0 || !strings.Contains(value, "{") {
+		return value
+	}
+
+	oldnew := make([]string, 0, 2*len(captures)) // nolint: gomnd
+
+	for name, capture := range captures {
+		oldnew = append(oldnew, "{"+name+"}", capture)
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(value)
+}
@@ -67,6 +67,7 @@ func TestURLRewriterRewrite(t *testing.T) {
 	for _, tc := range []struct {
 		uc       string
 		original string
+		captures map[string]string
 		rewriter *URLRewriter
 		expected string
 	}{
@@ -117,6 +118,23 @@ func TestURLRewriterRewrite(t *testing.T) {
 			},
 			expected: "https://foo.bar/baz/bar?baz=bar",
 		},
+		{
+			uc:       "cut and add path prefixes referencing captures",
+			original: "http://foo.bar/tenants/acme/users/1",
+			captures: map[string]string{"tenant": "acme"},
+			rewriter: &URLRewriter{
+				PathPrefixToCut: "/tenants/{tenant}",
+				PathPrefixToAdd: "/{tenant}/api",
+			},
+			expected: "http://foo.bar/acme/api/users/1",
+		},
+		{
+			uc:       "path prefix referencing unknown capture",
+			original: "http://foo.bar/foo/bar",
+			captures: map[string]string{"tenant": "acme"},
+			rewriter: &URLRewriter{PathPrefixToAdd: "/{id}"},
+			expected: "http://foo.bar/%7Bid%7D/foo/bar",
+		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -124,10 +142,36 @@ func TestURLRewriterRewrite(t *testing.T) {
 			require.NoError(t, err)
 
 			// WHEN
-			tc.rewriter.Rewrite(requestURL)
+			tc.rewriter.Rewrite(requestURL, tc.captures)
 
 			// THEN
 			assert.Equal(t, tc.expected, requestURL.String())
 		})
 	}
 }
+
+func TestURLRewriterIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		rewriter *URLRewriter
+		expected bool
+	}{
+		{uc: "no middlewares", rewriter: &URLRewriter{}, expected: true},
+		{uc: "scheme", rewriter: &URLRewriter{Scheme: "https"}},
+		{uc: "path regex", rewriter: &URLRewriter{PathRegex: &RegexSubstitution{Pattern: "foo"}}},
+		{uc: "path template", rewriter: &URLRewriter{PathTemplate: "/foo"}},
+		{uc: "renamed query parameters", rewriter: &URLRewriter{QueryParamsToRename: map[string]string{"a": "b"}}},
+		{uc: "added query parameters", rewriter: &URLRewriter{QueryParamsToAdd: map[string]string{"a": "b"}}},
+		{uc: "host header", rewriter: &URLRewriter{HostHeader: "foo.bar"}},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			result := tc.rewriter.IsEmpty()
+
+			// THEN
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
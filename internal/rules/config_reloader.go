@@ -0,0 +1,212 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// configReloader watches the config file heimdall has been started with and re-creates all
+// rules if it changes, so that changes to the mechanism catalogue and to the default rule
+// become effective without a restart. Changes to all other properties require a restart.
+type configReloader struct {
+	file   string
+	prefix config.EnvVarPrefix
+	mode   config.OperationMode
+	hash   []byte
+	p      *ruleSetProcessor
+	f      *reloadableRuleFactory
+	w      *fsnotify.Watcher
+	l      zerolog.Logger
+}
+
+func newConfigReloader(
+	conf *config.Configuration,
+	prefix config.EnvVarPrefix,
+	mode config.OperationMode,
+	processor *ruleSetProcessor,
+	factory *reloadableRuleFactory,
+	logger zerolog.Logger,
+) (*configReloader, error) {
+	logger = logger.With().Str("_config_file", conf.ConfigFile()).Logger()
+
+	if len(conf.ConfigFile()) == 0 {
+		return &configReloader{l: logger}, nil
+	}
+
+	absPath, err := filepath.Abs(conf.ConfigFile())
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to get the absolute path for the config file").
+			CausedBy(err)
+	}
+
+	hash, err := fileHash(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to instantiating new file watcher").
+			CausedBy(err)
+	}
+
+	return &configReloader{
+		file:   absPath,
+		prefix: prefix,
+		mode:   mode,
+		hash:   hash,
+		p:      processor,
+		f:      factory,
+		w:      watcher,
+		l:      logger,
+	}, nil
+}
+
+func (r *configReloader) Start(_ context.Context) error {
+	if r.w == nil {
+		return nil
+	}
+
+	r.l.Info().Msg("Starting config file watcher")
+
+	// the directory is watched to catch the config file being replaced, e.g. by
+	// editors, or by kubernetes if the file is mounted from a ConfigMap
+	if err := r.w.Add(filepath.Dir(r.file)); err != nil {
+		r.l.Error().Err(err).Msg("Failed to start config file watcher")
+
+		return err
+	}
+
+	go r.watchFile()
+
+	return nil
+}
+
+func (r *configReloader) Stop(_ context.Context) error {
+	if r.w == nil {
+		return nil
+	}
+
+	r.l.Info().Msg("Tearing down config file watcher")
+
+	return r.w.Close()
+}
+
+func (r *configReloader) watchFile() {
+	for {
+		select {
+		case _, ok := <-r.w.Events:
+			if !ok {
+				r.l.Debug().Msg("Watcher closed")
+
+				return
+			}
+
+			r.configFileChanged()
+		case err, ok := <-r.w.Errors:
+			if !ok {
+				r.l.Debug().Msg("Watcher error channel closed")
+
+				return
+			}
+
+			r.l.Warn().Err(err).Msg("Watcher error received")
+		}
+	}
+}
+
+func (r *configReloader) configFileChanged() {
+	hash, err := fileHash(r.file)
+	if err != nil {
+		// the file might be in the middle of being replaced
+		r.l.Debug().Err(err).Msg("Config file not readable")
+
+		return
+	}
+
+	if bytes.Equal(hash, r.hash) {
+		return
+	}
+
+	// the hash is updated even if the reload fails to not try loading the same contents again
+	r.hash = hash
+
+	r.l.Info().Msg("Config file changed. Reloading mechanisms and default rule")
+
+	if err = r.reload(); err != nil {
+		r.l.Error().Err(err).Msg("Failed to reload configuration. Keeping the current one")
+
+		return
+	}
+
+	r.l.Info().Msg("Configuration reloaded. Changes to properties other than mechanisms " +
+		"and default_rule require a restart")
+}
+
+func (r *configReloader) reload() error {
+	// the config file is validated against the schema by making use of config.ValidateConfig
+	conf, err := config.NewConfiguration(r.prefix, config.ConfigurationPath(r.file))
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to load config file").CausedBy(err)
+	}
+
+	mFactory, err := mechanisms.NewFactory(conf, r.l)
+	if err != nil {
+		return err
+	}
+
+	rFactory, err := NewRuleFactory(mFactory, conf, r.mode, r.l)
+	if err != nil {
+		return err
+	}
+
+	if err = r.p.reload(rFactory); err != nil {
+		return err
+	}
+
+	// makes the new mechanisms available to all other components creating rules,
+	// like the admission controller
+	r.f.set(rFactory)
+
+	return nil
+}
+
+func fileHash(file string) ([]byte, error) {
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to read config file").CausedBy(err)
+	}
+
+	hash := sha256.Sum256(contents)
+
+	return hash[:], nil
+}
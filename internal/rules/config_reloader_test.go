@@ -0,0 +1,224 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/event"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+const initialConfig = `
+mechanisms:
+  finalizers:
+    - id: noop
+      type: noop
+  authenticators:
+    - id: anon
+      type: anonymous
+`
+
+func setupConfigReloader(t *testing.T, contents string) (*configReloader, event.RuleSetChangedEventQueue) {
+	t.Helper()
+
+	configFile := filepath.Join(t.TempDir(), "heimdall.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(contents), 0o600))
+
+	conf, err := config.NewConfiguration("HEIMDALLCFG_", config.ConfigurationPath(configFile))
+	require.NoError(t, err)
+
+	mFactory, err := mechanisms.NewFactory(conf, log.Logger)
+	require.NoError(t, err)
+
+	rFactory, err := newReloadableRuleFactory(mFactory, conf, config.DecisionMode, log.Logger)
+	require.NoError(t, err)
+
+	queue := make(event.RuleSetChangedEventQueue, 10)
+	processor := newRuleSetProcessor(queue, rFactory, log.Logger)
+
+	require.NoError(t, processor.OnCreated(&config2.RuleSet{
+		MetaData: config2.MetaData{Source: "test"},
+		Version:  config2.CurrentRuleSetVersion,
+		Rules: []config2.Rule{
+			{
+				ID:          "rule:foo",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar/<**>", Strategy: "glob"},
+				Methods:     []string{http.MethodGet},
+				Execute:     []config.MechanismConfig{{"authenticator": "anon"}},
+			},
+		},
+	}))
+	require.Len(t, queue, 1)
+	<-queue
+
+	reloader, err := newConfigReloader(conf, "HEIMDALLCFG_", config.DecisionMode, processor, rFactory, log.Logger)
+	require.NoError(t, err)
+
+	return reloader, queue
+}
+
+func TestConfigReloaderWithoutConfigFile(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := context.Background()
+
+	conf, err := config.NewConfiguration("HEIMDALLCFG_", "")
+	require.NoError(t, err)
+
+	// WHEN
+	reloader, err := newConfigReloader(conf, "HEIMDALLCFG_", config.DecisionMode, nil, nil, log.Logger)
+
+	// THEN
+	require.NoError(t, err)
+	require.NoError(t, reloader.Start(ctx))
+	require.NoError(t, reloader.Stop(ctx))
+}
+
+func TestConfigReloaderConfigFileChanged(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		contents string
+		assert   func(t *testing.T, queue event.RuleSetChangedEventQueue, factory rule.Factory)
+	}{
+		{
+			uc:       "config file not changed",
+			contents: initialConfig,
+			assert: func(t *testing.T, queue event.RuleSetChangedEventQueue, factory rule.Factory) {
+				t.Helper()
+
+				assert.Empty(t, queue)
+				assert.False(t, factory.HasDefaultRule())
+			},
+		},
+		{
+			uc:       "config file does not comply with the schema",
+			contents: "mechanisms: foo",
+			assert: func(t *testing.T, queue event.RuleSetChangedEventQueue, factory rule.Factory) {
+				t.Helper()
+
+				assert.Empty(t, queue)
+				assert.False(t, factory.HasDefaultRule())
+			},
+		},
+		{
+			uc: "mechanism used by a rule is removed",
+			contents: `
+mechanisms:
+  finalizers:
+    - id: noop
+      type: noop
+  authenticators:
+    - id: unauthorized
+      type: unauthorized
+`,
+			assert: func(t *testing.T, queue event.RuleSetChangedEventQueue, factory rule.Factory) {
+				t.Helper()
+
+				assert.Empty(t, queue)
+				assert.False(t, factory.HasDefaultRule())
+			},
+		},
+		{
+			uc: "mechanism and default rule changed",
+			contents: `
+mechanisms:
+  finalizers:
+    - id: noop
+      type: noop
+  authenticators:
+    - id: anon
+      type: anonymous
+      config:
+        subject: foo
+default_rule:
+  methods:
+    - GET
+  execute:
+    - authenticator: anon
+`,
+			assert: func(t *testing.T, queue event.RuleSetChangedEventQueue, factory rule.Factory) {
+				t.Helper()
+
+				// the shared factory makes use of the new configuration as well
+				assert.True(t, factory.HasDefaultRule())
+				require.Len(t, queue, 1)
+
+				evt := <-queue
+				assert.Equal(t, event.Reload, evt.ChangeType)
+				require.Len(t, evt.Rules, 1)
+				assert.Equal(t, "rule:foo", evt.Rules[0].ID())
+				assert.Equal(t, "test", evt.Rules[0].SrcID())
+				require.NotNil(t, evt.Default)
+				assert.Equal(t, "default", evt.Default.ID())
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			reloader, queue := setupConfigReloader(t, initialConfig)
+
+			require.NoError(t, os.WriteFile(reloader.file, []byte(tc.contents), 0o600))
+
+			// WHEN
+			reloader.configFileChanged()
+
+			// THEN
+			tc.assert(t, queue, reloader.f)
+		})
+	}
+}
+
+func TestConfigReloaderLifecycle(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := context.Background()
+	reloader, queue := setupConfigReloader(t, initialConfig)
+
+	require.NoError(t, reloader.Start(ctx))
+
+	defer reloader.Stop(ctx)
+
+	// WHEN
+	require.NoError(t, os.WriteFile(reloader.file, []byte(initialConfig+"      config:\n        subject: bar\n"), 0o600))
+
+	// THEN
+	select {
+	case evt := <-queue:
+		assert.Equal(t, event.Reload, evt.ChangeType)
+		assert.Len(t, evt.Rules, 1)
+		assert.Nil(t, evt.Default)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reload event received")
+	}
+}
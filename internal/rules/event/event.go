@@ -17,6 +17,8 @@
 package event
 
 import (
+	"time"
+
 	"github.com/dadrus/heimdall/internal/rules/rule"
 )
 
@@ -27,6 +29,9 @@ const (
 	Create ChangeType = 1 << iota
 	Remove
 	Update
+	// Reload is used if all rules have been re-created, e.g. due to changed mechanism definitions.
+	// The event carries the rules of all rule sets and the default rule.
+	Reload
 )
 
 func (t ChangeType) String() string {
@@ -37,6 +42,8 @@ func (t ChangeType) String() string {
 		return "Remove"
 	case Update:
 		return "Update"
+	case Reload:
+		return "Reload"
 	default:
 		return "Unknown"
 	}
@@ -45,6 +52,10 @@ func (t ChangeType) String() string {
 type RuleSetChanged struct {
 	Source     string
 	Name       string
+	Hash       []byte
+	ModTime    time.Time
+	Revision   string
 	Rules      []rule.Rule
+	Default    rule.Rule
 	ChangeType ChangeType
 }
@@ -0,0 +1,94 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net/http"
+
+	"github.com/dadrus/heimdall/internal/explain"
+	"github.com/dadrus/heimdall/internal/heimdall"
+)
+
+type mechanism interface {
+	ID() string
+}
+
+// explainContext is used to execute a single pipeline step while a request is explained. It
+// records the headers and cookies the step sets for the upstream. Steps executed in shadow mode
+// do not set anything, so nothing is recorded for them.
+type explainContext struct {
+	heimdall.Context
+
+	step *explain.Step
+}
+
+func (c explainContext) shadowMode() bool { return inShadowMode(c.Context) }
+
+func (c explainContext) AddHeaderForUpstream(name, value string) {
+	if !c.step.Shadow {
+		if c.step.Headers == nil {
+			c.step.Headers = make(http.Header)
+		}
+
+		c.step.Headers.Add(name, value)
+	}
+
+	c.Context.AddHeaderForUpstream(name, value)
+}
+
+func (c explainContext) AddCookieForUpstream(name, value string) {
+	if !c.step.Shadow {
+		if c.step.Cookies == nil {
+			c.step.Cookies = make(map[string]string)
+		}
+
+		c.step.Cookies[name] = value
+	}
+
+	c.Context.AddCookieForUpstream(name, value)
+}
+
+// executeStep executes the given function and records its outcome if the request is explained.
+func executeStep(ctx heimdall.Context, typ string, mech mechanism, exec func(ctx heimdall.Context) error) error {
+	if !explain.Enabled(ctx.AppContext()) {
+		return exec(ctx)
+	}
+
+	step := &explain.Step{Type: typ, ID: mech.ID(), Shadow: inShadowMode(ctx)}
+
+	err := exec(explainContext{Context: ctx, step: step})
+
+	if err != nil {
+		step.Outcome = explain.OutcomeFailure
+		step.Error = err.Error()
+	} else if len(step.Outcome) == 0 {
+		step.Outcome = explain.OutcomeSuccess
+	}
+
+	explain.Record(ctx.AppContext(), step)
+
+	return err
+}
+
+// explainStep returns the step currently recorded, or nil if the request is not explained.
+func explainStep(ctx heimdall.Context) *explain.Step {
+	if ec, ok := ctx.(explainContext); ok {
+		return ec.step
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/explain"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestExecuteStepWithoutExplanation(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := heimdallmocks.NewContextMock(t)
+	ctx.EXPECT().AppContext().Return(context.Background())
+
+	// the id of the mechanism is not required if the request is not explained
+	handler := mocks.NewSubjectHandlerMock(t)
+
+	// WHEN
+	err := executeStep(ctx, "authorizer", handler, func(hctx heimdall.Context) error {
+		assert.Equal(t, ctx, hctx)
+
+		return testsupport.ErrTestPurpose
+	})
+
+	// THEN
+	require.ErrorIs(t, err, testsupport.ErrTestPurpose)
+}
+
+func TestPipelineExecutionIsExplained(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	appCtx := explain.New(context.Background())
+	sub := &subject.Subject{ID: "foo"}
+
+	ctx := heimdallmocks.NewContextMock(t)
+	ctx.EXPECT().AppContext().Return(appCtx)
+	ctx.EXPECT().AddHeaderForUpstream("X-User", "foo")
+	ctx.EXPECT().AddCookieForUpstream("user", "foo")
+
+	authenticator := mocks.NewSubjectCreatorMock(t)
+	authenticator.EXPECT().ID().Return("authn")
+	authenticator.EXPECT().Execute(mock.Anything).Return(sub, nil)
+
+	skippedCondition := mocks.NewExecutionConditionMock(t)
+	skippedCondition.EXPECT().CanExecute(mock.Anything, sub).Return(false, nil)
+
+	skipped := mocks.NewSubjectHandlerMock(t)
+	skipped.EXPECT().ID().Return("skipped")
+
+	shadowed := mocks.NewSubjectHandlerMock(t)
+	shadowed.EXPECT().ID().Return("shadowed")
+	shadowed.EXPECT().Execute(mock.Anything, mock.Anything).
+		Run(func(hctx heimdall.Context, _ *subject.Subject) { hctx.AddHeaderForUpstream("X-Shadow", "bar") }).
+		Return(testsupport.ErrTestPurpose)
+
+	finalizer := mocks.NewSubjectHandlerMock(t)
+	finalizer.EXPECT().ID().Return("fin")
+	finalizer.EXPECT().Execute(mock.Anything, sub).
+		Run(func(hctx heimdall.Context, _ *subject.Subject) {
+			hctx.AddHeaderForUpstream("X-User", "foo")
+			hctx.AddCookieForUpstream("user", "foo")
+		}).
+		Return(nil)
+
+	failing := mocks.NewSubjectHandlerMock(t)
+	failing.EXPECT().ID().Return("failing")
+	failing.EXPECT().Execute(mock.Anything, sub).Return(testsupport.ErrTestPurpose)
+	failing.EXPECT().ContinueOnError().Return(false)
+
+	errHandler := mocks.NewErrorHandlerMock(t)
+	errHandler.EXPECT().ID().Return("eh")
+	errHandler.EXPECT().Execute(mock.Anything, testsupport.ErrTestPurpose).Return(false, nil)
+
+	sc := compositeSubjectCreator{authenticator}
+	sh := compositeSubjectHandler{
+		&conditionalSubjectHandler{typ: "authorizer", h: skipped, c: skippedCondition},
+		&conditionalSubjectHandler{
+			typ: "contextualizer",
+			h:   &shadowSubjectHandler{h: shadowed},
+			c:   defaultExecutionCondition{},
+		},
+		&conditionalSubjectHandler{typ: "finalizer", h: finalizer, c: defaultExecutionCondition{}},
+		&conditionalSubjectHandler{typ: "finalizer", h: failing, c: defaultExecutionCondition{}},
+	}
+	eh := compositeErrorHandler{errHandler}
+
+	// WHEN
+	_, err := sc.Execute(ctx)
+	require.NoError(t, err)
+
+	err = sh.Execute(ctx, sub)
+	require.Error(t, err)
+
+	_, err = eh.Execute(ctx, err)
+	require.Error(t, err)
+
+	// THEN
+	steps := explain.Steps(appCtx)
+	require.Len(t, steps, 6)
+
+	assert.Equal(t, &explain.Step{Type: "authenticator", ID: "authn", Outcome: explain.OutcomeSuccess}, steps[0])
+	assert.Equal(t, &explain.Step{Type: "authorizer", ID: "skipped", Outcome: explain.OutcomeSkipped}, steps[1])
+	assert.Equal(t, &explain.Step{
+		Type:    "contextualizer",
+		ID:      "shadowed",
+		Shadow:  true,
+		Outcome: explain.OutcomeFailure,
+		Error:   testsupport.ErrTestPurpose.Error(),
+	}, steps[2])
+	assert.Equal(t, &explain.Step{
+		Type:    "finalizer",
+		ID:      "fin",
+		Outcome: explain.OutcomeSuccess,
+		Headers: http.Header{"X-User": []string{"foo"}},
+		Cookies: map[string]string{"user": "foo"},
+	}, steps[3])
+	assert.Equal(t, &explain.Step{
+		Type:    "finalizer",
+		ID:      "failing",
+		Outcome: explain.OutcomeFailure,
+		Error:   testsupport.ErrTestPurpose.Error(),
+	}, steps[4])
+	assert.Equal(t, &explain.Step{Type: "error_handler", ID: "eh", Outcome: explain.OutcomeSkipped}, steps[5])
+}
@@ -27,7 +27,7 @@ func TestCreateAuthenticatorPrototype(t *testing.T) {
 	t.Parallel()
 
 	// there are seven authenticators implemented, which should have been registered
-	require.Len(t, authenticatorTypeFactories, 6)
+	require.Len(t, authenticatorTypeFactories, 9)
 
 	for _, tc := range []struct {
 		uc     string
@@ -62,3 +62,18 @@ func decodeConfig(authenticatorType string, input, output any) error {
 
 	return nil
 }
+
+// validateAssertions is used by authenticators, which can take the required assertions
+// from the server metadata as well, if the latter is not configured.
+func validateAssertions(authenticatorType string, assertions oauth2.Expectation) error {
+	type Config struct {
+		Assertions oauth2.Expectation `mapstructure:"assertions" validate:"required"`
+	}
+
+	if err := validation.ValidateStruct(Config{Assertions: assertions}); err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed validating `%s` authenticator config", authenticatorType).CausedBy(err)
+	}
+
+	return nil
+}
@@ -17,10 +17,13 @@
 package authenticators
 
 const (
-	AuthenticatorUnauthorized        = "unauthorized"
-	AuthenticatorBasicAuth           = "basic_auth"
-	AuthenticatorAnonymous           = "anonymous"
-	AuthenticatorOAuth2Introspection = "oauth2_introspection"
-	AuthenticatorJwt                 = "jwt"
-	AuthenticatorGeneric             = "generic"
+	AuthenticatorUnauthorized          = "unauthorized"
+	AuthenticatorBasicAuth             = "basic_auth"
+	AuthenticatorAnonymous             = "anonymous"
+	AuthenticatorOAuth2Introspection   = "oauth2_introspection"
+	AuthenticatorJwt                   = "jwt"
+	AuthenticatorGeneric               = "generic"
+	AuthenticatorX509                  = "x509"
+	AuthenticatorHtpasswd              = "htpasswd"
+	AuthenticatorHTTPMessageSignatures = "http_message_signatures"
 )
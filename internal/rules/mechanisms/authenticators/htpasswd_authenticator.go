@@ -0,0 +1,255 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthenticatorTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorHtpasswd {
+				return false, nil, nil
+			}
+
+			auth, err := newHtpasswdAuthenticator(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type LockoutConfig struct {
+	MaxFailedAttempts int           `mapstructure:"max_failed_attempts" validate:"gt=0"`
+	Duration          time.Duration `mapstructure:"duration"            validate:"gt=0"`
+}
+
+type htpasswdAuthenticator struct {
+	id                   string
+	users                *htpasswdFile
+	attributes           map[string]map[string]any
+	lockout              *LockoutConfig
+	allowFallbackOnError bool
+}
+
+func newHtpasswdAuthenticator(id string, rawConfig map[string]any) (*htpasswdAuthenticator, error) {
+	type Config struct {
+		File                 string         `mapstructure:"htpasswd_file"           validate:"required"`
+		Lockout              *LockoutConfig `mapstructure:"lockout"`
+		AllowFallbackOnError bool           `mapstructure:"allow_fallback_on_error"`
+	}
+
+	// the attributes are free-form and are decoded separately, as the decode hooks used for
+	// the remaining configuration would convert their values, e.g. strings to endpoints
+	remaining := maps.Clone(rawConfig)
+	delete(remaining, "attributes")
+
+	var (
+		conf       Config
+		attributes map[string]map[string]any
+	)
+
+	if err := decodeConfig(AuthenticatorHtpasswd, remaining, &conf); err != nil {
+		return nil, err
+	}
+
+	if err := mapstructure.Decode(rawConfig["attributes"], &attributes); err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed decoding '%s' authenticator config", AuthenticatorHtpasswd).CausedBy(err)
+	}
+
+	users, err := newHtpasswdFile(conf.File)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed to load users for '%s' authenticator", AuthenticatorHtpasswd).CausedBy(err)
+	}
+
+	return &htpasswdAuthenticator{
+		id:                   id,
+		users:                users,
+		attributes:           attributes,
+		lockout:              conf.Lockout,
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}, nil
+}
+
+func (a *htpasswdAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using htpasswd authenticator")
+
+	strategy := extractors.HeaderValueExtractStrategy{Name: "Authorization", Schema: "Basic"}
+
+	authData, err := strategy.GetAuthData(ctx)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "expected header not present in request").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	res, err := base64.StdEncoding.DecodeString(authData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to decode received credentials value").
+			WithErrorContext(a)
+	}
+
+	// according to RFC 7617 the user-id cannot contain a colon, but the password can
+	userID, password, found := strings.Cut(string(res), ":")
+	if !found {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "malformed user-id - password scheme").
+			WithErrorContext(a)
+	}
+
+	cch := cache.Ctx(ctx.AppContext())
+	if a.lockout != nil && cache.IsDisabled(cch) {
+		// failed attempts cannot be counted without the cache, so the lockout would never apply
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "lockout requires the cache, which is disabled").
+			WithErrorContext(a)
+	}
+
+	lockoutKey := a.lockoutKey(userID, ctx.Request().ClientIP)
+
+	if a.isLockedOut(cch, lockoutKey) {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "too many failed authentication attempts").
+			WithErrorContext(a)
+	}
+
+	verifier, err := a.users.Lookup(userID)
+	if err != nil {
+		logger.Warn().Err(err).Str("_id", a.id).Msg("Failed to reload htpasswd file. Using previously loaded users")
+	}
+
+	if verifier == nil || !verifier.Verify(password) {
+		a.recordFailedAttempt(cch, lockoutKey)
+
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "invalid user credentials").
+			WithErrorContext(a)
+	}
+
+	a.resetFailedAttempts(cch, lockoutKey)
+
+	attributes := make(map[string]any)
+	maps.Copy(attributes, a.attributes[userID])
+
+	return &subject.Subject{ID: userID, Attributes: attributes}, nil
+}
+
+func (a *htpasswdAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows only the fallback to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		AllowFallbackOnError *bool `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorHtpasswd, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	return &htpasswdAuthenticator{
+		id:         a.id,
+		users:      a.users,
+		attributes: a.attributes,
+		lockout:    a.lockout,
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *htpasswdAuthenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *htpasswdAuthenticator) ID() string {
+	return a.id
+}
+
+func (a *htpasswdAuthenticator) lockoutKey(userID string, clientIPs []string) string {
+	if a.lockout == nil {
+		return ""
+	}
+
+	digest := sha256.New()
+	digest.Write(stringx.ToBytes(AuthenticatorHtpasswd))
+	digest.Write(stringx.ToBytes(a.id))
+	digest.Write(stringx.ToBytes(userID))
+
+	// the first entry is the address of the actual client
+	if len(clientIPs) != 0 {
+		digest.Write(stringx.ToBytes(clientIPs[0]))
+	}
+
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+func (a *htpasswdAuthenticator) failedAttempts(cch cache.Cache, key string) int {
+	if entry := cch.Get(key); entry != nil {
+		if count, ok := entry.(int); ok {
+			return count
+		}
+	}
+
+	return 0
+}
+
+func (a *htpasswdAuthenticator) isLockedOut(cch cache.Cache, key string) bool {
+	return a.lockout != nil && a.failedAttempts(cch, key) >= a.lockout.MaxFailedAttempts
+}
+
+func (a *htpasswdAuthenticator) recordFailedAttempt(cch cache.Cache, key string) {
+	if a.lockout == nil {
+		return
+	}
+
+	cch.Set(key, a.failedAttempts(cch, key)+1, a.lockout.Duration)
+}
+
+func (a *htpasswdAuthenticator) resetFailedAttempts(cch cache.Cache, key string) {
+	if a.lockout == nil {
+		return
+	}
+
+	cch.Delete(key)
+}
@@ -0,0 +1,654 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func bcryptHashFor(t *testing.T, password string) string {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	return string(hash)
+}
+
+func argon2HashFor(password string) string {
+	salt := []byte("some-random-salt")
+	hash := argon2.IDKey([]byte(password), salt, 1, 64*1024, 2, 32)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, 64*1024, 1, 2,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash))
+}
+
+func sha1HashFor(password string) string {
+	digest := sha1.Sum([]byte(password)) //nolint:gosec
+
+	return "{SHA}" + base64.StdEncoding.EncodeToString(digest[:])
+}
+
+func writeHtpasswdFile(t *testing.T, path string, lines ...string) {
+	t.Helper()
+
+	var contents string
+	for _, line := range lines {
+		contents += line + "\n"
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+}
+
+func TestCreateHtpasswdAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	validFile := filepath.Join(testDir, "valid")
+	writeHtpasswdFile(t, validFile,
+		"# some comment",
+		"alice:"+bcryptHashFor(t, "secret"),
+		"",
+		"bob:"+argon2HashFor("secret"),
+		"carol:"+sha1HashFor("secret"))
+
+	malformedFile := filepath.Join(testDir, "malformed")
+	writeHtpasswdFile(t, malformedFile, "alice")
+
+	unsupportedHashFile := filepath.Join(testDir, "unsupported")
+	writeHtpasswdFile(t, unsupportedHashFile, "alice:$apr1$0MEvKbC/$TO0BFp2Q4cC6VHe3.MnqL.")
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *htpasswdAuthenticator)
+	}{
+		{
+			uc:     "without htpasswd file",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'htpasswd_file' is a required field")
+			},
+		},
+		{
+			uc: "with unsupported fields",
+			config: []byte(`
+htpasswd_file: ` + validFile + `
+foo: bar`),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc:     "with not existing htpasswd file",
+			config: []byte(`htpasswd_file: ` + filepath.Join(testDir, "foo")),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to stat")
+			},
+		},
+		{
+			uc:     "with malformed htpasswd file",
+			config: []byte(`htpasswd_file: ` + malformedFile),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "malformed entry in line 1")
+			},
+		},
+		{
+			uc:     "with unsupported password hash",
+			config: []byte(`htpasswd_file: ` + unsupportedHashFile),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "'alice'")
+			},
+		},
+		{
+			uc: "with malformed attributes",
+			config: []byte(`
+htpasswd_file: ` + validFile + `
+attributes:
+  alice: admin`),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc: "with invalid lockout configuration",
+			config: []byte(`
+htpasswd_file: ` + validFile + `
+lockout:
+  duration: 1m`),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'max_failed_attempts' must be greater than 0")
+			},
+		},
+		{
+			uc:     "with minimal valid configuration",
+			id:     "auth1",
+			config: []byte(`htpasswd_file: ` + validFile),
+			assert: func(t *testing.T, err error, auth *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, "auth1", auth.ID())
+				assert.Len(t, auth.users.users, 3)
+				assert.IsType(t, bcryptHash{}, auth.users.users["alice"])
+				assert.IsType(t, &argon2Hash{}, auth.users.users["bob"])
+				assert.IsType(t, sha1Hash{}, auth.users.users["carol"])
+				assert.IsType(t, dummyHash{}, auth.users.dummy)
+				assert.Empty(t, auth.attributes)
+				assert.Nil(t, auth.lockout)
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with full configuration",
+			id: "auth1",
+			config: []byte(`
+htpasswd_file: ` + validFile + `
+attributes:
+  alice:
+    group: admin
+lockout:
+  max_failed_attempts: 3
+  duration: 1m
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, "auth1", auth.ID())
+				assert.Len(t, auth.users.users, 3)
+				assert.Equal(t, map[string]map[string]any{"alice": {"group": "admin"}}, auth.attributes)
+				assert.Equal(t, &LockoutConfig{MaxFailedAttempts: 3, Duration: time.Minute}, auth.lockout)
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newHtpasswdAuthenticator(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateHtpasswdAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	htpasswdFile := filepath.Join(t.TempDir(), "htpasswd")
+	writeHtpasswdFile(t, htpasswdFile, "alice:"+sha1HashFor("secret"))
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *htpasswdAuthenticator, configured *htpasswdAuthenticator)
+	}{
+		{
+			uc: "no new configuration provided",
+			assert: func(t *testing.T, err error, prototype *htpasswdAuthenticator, configured *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "htpasswd file reconfiguration is not allowed",
+			config: []byte(`htpasswd_file: /foo/bar`),
+			assert: func(t *testing.T, err error, _ *htpasswdAuthenticator, _ *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc:     "fallback reconfigured",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *htpasswdAuthenticator, configured *htpasswdAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Same(t, prototype.users, configured.users)
+				assert.Equal(t, prototype.attributes, configured.attributes)
+				assert.Equal(t, prototype.lockout, configured.lockout)
+				assert.False(t, prototype.IsFallbackOnErrorAllowed())
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+htpasswd_file: ` + htpasswdFile + `
+lockout:
+  max_failed_attempts: 3
+  duration: 1m`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newHtpasswdAuthenticator("auth1", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				htpasswdAuth *htpasswdAuthenticator
+				ok           bool
+			)
+
+			if err == nil {
+				htpasswdAuth, ok = auth.(*htpasswdAuthenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, htpasswdAuth)
+		})
+	}
+}
+
+func TestHtpasswdAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	type HandlerIdentifier interface {
+		ID() string
+	}
+
+	basicAuth := func(userID, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(userID+":"+password))
+	}
+
+	aliceHash := bcryptHashFor(t, "alice-secret")
+	bobHash := argon2HashFor("bob-secret")
+	carolHash := sha1HashFor("carol:secret")
+
+	for _, tc := range []struct {
+		uc string
+		// authorization header values sent in subsequent requests
+		credentials []string
+		// executed between the requests
+		modifyFile func(t *testing.T, path string)
+		assert     func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc:          "no required header present",
+			credentials: []string{""},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, heimdall.ErrArgument)
+				assert.Contains(t, err.Error(), "expected header not present")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth1", identifier.ID())
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:          "base64 decoding error",
+			credentials: []string{"Basic foo"},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "failed to decode")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:          "malformed credentials",
+			credentials: []string{"Basic " + base64.StdEncoding.EncodeToString([]byte("alice"))},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "malformed user-id - password scheme")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:          "unknown user",
+			credentials: []string{basicAuth("dave", "alice-secret")},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth1", identifier.ID())
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:          "invalid password",
+			credentials: []string{basicAuth("alice", "bob-secret")},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "invalid user credentials")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:          "valid credentials of user with bcrypt hash and configured attributes",
+			credentials: []string{basicAuth("alice", "alice-secret")},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "alice", sub.ID)
+				assert.Equal(t, map[string]any{"group": "admin"}, sub.Attributes)
+			},
+		},
+		{
+			uc:          "valid credentials of user with argon2 hash",
+			credentials: []string{basicAuth("bob", "bob-secret")},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "bob", sub.ID)
+				assert.Empty(t, sub.Attributes)
+			},
+		},
+		{
+			uc:          "valid credentials of user with SHA hash and password containing a colon",
+			credentials: []string{basicAuth("carol", "carol:secret")},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "carol", sub.ID)
+			},
+		},
+		{
+			uc: "user locked out after too many failed attempts",
+			credentials: []string{
+				basicAuth("alice", "foo"),
+				basicAuth("alice", "bar"),
+				basicAuth("alice", "alice-secret"),
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "too many failed authentication attempts")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc: "successful authentication resets failed attempts",
+			credentials: []string{
+				basicAuth("alice", "foo"),
+				basicAuth("alice", "alice-secret"),
+				basicAuth("alice", "bar"),
+				basicAuth("alice", "alice-secret"),
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "alice", sub.ID)
+			},
+		},
+		{
+			uc: "failed attempts of other users do not lead to a lockout",
+			credentials: []string{
+				basicAuth("bob", "foo"),
+				basicAuth("carol", "bar"),
+				basicAuth("alice", "alice-secret"),
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "alice", sub.ID)
+			},
+		},
+		{
+			uc:          "user added to the htpasswd file",
+			credentials: []string{basicAuth("dave", "foo"), basicAuth("dave", "dave-secret")},
+			modifyFile: func(t *testing.T, path string) {
+				t.Helper()
+
+				writeHtpasswdFile(t, path,
+					"alice:"+aliceHash,
+					"dave:"+sha1HashFor("dave-secret"))
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "dave", sub.ID)
+			},
+		},
+		{
+			uc:          "htpasswd file changed to malformed contents",
+			credentials: []string{basicAuth("bob", "bob-secret"), basicAuth("bob", "bob-secret")},
+			modifyFile: func(t *testing.T, path string) {
+				t.Helper()
+
+				writeHtpasswdFile(t, path, "foo")
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "bob", sub.ID)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			htpasswdFile := filepath.Join(t.TempDir(), "htpasswd")
+			writeHtpasswdFile(t, htpasswdFile,
+				"alice:"+aliceHash,
+				"bob:"+bobHash,
+				"carol:"+carolHash)
+
+			conf, err := testsupport.DecodeTestConfig([]byte(`
+htpasswd_file: ` + htpasswdFile + `
+attributes:
+  alice:
+    group: admin
+lockout:
+  max_failed_attempts: 2
+  duration: 1m`))
+			require.NoError(t, err)
+
+			auth, err := newHtpasswdAuthenticator("auth1", conf)
+			require.NoError(t, err)
+
+			cch := memory.New()
+
+			var sub *subject.Subject
+
+			for idx, credentials := range tc.credentials {
+				if idx == 1 && tc.modifyFile != nil {
+					tc.modifyFile(t, htpasswdFile)
+					// make sure the change is detected even on file systems with coarse timestamps
+					require.NoError(t, os.Chtimes(htpasswdFile, time.Now(), time.Now().Add(time.Minute)))
+				}
+
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header("Authorization").Return(credentials)
+
+				ctx := mocks.NewContextMock(t)
+				ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch))
+				ctx.EXPECT().Request().
+					Return(&heimdall.Request{RequestFunctions: fnt, ClientIP: []string{"10.10.10.10"}})
+
+				// WHEN
+				sub, err = auth.Execute(ctx)
+			}
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
+
+func TestHtpasswdAuthenticatorExecuteWithDisabledCache(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config string
+		assert func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc: "without lockout",
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+				assert.Equal(t, "alice", sub.ID)
+			},
+		},
+		{
+			uc: "with lockout",
+			config: `
+lockout:
+  max_failed_attempts: 2
+  duration: 1m`,
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "cache, which is disabled")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			htpasswdFile := filepath.Join(t.TempDir(), "htpasswd")
+			writeHtpasswdFile(t, htpasswdFile, "alice:"+sha1HashFor("alice-secret"))
+
+			conf, err := testsupport.DecodeTestConfig([]byte("htpasswd_file: " + htpasswdFile + tc.config))
+			require.NoError(t, err)
+
+			auth, err := newHtpasswdAuthenticator("auth1", conf)
+			require.NoError(t, err)
+
+			fnt := mocks.NewRequestFunctionsMock(t)
+			fnt.EXPECT().Header("Authorization").
+				Return("Basic " + base64.StdEncoding.EncodeToString([]byte("alice:alice-secret")))
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+			ctx.EXPECT().Request().
+				Return(&heimdall.Request{RequestFunctions: fnt, ClientIP: []string{"10.10.10.10"}})
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
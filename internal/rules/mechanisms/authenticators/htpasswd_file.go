@@ -0,0 +1,305 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+var ErrUnsupportedPasswordHash = errors.New("unsupported password hash")
+
+const argon2HashElements = 6
+
+type passwordVerifier interface {
+	Verify(password string) bool
+}
+
+type bcryptHash []byte
+
+func (h bcryptHash) Verify(password string) bool {
+	return bcrypt.CompareHashAndPassword(h, stringx.ToBytes(password)) == nil
+}
+
+type sha1Hash []byte
+
+func (h sha1Hash) Verify(password string) bool {
+	digest := sha1.Sum(stringx.ToBytes(password)) //nolint:gosec
+
+	return subtle.ConstantTimeCompare(h, digest[:]) == 1
+}
+
+type argon2Hash struct {
+	variant string
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	hash    []byte
+}
+
+func (h *argon2Hash) Verify(password string) bool {
+	var hash []byte
+
+	//nolint:gosec
+	// hash length is limited by the length of the encoded value
+	if h.variant == "argon2id" {
+		hash = argon2.IDKey(stringx.ToBytes(password), h.salt, h.time, h.memory, h.threads, uint32(len(h.hash)))
+	} else {
+		hash = argon2.Key(stringx.ToBytes(password), h.salt, h.time, h.memory, h.threads, uint32(len(h.hash)))
+	}
+
+	return subtle.ConstantTimeCompare(h.hash, hash) == 1
+}
+
+// dummyHash is used to verify the passwords of unknown users to not reveal, whether a user exists,
+// by the time it takes to reject the credentials. The verification never succeeds.
+type dummyHash struct {
+	passwordVerifier
+}
+
+func (h dummyHash) Verify(password string) bool {
+	h.passwordVerifier.Verify(password)
+
+	return false
+}
+
+// newDummyHash creates a dummy hash of the same kind and with the same parameters as the given one.
+func newDummyHash(verifier passwordVerifier) (passwordVerifier, error) {
+	switch hash := verifier.(type) {
+	case bcryptHash:
+		cost, _ := bcrypt.Cost(hash)
+		password := make([]byte, 16) //nolint:gomnd
+
+		if _, err := rand.Read(password); err != nil {
+			return nil, err
+		}
+
+		value, err := bcrypt.GenerateFromPassword(password, cost)
+		if err != nil {
+			return nil, err
+		}
+
+		return dummyHash{bcryptHash(value)}, nil
+	case *argon2Hash:
+		dummy := *hash
+		dummy.hash = make([]byte, len(hash.hash))
+
+		return dummyHash{&dummy}, nil
+	default:
+		return dummyHash{sha1Hash(make([]byte, sha1.Size))}, nil
+	}
+}
+
+func parsePasswordHash(value string) (passwordVerifier, error) {
+	switch {
+	case strings.HasPrefix(value, "$2a$"), strings.HasPrefix(value, "$2b$"), strings.HasPrefix(value, "$2y$"):
+		if _, err := bcrypt.Cost(stringx.ToBytes(value)); err != nil {
+			return nil, errorchain.NewWithMessage(ErrUnsupportedPasswordHash, "malformed bcrypt hash").CausedBy(err)
+		}
+
+		return bcryptHash(value), nil
+	case strings.HasPrefix(value, "$argon2id$"), strings.HasPrefix(value, "$argon2i$"):
+		return parseArgon2Hash(value)
+	case strings.HasPrefix(value, "{SHA}"):
+		digest, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, "{SHA}"))
+		if err != nil || len(digest) != sha1.Size {
+			return nil, errorchain.NewWithMessage(ErrUnsupportedPasswordHash, "malformed SHA hash")
+		}
+
+		return sha1Hash(digest), nil
+	default:
+		return nil, ErrUnsupportedPasswordHash
+	}
+}
+
+// parseArgon2Hash parses hashes in the PHC string format, like
+// $argon2id$v=19$m=65536,t=3,p=4$<base64 salt>$<base64 hash>.
+func parseArgon2Hash(value string) (passwordVerifier, error) {
+	parts := strings.Split(value, "$")
+	if len(parts) != argon2HashElements {
+		return nil, errorchain.NewWithMessage(ErrUnsupportedPasswordHash, "malformed argon2 hash")
+	}
+
+	var (
+		version int
+		hash    argon2Hash
+		err     error
+	)
+
+	hash.variant = parts[1]
+
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, errorchain.NewWithMessage(ErrUnsupportedPasswordHash, "unsupported argon2 version")
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.memory, &hash.time, &hash.threads); err != nil {
+		return nil, errorchain.NewWithMessage(ErrUnsupportedPasswordHash, "malformed argon2 parameters").
+			CausedBy(err)
+	}
+
+	// argon2 panics if the number of iterations or the degree of parallelism is 0
+	if hash.time < 1 || hash.threads < 1 {
+		return nil, errorchain.NewWithMessage(ErrUnsupportedPasswordHash, "invalid argon2 parameters")
+	}
+
+	if hash.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, errorchain.NewWithMessage(ErrUnsupportedPasswordHash, "malformed argon2 salt").CausedBy(err)
+	}
+
+	if hash.hash, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(hash.hash) == 0 {
+		return nil, errorchain.NewWithMessage(ErrUnsupportedPasswordHash, "malformed argon2 hash value")
+	}
+
+	return &hash, nil
+}
+
+// htpasswdFile holds the users read from an htpasswd file. The file is re-read if its
+// modification time or size changes, so that users can be added or removed without a restart.
+type htpasswdFile struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	size    int64
+	users   map[string]passwordVerifier
+	dummy   passwordVerifier
+}
+
+func newHtpasswdFile(path string) (*htpasswdFile, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed to stat htpasswd file %s", path).CausedBy(err)
+	}
+
+	users, dummy, err := readHtpasswdFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &htpasswdFile{path: path, modTime: fi.ModTime(), size: fi.Size(), users: users, dummy: dummy}, nil
+}
+
+// Lookup returns the password verifier for the given user. If there is no such user, a verifier
+// for a dummy hash of the same kind as used in the file is returned, which never succeeds, or nil
+// if the file does not contain any users. An error is returned if the file changed, but could not
+// be reloaded. In that case the previously loaded users are used.
+func (f *htpasswdFile) Lookup(user string) (passwordVerifier, error) {
+	err := f.refresh()
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if verifier, found := f.users[user]; found {
+		return verifier, err
+	}
+
+	return f.dummy, err
+}
+
+func (f *htpasswdFile) refresh() error {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return errorchain.NewWithMessagef(heimdall.ErrInternal,
+			"failed to stat htpasswd file %s", f.path).CausedBy(err)
+	}
+
+	f.mu.Lock()
+	if fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		f.mu.Unlock()
+
+		return nil
+	}
+
+	// updated even if the reload fails to not try loading the same contents again
+	f.modTime = fi.ModTime()
+	f.size = fi.Size()
+	f.mu.Unlock()
+
+	users, dummy, err := readHtpasswdFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.users = users
+	f.dummy = dummy
+	f.mu.Unlock()
+
+	return nil
+}
+
+func readHtpasswdFile(path string) (map[string]passwordVerifier, passwordVerifier, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed to read htpasswd file %s", path).CausedBy(err)
+	}
+
+	var dummy passwordVerifier
+
+	users := make(map[string]passwordVerifier)
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		user, hash, found := strings.Cut(line, ":")
+		if !found || len(user) == 0 {
+			return nil, nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"malformed entry in line %d of htpasswd file %s", lineNo, path)
+		}
+
+		verifier, err := parsePasswordHash(hash)
+		if err != nil {
+			return nil, nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"invalid password hash for user '%s' in htpasswd file %s", user, path).CausedBy(err)
+		}
+
+		if dummy == nil {
+			if dummy, err = newDummyHash(verifier); err != nil {
+				return nil, nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+					"failed to create dummy password hash").CausedBy(err)
+			}
+		}
+
+		users[user] = verifier
+	}
+
+	return users, dummy, nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestParsePasswordHash(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		hash   string
+		assert func(t *testing.T, err error, verifier passwordVerifier)
+	}{
+		{
+			uc:   "plain text password",
+			hash: "secret",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+			},
+		},
+		{
+			uc:   "malformed bcrypt hash",
+			hash: "$2y$10$foo",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "malformed bcrypt hash")
+			},
+		},
+		{
+			uc:   "SHA hash with wrong length",
+			hash: "{SHA}Zm9v",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "malformed SHA hash")
+			},
+		},
+		{
+			uc:   "argon2 hash with missing elements",
+			hash: "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "malformed argon2 hash")
+			},
+		},
+		{
+			uc:   "argon2 hash with unsupported version",
+			hash: "$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$aGFzaA",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "unsupported argon2 version")
+			},
+		},
+		{
+			uc:   "argon2 hash with malformed parameters",
+			hash: "$argon2id$v=19$m=foo,t=3,p=4$c2FsdA$aGFzaA",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "malformed argon2 parameters")
+			},
+		},
+		{
+			uc:   "argon2 hash with zero iterations",
+			hash: "$argon2id$v=19$m=65536,t=0,p=4$c2FsdA$aGFzaA",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "invalid argon2 parameters")
+			},
+		},
+		{
+			uc:   "argon2 hash with zero parallelism",
+			hash: "$argon2id$v=19$m=65536,t=3,p=0$c2FsdA$aGFzaA",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "invalid argon2 parameters")
+			},
+		},
+		{
+			uc:   "argon2 hash with malformed salt",
+			hash: "$argon2id$v=19$m=65536,t=3,p=4$!!!$aGFzaA",
+			assert: func(t *testing.T, err error, _ passwordVerifier) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+				assert.Contains(t, err.Error(), "malformed argon2 salt")
+			},
+		},
+		{
+			uc:   "valid argon2i hash",
+			hash: strings.Replace(argon2HashFor("secret"), "$argon2id$", "$argon2i$", 1),
+			assert: func(t *testing.T, err error, verifier passwordVerifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.IsType(t, &argon2Hash{}, verifier)
+				// the hash has been computed with argon2id
+				assert.False(t, verifier.Verify("secret"))
+			},
+		},
+		{
+			uc:   "valid argon2id hash",
+			hash: argon2HashFor("secret"),
+			assert: func(t *testing.T, err error, verifier passwordVerifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, verifier.Verify("secret"))
+				assert.False(t, verifier.Verify("foo"))
+			},
+		},
+		{
+			uc:   "valid SHA hash",
+			hash: sha1HashFor("secret"),
+			assert: func(t *testing.T, err error, verifier passwordVerifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, verifier.Verify("secret"))
+				assert.False(t, verifier.Verify("foo"))
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// WHEN
+			verifier, err := parsePasswordHash(tc.hash)
+
+			// THEN
+			tc.assert(t, err, verifier)
+		})
+	}
+}
+
+func TestNewDummyHash(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		hash   string
+		assert func(t *testing.T, verifier passwordVerifier, dummy passwordVerifier)
+	}{
+		{
+			uc:   "for bcrypt hash",
+			hash: bcryptHashFor(t, "secret"),
+			assert: func(t *testing.T, verifier passwordVerifier, dummy passwordVerifier) {
+				t.Helper()
+
+				expCost, err := bcrypt.Cost(verifier.(bcryptHash))
+				require.NoError(t, err)
+
+				require.IsType(t, bcryptHash{}, dummy)
+
+				cost, err := bcrypt.Cost(dummy.(bcryptHash))
+				require.NoError(t, err)
+				assert.Equal(t, expCost, cost)
+			},
+		},
+		{
+			uc:   "for argon2 hash",
+			hash: argon2HashFor("secret"),
+			assert: func(t *testing.T, verifier passwordVerifier, dummy passwordVerifier) {
+				t.Helper()
+
+				expected := verifier.(*argon2Hash)
+
+				require.IsType(t, &argon2Hash{}, dummy)
+
+				hash := dummy.(*argon2Hash)
+				assert.Equal(t, expected.variant, hash.variant)
+				assert.Equal(t, expected.memory, hash.memory)
+				assert.Equal(t, expected.time, hash.time)
+				assert.Equal(t, expected.threads, hash.threads)
+				assert.Len(t, hash.hash, len(expected.hash))
+				assert.NotEqual(t, expected.hash, hash.hash)
+			},
+		},
+		{
+			uc:   "for SHA hash",
+			hash: sha1HashFor("secret"),
+			assert: func(t *testing.T, _ passwordVerifier, dummy passwordVerifier) {
+				t.Helper()
+
+				assert.IsType(t, sha1Hash{}, dummy)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			verifier, err := parsePasswordHash(tc.hash)
+			require.NoError(t, err)
+
+			// WHEN
+			dummy, err := newDummyHash(verifier)
+
+			// THEN
+			require.NoError(t, err)
+			require.IsType(t, dummyHash{}, dummy)
+			assert.False(t, dummy.Verify("secret"))
+			tc.assert(t, verifier, dummy.(dummyHash).passwordVerifier)
+		})
+	}
+}
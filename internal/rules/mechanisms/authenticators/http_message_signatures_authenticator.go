@@ -0,0 +1,585 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/httpsig"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/pkix"
+	"github.com/dadrus/heimdall/internal/x/slicex"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	defaultHTTPMessageSignaturesTTL    = 10 * time.Minute
+	defaultHTTPMessageSignaturesMaxAge = 1 * time.Minute
+	defaultHTTPMessageSignaturesLeeway = 10 * time.Second
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthenticatorTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorHTTPMessageSignatures {
+				return false, nil, nil
+			}
+
+			auth, err := newHTTPMessageSignaturesAuthenticator(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type httpMessageSignaturesAuthenticator struct {
+	id                   string
+	e                    *endpoint.Endpoint
+	trustedKeys          map[string]*x509.Certificate
+	label                string
+	tag                  string
+	requiredComponents   []string
+	maxAge               time.Duration
+	leeway               time.Duration
+	requireNonce         bool
+	ttl                  *time.Duration
+	allowFallbackOnError bool
+}
+
+func newHTTPMessageSignaturesAuthenticator(
+	id string,
+	rawConfig map[string]any,
+) (*httpMessageSignaturesAuthenticator, error) {
+	type Config struct {
+		Endpoint             *endpoint.Endpoint    `mapstructure:"jwks_endpoint"           validate:"required_without=TrustStore,excluded_with=TrustStore"` //nolint:lll
+		TrustStore           truststore.TrustStore `mapstructure:"trust_store"             validate:"required_without=Endpoint"`                            //nolint:lll
+		Label                string                `mapstructure:"label"`
+		Tag                  string                `mapstructure:"tag"`
+		RequiredComponents   []string              `mapstructure:"required_components"`
+		MaxAge               *time.Duration        `mapstructure:"max_age"`
+		Leeway               *time.Duration        `mapstructure:"leeway"`
+		RequireNonce         bool                  `mapstructure:"require_nonce"`
+		CacheTTL             *time.Duration        `mapstructure:"cache_ttl"`
+		AllowFallbackOnError bool                  `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorHTTPMessageSignatures, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	if conf.Endpoint != nil {
+		setJWKSEndpointDefaults(conf.Endpoint)
+	}
+
+	trustedKeys, err := trustedKeysFrom(conf.TrustStore)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"failed to create '%s' authenticator", AuthenticatorHTTPMessageSignatures).CausedBy(err)
+	}
+
+	return &httpMessageSignaturesAuthenticator{
+		id:          id,
+		e:           conf.Endpoint,
+		trustedKeys: trustedKeys,
+		label:       conf.Label,
+		tag:         conf.Tag,
+		requiredComponents: x.IfThenElse(len(conf.RequiredComponents) != 0,
+			conf.RequiredComponents, []string{"@method", "@authority", "@path"}),
+		maxAge: x.IfThenElseExec(conf.MaxAge != nil,
+			func() time.Duration { return *conf.MaxAge },
+			func() time.Duration { return defaultHTTPMessageSignaturesMaxAge }),
+		leeway: x.IfThenElseExec(conf.Leeway != nil,
+			func() time.Duration { return *conf.Leeway },
+			func() time.Duration { return defaultHTTPMessageSignaturesLeeway }),
+		requireNonce:         conf.RequireNonce,
+		ttl:                  conf.CacheTTL,
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}, nil
+}
+
+// trustedKeysFrom indexes the certificates from the trust store by their key id, which is,
+// as for heimdall's own key store, the hex encoded subject key identifier.
+func trustedKeysFrom(ts truststore.TrustStore) (map[string]*x509.Certificate, error) {
+	keys := make(map[string]*x509.Certificate, len(ts))
+
+	for _, cert := range ts {
+		keyID := cert.SubjectKeyId
+		if len(keyID) == 0 {
+			var err error
+
+			if keyID, err = pkix.SubjectKeyID(cert.PublicKey); err != nil {
+				return nil, err
+			}
+		}
+
+		keys[hex.EncodeToString(keyID)] = cert
+	}
+
+	return keys, nil
+}
+
+func (a *httpMessageSignaturesAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using http_message_signatures authenticator")
+
+	req := ctx.Request()
+
+	sig, err := a.selectSignature(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.assertSignatureParameters(sig); err != nil {
+		return nil, err
+	}
+
+	key, keyAlg, err := a.getKey(ctx, sig.KeyID())
+	if err != nil {
+		return nil, err
+	}
+
+	alg, err := a.algorithm(sig, key, keyAlg)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := sig.Base(requestMessage{req: req})
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to create signature base").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if err = httpsig.Verify(alg, key, base, sig.Value); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to verify http message signature").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if sig.Covers("content-digest") {
+		if err = httpsig.VerifyContentDigest(req.Header("Content-Digest"), req.Body()); err != nil {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "failed to verify content digest").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+	}
+
+	// the nonce is recorded only after the signature has been verified, so that
+	// no one can prevent the usage of a nonce by sending an invalid signature
+	if err = a.checkNonce(ctx, sig); err != nil {
+		return nil, err
+	}
+
+	return &subject.Subject{
+		ID: sig.KeyID(),
+		Attributes: map[string]any{
+			"key_id":    sig.KeyID(),
+			"label":     sig.Label,
+			"algorithm": alg,
+			"covered_components": slicex.Map(sig.Components, func(c httpsig.Component) any {
+				return c.Identifier()
+			}),
+		},
+	}, nil
+}
+
+func (a *httpMessageSignaturesAuthenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Tag                  *string        `mapstructure:"tag"`
+		RequiredComponents   []string       `mapstructure:"required_components"`
+		MaxAge               *time.Duration `mapstructure:"max_age"`
+		Leeway               *time.Duration `mapstructure:"leeway"`
+		RequireNonce         *bool          `mapstructure:"require_nonce"`
+		AllowFallbackOnError *bool          `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorHTTPMessageSignatures, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	return &httpMessageSignaturesAuthenticator{
+		id:          a.id,
+		e:           a.e,
+		trustedKeys: a.trustedKeys,
+		label:       a.label,
+		tag: x.IfThenElseExec(conf.Tag != nil,
+			func() string { return *conf.Tag },
+			func() string { return a.tag }),
+		requiredComponents: x.IfThenElse(len(conf.RequiredComponents) != 0,
+			conf.RequiredComponents, a.requiredComponents),
+		maxAge: x.IfThenElseExec(conf.MaxAge != nil,
+			func() time.Duration { return *conf.MaxAge },
+			func() time.Duration { return a.maxAge }),
+		leeway: x.IfThenElseExec(conf.Leeway != nil,
+			func() time.Duration { return *conf.Leeway },
+			func() time.Duration { return a.leeway }),
+		requireNonce: x.IfThenElseExec(conf.RequireNonce != nil,
+			func() bool { return *conf.RequireNonce },
+			func() bool { return a.requireNonce }),
+		ttl: a.ttl,
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *httpMessageSignaturesAuthenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *httpMessageSignaturesAuthenticator) ID() string {
+	return a.id
+}
+
+func (a *httpMessageSignaturesAuthenticator) selectSignature(req *heimdall.Request) (*httpsig.Signature, error) {
+	signatureInput := req.Header("Signature-Input")
+	signature := req.Header("Signature")
+
+	if len(signatureInput) == 0 || len(signature) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no http message signature present in request").
+			WithErrorContext(a)
+	}
+
+	signatures, err := httpsig.ParseSignatures(signatureInput, signature)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "failed to parse http message signature").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(a.label) == 0 {
+		if len(signatures) == 0 {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrAuthentication, "no http message signature present in request").
+				WithErrorContext(a)
+		}
+
+		return signatures[0], nil
+	}
+
+	for _, sig := range signatures {
+		if sig.Label == a.label {
+			return sig, nil
+		}
+	}
+
+	return nil, errorchain.
+		NewWithMessagef(heimdall.ErrAuthentication, "no http message signature with label '%s' present", a.label).
+		WithErrorContext(a)
+}
+
+func (a *httpMessageSignaturesAuthenticator) assertSignatureParameters(sig *httpsig.Signature) error {
+	for _, component := range a.requiredComponents {
+		if !sig.Covers(component) {
+			return errorchain.
+				NewWithMessagef(heimdall.ErrAuthentication, "signature does not cover required component %s", component).
+				WithErrorContext(a)
+		}
+	}
+
+	if len(a.tag) != 0 && sig.Tag() != a.tag {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature tag does not match").
+			WithErrorContext(a)
+	}
+
+	if len(sig.KeyID()) == 0 {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature does not reference a key id").
+			WithErrorContext(a)
+	}
+
+	now := time.Now()
+
+	created := sig.Created()
+	if created == nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature does not have a creation time").
+			WithErrorContext(a)
+	}
+
+	if created.After(now.Add(a.leeway)) {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature has been created in the future").
+			WithErrorContext(a)
+	}
+
+	if created.Add(a.maxAge).Before(now.Add(-a.leeway)) {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature is too old").
+			WithErrorContext(a)
+	}
+
+	if expires := sig.Expires(); expires != nil && expires.Before(now.Add(-a.leeway)) {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature expired").
+			WithErrorContext(a)
+	}
+
+	if a.requireNonce && len(sig.Nonce()) == 0 {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature does not have a nonce").
+			WithErrorContext(a)
+	}
+
+	return nil
+}
+
+func (a *httpMessageSignaturesAuthenticator) algorithm(
+	sig *httpsig.Signature, key crypto.PublicKey, keyAlg string,
+) (string, error) {
+	if len(keyAlg) != 0 {
+		alg, err := httpsig.AlgorithmFromJWA(keyAlg)
+		if err != nil {
+			return "", errorchain.
+				NewWithMessagef(heimdall.ErrAuthentication, "key for keyID=%s cannot be used", sig.KeyID()).
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+
+		if len(sig.Algorithm()) != 0 && sig.Algorithm() != alg {
+			return "", errorchain.
+				NewWithMessage(heimdall.ErrAuthentication,
+					"algorithm in the signature does not match the algorithm referenced in the key").
+				WithErrorContext(a)
+		}
+
+		return alg, nil
+	}
+
+	if len(sig.Algorithm()) != 0 {
+		return sig.Algorithm(), nil
+	}
+
+	alg, err := httpsig.AlgorithmFromKey(key)
+	if err != nil {
+		return "", errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature does not reference an algorithm").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return alg, nil
+}
+
+func (a *httpMessageSignaturesAuthenticator) checkNonce(ctx heimdall.Context, sig *httpsig.Signature) error {
+	nonce := sig.Nonce()
+	if len(nonce) == 0 {
+		return nil
+	}
+
+	cch := cache.Ctx(ctx.AppContext())
+	if cache.IsDisabled(cch) {
+		// used nonces cannot be remembered without the cache, so replays would not be detected
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "nonce verification requires the cache, which is disabled").
+			WithErrorContext(a)
+	}
+
+	digest := sha256.New()
+	digest.Write(stringx.ToBytes(AuthenticatorHTTPMessageSignatures))
+	digest.Write(stringx.ToBytes(a.id))
+	digest.Write(stringx.ToBytes(sig.KeyID()))
+	digest.Write(stringx.ToBytes(nonce))
+	cacheKey := hex.EncodeToString(digest.Sum(nil))
+
+	// signatures older than that are rejected anyway
+	if !cch.SetIfAbsent(cacheKey, true, a.maxAge+2*a.leeway) {
+		return errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "signature nonce has already been used").
+			WithErrorContext(a)
+	}
+
+	return nil
+}
+
+func (a *httpMessageSignaturesAuthenticator) getKey(ctx heimdall.Context, keyID string) (
+	crypto.PublicKey, string, error,
+) {
+	if a.e == nil {
+		cert, ok := a.trustedKeys[keyID]
+		if !ok {
+			return nil, "", errorchain.
+				NewWithMessagef(heimdall.ErrAuthentication, "no key found for keyID=%s", keyID).
+				WithErrorContext(a)
+		}
+
+		if now := time.Now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return nil, "", errorchain.
+				NewWithMessagef(heimdall.ErrAuthentication, "certificate for keyID=%s is not valid", keyID).
+				WithErrorContext(a)
+		}
+
+		return cert.PublicKey, "", nil
+	}
+
+	jwk, err := a.getJWK(ctx, keyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return jwk.Key, jwk.Algorithm, nil
+}
+
+func (a *httpMessageSignaturesAuthenticator) isCacheEnabled() bool {
+	return a.ttl == nil || *a.ttl > 0
+}
+
+func (a *httpMessageSignaturesAuthenticator) getJWK(ctx heimdall.Context, keyID string) (*jose.JSONWebKey, error) {
+	cch := cache.Ctx(ctx.AppContext())
+	logger := zerolog.Ctx(ctx.AppContext())
+
+	var cacheKey string
+
+	if a.isCacheEnabled() {
+		digest := sha256.New()
+		digest.Write(a.e.Hash())
+		digest.Write(stringx.ToBytes(keyID))
+		cacheKey = hex.EncodeToString(digest.Sum(nil))
+
+		if entry := cch.Get(cacheKey); entry != nil {
+			if jwk, ok := entry.(*jose.JSONWebKey); ok {
+				logger.Debug().Msg("Reusing JWK from cache")
+
+				return jwk, nil
+			}
+
+			logger.Warn().Msg("Wrong object type from cache")
+			cch.Delete(cacheKey)
+		}
+	}
+
+	jwks, err := a.fetchJWKS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := jwks.Key(keyID)
+	if len(keys) != 1 || !keys[0].Valid() || (len(keys[0].Use) != 0 && keys[0].Use != "sig") {
+		return nil, errorchain.
+			NewWithMessagef(heimdall.ErrAuthentication,
+				"no (unique) signature key found for the keyID=%s referenced in the signature", keyID).
+			WithErrorContext(a)
+	}
+
+	jwk := keys[0].Public()
+
+	if a.isCacheEnabled() {
+		cch.Set(cacheKey, &jwk,
+			x.IfThenElseExec(a.ttl != nil,
+				func() time.Duration { return *a.ttl },
+				func() time.Duration { return defaultHTTPMessageSignaturesTTL }))
+	}
+
+	return &jwk, nil
+}
+
+func (a *httpMessageSignaturesAuthenticator) fetchJWKS(ctx heimdall.Context) (*jose.JSONWebKeySet, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+
+	logger.Debug().Msg("Retrieving JWKS from configured endpoint")
+
+	req, err := a.e.CreateRequest(ctx.AppContext(), nil, nil)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed creating request").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	resp, err := a.e.CreateClient(req.URL.Hostname()).Do(req)
+	if err != nil {
+		var clientErr *url.Error
+		if errors.As(err, &clientErr) && clientErr.Timeout() {
+			return nil, errorchain.
+				NewWithMessage(heimdall.ErrCommunicationTimeout, "request to JWKS endpoint timed out").
+				WithErrorContext(a).
+				CausedBy(err)
+		}
+
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "request to JWKS endpoint failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	defer resp.Body.Close()
+
+	if !(resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices) {
+		return nil, errorchain.
+			NewWithMessagef(heimdall.ErrCommunication, "unexpected response. code: %v", resp.StatusCode).
+			WithErrorContext(a)
+	}
+
+	rawData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to read response").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	var jwks jose.JSONWebKeySet
+	if err = json.Unmarshal(rawData, &jwks); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal received jwks").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return &jwks, nil
+}
+
+// requestMessage makes the heimdall request available for the signature base creation.
+type requestMessage struct {
+	req *heimdall.Request
+}
+
+func (m requestMessage) Method() string            { return m.req.Method }
+func (m requestMessage) URL() *url.URL             { return &m.req.URL.URL }
+func (m requestMessage) Header(name string) string { return m.req.Header(name) }
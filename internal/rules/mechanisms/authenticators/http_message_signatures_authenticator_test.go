@@ -0,0 +1,928 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/httpsig"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+type testHTTPMessage struct {
+	method string
+	url    *url.URL
+	header http.Header
+}
+
+func (m testHTTPMessage) Method() string            { return m.method }
+func (m testHTTPMessage) URL() *url.URL             { return m.url }
+func (m testHTTPMessage) Header(name string) string { return m.header.Get(name) }
+
+// signHTTPMessage adds the Signature-Input and the Signature headers to the given message. The
+// signature input is expected to be given without the label, which is always "sig".
+func signHTTPMessage(t *testing.T, msg testHTTPMessage, key crypto.Signer, input string) {
+	t.Helper()
+
+	sigs, err := httpsig.ParseSignatures("sig="+input, "sig=:AA==:")
+	require.NoError(t, err)
+
+	base, err := sigs[0].Base(msg)
+	require.NoError(t, err)
+
+	var signature []byte
+
+	switch privKey := key.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(privKey, base)
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(base)
+
+		r, s, err := ecdsa.Sign(rand.Reader, privKey, digest[:])
+		require.NoError(t, err)
+
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	default:
+		require.Fail(t, "unsupported key type")
+	}
+
+	msg.header.Set("Signature-Input", "sig="+input)
+	msg.header.Set("Signature", "sig=:"+base64.StdEncoding.EncodeToString(signature)+":")
+}
+
+func TestCreateHTTPMessageSignaturesAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(rootCA.Certificate))
+	require.NoError(t, err)
+
+	file, err := os.CreateTemp("", "test-create-http-message-signatures-authenticator-*")
+	require.NoError(t, err)
+
+	_, err = file.Write(pemBytes)
+	require.NoError(t, err)
+
+	defer os.Remove(file.Name())
+
+	trustStorePath := file.Name()
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *httpMessageSignaturesAuthenticator)
+	}{
+		{
+			uc:     "without key source",
+			config: []byte(`label: sig1`),
+			assert: func(t *testing.T, err error, _ *httpMessageSignaturesAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'jwks_endpoint' is a required field")
+				assert.Contains(t, err.Error(), "'trust_store' is a required field")
+			},
+		},
+		{
+			uc: "with jwks endpoint and trust store",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+trust_store: ` + trustStorePath),
+			assert: func(t *testing.T, err error, _ *httpMessageSignaturesAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'jwks_endpoint' cannot be used together with")
+			},
+		},
+		{
+			uc: "with unsupported properties",
+			config: []byte(`
+trust_store: ` + trustStorePath + `
+foo: bar`),
+			assert: func(t *testing.T, err error, _ *httpMessageSignaturesAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc: "with jwks endpoint only",
+			id: "auth1",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com`),
+			assert: func(t *testing.T, err error, auth *httpMessageSignaturesAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "auth1", auth.ID())
+				require.NotNil(t, auth.e)
+				assert.Equal(t, "http://test.com", auth.e.URL)
+				assert.Equal(t, http.MethodGet, auth.e.Method)
+				assert.Equal(t, "application/json", auth.e.Headers["Accept-Type"])
+				assert.Empty(t, auth.trustedKeys)
+				assert.Empty(t, auth.label)
+				assert.Empty(t, auth.tag)
+				assert.Equal(t, []string{"@method", "@authority", "@path"}, auth.requiredComponents)
+				assert.Equal(t, defaultHTTPMessageSignaturesMaxAge, auth.maxAge)
+				assert.Equal(t, defaultHTTPMessageSignaturesLeeway, auth.leeway)
+				assert.False(t, auth.requireNonce)
+				assert.Nil(t, auth.ttl)
+				assert.True(t, auth.isCacheEnabled())
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with trust store and all other properties",
+			id: "auth2",
+			config: []byte(`
+trust_store: ` + trustStorePath + `
+label: sig1
+tag: my-app
+required_components: ["@method", "@target-uri", "content-digest"]
+max_age: 5m
+leeway: 1s
+require_nonce: true
+cache_ttl: 0s
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *httpMessageSignaturesAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "auth2", auth.ID())
+				assert.Nil(t, auth.e)
+				assert.Len(t, auth.trustedKeys, 1)
+				assert.Equal(t, rootCA.Certificate,
+					auth.trustedKeys[hex.EncodeToString(rootCA.Certificate.SubjectKeyId)])
+				assert.Equal(t, "sig1", auth.label)
+				assert.Equal(t, "my-app", auth.tag)
+				assert.Equal(t, []string{"@method", "@target-uri", "content-digest"}, auth.requiredComponents)
+				assert.Equal(t, 5*time.Minute, auth.maxAge)
+				assert.Equal(t, 1*time.Second, auth.leeway)
+				assert.True(t, auth.requireNonce)
+				assert.False(t, auth.isCacheEnabled())
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newHTTPMessageSignaturesAuthenticator(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateHTTPMessageSignaturesAuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *httpMessageSignaturesAuthenticator,
+			configured *httpMessageSignaturesAuthenticator)
+	}{
+		{
+			uc: "no new configuration provided",
+			assert: func(t *testing.T, err error, prototype *httpMessageSignaturesAuthenticator,
+				configured *httpMessageSignaturesAuthenticator,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "key source reconfiguration is not allowed",
+			config: []byte(`jwks_endpoint: http://foo.bar`),
+			assert: func(t *testing.T, err error, _ *httpMessageSignaturesAuthenticator,
+				_ *httpMessageSignaturesAuthenticator,
+			) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc: "all reconfigurable properties",
+			config: []byte(`
+tag: other-app
+required_components: ["@method"]
+max_age: 10s
+leeway: 2s
+require_nonce: true
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *httpMessageSignaturesAuthenticator,
+				configured *httpMessageSignaturesAuthenticator,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.e, configured.e)
+				assert.Equal(t, prototype.label, configured.label)
+				assert.Equal(t, prototype.ttl, configured.ttl)
+				assert.Equal(t, "my-app", prototype.tag)
+				assert.Equal(t, "other-app", configured.tag)
+				assert.Equal(t, []string{"@method", "@authority", "@path"}, prototype.requiredComponents)
+				assert.Equal(t, []string{"@method"}, configured.requiredComponents)
+				assert.Equal(t, 10*time.Second, configured.maxAge)
+				assert.Equal(t, 2*time.Second, configured.leeway)
+				assert.False(t, prototype.requireNonce)
+				assert.True(t, configured.requireNonce)
+				assert.False(t, prototype.IsFallbackOnErrorAllowed())
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc:     "only fallback reconfigured",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *httpMessageSignaturesAuthenticator,
+				configured *httpMessageSignaturesAuthenticator,
+			) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype.tag, configured.tag)
+				assert.Equal(t, prototype.requiredComponents, configured.requiredComponents)
+				assert.Equal(t, prototype.maxAge, configured.maxAge)
+				assert.Equal(t, prototype.leeway, configured.leeway)
+				assert.Equal(t, prototype.requireNonce, configured.requireNonce)
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+jwks_endpoint: http://test.com
+label: sig1
+tag: my-app`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newHTTPMessageSignaturesAuthenticator("auth1", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				hmsAuth *httpMessageSignaturesAuthenticator
+				ok      bool
+			)
+
+			if err == nil {
+				hmsAuth, ok = auth.(*httpMessageSignaturesAuthenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, hmsAuth)
+		})
+	}
+}
+
+func TestHTTPMessageSignaturesAuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	// key available via the trust store
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	cert, err := rootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "Test Client", Organization: []string{"Test"}}),
+		testsupport.WithValidity(time.Now(), time.Hour),
+		testsupport.WithSubjectPubKey(&ecdsaKey.PublicKey, x509.ECDSAWithSHA256),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithGeneratedSubjectKeyID())
+	require.NoError(t, err)
+
+	certKeyID := hex.EncodeToString(cert.SubjectKeyId)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(cert))
+	require.NoError(t, err)
+
+	trustStorePath := t.TempDir() + "/trust_store.pem"
+	require.NoError(t, os.WriteFile(trustStorePath, pemBytes, 0o600))
+
+	// key available via the jwks endpoint
+	ed25519PubKey, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: ed25519PubKey, KeyID: "ed-key", Algorithm: "EdDSA", Use: "sig"},
+		{Key: ed25519PubKey, KeyID: "enc-key", Algorithm: "EdDSA", Use: "enc"},
+	}})
+	require.NoError(t, err)
+
+	var (
+		endpointCalls int
+		responseCode  int
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		endpointCalls++
+
+		if responseCode != http.StatusOK {
+			w.WriteHeader(responseCode)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(jwks)
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	trustStoreConfig := []byte(`
+trust_store: ` + trustStorePath + `
+max_age: 1m
+leeway: 1s`)
+	jwksConfig := []byte(`jwks_endpoint: ` + srv.URL)
+
+	created := func(offset time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(offset).Unix(), 10)
+	}
+
+	body := []byte(`{"hello": "world"}`)
+	bodyDigest := sha512.Sum512(body)
+	contentDigest := "sha-512=:" + base64.StdEncoding.EncodeToString(bodyDigest[:]) + ":"
+
+	for _, tc := range []struct {
+		uc            string
+		config        []byte
+		responseCode  int
+		executions    int
+		configureMsg  func(t *testing.T, msg testHTTPMessage)
+		expectedCalls int
+		assert        func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc:     "no signature present",
+			config: trustStoreConfig,
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no http message signature present")
+			},
+		},
+		{
+			uc:     "malformed signature",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				msg.header.Set("Signature-Input", `sig=("@method"`)
+				msg.header.Set("Signature", `sig=:AA==:`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, httpsig.ErrMalformedSignature)
+			},
+		},
+		{
+			uc:     "signature with configured label not present",
+			config: append([]byte("label: sig1\n"), trustStoreConfig...),
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="`+certKeyID+`"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no http message signature with label 'sig1'")
+			},
+		},
+		{
+			uc:     "required component not covered",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@path");created=`+created(0)+`;keyid="`+certKeyID+`"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "does not cover required component @authority")
+			},
+		},
+		{
+			uc:     "tag does not match",
+			config: append([]byte("tag: my-app\n"), trustStoreConfig...),
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="`+certKeyID+`";tag="other"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "tag does not match")
+			},
+		},
+		{
+			uc:     "no key id",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey, `("@method" "@authority" "@path");created=`+created(0))
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "does not reference a key id")
+			},
+		},
+		{
+			uc:     "no creation time",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey, `("@method" "@authority" "@path");keyid="`+certKeyID+`"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "does not have a creation time")
+			},
+		},
+		{
+			uc:     "created in the future",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path");created=`+created(time.Minute)+`;keyid="`+certKeyID+`"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "created in the future")
+			},
+		},
+		{
+			uc:     "signature too old",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path");created=`+created(-2*time.Minute)+`;keyid="`+certKeyID+`"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "too old")
+			},
+		},
+		{
+			uc:     "signature expired",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path");created=`+created(-20*time.Second)+
+						`;expires=`+created(-10*time.Second)+`;keyid="`+certKeyID+`"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "signature expired")
+			},
+		},
+		{
+			uc:     "nonce required but not present",
+			config: append([]byte("require_nonce: true\n"), trustStoreConfig...),
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="`+certKeyID+`"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "does not have a nonce")
+			},
+		},
+		{
+			uc:     "unknown key id",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="foo"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no key found for keyID=foo")
+			},
+		},
+		{
+			uc:     "signature created with another key",
+			config: jwksConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, otherKey,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="ed-key"`)
+			},
+			expectedCalls: 1,
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, httpsig.ErrInvalidSignature)
+			},
+		},
+		{
+			uc:     "algorithm in signature does not match the one of the key",
+			config: jwksConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ed25519Key,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="ed-key";alg="rsa-pss-sha512"`)
+			},
+			expectedCalls: 1,
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "does not match the algorithm referenced in the key")
+			},
+		},
+		{
+			uc:     "key not intended for signatures",
+			config: jwksConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ed25519Key,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="enc-key"`)
+			},
+			expectedCalls: 1,
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no (unique) signature key found for the keyID=enc-key")
+			},
+		},
+		{
+			uc:           "jwks endpoint responds with an error",
+			config:       jwksConfig,
+			responseCode: http.StatusInternalServerError,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ed25519Key,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="ed-key"`)
+			},
+			expectedCalls: 1,
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "unexpected response")
+			},
+		},
+		{
+			uc:     "content digest does not match",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				msg.header.Set("Content-Digest", "sha-256=:Y2FmZWJhYmU=:")
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path" "content-digest");created=`+created(0)+`;keyid="`+certKeyID+`"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, httpsig.ErrContentDigestMismatch)
+			},
+		},
+		{
+			uc:         "nonce replayed",
+			config:     trustStoreConfig,
+			executions: 2,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="`+certKeyID+`";nonce="abc"`)
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "nonce has already been used")
+			},
+		},
+		{
+			uc:     "successful authentication with key from trust store",
+			config: trustStoreConfig,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				msg.header.Set("Content-Digest", contentDigest)
+				signHTTPMessage(t, msg, ecdsaKey,
+					`("@method" "@authority" "@path" "@query-param";name="foo" "content-digest");created=`+
+						created(0)+`;keyid="`+certKeyID+`";nonce="abc"`)
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, certKeyID, sub.ID)
+				assert.Equal(t, map[string]any{
+					"key_id":    certKeyID,
+					"label":     "sig",
+					"algorithm": httpsig.AlgorithmECDSAP256SHA256,
+					"covered_components": []any{
+						"@method", "@authority", "@path", `@query-param;name="foo"`, "content-digest",
+					},
+				}, sub.Attributes)
+			},
+		},
+		{
+			uc:         "successful authentication with key from jwks endpoint, which is cached",
+			config:     jwksConfig,
+			executions: 2,
+			configureMsg: func(t *testing.T, msg testHTTPMessage) {
+				t.Helper()
+
+				signHTTPMessage(t, msg, ed25519Key,
+					`("@method" "@authority" "@path");created=`+created(0)+`;keyid="ed-key";alg="ed25519"`)
+			},
+			expectedCalls: 1,
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "ed-key", sub.ID)
+				assert.Equal(t, httpsig.AlgorithmEd25519, sub.Attributes["algorithm"])
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			endpointCalls = 0
+			responseCode = x.IfThenElse(tc.responseCode != 0, tc.responseCode, http.StatusOK)
+			executions := x.IfThenElse(tc.executions != 0, tc.executions, 1)
+			configureMsg := x.IfThenElse(tc.configureMsg != nil, tc.configureMsg,
+				func(t *testing.T, _ testHTTPMessage) { t.Helper() })
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			auth, err := newHTTPMessageSignaturesAuthenticator("auth1", conf)
+			require.NoError(t, err)
+
+			msgURL, err := url.Parse("https://example.com/foo?foo=bar")
+			require.NoError(t, err)
+
+			msg := testHTTPMessage{method: http.MethodPost, url: msgURL, header: http.Header{}}
+			configureMsg(t, msg)
+
+			cch := memory.New()
+
+			var sub *subject.Subject
+
+			for i := 0; i < executions; i++ {
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header(mock.Anything).RunAndReturn(msg.header.Get).Maybe()
+				fnt.EXPECT().Body().Return(body).Maybe()
+
+				ctx := mocks.NewContextMock(t)
+				ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), cch)).Maybe()
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           msg.method,
+					URL:              &heimdall.URL{URL: *msgURL},
+				})
+
+				// WHEN
+				sub, err = auth.Execute(ctx)
+			}
+
+			// THEN
+			assert.Equal(t, tc.expectedCalls, endpointCalls)
+			tc.assert(t, err, sub)
+		})
+	}
+}
+
+func TestHTTPMessageSignaturesAuthenticatorNonceVerification(t *testing.T) {
+	t.Parallel()
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	cert, err := rootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "Test Client", Organization: []string{"Test"}}),
+		testsupport.WithValidity(time.Now(), time.Hour),
+		testsupport.WithSubjectPubKey(&ecdsaKey.PublicKey, x509.ECDSAWithSHA256),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+		testsupport.WithGeneratedSubjectKeyID())
+	require.NoError(t, err)
+
+	certKeyID := hex.EncodeToString(cert.SubjectKeyId)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(cert))
+	require.NoError(t, err)
+
+	trustStorePath := t.TempDir() + "/trust_store.pem"
+	require.NoError(t, os.WriteFile(trustStorePath, pemBytes, 0o600))
+
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+trust_store: ` + trustStorePath + `
+max_age: 1m
+leeway: 1s`))
+	require.NoError(t, err)
+
+	msgURL, err := url.Parse("https://example.com/foo")
+	require.NoError(t, err)
+
+	msg := testHTTPMessage{method: http.MethodGet, url: msgURL, header: http.Header{}}
+	signHTTPMessage(t, msg, ecdsaKey,
+		`("@method" "@authority" "@path");created=`+strconv.FormatInt(time.Now().Unix(), 10)+
+			`;keyid="`+certKeyID+`";nonce="abc"`)
+
+	newContext := func(appCtx context.Context) heimdall.Context {
+		fnt := mocks.NewRequestFunctionsMock(t)
+		fnt.EXPECT().Header(mock.Anything).RunAndReturn(msg.header.Get).Maybe()
+
+		ctx := mocks.NewContextMock(t)
+		ctx.EXPECT().AppContext().Return(appCtx).Maybe()
+		ctx.EXPECT().Request().Return(&heimdall.Request{
+			RequestFunctions: fnt,
+			Method:           msg.method,
+			URL:              &heimdall.URL{URL: *msgURL},
+		})
+
+		return ctx
+	}
+
+	t.Run("case=nonce replayed concurrently", func(t *testing.T) {
+		// GIVEN
+		const requests = 20
+
+		auth, err := newHTTPMessageSignaturesAuthenticator("auth1", conf)
+		require.NoError(t, err)
+
+		appCtx := cache.WithContext(context.Background(), memory.New())
+		errs := make([]error, requests)
+
+		var wg sync.WaitGroup
+
+		// WHEN
+		for i := 0; i < requests; i++ {
+			ctx := newContext(appCtx)
+
+			wg.Add(1)
+
+			go func(idx int) {
+				defer wg.Done()
+
+				_, errs[idx] = auth.Execute(ctx)
+			}(i)
+		}
+
+		wg.Wait()
+
+		// THEN
+		succeeded := 0
+
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+
+				continue
+			}
+
+			require.ErrorIs(t, err, heimdall.ErrAuthentication)
+			assert.Contains(t, err.Error(), "nonce has already been used")
+		}
+
+		assert.Equal(t, 1, succeeded)
+	})
+
+	t.Run("case=cache disabled", func(t *testing.T) {
+		// GIVEN
+		auth, err := newHTTPMessageSignaturesAuthenticator("auth1", conf)
+		require.NoError(t, err)
+
+		// WHEN
+		_, err = auth.Execute(newContext(context.Background()))
+
+		// THEN
+		require.Error(t, err)
+		require.ErrorIs(t, err, heimdall.ErrInternal)
+		assert.Contains(t, err.Error(), "cache, which is disabled")
+	})
+}
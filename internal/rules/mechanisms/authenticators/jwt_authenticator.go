@@ -65,6 +65,7 @@ func init() {
 type jwtAuthenticator struct {
 	id                   string
 	e                    endpoint.Endpoint
+	m                    *oauth2.MetadataEndpoint
 	a                    oauth2.Expectation
 	ttl                  *time.Duration
 	sf                   SubjectFactory
@@ -72,18 +73,21 @@ type jwtAuthenticator struct {
 	allowFallbackOnError bool
 	trustStore           truststore.TrustStore
 	validateJWKCert      bool
+	dpop                 *dpopVerifier
 }
 
 func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator, error) { // nolint: funlen
 	type Config struct {
-		Endpoint             endpoint.Endpoint                   `mapstructure:"jwks_endpoint"           validate:"required"`
-		Assertions           oauth2.Expectation                  `mapstructure:"assertions"              validate:"required"`
+		Endpoint             *endpoint.Endpoint                  `mapstructure:"jwks_endpoint"           validate:"required_without=MetadataEndpoint"`                //nolint:lll
+		MetadataEndpoint     *oauth2.MetadataEndpoint            `mapstructure:"metadata_endpoint"       validate:"required_without=Endpoint,excluded_with=Endpoint"` //nolint:lll
+		Assertions           oauth2.Expectation                  `mapstructure:"assertions"              validate:"-"`
 		SubjectInfo          SubjectInfo                         `mapstructure:"subject"                 validate:"-"`
 		AuthDataSource       extractors.CompositeExtractStrategy `mapstructure:"jwt_source"`
 		CacheTTL             *time.Duration                      `mapstructure:"cache_ttl"`
 		AllowFallbackOnError bool                                `mapstructure:"allow_fallback_on_error"`
 		ValidateJWK          *bool                               `mapstructure:"validate_jwk"`
 		TrustStore           truststore.TrustStore               `mapstructure:"trust_store"`
+		DPoP                 *DPoPConfig                         `mapstructure:"dpop"`
 	}
 
 	var conf Config
@@ -91,20 +95,22 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 		return nil, err
 	}
 
-	if conf.Endpoint.Headers == nil {
-		conf.Endpoint.Headers = make(map[string]string)
-	}
-
-	if _, ok := conf.Endpoint.Headers["Accept-Type"]; !ok {
-		conf.Endpoint.Headers["Accept-Type"] = "application/json"
-	}
+	if conf.MetadataEndpoint == nil {
+		// issuers and allowed algorithms can only be taken from the server metadata
+		// if the latter is configured
+		if err := validateAssertions(AuthenticatorJwt, conf.Assertions); err != nil {
+			return nil, err
+		}
 
-	if len(conf.Endpoint.Method) == 0 {
-		conf.Endpoint.Method = "GET"
+		if len(conf.Assertions.AllowedAlgorithms) == 0 {
+			conf.Assertions.AllowedAlgorithms = defaultAllowedAlgorithms()
+		}
 	}
 
-	if len(conf.Assertions.AllowedAlgorithms) == 0 {
-		conf.Assertions.AllowedAlgorithms = defaultAllowedAlgorithms()
+	var ep endpoint.Endpoint
+	if conf.Endpoint != nil {
+		ep = *conf.Endpoint
+		setJWKSEndpointDefaults(&ep)
 	}
 
 	if conf.Assertions.ScopesMatcher == nil {
@@ -115,24 +121,38 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 		conf.SubjectInfo.IDFrom = "sub"
 	}
 
+	dpop, err := newDPoPVerifier(conf.DPoP)
+	if err != nil {
+		return nil, err
+	}
+
 	validateJWKCert := x.IfThenElseExec(conf.ValidateJWK != nil,
 		func() bool { return *conf.ValidateJWK },
 		func() bool { return true })
 
 	ads := x.IfThenElseExec(conf.AuthDataSource == nil,
 		func() extractors.CompositeExtractStrategy {
-			return extractors.CompositeExtractStrategy{
+			strategies := extractors.CompositeExtractStrategy{
 				extractors.HeaderValueExtractStrategy{Name: "Authorization", Schema: "Bearer"},
 				extractors.QueryParameterExtractStrategy{Name: "access_token"},
 				extractors.BodyParameterExtractStrategy{Name: "access_token"},
 			}
+
+			if dpop != nil {
+				// DPoP bound access tokens are sent using the DPoP authentication scheme
+				strategies = append(strategies,
+					extractors.HeaderValueExtractStrategy{Name: "Authorization", Schema: "DPoP"})
+			}
+
+			return strategies
 		},
 		func() extractors.CompositeExtractStrategy { return conf.AuthDataSource },
 	)
 
 	return &jwtAuthenticator{
 		id:                   id,
-		e:                    conf.Endpoint,
+		e:                    ep,
+		m:                    conf.MetadataEndpoint,
 		a:                    conf.Assertions,
 		ttl:                  conf.CacheTTL,
 		sf:                   &conf.SubjectInfo,
@@ -140,6 +160,7 @@ func newJwtAuthenticator(id string, rawConfig map[string]any) (*jwtAuthenticator
 		allowFallbackOnError: conf.AllowFallbackOnError,
 		validateJWKCert:      validateJWKCert,
 		trustStore:           conf.TrustStore,
+		dpop:                 dpop,
 	}, nil
 }
 
@@ -164,11 +185,22 @@ func (a *jwtAuthenticator) Execute(ctx heimdall.Context) (*subject.Subject, erro
 			CausedBy(err)
 	}
 
-	rawClaims, err := a.verifyToken(ctx, token)
+	auth, err := a.resolveServerMetadata(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	rawClaims, err := auth.verifyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth.dpop != nil {
+		if err = auth.verifyDPoP(ctx, jwtAd, rawClaims); err != nil {
+			return nil, err
+		}
+	}
+
 	sub, err := a.sf.CreateSubject(rawClaims)
 	if err != nil {
 		return nil, errorchain.
@@ -200,6 +232,7 @@ func (a *jwtAuthenticator) WithConfig(config map[string]any) (Authenticator, err
 	return &jwtAuthenticator{
 		id:  a.id,
 		e:   a.e,
+		m:   a.m,
 		a:   conf.Assertions.Merge(&a.a),
 		ttl: x.IfThenElse(conf.CacheTTL != nil, conf.CacheTTL, a.ttl),
 		sf:  a.sf,
@@ -209,6 +242,7 @@ func (a *jwtAuthenticator) WithConfig(config map[string]any) (Authenticator, err
 			func() bool { return a.allowFallbackOnError }),
 		validateJWKCert: a.validateJWKCert,
 		trustStore:      a.trustStore,
+		dpop:            a.dpop,
 	}, nil
 }
 
@@ -220,6 +254,46 @@ func (a *jwtAuthenticator) ID() string {
 	return a.id
 }
 
+// resolveServerMetadata returns an authenticator instance, which uses the JWKS endpoint, the trusted
+// issuer and the allowed algorithms from the server metadata document, if a metadata endpoint
+// is configured. Settings configured explicitly for the assertions take precedence.
+func (a *jwtAuthenticator) resolveServerMetadata(ctx heimdall.Context) (*jwtAuthenticator, error) {
+	if a.m == nil {
+		return a, nil
+	}
+
+	metadata, err := a.m.Get(ctx.AppContext())
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "failed retrieving server metadata").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(metadata.JWKSEndpointURL) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "server metadata does not contain a jwks_uri").
+			WithErrorContext(a)
+	}
+
+	auth := *a
+	auth.e = a.m.ResolvedEndpoint(metadata.JWKSEndpointURL)
+	setJWKSEndpointDefaults(&auth.e)
+
+	if len(auth.a.TrustedIssuers) == 0 {
+		auth.a.TrustedIssuers = []string{metadata.Issuer}
+	}
+
+	if len(auth.a.AllowedAlgorithms) == 0 {
+		algorithms := metadata.AllowedAlgorithms()
+		auth.a.AllowedAlgorithms = x.IfThenElseExec(len(algorithms) != 0,
+			func() []string { return algorithms },
+			defaultAllowedAlgorithms)
+	}
+
+	return &auth, nil
+}
+
 func (a *jwtAuthenticator) isCacheEnabled() bool {
 	// cache is enabled if ttl is not configured (in that case the ttl value from either
 	// the jwk cert (if available) or the defaultTTL is used), or if ttl is configured and
@@ -490,6 +564,20 @@ func (a *jwtAuthenticator) calculateCacheKey(reference string) string {
 	return hex.EncodeToString(digest.Sum(nil))
 }
 
+func setJWKSEndpointDefaults(ep *endpoint.Endpoint) {
+	if ep.Headers == nil {
+		ep.Headers = make(map[string]string)
+	}
+
+	if _, ok := ep.Headers["Accept-Type"]; !ok {
+		ep.Headers["Accept-Type"] = "application/json"
+	}
+
+	if len(ep.Method) == 0 {
+		ep.Method = "GET"
+	}
+}
+
 func (a *jwtAuthenticator) validateJWK(jwk *jose.JSONWebKey) error {
 	if !a.validateJWKCert || len(jwk.Certificates) == 0 {
 		return nil
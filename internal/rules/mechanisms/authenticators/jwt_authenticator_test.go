@@ -33,6 +33,7 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/square/go-jose.v2"
 	"gopkg.in/square/go-jose.v2/jwt"
@@ -136,6 +137,67 @@ subject:
 				assert.Contains(t, err.Error(), "'issuers' is a required field")
 			},
 		},
+		{
+			uc: "with both, jwks and metadata endpoints configured",
+			config: []byte(`
+jwks_endpoint:
+  url: http://test.com
+metadata_endpoint:
+  url: http://test.com/.well-known/openid-configuration
+`),
+			assert: func(t *testing.T, err error, a *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'metadata_endpoint' cannot be used together with endpoint")
+			},
+		},
+		{
+			uc: "with metadata endpoint without url",
+			config: []byte(`
+metadata_endpoint:
+  disable_issuer_identifier_verification: true
+`),
+			assert: func(t *testing.T, err error, a *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'url' is a required field")
+			},
+		},
+		{
+			uc: "valid configuration with metadata endpoint and without assertions",
+			id: "auth1",
+			config: []byte(`
+metadata_endpoint:
+  url: http://test.com/.well-known/openid-configuration
+  resolved_endpoints:
+    enable_http_cache: true
+`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				// endpoints settings
+				assert.Empty(t, auth.e.URL)
+				require.NotNil(t, auth.m)
+				assert.Equal(t, "http://test.com/.well-known/openid-configuration", auth.m.URL)
+				assert.False(t, auth.m.DisableIssuerIdentifierVerification)
+				require.NotNil(t, auth.m.ResolvedEndpoints.HTTPCacheEnabled)
+				assert.True(t, *auth.m.ResolvedEndpoints.HTTPCacheEnabled)
+
+				// assertions are taken from the server metadata
+				require.NoError(t, auth.a.ScopesMatcher.Match([]string{}))
+				assert.Empty(t, auth.a.TrustedIssuers)
+				assert.Empty(t, auth.a.AllowedAlgorithms)
+
+				// id
+				assert.Equal(t, "auth1", auth.ID())
+			},
+		},
 		{
 			uc: "valid configuration with defaults, without cache",
 			id: "auth1",
@@ -1229,6 +1291,142 @@ func TestJwtAuthenticatorExecute(t *testing.T) {
 				assert.Equal(t, "auth3", identifier.ID())
 			},
 		},
+		{
+			uc: "with failing server metadata retrieval",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				m: &oauth2.MetadataEndpoint{
+					Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"},
+				},
+				a: oauth2.Expectation{ScopesMatcher: oauth2.ExactScopeStrategyMatcher{}},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+				cch.EXPECT().Get(mock.Anything).Return(nil)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkRequest = func(req *http.Request) {
+					assert.Equal(t, http.MethodGet, req.Method)
+					assert.Equal(t, "/.well-known/openid-configuration", req.URL.Path)
+					assert.Equal(t, "application/json", req.Header.Get("Accept"))
+				}
+
+				responseCode = http.StatusInternalServerError
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "failed retrieving server metadata")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
+		{
+			uc: "with server metadata not referencing a jwks endpoint",
+			authenticator: &jwtAuthenticator{
+				id: "auth3",
+				m: &oauth2.MetadataEndpoint{
+					Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"},
+				},
+				a: oauth2.Expectation{ScopesMatcher: oauth2.ExactScopeStrategyMatcher{}},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+				cch.EXPECT().Get(mock.Anything).Return(&oauth2.ServerMetadata{Issuer: issuer})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "does not contain a jwks_uri")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
+		{
+			uc: "successful with settings taken from server metadata",
+			authenticator: &jwtAuthenticator{
+				m: &oauth2.MetadataEndpoint{
+					Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"},
+				},
+				a:   oauth2.Expectation{ScopesMatcher: oauth2.ExactScopeStrategyMatcher{}},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &tenSecondsTTL,
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				auth *jwtAuthenticator,
+			) {
+				t.Helper()
+
+				var jwks jose.JSONWebKeySet
+				err := json.Unmarshal(jwksWithOneKeyOnlyEntry, &jwks)
+				require.NoError(t, err)
+
+				keys := jwks.Key(kidKeyWithoutCert)
+
+				ads.EXPECT().GetAuthData(ctx).Return(jwtSignedWithKeyOnlyJWK, nil)
+				cch.EXPECT().Get(mock.Anything).Return(&oauth2.ServerMetadata{
+					Issuer:            issuer,
+					JWKSEndpointURL:   srv.URL + "/jwks",
+					SigningAlgorithms: []string{"none", "ES384"},
+				}).Once()
+				cch.EXPECT().Get(mock.Anything).Return(nil).Once()
+				cch.EXPECT().Set(mock.Anything, &keys[0], *auth.ttl)
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkRequest = func(req *http.Request) {
+					assert.Equal(t, http.MethodGet, req.Method)
+					assert.Equal(t, "/jwks", req.URL.Path)
+				}
+
+				responseCode = http.StatusOK
+				responseContent = jwksWithOneKeyOnlyEntry
+				responseContentType = "application/json"
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, subjectID, sub.ID)
+				assert.Equal(t, issuer, sub.Attributes["iss"])
+			},
+		},
 		{
 			uc: "successful with positive cache hit",
 			authenticator: &jwtAuthenticator{
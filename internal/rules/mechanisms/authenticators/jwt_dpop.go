@@ -0,0 +1,302 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/oauth2"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	defaultDPoPProofMaxAge = 1 * time.Minute
+	defaultDPoPProofLeeway = 5 * time.Second
+
+	dpopProofType = "dpop+jwt"
+)
+
+var (
+	errInvalidDPoPProof      = errors.New("invalid DPoP proof")
+	errInvalidDPoPBoundToken = errors.New("invalid DPoP bound access token")
+)
+
+type DPoPConfig struct {
+	Required          bool           `mapstructure:"required"`
+	AllowedAlgorithms []string       `mapstructure:"allowed_algorithms"`
+	MaxAge            *time.Duration `mapstructure:"max_age"`
+	Leeway            *time.Duration `mapstructure:"leeway"`
+}
+
+// dpopVerifier holds the settings for the verification of DPoP proofs (RFC 9449).
+type dpopVerifier struct {
+	required   bool
+	algorithms []string
+	maxAge     time.Duration
+	leeway     time.Duration
+}
+
+func newDPoPVerifier(conf *DPoPConfig) (*dpopVerifier, error) {
+	if conf == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	// symmetric algorithms cannot be used, as the key is taken from the proof itself
+	supported := []string{
+		string(jose.ES256), string(jose.ES384), string(jose.ES512),
+		string(jose.PS256), string(jose.PS384), string(jose.PS512),
+		string(jose.RS256), string(jose.RS384), string(jose.RS512),
+		string(jose.EdDSA),
+	}
+
+	for _, alg := range conf.AllowedAlgorithms {
+		if !slices.Contains(supported, alg) {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"%s algorithm is not supported for DPoP proofs", alg)
+		}
+	}
+
+	return &dpopVerifier{
+		required: conf.Required,
+		algorithms: x.IfThenElseExec(len(conf.AllowedAlgorithms) != 0,
+			func() []string { return conf.AllowedAlgorithms },
+			func() []string { return append(defaultAllowedAlgorithms(), string(jose.EdDSA)) }),
+		maxAge: x.IfThenElseExec(conf.MaxAge != nil,
+			func() time.Duration { return *conf.MaxAge },
+			func() time.Duration { return defaultDPoPProofMaxAge }),
+		leeway: x.IfThenElseExec(conf.Leeway != nil,
+			func() time.Duration { return *conf.Leeway },
+			func() time.Duration { return defaultDPoPProofLeeway }),
+	}, nil
+}
+
+type dpopProofClaims struct {
+	ID              string              `json:"jti"`
+	Method          string              `json:"htm"`
+	URI             string              `json:"htu"`
+	IssuedAt        *oauth2.NumericDate `json:"iat"`
+	AccessTokenHash string              `json:"ath"`
+}
+
+// verifyDPoP verifies the DPoP proof sent with the request according to RFC 9449, section 4.3
+// and checks the access token is bound to the key used for the proof.
+func (a *jwtAuthenticator) verifyDPoP(ctx heimdall.Context, accessToken string, rawClaims json.RawMessage) error {
+	var claims struct {
+		Confirmation struct {
+			JWKThumbprint string `json:"jkt"`
+		} `json:"cnf"`
+	}
+
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal jwt payload").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	authorization := ctx.Request().Header("Authorization")
+
+	jkt := claims.Confirmation.JWKThumbprint
+	if len(jkt) == 0 {
+		// a token sent using the DPoP scheme claims to be bound, which is not the case (RFC 9449, section 7.1)
+		if a.dpop.required || strings.HasPrefix(authorization, "DPoP ") {
+			return a.dpopError(errInvalidDPoPBoundToken, "access token is not DPoP bound")
+		}
+
+		return nil
+	}
+
+	if strings.HasPrefix(authorization, "Bearer ") {
+		return a.dpopError(errInvalidDPoPBoundToken,
+			"DPoP bound access token must not be used with the Bearer authentication scheme")
+	}
+
+	jwk, proof, err := a.verifyDPoPProof(ctx.Request())
+	if err != nil {
+		return err
+	}
+
+	tokenHash := sha256.Sum256(stringx.ToBytes(accessToken))
+	if proof.AccessTokenHash != base64.RawURLEncoding.EncodeToString(tokenHash[:]) {
+		return a.dpopError(errInvalidDPoPProof, "DPoP proof is not bound to the access token")
+	}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return a.dpopError(errInvalidDPoPProof, "failed to calculate the thumbprint of the DPoP proof key").
+			CausedBy(err)
+	}
+
+	if subtle.ConstantTimeCompare(stringx.ToBytes(base64.RawURLEncoding.EncodeToString(thumbprint)),
+		stringx.ToBytes(jkt)) != 1 {
+		return a.dpopError(errInvalidDPoPBoundToken, "access token is not bound to the DPoP proof key")
+	}
+
+	// the jti is recorded only after all other checks succeeded, so that no one
+	// can prevent the usage of a proof by sending it together with an invalid token
+	return a.checkDPoPProofID(ctx, jkt, proof.ID)
+}
+
+func (a *jwtAuthenticator) verifyDPoPProof(req *heimdall.Request) (*jose.JSONWebKey, *dpopProofClaims, error) {
+	value := req.Header("DPoP")
+	if len(value) == 0 {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "no DPoP proof present")
+	}
+
+	if strings.Contains(value, ",") {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "multiple DPoP proofs present")
+	}
+
+	token, err := jwt.ParseSigned(value)
+	if err != nil {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "failed to parse DPoP proof").CausedBy(err)
+	}
+
+	header := token.Headers[0]
+
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); !strings.EqualFold(typ, dpopProofType) {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "DPoP proof has an unexpected type")
+	}
+
+	if !slices.Contains(a.dpop.algorithms, header.Algorithm) {
+		return nil, nil, a.dpopError(errInvalidDPoPProof,
+			fmt.Sprintf("%s algorithm is not allowed for DPoP proofs", header.Algorithm))
+	}
+
+	jwk := header.JSONWebKey
+	if jwk == nil || !jwk.Valid() || !jwk.IsPublic() {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "DPoP proof does not contain a valid public key")
+	}
+
+	var claims dpopProofClaims
+	if err = token.Claims(jwk, &claims); err != nil {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "failed to verify DPoP proof signature").CausedBy(err)
+	}
+
+	if len(claims.ID) == 0 || claims.IssuedAt == nil {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "DPoP proof does not contain the jti or the iat claim")
+	}
+
+	if claims.Method != req.Method {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "DPoP proof was created for another HTTP method")
+	}
+
+	htu, err := url.Parse(claims.URI)
+	if err != nil || normalizeDPoPURI(htu) != normalizeDPoPURI(&req.URL.URL) {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "DPoP proof was created for another URI")
+	}
+
+	now := time.Now()
+	issuedAt := claims.IssuedAt.Time()
+
+	if issuedAt.After(now.Add(a.dpop.leeway)) {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "DPoP proof has been issued in the future")
+	}
+
+	if issuedAt.Add(a.dpop.maxAge).Before(now.Add(-a.dpop.leeway)) {
+		return nil, nil, a.dpopError(errInvalidDPoPProof, "DPoP proof is too old")
+	}
+
+	return jwk, &claims, nil
+}
+
+func (a *jwtAuthenticator) checkDPoPProofID(ctx heimdall.Context, jkt, jti string) error {
+	cch := cache.Ctx(ctx.AppContext())
+	if cache.IsDisabled(cch) {
+		// used jti values cannot be remembered without the cache, so replays would not be detected
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "DPoP proof verification requires the cache, which is disabled").
+			WithErrorContext(a)
+	}
+
+	digest := sha256.New()
+	digest.Write(stringx.ToBytes(AuthenticatorJwt))
+	digest.Write(stringx.ToBytes(a.id))
+	digest.Write(stringx.ToBytes(jkt))
+	digest.Write(stringx.ToBytes(jti))
+	cacheKey := hex.EncodeToString(digest.Sum(nil))
+
+	// proofs older than that are rejected anyway
+	if !cch.SetIfAbsent(cacheKey, true, a.dpop.maxAge+2*a.dpop.leeway) {
+		return a.dpopError(errInvalidDPoPProof, "DPoP proof has already been used")
+	}
+
+	return nil
+}
+
+func (a *jwtAuthenticator) dpopError(cause error, message string) *errorchain.ErrorChain {
+	return errorchain.
+		NewWithMessage(heimdall.ErrAuthentication, message).
+		WithErrorContext(a).
+		CausedBy(cause)
+}
+
+// WWWAuthenticateChallenge returns the DPoP challenge as described in RFC 9449, section 7.1,
+// matching the given error, if DPoP support is enabled. Otherwise, an empty string is returned.
+func (a *jwtAuthenticator) WWWAuthenticateChallenge(err error) string {
+	if a.dpop == nil {
+		return ""
+	}
+
+	algs := strings.Join(a.dpop.algorithms, " ")
+
+	switch {
+	case errors.Is(err, errInvalidDPoPProof):
+		return fmt.Sprintf(`DPoP error="invalid_dpop_proof", algs="%s"`, algs)
+	case errors.Is(err, errInvalidDPoPBoundToken):
+		return fmt.Sprintf(`DPoP error="invalid_token", algs="%s"`, algs)
+	default:
+		return fmt.Sprintf(`DPoP algs="%s"`, algs)
+	}
+}
+
+// normalizeDPoPURI returns the URI without query and fragment in its normalized form
+// as required for the comparison of the htu claim (RFC 9449, section 4.3).
+func normalizeDPoPURI(uri *url.URL) string {
+	scheme := strings.ToLower(uri.Scheme)
+	host := strings.ToLower(uri.Hostname())
+
+	if port := uri.Port(); len(port) != 0 &&
+		!(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host = host + ":" + port
+	}
+
+	path := uri.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	return scheme + "://" + host + path
+}
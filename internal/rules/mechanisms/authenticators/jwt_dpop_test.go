@@ -0,0 +1,757 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/authenticators/extractors"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+type dpopProof struct {
+	typ    string
+	key    *ecdsa.PrivateKey
+	alg    jose.SignatureAlgorithm
+	claims map[string]any
+}
+
+func createDPoPProof(t *testing.T, proof dpopProof) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: proof.alg, Key: proof.key},
+		(&jose.SignerOptions{EmbedJWK: true}).WithType(jose.ContentType(proof.typ)))
+	require.NoError(t, err)
+
+	raw, err := jwt.Signed(signer).Claims(proof.claims).CompactSerialize()
+	require.NoError(t, err)
+
+	return raw
+}
+
+func dpopThumbprint(t *testing.T, key *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	jwk := jose.JSONWebKey{Key: key.Public()}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint)
+}
+
+func dpopAccessTokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func TestCreateJwtAuthenticatorWithDPoP(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, auth *jwtAuthenticator)
+	}{
+		{
+			uc: "without dpop configuration",
+			config: []byte(`
+jwks_endpoint: http://test.com
+assertions:
+  issuers: [foo]`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Nil(t, auth.dpop)
+				assert.Empty(t, auth.WWWAuthenticateChallenge(heimdall.ErrAuthentication))
+				assert.Len(t, auth.ads, 3)
+			},
+		},
+		{
+			uc: "with default dpop configuration",
+			config: []byte(`
+jwks_endpoint: http://test.com
+assertions:
+  issuers: [foo]
+dpop: {}`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth.dpop)
+				assert.False(t, auth.dpop.required)
+				assert.Equal(t, []string{
+					string(jose.ES256), string(jose.ES384), string(jose.ES512),
+					string(jose.PS256), string(jose.PS384), string(jose.PS512),
+					string(jose.EdDSA),
+				}, auth.dpop.algorithms)
+				assert.Equal(t, defaultDPoPProofMaxAge, auth.dpop.maxAge)
+				assert.Equal(t, defaultDPoPProofLeeway, auth.dpop.leeway)
+
+				require.Len(t, auth.ads, 4)
+				assert.Contains(t, auth.ads,
+					extractors.HeaderValueExtractStrategy{Name: "Authorization", Schema: "DPoP"})
+			},
+		},
+		{
+			uc: "with full dpop configuration",
+			config: []byte(`
+jwks_endpoint: http://test.com
+assertions:
+  issuers: [foo]
+dpop:
+  required: true
+  allowed_algorithms: [ES256, RS256]
+  max_age: 30s
+  leeway: 1s`),
+			assert: func(t *testing.T, err error, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, auth.dpop)
+				assert.True(t, auth.dpop.required)
+				assert.Equal(t, []string{"ES256", "RS256"}, auth.dpop.algorithms)
+				assert.Equal(t, 30*time.Second, auth.dpop.maxAge)
+				assert.Equal(t, 1*time.Second, auth.dpop.leeway)
+			},
+		},
+		{
+			uc: "with symmetric algorithm for dpop proofs",
+			config: []byte(`
+jwks_endpoint: http://test.com
+assertions:
+  issuers: [foo]
+dpop:
+  allowed_algorithms: [HS256]`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "HS256 algorithm is not supported")
+			},
+		},
+		{
+			uc: "with unsupported dpop properties",
+			config: []byte(`
+jwks_endpoint: http://test.com
+assertions:
+  issuers: [foo]
+dpop:
+  foo: bar`),
+			assert: func(t *testing.T, err error, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newJwtAuthenticator("auth1", conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestJwtAuthenticatorExecuteWithDPoP(t *testing.T) {
+	t.Parallel()
+
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherClientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p384ClientKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: issuerKey.Public(), KeyID: "issuer-key", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(jwks)
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	createAccessToken := func(t *testing.T, jkt string) string {
+		t.Helper()
+
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.ES256, Key: issuerKey},
+			(&jose.SignerOptions{}).WithType("at+jwt").WithHeader("kid", "issuer-key"))
+		require.NoError(t, err)
+
+		claims := map[string]any{
+			"sub": "foo",
+			"iss": "issuer",
+			"iat": time.Now().Unix() - 1,
+			"exp": time.Now().Unix() + 60,
+		}
+
+		if len(jkt) != 0 {
+			claims["cnf"] = map[string]any{"jkt": jkt}
+		}
+
+		raw, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+
+		return raw
+	}
+
+	boundToken := createAccessToken(t, dpopThumbprint(t, clientKey))
+	unboundToken := createAccessToken(t, "")
+
+	proofClaims := func(token string, modify func(claims map[string]any)) map[string]any {
+		claims := map[string]any{
+			"jti": "4711",
+			"htm": http.MethodGet,
+			"htu": "https://api.example.com/resource",
+			"iat": time.Now().Unix(),
+			"ath": dpopAccessTokenHash(token),
+		}
+
+		if modify != nil {
+			modify(claims)
+		}
+
+		return claims
+	}
+
+	validProof := func(t *testing.T) string {
+		t.Helper()
+
+		return createDPoPProof(t, dpopProof{
+			typ: dpopProofType, key: clientKey, alg: jose.ES256, claims: proofClaims(boundToken, nil),
+		})
+	}
+
+	for _, tc := range []struct {
+		uc            string
+		required      bool
+		executions    int
+		concurrent    bool
+		cacheDisabled bool
+		headers       func(t *testing.T) map[string]string
+		assert        func(t *testing.T, err error, sub *subject.Subject, auth *jwtAuthenticator)
+	}{
+		{
+			uc: "not bound access token without proof",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "Bearer " + unboundToken}
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", sub.ID)
+			},
+		},
+		{
+			uc:       "not bound access token, but dpop is required",
+			required: true,
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "Bearer " + unboundToken}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errInvalidDPoPBoundToken)
+				assert.Contains(t, err.Error(), "access token is not DPoP bound")
+				assert.Equal(t, `DPoP error="invalid_token", algs="ES256"`, auth.WWWAuthenticateChallenge(err))
+			},
+		},
+		{
+			uc: "not bound access token used with the DPoP scheme",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "DPoP " + unboundToken}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errInvalidDPoPBoundToken)
+				assert.Contains(t, err.Error(), "access token is not DPoP bound")
+			},
+		},
+		{
+			uc: "bound access token used with the Bearer scheme",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "Bearer " + boundToken, "Dpop": validProof(t)}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errInvalidDPoPBoundToken)
+				assert.Contains(t, err.Error(), "must not be used with the Bearer authentication scheme")
+			},
+		},
+		{
+			uc: "bound access token without proof",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "DPoP " + boundToken}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, auth *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "no DPoP proof present")
+				assert.Equal(t, `DPoP error="invalid_dpop_proof", algs="ES256"`, auth.WWWAuthenticateChallenge(err))
+			},
+		},
+		{
+			uc: "multiple proofs",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "DPoP " + boundToken, "Dpop": validProof(t) + "," + validProof(t)}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "multiple DPoP proofs present")
+			},
+		},
+		{
+			uc: "malformed proof",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "DPoP " + boundToken, "Dpop": "foo.bar"}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "failed to parse DPoP proof")
+			},
+		},
+		{
+			uc: "proof with wrong type",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: "JWT", key: clientKey, alg: jose.ES256, claims: proofClaims(boundToken, nil),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "unexpected type")
+			},
+		},
+		{
+			uc: "proof signed with not allowed algorithm",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: p384ClientKey, alg: jose.ES384, claims: proofClaims(boundToken, nil),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "ES384 algorithm is not allowed")
+			},
+		},
+		{
+			uc: "proof with invalid signature",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				proof := validProof(t)
+				idx := strings.LastIndex(proof, ".")
+				other := createDPoPProof(t, dpopProof{
+					typ: dpopProofType, key: otherClientKey, alg: jose.ES256, claims: proofClaims(boundToken, nil),
+				})
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop":          proof[:idx] + other[strings.LastIndex(other, "."):],
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "failed to verify DPoP proof signature")
+			},
+		},
+		{
+			uc: "proof without jti",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: clientKey, alg: jose.ES256,
+						claims: proofClaims(boundToken, func(claims map[string]any) { delete(claims, "jti") }),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "does not contain the jti or the iat claim")
+			},
+		},
+		{
+			uc: "proof for another method",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: clientKey, alg: jose.ES256,
+						claims: proofClaims(boundToken, func(claims map[string]any) { claims["htm"] = "POST" }),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "created for another HTTP method")
+			},
+		},
+		{
+			uc: "proof for another uri",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: clientKey, alg: jose.ES256,
+						claims: proofClaims(boundToken, func(claims map[string]any) {
+							claims["htu"] = "https://api.example.com/other"
+						}),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "created for another URI")
+			},
+		},
+		{
+			uc: "proof issued in the future",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: clientKey, alg: jose.ES256,
+						claims: proofClaims(boundToken, func(claims map[string]any) {
+							claims["iat"] = time.Now().Add(time.Minute).Unix()
+						}),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "issued in the future")
+			},
+		},
+		{
+			uc: "too old proof",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: clientKey, alg: jose.ES256,
+						claims: proofClaims(boundToken, func(claims map[string]any) {
+							claims["iat"] = time.Now().Add(-2 * time.Minute).Unix()
+						}),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "too old")
+			},
+		},
+		{
+			uc: "proof for another access token",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: clientKey, alg: jose.ES256, claims: proofClaims(unboundToken, nil),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "not bound to the access token")
+			},
+		},
+		{
+			uc: "access token bound to another key",
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: otherClientKey, alg: jose.ES256, claims: proofClaims(boundToken, nil),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPBoundToken)
+				assert.Contains(t, err.Error(), "not bound to the DPoP proof key")
+			},
+		},
+		{
+			uc:         "replayed proof",
+			executions: 2,
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "DPoP " + boundToken, "Dpop": validProof(t)}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, errInvalidDPoPProof)
+				assert.Contains(t, err.Error(), "already been used")
+			},
+		},
+		{
+			uc:         "proof replayed concurrently",
+			executions: 20,
+			concurrent: true,
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "DPoP " + boundToken, "Dpop": validProof(t)}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				var joined interface{ Unwrap() []error }
+
+				// all but one request must fail
+				require.ErrorAs(t, err, &joined)
+				require.Len(t, joined.Unwrap(), 19)
+
+				for _, err := range joined.Unwrap() {
+					require.ErrorIs(t, err, errInvalidDPoPProof)
+					assert.Contains(t, err.Error(), "already been used")
+				}
+			},
+		},
+		{
+			uc:            "valid proof, but cache disabled",
+			cacheDisabled: true,
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{"Authorization": "DPoP " + boundToken, "Dpop": validProof(t)}
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "cache, which is disabled")
+			},
+		},
+		{
+			uc:       "valid proof",
+			required: true,
+			headers: func(t *testing.T) map[string]string {
+				t.Helper()
+
+				return map[string]string{
+					"Authorization": "DPoP " + boundToken,
+					"Dpop": createDPoPProof(t, dpopProof{
+						typ: dpopProofType, key: clientKey, alg: jose.ES256,
+						claims: proofClaims(boundToken, func(claims map[string]any) {
+							// query and default port are not considered
+							claims["htu"] = "https://API.example.com:443/resource?foo=bar"
+						}),
+					}),
+				}
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject, _ *jwtAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "foo", sub.ID)
+				assert.Equal(t, map[string]any{"jkt": dpopThumbprint(t, clientKey)}, sub.Attributes["cnf"])
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			executions := x.IfThenElse(tc.executions != 0, tc.executions, 1)
+
+			conf, err := testsupport.DecodeTestConfig([]byte(`
+jwks_endpoint: ` + srv.URL + `
+assertions:
+  issuers: [issuer]
+dpop:
+  allowed_algorithms: [ES256]
+  required: ` + strconv.FormatBool(tc.required)))
+			require.NoError(t, err)
+
+			auth, err := newJwtAuthenticator("auth1", conf)
+			require.NoError(t, err)
+
+			headers := tc.headers(t)
+			reqURL, err := url.Parse("https://api.example.com/resource?bar=baz")
+			require.NoError(t, err)
+
+			appCtx := x.IfThenElse(tc.cacheDisabled,
+				context.Background(), cache.WithContext(context.Background(), memory.New()))
+
+			var (
+				sub  *subject.Subject
+				wg   sync.WaitGroup
+				errs = make([]error, executions)
+			)
+
+			for i := 0; i < executions; i++ {
+				fnt := mocks.NewRequestFunctionsMock(t)
+				fnt.EXPECT().Header(mock.Anything).
+					RunAndReturn(func(name string) string { return headers[http.CanonicalHeaderKey(name)] }).
+					Maybe()
+				fnt.EXPECT().Body().Return(nil).Maybe()
+
+				ctx := mocks.NewContextMock(t)
+				ctx.EXPECT().AppContext().Return(appCtx).Maybe()
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					RequestFunctions: fnt,
+					Method:           http.MethodGet,
+					URL:              &heimdall.URL{URL: *reqURL},
+				})
+
+				// WHEN
+				if !tc.concurrent {
+					sub, err = auth.Execute(ctx)
+
+					continue
+				}
+
+				wg.Add(1)
+
+				go func(idx int) {
+					defer wg.Done()
+
+					_, errs[idx] = auth.Execute(ctx)
+				}(i)
+			}
+
+			wg.Wait()
+
+			if tc.concurrent {
+				err = errors.Join(errs...)
+			}
+
+			// THEN
+			tc.assert(t, err, sub, auth)
+		})
+	}
+}
@@ -59,6 +59,7 @@ func init() {
 type oauth2IntrospectionAuthenticator struct {
 	id                   string
 	e                    endpoint.Endpoint
+	m                    *oauth2.MetadataEndpoint
 	a                    oauth2.Expectation
 	sf                   SubjectFactory
 	ads                  extractors.AuthDataExtractStrategy
@@ -71,8 +72,9 @@ func newOAuth2IntrospectionAuthenticator(id string, rawConfig map[string]any) (
 	error,
 ) {
 	type Config struct {
-		Endpoint             endpoint.Endpoint                   `mapstructure:"introspection_endpoint"  validate:"required"`
-		Assertions           oauth2.Expectation                  `mapstructure:"assertions"              validate:"required"`
+		Endpoint             *endpoint.Endpoint                  `mapstructure:"introspection_endpoint"  validate:"required_without=MetadataEndpoint"`                //nolint:lll
+		MetadataEndpoint     *oauth2.MetadataEndpoint            `mapstructure:"metadata_endpoint"       validate:"required_without=Endpoint,excluded_with=Endpoint"` //nolint:lll
+		Assertions           oauth2.Expectation                  `mapstructure:"assertions"              validate:"-"`
 		SubjectInfo          SubjectInfo                         `mapstructure:"subject"                 validate:"-"`
 		AuthDataSource       extractors.CompositeExtractStrategy `mapstructure:"token_source"`
 		CacheTTL             *time.Duration                      `mapstructure:"cache_ttl"`
@@ -88,24 +90,22 @@ func newOAuth2IntrospectionAuthenticator(id string, rawConfig map[string]any) (
 		conf.SubjectInfo.IDFrom = "sub"
 	}
 
-	if conf.Endpoint.Headers == nil {
-		conf.Endpoint.Headers = make(map[string]string)
-	}
-
-	if _, ok := conf.Endpoint.Headers["Content-Type"]; !ok {
-		conf.Endpoint.Headers["Content-Type"] = "application/x-www-form-urlencoded"
-	}
-
-	if _, ok := conf.Endpoint.Headers["Accept"]; !ok {
-		conf.Endpoint.Headers["Accept"] = "application/json"
-	}
+	if conf.MetadataEndpoint == nil {
+		// issuers and allowed algorithms can only be taken from the server metadata
+		// if the latter is configured
+		if err := validateAssertions(AuthenticatorOAuth2Introspection, conf.Assertions); err != nil {
+			return nil, err
+		}
 
-	if len(conf.Endpoint.Method) == 0 {
-		conf.Endpoint.Method = http.MethodPost
+		if len(conf.Assertions.AllowedAlgorithms) == 0 {
+			conf.Assertions.AllowedAlgorithms = defaultAllowedAlgorithms()
+		}
 	}
 
-	if len(conf.Assertions.AllowedAlgorithms) == 0 {
-		conf.Assertions.AllowedAlgorithms = defaultAllowedAlgorithms()
+	var ep endpoint.Endpoint
+	if conf.Endpoint != nil {
+		ep = *conf.Endpoint
+		setIntrospectionEndpointDefaults(&ep)
 	}
 
 	if conf.Assertions.ScopesMatcher == nil {
@@ -126,7 +126,8 @@ func newOAuth2IntrospectionAuthenticator(id string, rawConfig map[string]any) (
 	return &oauth2IntrospectionAuthenticator{
 		id:                   id,
 		ads:                  ads,
-		e:                    conf.Endpoint,
+		e:                    ep,
+		m:                    conf.MetadataEndpoint,
 		a:                    conf.Assertions,
 		sf:                   &conf.SubjectInfo,
 		ttl:                  conf.CacheTTL,
@@ -146,7 +147,12 @@ func (a *oauth2IntrospectionAuthenticator) Execute(ctx heimdall.Context) (*subje
 			CausedBy(err)
 	}
 
-	rawResp, err := a.getSubjectInformation(ctx, accessToken)
+	auth, err := a.resolveServerMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawResp, err := auth.getSubjectInformation(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -183,6 +189,7 @@ func (a *oauth2IntrospectionAuthenticator) WithConfig(rawConfig map[string]any)
 	return &oauth2IntrospectionAuthenticator{
 		id:  a.id,
 		e:   a.e,
+		m:   a.m,
 		a:   conf.Assertions.Merge(&a.a),
 		sf:  a.sf,
 		ads: a.ads,
@@ -201,6 +208,48 @@ func (a *oauth2IntrospectionAuthenticator) ID() string {
 	return a.id
 }
 
+// resolveServerMetadata returns an authenticator instance, which uses the introspection endpoint,
+// the trusted issuer and the allowed algorithms from the server metadata document, if a metadata
+// endpoint is configured. Settings configured explicitly for the assertions take precedence.
+func (a *oauth2IntrospectionAuthenticator) resolveServerMetadata(ctx heimdall.Context) (
+	*oauth2IntrospectionAuthenticator, error,
+) {
+	if a.m == nil {
+		return a, nil
+	}
+
+	metadata, err := a.m.Get(ctx.AppContext())
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "failed retrieving server metadata").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(metadata.IntrospectionEndpointURL) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "server metadata does not contain an introspection_endpoint").
+			WithErrorContext(a)
+	}
+
+	auth := *a
+	auth.e = a.m.ResolvedEndpoint(metadata.IntrospectionEndpointURL)
+	setIntrospectionEndpointDefaults(&auth.e)
+
+	if len(auth.a.TrustedIssuers) == 0 {
+		auth.a.TrustedIssuers = []string{metadata.Issuer}
+	}
+
+	if len(auth.a.AllowedAlgorithms) == 0 {
+		algorithms := metadata.AllowedAlgorithms()
+		auth.a.AllowedAlgorithms = x.IfThenElseExec(len(algorithms) != 0,
+			func() []string { return algorithms },
+			defaultAllowedAlgorithms)
+	}
+
+	return &auth, nil
+}
+
 func (a *oauth2IntrospectionAuthenticator) getSubjectInformation(ctx heimdall.Context, token string) ([]byte, error) {
 	cch := cache.Ctx(ctx.AppContext())
 	logger := zerolog.Ctx(ctx.AppContext())
@@ -358,6 +407,24 @@ func (a *oauth2IntrospectionAuthenticator) getCacheTTL(introspectResp *oauth2.In
 	}
 }
 
+func setIntrospectionEndpointDefaults(ep *endpoint.Endpoint) {
+	if ep.Headers == nil {
+		ep.Headers = make(map[string]string)
+	}
+
+	if _, ok := ep.Headers["Content-Type"]; !ok {
+		ep.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+	}
+
+	if _, ok := ep.Headers["Accept"]; !ok {
+		ep.Headers["Accept"] = "application/json"
+	}
+
+	if len(ep.Method) == 0 {
+		ep.Method = http.MethodPost
+	}
+}
+
 func (a *oauth2IntrospectionAuthenticator) calculateCacheKey(reference string) string {
 	digest := sha256.New()
 	digest.Write(a.e.Hash())
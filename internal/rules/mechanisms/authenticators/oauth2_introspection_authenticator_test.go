@@ -103,6 +103,58 @@ subject:
 				assert.Contains(t, err.Error(), "'assertions' is a required field")
 			},
 		},
+		{
+			uc: "with both, introspection and metadata endpoints configured",
+			config: []byte(`
+introspection_endpoint:
+  url: http://foobar.local
+metadata_endpoint:
+  url: http://foobar.local/.well-known/oauth-authorization-server
+`),
+			assert: func(t *testing.T, err error, _ *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'metadata_endpoint' cannot be used together with endpoint")
+			},
+		},
+		{
+			uc: "with valid config using metadata endpoint",
+			id: "auth1",
+			config: []byte(`
+metadata_endpoint:
+  url: http://foobar.local/.well-known/oauth-authorization-server
+  resolved_endpoints:
+    auth:
+      type: basic_auth
+      config:
+        user: foo
+        password: bar
+assertions:
+  audience:
+    - baz
+`),
+			assert: func(t *testing.T, err error, auth *oauth2IntrospectionAuthenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				// endpoint settings
+				assert.Empty(t, auth.e.URL)
+				require.NotNil(t, auth.m)
+				assert.Equal(t, "http://foobar.local/.well-known/oauth-authorization-server", auth.m.URL)
+				assert.NotNil(t, auth.m.ResolvedEndpoints.AuthStrategy)
+
+				// assertions, which are not configured, are taken from the server metadata
+				assert.Empty(t, auth.a.TrustedIssuers)
+				assert.Empty(t, auth.a.AllowedAlgorithms)
+				assert.Equal(t, []string{"baz"}, auth.a.TargetAudiences)
+
+				// id
+				assert.Equal(t, "auth1", auth.ID())
+			},
+		},
 		{
 			uc: "with missing subject config",
 			id: "auth1",
@@ -913,6 +965,103 @@ func TestOauth2IntrospectionAuthenticatorExecute(t *testing.T) {
 				assert.NotEmpty(t, sub.Attributes["exp"])
 			},
 		},
+		{
+			uc: "with server metadata not referencing an introspection endpoint",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				id: "auth3",
+				m: &oauth2.MetadataEndpoint{
+					Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/oauth-authorization-server"},
+				},
+				a: oauth2.Expectation{ScopesMatcher: oauth2.ExactScopeStrategyMatcher{}},
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				cch.EXPECT().Get(mock.Anything).Return(&oauth2.ServerMetadata{Issuer: "foobar"})
+			},
+			assert: func(t *testing.T, err error, _ *subject.Subject) {
+				t.Helper()
+
+				assert.False(t, endpointCalled)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "does not contain an introspection_endpoint")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth3", identifier.ID())
+			},
+		},
+		{
+			uc: "with disabled cache, server metadata and successful execution",
+			authenticator: &oauth2IntrospectionAuthenticator{
+				m: &oauth2.MetadataEndpoint{
+					Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/oauth-authorization-server"},
+				},
+				a:   oauth2.Expectation{ScopesMatcher: oauth2.ExactScopeStrategyMatcher{}},
+				sf:  &SubjectInfo{IDFrom: "sub"},
+				ttl: &zeroTTL,
+			},
+			configureMocks: func(t *testing.T,
+				ctx *heimdallmocks.ContextMock,
+				cch *mocks.CacheMock,
+				ads *mocks2.AuthDataExtractStrategyMock,
+				_ *oauth2IntrospectionAuthenticator,
+			) {
+				t.Helper()
+
+				ads.EXPECT().GetAuthData(ctx).Return("test_access_token", nil)
+				cch.EXPECT().Get(mock.Anything).Return(&oauth2.ServerMetadata{
+					Issuer:                   "foobar",
+					IntrospectionEndpointURL: srv.URL + "/introspect",
+				})
+			},
+			instructServer: func(t *testing.T) {
+				t.Helper()
+
+				checkRequest = func(req *http.Request) {
+					t.Helper()
+
+					assert.Equal(t, "/introspect", req.URL.Path)
+					assert.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+					assert.Equal(t, "application/json", req.Header.Get("Accept"))
+					assert.Equal(t, http.MethodPost, req.Method)
+
+					require.NoError(t, req.ParseForm())
+					assert.Equal(t, "test_access_token", req.Form.Get("token"))
+				}
+
+				rawIntrospectResponse, err := json.Marshal(map[string]any{
+					"active": true,
+					"sub":    "foo",
+					"iss":    "foobar",
+					"exp":    time.Now().Unix() + 30,
+				})
+				require.NoError(t, err)
+
+				responseContentType = "application/json"
+				responseContent = rawIntrospectResponse
+				responseCode = http.StatusOK
+			},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				assert.True(t, endpointCalled)
+
+				require.NoError(t, err)
+
+				require.NotNil(t, sub)
+				assert.Equal(t, "foo", sub.ID)
+				assert.Equal(t, "foobar", sub.Attributes["iss"])
+			},
+		},
 		{
 			uc: "with default cache, without cache hit and successful execution",
 			authenticator: &oauth2IntrospectionAuthenticator{
@@ -0,0 +1,212 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	pkixx "github.com/dadrus/heimdall/internal/x/pkix"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthenticatorTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authenticator, error) {
+			if typ != AuthenticatorX509 {
+				return false, nil, nil
+			}
+
+			auth, err := newX509Authenticator(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type x509Authenticator struct {
+	id                   string
+	trustStore           truststore.TrustStore
+	sf                   SubjectFactory
+	allowFallbackOnError bool
+}
+
+func newX509Authenticator(id string, rawConfig map[string]any) (*x509Authenticator, error) {
+	type Config struct {
+		TrustStore           truststore.TrustStore `mapstructure:"trust_store"             validate:"required"`
+		SubjectInfo          SubjectInfo           `mapstructure:"subject"                 validate:"-"`
+		AllowFallbackOnError bool                  `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorX509, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	if len(conf.SubjectInfo.IDFrom) == 0 {
+		conf.SubjectInfo.IDFrom = "subject.common_name"
+	}
+
+	return &x509Authenticator{
+		id:                   id,
+		trustStore:           conf.TrustStore,
+		sf:                   &conf.SubjectInfo,
+		allowFallbackOnError: conf.AllowFallbackOnError,
+	}, nil
+}
+
+func (a *x509Authenticator) Execute(ctx heimdall.Context) (*subject.Subject, error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authenticating using x509 authenticator")
+
+	chain := ctx.Request().ClientCertificates
+	if len(chain) == 0 {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "no client certificate present in request").
+			WithErrorContext(a)
+	}
+
+	if err := pkixx.ValidateCertificate(chain[0],
+		pkixx.WithIntermediateCACertificates(chain[1:]),
+		pkixx.WithRootCACertificates(a.trustStore),
+		pkixx.WithExtendedKeyUsage(x509.ExtKeyUsageClientAuth),
+	); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrAuthentication, "client certificate validation failed").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	rawData, err := json.Marshal(newCertificateInfo(chain[0]))
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to marshal client certificate data").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	sub, err := a.sf.CreateSubject(rawData)
+	if err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to extract subject information from client certificate").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	return sub, nil
+}
+
+func (a *x509Authenticator) WithConfig(rawConfig map[string]any) (Authenticator, error) {
+	// this authenticator allows only the fallback to be redefined on the rule level
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		AllowFallbackOnError *bool `mapstructure:"allow_fallback_on_error"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthenticatorX509, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	return &x509Authenticator{
+		id:         a.id,
+		trustStore: a.trustStore,
+		sf:         a.sf,
+		allowFallbackOnError: x.IfThenElseExec(conf.AllowFallbackOnError != nil,
+			func() bool { return *conf.AllowFallbackOnError },
+			func() bool { return a.allowFallbackOnError }),
+	}, nil
+}
+
+func (a *x509Authenticator) IsFallbackOnErrorAllowed() bool {
+	return a.allowFallbackOnError
+}
+
+func (a *x509Authenticator) ID() string {
+	return a.id
+}
+
+type distinguishedName struct {
+	DN                 string   `json:"dn"`
+	CommonName         string   `json:"common_name"`
+	SerialNumber       string   `json:"serial_number,omitempty"`
+	Organization       []string `json:"organization,omitempty"`
+	OrganizationalUnit []string `json:"organizational_unit,omitempty"`
+	Country            []string `json:"country,omitempty"`
+	Province           []string `json:"province,omitempty"`
+	Locality           []string `json:"locality,omitempty"`
+}
+
+type certificateInfo struct {
+	Subject        distinguishedName `json:"subject"`
+	Issuer         distinguishedName `json:"issuer"`
+	SerialNumber   string            `json:"serial_number"`
+	Fingerprint    string            `json:"fingerprint"`
+	DNSNames       []string          `json:"dns_names,omitempty"`
+	EmailAddresses []string          `json:"email_addresses,omitempty"`
+	URIs           []string          `json:"uris,omitempty"`
+	NotBefore      int64             `json:"not_before"`
+	NotAfter       int64             `json:"not_after"`
+}
+
+func newDistinguishedName(name pkix.Name) distinguishedName {
+	return distinguishedName{
+		DN:                 name.String(),
+		CommonName:         name.CommonName,
+		SerialNumber:       name.SerialNumber,
+		Organization:       name.Organization,
+		OrganizationalUnit: name.OrganizationalUnit,
+		Country:            name.Country,
+		Province:           name.Province,
+		Locality:           name.Locality,
+	}
+}
+
+func newCertificateInfo(cert *x509.Certificate) certificateInfo {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	uris := make([]string, len(cert.URIs))
+	for idx, uri := range cert.URIs {
+		uris[idx] = uri.String()
+	}
+
+	return certificateInfo{
+		Subject:        newDistinguishedName(cert.Subject),
+		Issuer:         newDistinguishedName(cert.Issuer),
+		SerialNumber:   cert.SerialNumber.String(),
+		Fingerprint:    hex.EncodeToString(fingerprint[:]),
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		URIs:           uris,
+		NotBefore:      cert.NotBefore.Unix(),
+		NotAfter:       cert.NotAfter.Unix(),
+	}
+}
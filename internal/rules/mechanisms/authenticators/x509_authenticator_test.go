@@ -0,0 +1,450 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authenticators
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateX509Authenticator(t *testing.T) {
+	t.Parallel()
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(rootCA.Certificate))
+	require.NoError(t, err)
+
+	file, err := os.CreateTemp("", "test-create-x509-authenticator-*")
+	require.NoError(t, err)
+
+	_, err = file.Write(pemBytes)
+	require.NoError(t, err)
+
+	defer os.Remove(file.Name())
+
+	trustStorePath := file.Name()
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *x509Authenticator)
+	}{
+		{
+			uc:     "without trust store",
+			config: []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'trust_store' is a required field")
+			},
+		},
+		{
+			uc:     "with not existing trust store",
+			config: []byte(`trust_store: /does/not/exist.pem`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc: "with unsupported fields",
+			config: []byte(`
+trust_store: ` + trustStorePath + `
+foo: bar`),
+			assert: func(t *testing.T, err error, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc:     "with minimal valid configuration",
+			id:     "auth1",
+			config: []byte(`trust_store: ` + trustStorePath),
+			assert: func(t *testing.T, err error, auth *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, "auth1", auth.ID())
+				assert.Len(t, auth.trustStore, 1)
+				assert.Equal(t, rootCA.Certificate, auth.trustStore[0])
+				assert.Equal(t, &SubjectInfo{IDFrom: "subject.common_name"}, auth.sf)
+				assert.False(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+		{
+			uc: "with full configuration",
+			id: "auth1",
+			config: []byte(`
+trust_store: ` + trustStorePath + `
+subject:
+  id: subject.serial_number
+  attributes: subject
+allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, auth *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				assert.Equal(t, "auth1", auth.ID())
+				assert.Len(t, auth.trustStore, 1)
+				assert.Equal(t, &SubjectInfo{IDFrom: "subject.serial_number", AttributesFrom: "subject"}, auth.sf)
+				assert.True(t, auth.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := newX509Authenticator(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, auth)
+		})
+	}
+}
+
+func TestCreateX509AuthenticatorFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(rootCA.Certificate))
+	require.NoError(t, err)
+
+	file, err := os.CreateTemp("", "test-create-x509-authenticator-*")
+	require.NoError(t, err)
+
+	_, err = file.Write(pemBytes)
+	require.NoError(t, err)
+
+	defer os.Remove(file.Name())
+
+	for _, tc := range []struct {
+		uc              string
+		prototypeConfig []byte
+		config          []byte
+		assert          func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator)
+	}{
+		{
+			uc:              "no new configuration provided",
+			prototypeConfig: []byte(`trust_store: ` + file.Name()),
+			assert: func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:              "trust store reconfiguration is not allowed",
+			prototypeConfig: []byte(`trust_store: ` + file.Name()),
+			config:          []byte(`trust_store: ` + file.Name()),
+			assert: func(t *testing.T, err error, _ *x509Authenticator, _ *x509Authenticator) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc:              "fallback reconfigured",
+			prototypeConfig: []byte(`trust_store: ` + file.Name()),
+			config:          []byte(`allow_fallback_on_error: true`),
+			assert: func(t *testing.T, err error, prototype *x509Authenticator, configured *x509Authenticator) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.trustStore, configured.trustStore)
+				assert.Equal(t, prototype.sf, configured.sf)
+				assert.False(t, prototype.IsFallbackOnErrorAllowed())
+				assert.True(t, configured.IsFallbackOnErrorAllowed())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig(tc.prototypeConfig)
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newX509Authenticator("auth1", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				x509Auth *x509Authenticator
+				ok       bool
+			)
+
+			if err == nil {
+				x509Auth, ok = auth.(*x509Authenticator)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, x509Auth)
+		})
+	}
+}
+
+func TestX509AuthenticatorExecute(t *testing.T) {
+	t.Parallel()
+
+	type HandlerIdentifier interface {
+		ID() string
+	}
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	otherCA, err := testsupport.NewRootCA("Other Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	intCAPrivKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	intCACert, err := rootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "Test Int CA", Organization: []string{"Test"}}),
+		testsupport.WithIsCA(),
+		testsupport.WithValidity(time.Now(), time.Hour),
+		testsupport.WithSubjectPubKey(&intCAPrivKey.PublicKey, x509.ECDSAWithSHA384))
+	require.NoError(t, err)
+
+	intCA := testsupport.NewCA(intCAPrivKey, intCACert)
+
+	eePrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	issueCert := func(ca *testsupport.CA, usage x509.ExtKeyUsage) *x509.Certificate {
+		cert, err := ca.IssueCertificate(
+			testsupport.WithSubject(pkix.Name{
+				CommonName:         "my-service",
+				Organization:       []string{"Test"},
+				OrganizationalUnit: []string{"Mesh"},
+				Country:            []string{"EU"},
+			}),
+			testsupport.WithValidity(time.Now(), time.Hour),
+			testsupport.WithSubjectPubKey(&eePrivKey.PublicKey, x509.ECDSAWithSHA256),
+			testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature),
+			testsupport.WithExtendedKeyUsage(usage),
+			testsupport.WithDNSNames([]string{"my-service.example.com"}))
+		require.NoError(t, err)
+
+		return cert
+	}
+
+	clientCert := issueCert(intCA, x509.ExtKeyUsageClientAuth)
+	serverCert := issueCert(intCA, x509.ExtKeyUsageServerAuth)
+	untrustedCert := issueCert(otherCA, x509.ExtKeyUsageClientAuth)
+
+	pemBytes, err := pemx.BuildPEM(pemx.WithX509Certificate(rootCA.Certificate))
+	require.NoError(t, err)
+
+	file, err := os.CreateTemp("", "test-x509-authenticator-*")
+	require.NoError(t, err)
+
+	_, err = file.Write(pemBytes)
+	require.NoError(t, err)
+
+	defer os.Remove(file.Name())
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		chain  []*x509.Certificate
+		assert func(t *testing.T, err error, sub *subject.Subject)
+	}{
+		{
+			uc:     "no client certificate present",
+			config: []byte(`trust_store: ` + file.Name()),
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "no client certificate")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth1", identifier.ID())
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:     "client certificate issued by untrusted ca",
+			config: []byte(`trust_store: ` + file.Name()),
+			chain:  []*x509.Certificate{untrustedCert},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "validation failed")
+
+				var identifier HandlerIdentifier
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "auth1", identifier.ID())
+
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:     "client certificate without intermediate ca",
+			config: []byte(`trust_store: ` + file.Name()),
+			chain:  []*x509.Certificate{clientCert},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "validation failed")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:     "certificate not usable for client authentication",
+			config: []byte(`trust_store: ` + file.Name()),
+			chain:  []*x509.Certificate{serverCert, intCACert},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrAuthentication)
+				assert.Contains(t, err.Error(), "validation failed")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc: "subject id cannot be extracted",
+			config: []byte(`
+trust_store: ` + file.Name() + `
+subject:
+  id: foo`),
+			chain: []*x509.Certificate{clientCert, intCACert},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to extract subject")
+				assert.Nil(t, sub)
+			},
+		},
+		{
+			uc:     "valid client certificate with default subject configuration",
+			config: []byte(`trust_store: ` + file.Name()),
+			chain:  []*x509.Certificate{clientCert, intCACert},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, "my-service", sub.ID)
+				assert.Equal(t, clientCert.SerialNumber.String(), sub.Attributes["serial_number"])
+				assert.Equal(t, []any{"my-service.example.com"}, sub.Attributes["dns_names"])
+
+				subjectDN, ok := sub.Attributes["subject"].(map[string]any)
+				require.True(t, ok)
+				assert.Equal(t, "my-service", subjectDN["common_name"])
+				assert.Equal(t, clientCert.Subject.String(), subjectDN["dn"])
+				assert.Equal(t, []any{"Mesh"}, subjectDN["organizational_unit"])
+
+				issuerDN, ok := sub.Attributes["issuer"].(map[string]any)
+				require.True(t, ok)
+				assert.Equal(t, "Test Int CA", issuerDN["common_name"])
+			},
+		},
+		{
+			uc: "valid client certificate with custom subject configuration",
+			config: []byte(`
+trust_store: ` + file.Name() + `
+subject:
+  id: subject.dn
+  attributes: subject`),
+			chain: []*x509.Certificate{clientCert, intCACert},
+			assert: func(t *testing.T, err error, sub *subject.Subject) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, sub)
+
+				assert.Equal(t, clientCert.Subject.String(), sub.ID)
+				assert.Equal(t, "my-service", sub.Attributes["common_name"])
+				assert.NotContains(t, sub.Attributes, "issuer")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			auth, err := newX509Authenticator("auth1", conf)
+			require.NoError(t, err)
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+			ctx.EXPECT().Request().Return(&heimdall.Request{ClientCertificates: tc.chain})
+
+			// WHEN
+			sub, err := auth.Execute(ctx)
+
+			// THEN
+			tc.assert(t, err, sub)
+		})
+	}
+}
@@ -26,8 +26,8 @@ import (
 func TestCreateAuthorizerPrototypeUsingKnowType(t *testing.T) {
 	t.Parallel()
 
-	// there are 5 authorizers implemented, which should have been registered
-	require.Len(t, authorizerTypeFactories, 4)
+	// there are 6 authorizers implemented, which should have been registered
+	require.Len(t, authorizerTypeFactories, 5)
 
 	for _, tc := range []struct {
 		uc     string
@@ -17,9 +17,10 @@
 package authorizers
 
 const (
-	AuthorizerAllow  = "allow"
-	AuthorizerDeny   = "deny"
-	AuthorizerLocal  = "local"
-	AuthorizerCEL    = "cel"
-	AuthorizerRemote = "remote"
+	AuthorizerAllow     = "allow"
+	AuthorizerDeny      = "deny"
+	AuthorizerLocal     = "local"
+	AuthorizerCEL       = "cel"
+	AuthorizerRemote    = "remote"
+	AuthorizerRateLimit = "rate_limit"
 )
@@ -0,0 +1,288 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	rateLimitAlgorithmTokenBucket   = "token_bucket"
+	rateLimitAlgorithmSlidingWindow = "sliding_window"
+)
+
+// by intention. Used only during application bootstrap
+//
+//nolint:gochecknoinits
+func init() {
+	registerAuthorizerTypeFactory(
+		func(id string, typ string, conf map[string]any) (bool, Authorizer, error) {
+			if typ != AuthorizerRateLimit {
+				return false, nil, nil
+			}
+
+			auth, err := newRateLimitAuthorizer(id, conf)
+
+			return true, auth, err
+		})
+}
+
+type rateLimitAuthorizer struct {
+	id        string
+	key       template.Template
+	algorithm string
+	limit     int
+	period    time.Duration
+	burst     int
+	// guards the read-modify-write cycle on the counters kept in the cache.
+	// shared between all instances created from the same prototype
+	mut *sync.Mutex
+}
+
+func newRateLimitAuthorizer(id string, rawConfig map[string]any) (*rateLimitAuthorizer, error) {
+	type Config struct {
+		Key       template.Template `mapstructure:"key"       validate:"required"`
+		Algorithm string            `mapstructure:"algorithm" validate:"omitempty,oneof=token_bucket sliding_window"`
+		Limit     int               `mapstructure:"limit"     validate:"required,gt=0"`
+		Period    time.Duration     `mapstructure:"period"    validate:"required,gt=0"`
+		Burst     int               `mapstructure:"burst"     validate:"omitempty,gt=0"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthorizerRateLimit, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	return &rateLimitAuthorizer{
+		id:        id,
+		key:       conf.Key,
+		algorithm: x.IfThenElse(len(conf.Algorithm) != 0, conf.Algorithm, rateLimitAlgorithmTokenBucket),
+		limit:     conf.Limit,
+		period:    conf.Period,
+		burst:     conf.Burst,
+		mut:       &sync.Mutex{},
+	}, nil
+}
+
+func (a *rateLimitAuthorizer) Execute(ctx heimdall.Context, sub *subject.Subject) error {
+	logger := zerolog.Ctx(ctx.AppContext())
+	logger.Debug().Str("_id", a.id).Msg("Authorizing using rate_limit authorizer")
+
+	if sub == nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to execute rate_limit authorizer due to 'nil' subject").
+			WithErrorContext(a)
+	}
+
+	key, err := a.key.Render(map[string]any{
+		"Request": ctx.Request(),
+		"Subject": sub,
+	})
+	if err != nil {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to render rate limit key").
+			WithErrorContext(a).
+			CausedBy(err)
+	}
+
+	if len(key) == 0 {
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "rendered rate limit key is empty").
+			WithErrorContext(a)
+	}
+
+	cch := cache.Ctx(ctx.AppContext())
+	if cache.IsDisabled(cch) {
+		// without the cache, the consumed budget cannot be tracked and the limit would never be enforced
+		return errorchain.
+			NewWithMessage(heimdall.ErrInternal, "rate_limit authorizer requires the cache, which is disabled").
+			WithErrorContext(a)
+	}
+
+	allowed, retryAfter := a.take(cch, a.calculateCacheKey(key), time.Now())
+	if !allowed {
+		logger.Debug().Str("_id", a.id).Msg("Rate limit exceeded")
+
+		return errorchain.
+			New(&heimdall.TooManyRequestsError{Message: "rate limit exceeded", RetryAfter: retryAfter}).
+			WithErrorContext(a)
+	}
+
+	return nil
+}
+
+func (a *rateLimitAuthorizer) WithConfig(rawConfig map[string]any) (Authorizer, error) {
+	if len(rawConfig) == 0 {
+		return a, nil
+	}
+
+	type Config struct {
+		Key       template.Template `mapstructure:"key"`
+		Algorithm string            `mapstructure:"algorithm" validate:"omitempty,oneof=token_bucket sliding_window"`
+		Limit     int               `mapstructure:"limit"     validate:"omitempty,gt=0"`
+		Period    time.Duration     `mapstructure:"period"    validate:"omitempty,gt=0"`
+		Burst     int               `mapstructure:"burst"     validate:"omitempty,gt=0"`
+	}
+
+	var conf Config
+	if err := decodeConfig(AuthorizerRateLimit, rawConfig, &conf); err != nil {
+		return nil, err
+	}
+
+	return &rateLimitAuthorizer{
+		id:        a.id,
+		key:       x.IfThenElse(conf.Key != nil, conf.Key, a.key),
+		algorithm: x.IfThenElse(len(conf.Algorithm) != 0, conf.Algorithm, a.algorithm),
+		limit:     x.IfThenElse(conf.Limit > 0, conf.Limit, a.limit),
+		period:    x.IfThenElse(conf.Period > 0, conf.Period, a.period),
+		burst:     x.IfThenElse(conf.Burst > 0, conf.Burst, a.burst),
+		mut:       a.mut,
+	}, nil
+}
+
+func (a *rateLimitAuthorizer) ID() string { return a.id }
+
+func (a *rateLimitAuthorizer) ContinueOnError() bool { return false }
+
+// take consumes one request from the budget available for the given key. If the budget
+// is exhausted, false is returned together with the duration after which a next attempt
+// may succeed. The read-modify-write cycle is only atomic within a single heimdall instance.
+// With a distributed cache, concurrent instances may therefore slightly exceed the limit.
+func (a *rateLimitAuthorizer) take(cch cache.Cache, key string, now time.Time) (bool, time.Duration) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if a.algorithm == rateLimitAlgorithmSlidingWindow {
+		return a.takeFromSlidingWindow(cch, key, now)
+	}
+
+	return a.takeFromTokenBucket(cch, key, now)
+}
+
+type tokenBucketState struct {
+	Tokens     float64
+	LastRefill time.Time
+}
+
+func (a *rateLimitAuthorizer) takeFromTokenBucket(cch cache.Cache, key string, now time.Time) (bool, time.Duration) {
+	capacity := float64(x.IfThenElse(a.burst > 0, a.burst, a.limit))
+	tokensPerSecond := float64(a.limit) / a.period.Seconds()
+
+	state, ok := cch.Get(key).(tokenBucketState)
+	if !ok {
+		state = tokenBucketState{Tokens: capacity, LastRefill: now}
+	}
+
+	if elapsed := now.Sub(state.LastRefill); elapsed > 0 {
+		state.Tokens = min(capacity, state.Tokens+elapsed.Seconds()*tokensPerSecond)
+		state.LastRefill = now
+	}
+
+	// the bucket is full again after this duration, so there is no need to keep the state longer
+	ttl := time.Duration(capacity / tokensPerSecond * float64(time.Second))
+
+	if state.Tokens < 1 {
+		cch.Set(key, state, ttl)
+
+		return false, time.Duration((1 - state.Tokens) / tokensPerSecond * float64(time.Second))
+	}
+
+	state.Tokens--
+	cch.Set(key, state, ttl)
+
+	return true, 0
+}
+
+type slidingWindowState struct {
+	WindowStart time.Time
+	Previous    int
+	Current     int
+}
+
+func (a *rateLimitAuthorizer) takeFromSlidingWindow(cch cache.Cache, key string, now time.Time) (bool, time.Duration) {
+	windowStart := now.Truncate(a.period)
+
+	state, _ := cch.Get(key).(slidingWindowState)
+	if !state.WindowStart.Equal(windowStart) {
+		state = slidingWindowState{
+			WindowStart: windowStart,
+			Previous: x.IfThenElse(state.WindowStart.Add(a.period).Equal(windowStart),
+				state.Current, 0),
+		}
+	}
+
+	elapsed := now.Sub(windowStart)
+	previousWeight := 1 - float64(elapsed)/float64(a.period)
+	estimated := float64(state.Previous)*previousWeight + float64(state.Current)
+
+	if estimated+1 > float64(a.limit) {
+		cch.Set(key, state, 2*a.period) //nolint:gomnd
+
+		if state.Current+1 > a.limit {
+			return false, a.period - elapsed
+		}
+
+		// the weight of the previous window must drop until there is room for one more request
+		requiredWeight := float64(a.limit-state.Current-1) / float64(state.Previous)
+		waitUntil := time.Duration((1 - requiredWeight) * float64(a.period))
+
+		return false, waitUntil - elapsed
+	}
+
+	state.Current++
+	cch.Set(key, state, 2*a.period) //nolint:gomnd
+
+	return true, 0
+}
+
+func (a *rateLimitAuthorizer) calculateCacheKey(key string) string {
+	const int64BytesCount = 8
+
+	limitBytes := make([]byte, int64BytesCount)
+	binary.LittleEndian.PutUint64(limitBytes, uint64(a.limit))
+
+	periodBytes := make([]byte, int64BytesCount)
+	binary.LittleEndian.PutUint64(periodBytes, uint64(a.period))
+
+	burstBytes := make([]byte, int64BytesCount)
+	binary.LittleEndian.PutUint64(burstBytes, uint64(a.burst))
+
+	hash := sha256.New()
+	hash.Write(stringx.ToBytes(AuthorizerRateLimit))
+	hash.Write(stringx.ToBytes(a.id))
+	hash.Write(stringx.ToBytes(a.algorithm))
+	hash.Write(limitBytes)
+	hash.Write(periodBytes)
+	hash.Write(burstBytes)
+	hash.Write(stringx.ToBytes(key))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
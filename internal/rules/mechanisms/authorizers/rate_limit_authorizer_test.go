@@ -0,0 +1,521 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package authorizers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestCreateRateLimitAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		id     string
+		config []byte
+		assert func(t *testing.T, err error, auth *rateLimitAuthorizer)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'key' is a required field")
+			},
+		},
+		{
+			uc: "without limit",
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+period: 1m
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'limit' is a required field")
+			},
+		},
+		{
+			uc: "without period",
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'period' is a required field")
+			},
+		},
+		{
+			uc: "with unsupported algorithm",
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+algorithm: leaky_bucket
+limit: 10
+period: 1m
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'algorithm' must be one of")
+			},
+		},
+		{
+			uc: "with unsupported attributes",
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+period: 1m
+foo: bar
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc: "with minimal valid configuration",
+			id: "authz",
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+period: 1m
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "authz", auth.ID())
+				assert.NotNil(t, auth.key)
+				assert.Equal(t, rateLimitAlgorithmTokenBucket, auth.algorithm)
+				assert.Equal(t, 10, auth.limit)
+				assert.Equal(t, time.Minute, auth.period)
+				assert.Equal(t, 0, auth.burst)
+				assert.NotNil(t, auth.mut)
+				assert.False(t, auth.ContinueOnError())
+			},
+		},
+		{
+			uc: "with full valid configuration",
+			id: "authz",
+			config: []byte(`
+key: "{{ .Request.ClientIP | first }}"
+algorithm: sliding_window
+limit: 10
+period: 1s
+burst: 20
+`),
+			assert: func(t *testing.T, err error, auth *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "authz", auth.ID())
+				assert.Equal(t, rateLimitAlgorithmSlidingWindow, auth.algorithm)
+				assert.Equal(t, 10, auth.limit)
+				assert.Equal(t, time.Second, auth.period)
+				assert.Equal(t, 20, auth.burst)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			// WHEN
+			a, err := newRateLimitAuthorizer(tc.id, conf)
+
+			// THEN
+			tc.assert(t, err, a)
+		})
+	}
+}
+
+func TestCreateRateLimitAuthorizerFromPrototype(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		config []byte
+		assert func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer)
+	}{
+		{
+			uc: "no new configuration provided",
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, prototype, configured)
+			},
+		},
+		{
+			uc:     "with invalid limit",
+			config: []byte(`limit: -1`),
+			assert: func(t *testing.T, err error, _ *rateLimitAuthorizer, _ *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "'limit' must be greater than 0")
+			},
+		},
+		{
+			uc: "with limit and period overridden",
+			config: []byte(`
+limit: 5
+period: 1s
+`),
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype, configured)
+				assert.Equal(t, prototype.ID(), configured.ID())
+				assert.Equal(t, prototype.key, configured.key)
+				assert.Equal(t, prototype.algorithm, configured.algorithm)
+				assert.Equal(t, prototype.burst, configured.burst)
+				assert.Same(t, prototype.mut, configured.mut)
+				assert.Equal(t, 5, configured.limit)
+				assert.Equal(t, time.Second, configured.period)
+			},
+		},
+		{
+			uc: "with key, algorithm and burst overridden",
+			config: []byte(`
+key: "{{ .Request.Header \"X-Api-Key\" }}"
+algorithm: sliding_window
+burst: 3
+`),
+			assert: func(t *testing.T, err error, prototype *rateLimitAuthorizer, configured *rateLimitAuthorizer) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.NotEqual(t, prototype.key, configured.key)
+				assert.Equal(t, rateLimitAlgorithmSlidingWindow, configured.algorithm)
+				assert.Equal(t, 3, configured.burst)
+				assert.Equal(t, prototype.limit, configured.limit)
+				assert.Equal(t, prototype.period, configured.period)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			pc, err := testsupport.DecodeTestConfig([]byte(`
+key: "{{ .Subject.ID }}"
+limit: 10
+period: 1m
+`))
+			require.NoError(t, err)
+
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			prototype, err := newRateLimitAuthorizer("authz", pc)
+			require.NoError(t, err)
+
+			// WHEN
+			auth, err := prototype.WithConfig(conf)
+
+			// THEN
+			var (
+				rla *rateLimitAuthorizer
+				ok  bool
+			)
+
+			if err == nil {
+				rla, ok = auth.(*rateLimitAuthorizer)
+				require.True(t, ok)
+			}
+
+			tc.assert(t, err, prototype, rla)
+		})
+	}
+}
+
+func TestRateLimitAuthorizerTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cch := memory.New()
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	auth := &rateLimitAuthorizer{
+		algorithm: rateLimitAlgorithmTokenBucket,
+		limit:     2,
+		period:    time.Second,
+		burst:     3,
+		mut:       &sync.Mutex{},
+	}
+
+	// WHEN & THEN
+	// the bucket starts full, so a burst is allowed
+	for i := 0; i < 3; i++ {
+		allowed, _ := auth.take(cch, "foo", now)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter := auth.take(cch, "foo", now)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// other keys have their own bucket
+	allowed, _ = auth.take(cch, "bar", now)
+	assert.True(t, allowed)
+
+	// one token is refilled every 500ms
+	allowed, _ = auth.take(cch, "foo", now.Add(500*time.Millisecond))
+	assert.True(t, allowed)
+
+	allowed, retryAfter = auth.take(cch, "foo", now.Add(750*time.Millisecond))
+	assert.False(t, allowed)
+	assert.Equal(t, 250*time.Millisecond, retryAfter)
+
+	// the bucket never holds more tokens than its capacity
+	for i := 0; i < 3; i++ {
+		allowed, _ = auth.take(cch, "foo", now.Add(time.Hour))
+		assert.True(t, allowed)
+	}
+
+	allowed, _ = auth.take(cch, "foo", now.Add(time.Hour))
+	assert.False(t, allowed)
+}
+
+func TestRateLimitAuthorizerSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cch := memory.New()
+	windowStart := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	auth := &rateLimitAuthorizer{
+		algorithm: rateLimitAlgorithmSlidingWindow,
+		limit:     4,
+		period:    time.Minute,
+		mut:       &sync.Mutex{},
+	}
+
+	// WHEN & THEN
+	for i := 0; i < 4; i++ {
+		allowed, _ := auth.take(cch, "foo", windowStart.Add(30*time.Second))
+		assert.True(t, allowed)
+	}
+
+	// the current window is exhausted
+	allowed, retryAfter := auth.take(cch, "foo", windowStart.Add(30*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 30*time.Second, retryAfter)
+
+	// 15s into the next window, the previous one still counts with 75%, leaving room for a single request
+	allowed, _ = auth.take(cch, "foo", windowStart.Add(75*time.Second))
+	assert.True(t, allowed)
+
+	allowed, retryAfter = auth.take(cch, "foo", windowStart.Add(75*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 15*time.Second, retryAfter)
+
+	// 30s into the next window, the previous one counts with 50% only
+	allowed, _ = auth.take(cch, "foo", windowStart.Add(90*time.Second))
+	assert.True(t, allowed)
+
+	allowed, retryAfter = auth.take(cch, "foo", windowStart.Add(90*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 15*time.Second, retryAfter)
+
+	// if a window has been skipped, the counters start from scratch
+	for i := 0; i < 4; i++ {
+		allowed, _ = auth.take(cch, "foo", windowStart.Add(5*time.Minute))
+		assert.True(t, allowed)
+	}
+}
+
+func TestRateLimitAuthorizerExecute(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc      string
+		config  []byte
+		sub     *subject.Subject
+		request *heimdall.Request
+		calls   int
+		assert  func(t *testing.T, err error)
+	}{
+		{
+			uc: "with nil subject",
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 1
+period: 1m
+`),
+			calls: 1,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "'nil' subject")
+			},
+		},
+		{
+			uc: "with key, which cannot be rendered",
+			config: []byte(`
+key: "{{ .Subject.Foo.Bar }}"
+limit: 1
+period: 1m
+`),
+			sub:   &subject.Subject{ID: "foo"},
+			calls: 1,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to render")
+			},
+		},
+		{
+			uc: "with key rendering to an empty string",
+			config: []byte(`
+key: "{{ if .Subject.Attributes.tenant }}{{ .Subject.Attributes.tenant }}{{ end }}"
+limit: 1
+period: 1m
+`),
+			sub:   &subject.Subject{ID: "foo", Attributes: map[string]any{}},
+			calls: 1,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "empty")
+			},
+		},
+		{
+			uc: "within the limit",
+			config: []byte(`
+key: "{{ .Subject.ID }}"
+limit: 2
+period: 1m
+`),
+			sub:   &subject.Subject{ID: "foo"},
+			calls: 2,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc: "limit exceeded",
+			config: []byte(`
+key: "{{ index .Request.ClientIP 0 }}"
+limit: 2
+period: 1m
+`),
+			sub:     &subject.Subject{ID: "foo"},
+			request: &heimdall.Request{ClientIP: []string{"192.168.1.1"}},
+			calls:   3,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				var (
+					tmrErr     *heimdall.TooManyRequestsError
+					identifier interface{ ID() string }
+				)
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, &heimdall.TooManyRequestsError{})
+				require.ErrorAs(t, err, &tmrErr)
+				assert.Greater(t, tmrErr.RetryAfter, 29*time.Second)
+				assert.LessOrEqual(t, tmrErr.RetryAfter, 30*time.Second)
+
+				require.ErrorAs(t, err, &identifier)
+				assert.Equal(t, "authz", identifier.ID())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf, err := testsupport.DecodeTestConfig(tc.config)
+			require.NoError(t, err)
+
+			auth, err := newRateLimitAuthorizer("authz", conf)
+			require.NoError(t, err)
+
+			ctx := mocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(cache.WithContext(context.Background(), memory.New()))
+			ctx.EXPECT().Request().Return(tc.request).Maybe()
+
+			// WHEN
+			for i := 0; i < tc.calls-1; i++ {
+				require.NoError(t, auth.Execute(ctx, tc.sub))
+			}
+
+			err = auth.Execute(ctx, tc.sub)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
+
+func TestRateLimitAuthorizerExecuteWithDisabledCache(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	conf, err := testsupport.DecodeTestConfig([]byte(`
+key: "{{ .Subject.ID }}"
+limit: 1
+period: 1m
+`))
+	require.NoError(t, err)
+
+	auth, err := newRateLimitAuthorizer("authz", conf)
+	require.NoError(t, err)
+
+	ctx := mocks.NewContextMock(t)
+	ctx.EXPECT().AppContext().Return(context.Background())
+	ctx.EXPECT().Request().Return(nil)
+
+	// WHEN
+	err = auth.Execute(ctx, &subject.Subject{ID: "foo"})
+
+	// THEN
+	require.Error(t, err)
+	require.ErrorIs(t, err, heimdall.ErrInternal)
+	assert.Contains(t, err.Error(), "cache, which is disabled")
+}
@@ -33,7 +33,7 @@ func TestRequests(t *testing.T) {
 	req := &heimdall.Request{
 		RequestFunctions: reqf,
 		Method:           http.MethodHead,
-		URL:              uri,
+		URL:              &heimdall.URL{URL: *uri},
 		ClientIP:         []string{"1.1.1.1"},
 	}
 
@@ -6,6 +6,8 @@ import (
 
 	"github.com/google/cel-go/cel"
 	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
 func TestUrls(t *testing.T) {
@@ -18,15 +20,21 @@ func TestUrls(t *testing.T) {
 	require.NoError(t, err)
 
 	rawURI := "http://localhost/foo/bar?foo=bar&foo=baz&bar=foo"
-	uri, err := url.Parse("http://localhost/foo/bar?foo=bar&foo=baz&bar=foo")
+	parsed, err := url.Parse("http://localhost/foo/bar?foo=bar&foo=baz&bar=foo")
 	require.NoError(t, err)
 
+	uri := &heimdall.URL{URL: *parsed, Captures: map[string]string{"foo": "bar"}}
+
 	for _, tc := range []struct {
 		expr string
 	}{
 		{expr: `uri.String() == "` + rawURI + `"`},
 		{expr: `uri.Query() == {"foo":["bar", "baz"], "bar": ["foo"]}`},
 		{expr: `uri.Query().bar == ["foo"]`},
+		{expr: `uri.Path == "/foo/bar"`},
+		{expr: `uri.Host == "localhost"`},
+		{expr: `uri.Captures.foo == "bar"`},
+		{expr: `uri.Captures == {"foo": "bar"}`},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			ast, iss := env.Compile(tc.expr)
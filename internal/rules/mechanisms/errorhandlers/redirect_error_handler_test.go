@@ -174,7 +174,7 @@ when:
 
 				ctx := mocks.NewContextMock(t)
 				ctx.EXPECT().Request().
-					Return(&heimdall.Request{URL: &url.URL{Scheme: "http", Host: "foobar.baz", Path: "zab"}})
+					Return(&heimdall.Request{URL: &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foobar.baz", Path: "zab"}}})
 
 				toURL, err := redEH.to.Render(map[string]any{
 					"Request": ctx.Request(),
@@ -452,7 +452,7 @@ when:
 				requestURL, err := url.Parse("http://test.org")
 				require.NoError(t, err)
 
-				ctx.EXPECT().Request().Return(&heimdall.Request{URL: requestURL})
+				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &heimdall.URL{URL: *requestURL}})
 				ctx.EXPECT().SetPipelineError(mock.MatchedBy(func(redirErr *heimdall.RedirectError) bool {
 					t.Helper()
 
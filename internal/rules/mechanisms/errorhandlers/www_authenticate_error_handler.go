@@ -17,6 +17,7 @@
 package errorhandlers
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/rs/zerolog"
@@ -42,6 +43,12 @@ func init() {
 		})
 }
 
+// challenger is implemented by mechanisms, which require a challenge other than "Basic", like the
+// jwt authenticator with enabled DPoP support. An empty challenge lets the handler fall back to "Basic".
+type challenger interface {
+	WWWAuthenticateChallenge(err error) string
+}
+
 type wwwAuthenticateErrorHandler struct {
 	id    string
 	realm string
@@ -77,7 +84,16 @@ func (eh *wwwAuthenticateErrorHandler) Execute(ctx heimdall.Context, err error)
 
 	logger.Debug().Str("_id", eh.id).Msg("Handling error using www-authenticate error handler")
 
-	ctx.AddHeaderForUpstream("WWW-Authenticate", fmt.Sprintf("Basic realm=%s", eh.realm))
+	challenge := fmt.Sprintf("Basic realm=%s", eh.realm)
+
+	var source challenger
+	if errors.As(err, &source) {
+		if value := source.WWWAuthenticateChallenge(err); len(value) != 0 {
+			challenge = value
+		}
+	}
+
+	ctx.AddHeaderForUpstream("WWW-Authenticate", challenge)
 	ctx.SetPipelineError(heimdall.ErrAuthentication)
 
 	return true, nil
@@ -28,9 +28,16 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/heimdall/mocks"
 	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 )
 
+type testChallenger struct {
+	challenge string
+}
+
+func (c testChallenger) WWWAuthenticateChallenge(_ error) string { return c.challenge }
+
 func TestCreateWWWAuthenticateErrorHandler(t *testing.T) {
 	t.Parallel()
 
@@ -383,6 +390,49 @@ when:
 			assert: func(t *testing.T, wasResponsible bool, err error) {
 				t.Helper()
 
+				require.NoError(t, err)
+				assert.True(t, wasResponsible)
+			},
+		},
+		{
+			uc: "responsible for error raised by a mechanism providing its own challenge",
+			config: []byte(`
+when:
+  - error:
+      - type: authentication_error
+`),
+			error: errorchain.New(heimdall.ErrAuthentication).
+				WithErrorContext(testChallenger{challenge: `DPoP error="invalid_dpop_proof", algs="ES256"`}),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(heimdall.ErrAuthentication)
+				ctx.EXPECT().AddHeaderForUpstream("WWW-Authenticate", `DPoP error="invalid_dpop_proof", algs="ES256"`)
+			},
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, wasResponsible)
+			},
+		},
+		{
+			uc: "responsible for error raised by a mechanism without own challenge",
+			config: []byte(`
+when:
+  - error:
+      - type: authentication_error
+`),
+			error: errorchain.New(heimdall.ErrAuthentication).WithErrorContext(testChallenger{}),
+			configureContext: func(t *testing.T, ctx *mocks.ContextMock) {
+				t.Helper()
+
+				ctx.EXPECT().SetPipelineError(heimdall.ErrAuthentication)
+				ctx.EXPECT().AddHeaderForUpstream("WWW-Authenticate", "Basic realm=Please authenticate")
+			},
+			assert: func(t *testing.T, wasResponsible bool, err error) {
+				t.Helper()
+
 				require.NoError(t, err)
 				assert.True(t, wasResponsible)
 			},
@@ -0,0 +1,166 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// Algorithms defined in RFC 9421, section 3.3. hmac-sha256 is not supported as only
+// public keys can be used for signature verification purposes.
+const (
+	AlgorithmRSAPSSSHA512    = "rsa-pss-sha512"
+	AlgorithmRSAv15SHA256    = "rsa-v1_5-sha256"
+	AlgorithmECDSAP256SHA256 = "ecdsa-p256-sha256"
+	AlgorithmECDSAP384SHA384 = "ecdsa-p384-sha384"
+	AlgorithmEd25519         = "ed25519"
+)
+
+const rsaPSSSaltLength = 64
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	ErrInvalidSignature     = errors.New("invalid signature")
+)
+
+// AlgorithmFromJWA maps the JSON Web Algorithm name of a JWK to the corresponding
+// HTTP message signature algorithm.
+func AlgorithmFromJWA(jwa string) (string, error) {
+	switch jwa {
+	case "PS512":
+		return AlgorithmRSAPSSSHA512, nil
+	case "RS256":
+		return AlgorithmRSAv15SHA256, nil
+	case "ES256":
+		return AlgorithmECDSAP256SHA256, nil
+	case "ES384":
+		return AlgorithmECDSAP384SHA384, nil
+	case "EdDSA":
+		return AlgorithmEd25519, nil
+	default:
+		return "", errorchain.NewWithMessage(ErrUnsupportedAlgorithm, jwa)
+	}
+}
+
+// AlgorithmFromKey determines the HTTP message signature algorithm from the type of the given key.
+// This is not possible for RSA keys, as these can be used with different algorithms.
+func AlgorithmFromKey(key crypto.PublicKey) (string, error) {
+	switch pubKey := key.(type) {
+	case *ecdsa.PublicKey:
+		switch pubKey.Curve {
+		case elliptic.P256():
+			return AlgorithmECDSAP256SHA256, nil
+		case elliptic.P384():
+			return AlgorithmECDSAP384SHA384, nil
+		}
+	case ed25519.PublicKey:
+		return AlgorithmEd25519, nil
+	}
+
+	return "", errorchain.NewWithMessage(ErrUnsupportedAlgorithm,
+		"cannot determine the algorithm from the key type")
+}
+
+// Verify verifies the signature over the given signature base using the given algorithm and key.
+func Verify(alg string, key crypto.PublicKey, base, signature []byte) error {
+	var err error
+
+	switch alg {
+	case AlgorithmRSAPSSSHA512:
+		err = verifyRSA(key, base, signature, true)
+	case AlgorithmRSAv15SHA256:
+		err = verifyRSA(key, base, signature, false)
+	case AlgorithmECDSAP256SHA256:
+		err = verifyECDSA(key, elliptic.P256(), crypto.SHA256, base, signature)
+	case AlgorithmECDSAP384SHA384:
+		err = verifyECDSA(key, elliptic.P384(), crypto.SHA384, base, signature)
+	case AlgorithmEd25519:
+		pubKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return errorchain.NewWithMessagef(ErrUnsupportedAlgorithm, "key cannot be used with %s", alg)
+		}
+
+		if !ed25519.Verify(pubKey, base, signature) {
+			err = ErrInvalidSignature
+		}
+	default:
+		return errorchain.NewWithMessage(ErrUnsupportedAlgorithm, alg)
+	}
+
+	return err
+}
+
+func verifyRSA(key crypto.PublicKey, base, signature []byte, pss bool) error {
+	pubKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return errorchain.NewWithMessage(ErrUnsupportedAlgorithm, "key is not an RSA key")
+	}
+
+	var err error
+
+	if pss {
+		digest := sha512.Sum512(base)
+		err = rsa.VerifyPSS(pubKey, crypto.SHA512, digest[:], signature,
+			&rsa.PSSOptions{SaltLength: rsaPSSSaltLength, Hash: crypto.SHA512})
+	} else {
+		digest := sha256.Sum256(base)
+		err = rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, digest[:], signature)
+	}
+
+	if err != nil {
+		return errorchain.New(ErrInvalidSignature).CausedBy(err)
+	}
+
+	return nil
+}
+
+func verifyECDSA(key crypto.PublicKey, curve elliptic.Curve, hash crypto.Hash, base, signature []byte) error {
+	pubKey, ok := key.(*ecdsa.PublicKey)
+	if !ok || pubKey.Curve != curve {
+		return errorchain.NewWithMessagef(ErrUnsupportedAlgorithm, "key is not an ECDSA %s key",
+			curve.Params().Name)
+	}
+
+	// the signature is the concatenation of r and s, each encoded as big-endian
+	// unsigned integer of the size of the curve
+	size := (curve.Params().BitSize + 7) / 8 //nolint:gomnd
+	if len(signature) != 2*size {
+		return errorchain.NewWithMessage(ErrInvalidSignature, "unexpected signature length")
+	}
+
+	digest := hash.New()
+	digest.Write(base)
+
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+
+	if !ecdsa.Verify(pubKey, digest.Sum(nil), r, s) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,291 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/x"
+)
+
+func sign(t *testing.T, alg string, key crypto.Signer, base []byte) []byte {
+	t.Helper()
+
+	var (
+		sig []byte
+		err error
+	)
+
+	switch alg {
+	case AlgorithmRSAPSSSHA512:
+		digest := sha512.Sum512(base)
+		sig, err = rsa.SignPSS(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA512, digest[:],
+			&rsa.PSSOptions{SaltLength: rsaPSSSaltLength, Hash: crypto.SHA512})
+	case AlgorithmRSAv15SHA256:
+		digest := sha256.Sum256(base)
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+	case AlgorithmECDSAP256SHA256, AlgorithmECDSAP384SHA384:
+		privKey := key.(*ecdsa.PrivateKey)
+		hash := x.IfThenElse(alg == AlgorithmECDSAP256SHA256, crypto.SHA256, crypto.SHA384)
+		md := hash.New()
+		md.Write(base)
+
+		r, s, serr := ecdsa.Sign(rand.Reader, privKey, md.Sum(nil))
+		require.NoError(t, serr)
+
+		size := (privKey.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+	case AlgorithmEd25519:
+		sig = ed25519.Sign(key.(ed25519.PrivateKey), base)
+	}
+
+	require.NoError(t, err)
+
+	return sig
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	base := []byte("\"@method\": GET\n\"@signature-params\": (\"@method\")")
+
+	for _, tc := range []struct {
+		uc        string
+		alg       string
+		signKey   crypto.Signer
+		verifyKey crypto.PublicKey
+		tamper    bool
+		assert    func(t *testing.T, err error)
+	}{
+		{uc: "rsa-pss-sha512", alg: AlgorithmRSAPSSSHA512, signKey: rsaKey, verifyKey: rsaKey.Public()},
+		{uc: "rsa-v1_5-sha256", alg: AlgorithmRSAv15SHA256, signKey: rsaKey, verifyKey: rsaKey.Public()},
+		{uc: "ecdsa-p256-sha256", alg: AlgorithmECDSAP256SHA256, signKey: p256Key, verifyKey: p256Key.Public()},
+		{uc: "ecdsa-p384-sha384", alg: AlgorithmECDSAP384SHA384, signKey: p384Key, verifyKey: p384Key.Public()},
+		{uc: "ed25519", alg: AlgorithmEd25519, signKey: ed25519Key, verifyKey: ed25519Key.Public()},
+		{
+			uc:        "rsa-pss-sha512 with tampered signature",
+			alg:       AlgorithmRSAPSSSHA512,
+			signKey:   rsaKey,
+			verifyKey: rsaKey.Public(),
+			tamper:    true,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidSignature)
+			},
+		},
+		{
+			uc:        "rsa-v1_5-sha256 with tampered signature",
+			alg:       AlgorithmRSAv15SHA256,
+			signKey:   rsaKey,
+			verifyKey: rsaKey.Public(),
+			tamper:    true,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidSignature)
+			},
+		},
+		{
+			uc:        "ecdsa-p256-sha256 with tampered signature",
+			alg:       AlgorithmECDSAP256SHA256,
+			signKey:   p256Key,
+			verifyKey: p256Key.Public(),
+			tamper:    true,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidSignature)
+			},
+		},
+		{
+			uc:        "ed25519 with tampered signature",
+			alg:       AlgorithmEd25519,
+			signKey:   ed25519Key,
+			verifyKey: ed25519Key.Public(),
+			tamper:    true,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrInvalidSignature)
+			},
+		},
+		{
+			uc:        "rsa algorithm with ecdsa key",
+			alg:       AlgorithmRSAPSSSHA512,
+			signKey:   rsaKey,
+			verifyKey: p256Key.Public(),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+			},
+		},
+		{
+			uc:        "ecdsa-p384-sha384 with p256 key",
+			alg:       AlgorithmECDSAP384SHA384,
+			signKey:   p384Key,
+			verifyKey: p256Key.Public(),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+			},
+		},
+		{
+			uc:        "ed25519 with rsa key",
+			alg:       AlgorithmEd25519,
+			signKey:   ed25519Key,
+			verifyKey: rsaKey.Public(),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+			},
+		},
+		{
+			uc:        "unsupported algorithm",
+			alg:       "hmac-sha256",
+			signKey:   ed25519Key,
+			verifyKey: ed25519Key.Public(),
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+				assert.Contains(t, err.Error(), "hmac-sha256")
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			sig := sign(t, tc.alg, tc.signKey, base)
+			if tc.tamper {
+				sig[len(sig)-1] ^= 0xff
+			}
+
+			assertResult := tc.assert
+			if assertResult == nil {
+				assertResult = func(t *testing.T, err error) {
+					t.Helper()
+
+					require.NoError(t, err)
+				}
+			}
+
+			// WHEN
+			err := Verify(tc.alg, tc.verifyKey, base, sig)
+
+			// THEN
+			assertResult(t, err)
+		})
+	}
+}
+
+func TestAlgorithmFromJWA(t *testing.T) {
+	t.Parallel()
+
+	for jwa, expected := range map[string]string{
+		"PS512": AlgorithmRSAPSSSHA512,
+		"RS256": AlgorithmRSAv15SHA256,
+		"ES256": AlgorithmECDSAP256SHA256,
+		"ES384": AlgorithmECDSAP384SHA384,
+		"EdDSA": AlgorithmEd25519,
+		"HS256": "",
+	} {
+		t.Run(jwa, func(t *testing.T) {
+			// WHEN
+			alg, err := AlgorithmFromJWA(jwa)
+
+			// THEN
+			if len(expected) == 0 {
+				require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, expected, alg)
+			}
+		})
+	}
+}
+
+func TestAlgorithmFromKey(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	ed25519Key, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc       string
+		key      crypto.PublicKey
+		expected string
+	}{
+		{uc: "rsa key", key: rsaKey.Public()},
+		{uc: "ecdsa p256 key", key: p256Key.Public(), expected: AlgorithmECDSAP256SHA256},
+		{uc: "ecdsa p384 key", key: p384Key.Public(), expected: AlgorithmECDSAP384SHA384},
+		{uc: "ecdsa p521 key", key: p521Key.Public()},
+		{uc: "ed25519 key", key: ed25519Key, expected: AlgorithmEd25519},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			alg, err := AlgorithmFromKey(tc.key)
+
+			// THEN
+			if len(tc.expected) == 0 {
+				require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, alg)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var (
+	ErrUnsupportedComponent = errors.New("unsupported component")
+	ErrMissingComponent     = errors.New("missing component")
+)
+
+// Message provides access to the parts of an HTTP request, which can be covered by a signature.
+type Message interface {
+	Method() string
+	URL() *url.URL
+	Header(name string) string
+}
+
+// Component is a component identifier (RFC 9421, section 2) covered by a signature.
+type Component struct {
+	Name   string
+	Params Params
+}
+
+// String returns the serialized component identifier as used in the signature base,
+// e.g. "@query-param";name="foo".
+func (c Component) String() string {
+	return serializeItem(Item{Value: c.Name, Params: c.Params})
+}
+
+// Identifier returns the component identifier without the quotes around the component name,
+// e.g. @query-param;name="foo".
+func (c Component) Identifier() string {
+	return c.Name + serializeParams(c.Params)
+}
+
+func (c Component) value(msg Message) (string, error) {
+	if !strings.HasPrefix(c.Name, "@") {
+		return c.fieldValue(msg)
+	}
+
+	if len(c.Params) != 0 && c.Name != "@query-param" {
+		return "", errorchain.NewWithMessagef(ErrUnsupportedComponent, "%s with parameters", c.Name)
+	}
+
+	reqURL := msg.URL()
+
+	switch c.Name {
+	case "@method":
+		return msg.Method(), nil
+	case "@target-uri":
+		return reqURL.String(), nil
+	case "@authority":
+		return authority(reqURL), nil
+	case "@scheme":
+		return strings.ToLower(reqURL.Scheme), nil
+	case "@request-target":
+		return reqURL.RequestURI(), nil
+	case "@path":
+		if path := reqURL.EscapedPath(); len(path) != 0 {
+			return path, nil
+		}
+
+		return "/", nil
+	case "@query":
+		return "?" + reqURL.RawQuery, nil
+	case "@query-param":
+		return c.queryParamValue(reqURL)
+	default:
+		return "", errorchain.NewWithMessage(ErrUnsupportedComponent, c.Name)
+	}
+}
+
+func (c Component) fieldValue(msg Message) (string, error) {
+	if len(c.Params) != 0 {
+		return "", errorchain.NewWithMessagef(ErrUnsupportedComponent, "%s with parameters", c.Name)
+	}
+
+	if c.Name != strings.ToLower(c.Name) {
+		return "", errorchain.NewWithMessagef(ErrUnsupportedComponent,
+			"%s is not a lowercase field name", c.Name)
+	}
+
+	value := msg.Header(c.Name)
+	if len(value) == 0 {
+		return "", errorchain.NewWithMessage(ErrMissingComponent, c.Name)
+	}
+
+	return strings.TrimSpace(value), nil
+}
+
+func (c Component) queryParamValue(reqURL *url.URL) (string, error) {
+	if len(c.Params) != 1 {
+		return "", errorchain.NewWithMessage(ErrUnsupportedComponent,
+			"@query-param requires exactly the name parameter")
+	}
+
+	encodedName, ok := c.Params[0].Value.(string)
+	if c.Params[0].Key != "name" || !ok {
+		return "", errorchain.NewWithMessage(ErrUnsupportedComponent,
+			"@query-param requires exactly the name parameter")
+	}
+
+	name, err := url.QueryUnescape(encodedName)
+	if err != nil {
+		return "", errorchain.NewWithMessage(ErrUnsupportedComponent,
+			"malformed @query-param name parameter").CausedBy(err)
+	}
+
+	values := reqURL.Query()[name]
+
+	switch len(values) {
+	case 0:
+		return "", errorchain.NewWithMessage(ErrMissingComponent, c.Identifier())
+	case 1:
+		return strings.ReplaceAll(url.QueryEscape(values[0]), "+", "%20"), nil
+	default:
+		return "", errorchain.NewWithMessagef(ErrUnsupportedComponent,
+			"%s is present multiple times", c.Identifier())
+	}
+}
+
+func authority(reqURL *url.URL) string {
+	host := strings.ToLower(reqURL.Host)
+
+	switch {
+	case reqURL.Scheme == "http" && strings.HasSuffix(host, ":80"):
+		return strings.TrimSuffix(host, ":80")
+	case reqURL.Scheme == "https" && strings.HasSuffix(host, ":443"):
+		return strings.TrimSuffix(host, ":443")
+	default:
+		return host
+	}
+}
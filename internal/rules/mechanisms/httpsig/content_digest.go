@@ -0,0 +1,79 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"hash"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var ErrContentDigestMismatch = errors.New("content digest mismatch")
+
+// VerifyContentDigest verifies the value of the Content-Digest header (RFC 9530) against the
+// given body. All digests computed with a supported algorithm (sha-256 and sha-512) must match
+// and at least one such digest must be present.
+func VerifyContentDigest(contentDigest string, body []byte) error {
+	digests, err := ParseDictionary(contentDigest)
+	if err != nil {
+		return errorchain.NewWithMessage(ErrContentDigestMismatch,
+			"failed to parse Content-Digest header").CausedBy(err)
+	}
+
+	verified := false
+
+	for _, member := range digests {
+		var md hash.Hash
+
+		switch member.Key {
+		case "sha-256":
+			md = sha256.New()
+		case "sha-512":
+			md = sha512.New()
+		default:
+			continue
+		}
+
+		item, ok := member.Value.(Item)
+		if !ok {
+			return errorchain.NewWithMessagef(ErrContentDigestMismatch, "malformed %s digest", member.Key)
+		}
+
+		expected, ok := item.Value.([]byte)
+		if !ok {
+			return errorchain.NewWithMessagef(ErrContentDigestMismatch, "malformed %s digest", member.Key)
+		}
+
+		md.Write(body)
+
+		if subtle.ConstantTimeCompare(md.Sum(nil), expected) != 1 {
+			return errorchain.NewWithMessagef(ErrContentDigestMismatch, "%s digest does not match", member.Key)
+		}
+
+		verified = true
+	}
+
+	if !verified {
+		return errorchain.NewWithMessage(ErrContentDigestMismatch, "no digest with a supported algorithm present")
+	}
+
+	return nil
+}
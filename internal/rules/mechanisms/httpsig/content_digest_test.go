@@ -0,0 +1,104 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyContentDigest(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"hello": "world"}`)
+
+	for _, tc := range []struct {
+		uc     string
+		header string
+		assert func(t *testing.T, err error)
+	}{
+		{
+			// test vector from RFC 9421, appendix B.2
+			uc:     "valid sha-512 digest",
+			header: "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:     "valid sha-256 digest together with an unsupported one",
+			header: "md5=:Y2FmZWJhYmU=:, sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.NoError(t, err)
+			},
+		},
+		{
+			uc:     "not matching digest",
+			header: "sha-256=:Y2FmZWJhYmU=:",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrContentDigestMismatch)
+				assert.Contains(t, err.Error(), "sha-256 digest does not match")
+			},
+		},
+		{
+			uc:     "only unsupported digests",
+			header: "md5=:Y2FmZWJhYmU=:",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrContentDigestMismatch)
+				assert.Contains(t, err.Error(), "no digest with a supported algorithm")
+			},
+		},
+		{
+			uc:     "digest is not a byte sequence",
+			header: `sha-256="X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE="`,
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrContentDigestMismatch)
+				assert.Contains(t, err.Error(), "malformed sha-256 digest")
+			},
+		},
+		{
+			uc:     "malformed header",
+			header: "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=",
+			assert: func(t *testing.T, err error) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrContentDigestMismatch)
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			err := VerifyContentDigest(tc.header, body)
+
+			// THEN
+			tc.assert(t, err)
+		})
+	}
+}
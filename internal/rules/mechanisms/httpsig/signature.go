@@ -0,0 +1,182 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var ErrMalformedSignature = errors.New("malformed signature")
+
+// Signature is a signature received in the Signature header together with its
+// parameters from the Signature-Input header (RFC 9421, section 4).
+type Signature struct {
+	Label      string
+	Components []Component
+	Value      []byte
+
+	input InnerList
+}
+
+// ParseSignatures parses the values of the Signature-Input and Signature headers and returns the
+// contained signatures in the order of their definition in the Signature-Input header.
+func ParseSignatures(signatureInput, signature string) ([]*Signature, error) {
+	inputs, err := ParseDictionary(signatureInput)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrMalformedSignature,
+			"failed to parse Signature-Input header").CausedBy(err)
+	}
+
+	values, err := ParseDictionary(signature)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(ErrMalformedSignature,
+			"failed to parse Signature header").CausedBy(err)
+	}
+
+	signatures := make([]*Signature, 0, len(inputs))
+
+	for _, member := range inputs {
+		sig, err := newSignature(member, values)
+		if err != nil {
+			return nil, err
+		}
+
+		signatures = append(signatures, sig)
+	}
+
+	return signatures, nil
+}
+
+func newSignature(input DictionaryMember, values Dictionary) (*Signature, error) {
+	list, ok := input.Value.(InnerList)
+	if !ok {
+		return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+			"signature input for label '%s' is not an inner list", input.Key)
+	}
+
+	value, present := values.Get(input.Key)
+	if !present {
+		return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+			"no signature present for label '%s'", input.Key)
+	}
+
+	item, ok := value.(Item)
+	if !ok {
+		return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+			"signature for label '%s' is not a byte sequence", input.Key)
+	}
+
+	sigValue, ok := item.Value.([]byte)
+	if !ok {
+		return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+			"signature for label '%s' is not a byte sequence", input.Key)
+	}
+
+	components := make([]Component, len(list.Items))
+	seen := make(map[string]bool, len(list.Items))
+
+	for idx, item := range list.Items {
+		name, ok := item.Value.(string)
+		if !ok {
+			return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+				"component identifiers for label '%s' must be strings", input.Key)
+		}
+
+		components[idx] = Component{Name: name, Params: item.Params}
+
+		if seen[components[idx].String()] {
+			return nil, errorchain.NewWithMessagef(ErrMalformedSignature,
+				"component %s is referenced multiple times for label '%s'", components[idx], input.Key)
+		}
+
+		seen[components[idx].String()] = true
+	}
+
+	return &Signature{
+		Label:      input.Key,
+		Components: components,
+		Value:      sigValue,
+		input:      list,
+	}, nil
+}
+
+func (s *Signature) KeyID() string       { return s.stringParam("keyid") }
+func (s *Signature) Algorithm() string   { return s.stringParam("alg") }
+func (s *Signature) Nonce() string       { return s.stringParam("nonce") }
+func (s *Signature) Tag() string         { return s.stringParam("tag") }
+func (s *Signature) Created() *time.Time { return s.timeParam("created") }
+func (s *Signature) Expires() *time.Time { return s.timeParam("expires") }
+
+// Covers returns true, if the given component identifier, as returned by Component.Identifier,
+// is covered by the signature.
+func (s *Signature) Covers(identifier string) bool {
+	for _, component := range s.Components {
+		if component.Identifier() == identifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Base creates the signature base (RFC 9421, section 2.5) for the given message.
+func (s *Signature) Base(msg Message) ([]byte, error) {
+	var builder strings.Builder
+
+	for _, component := range s.Components {
+		value, err := component.value(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		builder.WriteString(component.String())
+		builder.WriteString(": ")
+		builder.WriteString(value)
+		builder.WriteByte('\n')
+	}
+
+	builder.WriteString(`"@signature-params": `)
+	builder.WriteString(serializeInnerList(s.input))
+
+	return []byte(builder.String()), nil
+}
+
+func (s *Signature) stringParam(key string) string {
+	if value, ok := s.input.Params.Get(key); ok {
+		if str, ok := value.(string); ok {
+			return str
+		}
+	}
+
+	return ""
+}
+
+func (s *Signature) timeParam(key string) *time.Time {
+	if value, ok := s.input.Params.Get(key); ok {
+		if timestamp, ok := value.(int64); ok {
+			ts := time.Unix(timestamp, 0)
+
+			return &ts
+		}
+	}
+
+	return nil
+}
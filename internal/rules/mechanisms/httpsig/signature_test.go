@@ -0,0 +1,359 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMessage struct {
+	method  string
+	url     string
+	headers map[string]string
+}
+
+func (m testMessage) Method() string { return m.method }
+
+func (m testMessage) URL() *url.URL {
+	reqURL, _ := url.Parse(m.url)
+
+	return reqURL
+}
+
+func (m testMessage) Header(name string) string { return m.headers[name] }
+
+func TestParseSignatures(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc             string
+		signatureInput string
+		signature      string
+		assert         func(t *testing.T, err error, sigs []*Signature)
+	}{
+		{
+			uc:             "malformed signature input",
+			signatureInput: `sig1=("@method"`,
+			signature:      `sig1=:aGVsbG8=:`,
+			assert: func(t *testing.T, err error, _ []*Signature) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedSignature)
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "Signature-Input")
+			},
+		},
+		{
+			uc:             "malformed signature",
+			signatureInput: `sig1=("@method")`,
+			signature:      `sig1=:aGVsbG8=`,
+			assert: func(t *testing.T, err error, _ []*Signature) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedSignature)
+				assert.Contains(t, err.Error(), "Signature header")
+			},
+		},
+		{
+			uc:             "signature input is not an inner list",
+			signatureInput: `sig1="@method"`,
+			signature:      `sig1=:aGVsbG8=:`,
+			assert: func(t *testing.T, err error, _ []*Signature) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedSignature)
+				assert.Contains(t, err.Error(), "not an inner list")
+			},
+		},
+		{
+			uc:             "no signature for label",
+			signatureInput: `sig1=("@method")`,
+			signature:      `sig2=:aGVsbG8=:`,
+			assert: func(t *testing.T, err error, _ []*Signature) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedSignature)
+				assert.Contains(t, err.Error(), "no signature present for label 'sig1'")
+			},
+		},
+		{
+			uc:             "signature is not a byte sequence",
+			signatureInput: `sig1=("@method")`,
+			signature:      `sig1="aGVsbG8="`,
+			assert: func(t *testing.T, err error, _ []*Signature) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedSignature)
+				assert.Contains(t, err.Error(), "not a byte sequence")
+			},
+		},
+		{
+			uc:             "component identifier is not a string",
+			signatureInput: `sig1=(method)`,
+			signature:      `sig1=:aGVsbG8=:`,
+			assert: func(t *testing.T, err error, _ []*Signature) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedSignature)
+				assert.Contains(t, err.Error(), "must be strings")
+			},
+		},
+		{
+			uc:             "component referenced multiple times",
+			signatureInput: `sig1=("@method" "@path" "@method")`,
+			signature:      `sig1=:aGVsbG8=:`,
+			assert: func(t *testing.T, err error, _ []*Signature) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedSignature)
+				assert.Contains(t, err.Error(), "referenced multiple times")
+			},
+		},
+		{
+			uc: "multiple signatures",
+			signatureInput: `sig1=("@method" "@query-param";name="foo");created=1618884473;expires=1618884773;` +
+				`keyid="test-key";alg="ed25519";nonce="abc";tag="app", sig2=()`,
+			signature: `sig2=:d29ybGQ=:, sig1=:aGVsbG8=:`,
+			assert: func(t *testing.T, err error, sigs []*Signature) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, sigs, 2)
+
+				sig := sigs[0]
+				assert.Equal(t, "sig1", sig.Label)
+				assert.Equal(t, []byte("hello"), sig.Value)
+				assert.Equal(t, []Component{
+					{Name: "@method"},
+					{Name: "@query-param", Params: Params{{Key: "name", Value: "foo"}}},
+				}, sig.Components)
+				assert.Equal(t, "test-key", sig.KeyID())
+				assert.Equal(t, "ed25519", sig.Algorithm())
+				assert.Equal(t, "abc", sig.Nonce())
+				assert.Equal(t, "app", sig.Tag())
+				assert.Equal(t, time.Unix(1618884473, 0), *sig.Created())
+				assert.Equal(t, time.Unix(1618884773, 0), *sig.Expires())
+				assert.True(t, sig.Covers("@method"))
+				assert.True(t, sig.Covers(`@query-param;name="foo"`))
+				assert.False(t, sig.Covers("@path"))
+
+				sig = sigs[1]
+				assert.Equal(t, "sig2", sig.Label)
+				assert.Equal(t, []byte("world"), sig.Value)
+				assert.Empty(t, sig.Components)
+				assert.Empty(t, sig.KeyID())
+				assert.Nil(t, sig.Created())
+				assert.Nil(t, sig.Expires())
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			sigs, err := ParseSignatures(tc.signatureInput, tc.signature)
+
+			// THEN
+			tc.assert(t, err, sigs)
+		})
+	}
+}
+
+func TestSignatureBase(t *testing.T) {
+	t.Parallel()
+
+	msg := testMessage{
+		method: "POST",
+		url:    "https://Example.com:443/foo%20bar?param=Value&Pet=dog&foo=a+b&dup=1&dup=2",
+		headers: map[string]string{
+			"content-type": "  application/json ",
+			"Content-Type": "wrong case",
+		},
+	}
+
+	for _, tc := range []struct {
+		uc     string
+		input  string
+		assert func(t *testing.T, err error, base []byte)
+	}{
+		{
+			uc: "all supported derived components",
+			input: `("@method" "@target-uri" "@authority" "@scheme" "@request-target" "@path" "@query" ` +
+				`"@query-param";name="Pet" "@query-param";name="foo");keyid="test"`,
+			assert: func(t *testing.T, err error, base []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, `"@method": POST
+"@target-uri": https://Example.com:443/foo%20bar?param=Value&Pet=dog&foo=a+b&dup=1&dup=2
+"@authority": example.com
+"@scheme": https
+"@request-target": /foo%20bar?param=Value&Pet=dog&foo=a+b&dup=1&dup=2
+"@path": /foo%20bar
+"@query": ?param=Value&Pet=dog&foo=a+b&dup=1&dup=2
+"@query-param";name="Pet": dog
+"@query-param";name="foo": a%20b
+"@signature-params": ("@method" "@target-uri" "@authority" "@scheme" "@request-target" "@path" "@query" `+
+					`"@query-param";name="Pet" "@query-param";name="foo");keyid="test"`, string(base))
+			},
+		},
+		{
+			uc:    "header field",
+			input: `("content-type")`,
+			assert: func(t *testing.T, err error, base []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "\"content-type\": application/json\n\"@signature-params\": (\"content-type\")",
+					string(base))
+			},
+		},
+		{
+			uc:    "missing header field",
+			input: `("content-digest")`,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMissingComponent)
+				assert.Contains(t, err.Error(), "content-digest")
+			},
+		},
+		{
+			uc:    "header field with parameters",
+			input: `("content-type";sf)`,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedComponent)
+			},
+		},
+		{
+			uc:    "header field with upper case name",
+			input: `("Content-Type")`,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedComponent)
+				assert.Contains(t, err.Error(), "lowercase")
+			},
+		},
+		{
+			uc:    "unsupported derived component",
+			input: `("@status")`,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedComponent)
+				assert.Contains(t, err.Error(), "@status")
+			},
+		},
+		{
+			uc:    "derived component with parameters",
+			input: `("@method";req)`,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedComponent)
+			},
+		},
+		{
+			uc:    "query parameter without name",
+			input: `("@query-param")`,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedComponent)
+				assert.Contains(t, err.Error(), "name parameter")
+			},
+		},
+		{
+			uc:    "not present query parameter",
+			input: `("@query-param";name="bar")`,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMissingComponent)
+			},
+		},
+		{
+			uc:    "query parameter present multiple times",
+			input: `("@query-param";name="dup")`,
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrUnsupportedComponent)
+				assert.Contains(t, err.Error(), "multiple times")
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			sigs, err := ParseSignatures("sig="+tc.input, "sig=:aGVsbG8=:")
+			require.NoError(t, err)
+
+			// WHEN
+			base, err := sigs[0].Base(msg)
+
+			// THEN
+			tc.assert(t, err, base)
+		})
+	}
+}
+
+func TestVerifyRFC9421TestVector(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	// test key and signature from RFC 9421, appendix B.1.4 and B.2.6
+	block, _ := pem.Decode([]byte(`-----BEGIN PUBLIC KEY-----
+MCowBQYDK2VwAyEAJrQLj5P/89iXES9+vFgrIy29clF9CC/oPPsw3c5D0bs=
+-----END PUBLIC KEY-----`))
+	require.NotNil(t, block)
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(t, err)
+
+	sigs, err := ParseSignatures(
+		`sig-b26=("date" "@method" "@path" "@authority" "content-type" "content-length");`+
+			`created=1618884473;keyid="test-key-ed25519"`,
+		`sig-b26=:wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw==:`)
+	require.NoError(t, err)
+
+	msg := testMessage{
+		method: "POST",
+		url:    "https://example.com/foo?param=Value&Pet=dog",
+		headers: map[string]string{
+			"date":           "Tue, 20 Apr 2021 02:07:55 GMT",
+			"content-type":   "application/json",
+			"content-length": "18",
+		},
+	}
+
+	// WHEN
+	base, err := sigs[0].Base(msg)
+	require.NoError(t, err)
+
+	err = Verify(AlgorithmEd25519, key, base, sigs[0].Value)
+
+	// THEN
+	require.NoError(t, err)
+}
@@ -0,0 +1,495 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// This file implements the subset of Structured Field Values for HTTP (RFC 8941) required
+// to process the Signature, Signature-Input and Content-Digest headers.
+
+var ErrMalformedStructuredField = errors.New("malformed structured field")
+
+// Token represents a structured field token, which, unlike a string, is serialized without quotes.
+type Token string
+
+type Param struct {
+	Key   string
+	Value any
+}
+
+type Params []Param
+
+func (p Params) Get(key string) (any, bool) {
+	for _, param := range p {
+		if param.Key == key {
+			return param.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+type Item struct {
+	Value  any
+	Params Params
+}
+
+type InnerList struct {
+	Items  []Item
+	Params Params
+}
+
+type DictionaryMember struct {
+	Key string
+	// Value is either an Item, or an InnerList
+	Value any
+}
+
+type Dictionary []DictionaryMember
+
+func (d Dictionary) Get(key string) (any, bool) {
+	for _, member := range d {
+		if member.Key == key {
+			return member.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+type sfParser struct {
+	input string
+	pos   int
+}
+
+func ParseDictionary(value string) (Dictionary, error) {
+	parser := &sfParser{input: value}
+	parser.skipSP()
+
+	dict, err := parser.parseDictionary()
+	if err != nil {
+		return nil, err
+	}
+
+	parser.skipSP()
+
+	if !parser.eof() {
+		return nil, parser.error("unexpected trailing characters")
+	}
+
+	return dict, nil
+}
+
+func (p *sfParser) eof() bool { return p.pos >= len(p.input) }
+
+func (p *sfParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *sfParser) error(msg string) error {
+	return errorchain.NewWithMessagef(ErrMalformedStructuredField, "%s at position %d", msg, p.pos)
+}
+
+func (p *sfParser) skipSP() {
+	for !p.eof() && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *sfParser) skipOWS() {
+	for !p.eof() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *sfParser) parseDictionary() (Dictionary, error) {
+	var dict Dictionary
+
+	for !p.eof() {
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		var member any
+
+		if p.peek() == '=' {
+			p.pos++
+
+			member, err = p.parseItemOrInnerList()
+		} else {
+			var params Params
+
+			params, err = p.parseParams()
+			member = Item{Value: true, Params: params}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// a later member with the same key overrides the earlier one
+		dict = append(removeMember(dict, key), DictionaryMember{Key: key, Value: member})
+
+		p.skipOWS()
+
+		if p.eof() {
+			return dict, nil
+		}
+
+		if p.peek() != ',' {
+			return nil, p.error("expected ','")
+		}
+
+		p.pos++
+		p.skipOWS()
+
+		if p.eof() {
+			return nil, p.error("unexpected trailing ','")
+		}
+	}
+
+	return dict, nil
+}
+
+func removeMember(dict Dictionary, key string) Dictionary {
+	for idx, member := range dict {
+		if member.Key == key {
+			return append(dict[:idx], dict[idx+1:]...)
+		}
+	}
+
+	return dict
+}
+
+func (p *sfParser) parseItemOrInnerList() (any, error) {
+	if p.peek() == '(' {
+		return p.parseInnerList()
+	}
+
+	return p.parseItem()
+}
+
+func (p *sfParser) parseInnerList() (InnerList, error) {
+	var list InnerList
+
+	p.pos++ // consume '('
+
+	for !p.eof() {
+		p.skipSP()
+
+		if p.peek() == ')' {
+			p.pos++
+
+			params, err := p.parseParams()
+			if err != nil {
+				return list, err
+			}
+
+			list.Params = params
+
+			return list, nil
+		}
+
+		item, err := p.parseItem()
+		if err != nil {
+			return list, err
+		}
+
+		list.Items = append(list.Items, item)
+
+		if c := p.peek(); !p.eof() && c != ' ' && c != ')' {
+			return list, p.error("expected ' ' or ')'")
+		}
+	}
+
+	return list, p.error("unterminated inner list")
+}
+
+func (p *sfParser) parseItem() (Item, error) {
+	value, err := p.parseBareItem()
+	if err != nil {
+		return Item{}, err
+	}
+
+	params, err := p.parseParams()
+	if err != nil {
+		return Item{}, err
+	}
+
+	return Item{Value: value, Params: params}, nil
+}
+
+func (p *sfParser) parseParams() (Params, error) {
+	var params Params
+
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSP()
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		var value any = true
+
+		if p.peek() == '=' {
+			p.pos++
+
+			if value, err = p.parseBareItem(); err != nil {
+				return nil, err
+			}
+		}
+
+		params = append(params, Param{Key: key, Value: value})
+	}
+
+	return params, nil
+}
+
+func (p *sfParser) parseKey() (string, error) {
+	start := p.pos
+
+	if c := p.peek(); !isLCAlpha(c) && c != '*' {
+		return "", p.error("expected key")
+	}
+
+	for !p.eof() {
+		c := p.peek()
+		if !isLCAlpha(c) && !isDigit(c) && c != '_' && c != '-' && c != '.' && c != '*' {
+			break
+		}
+
+		p.pos++
+	}
+
+	return p.input[start:p.pos], nil
+}
+
+func (p *sfParser) parseBareItem() (any, error) {
+	c := p.peek()
+
+	switch {
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case c == '"':
+		return p.parseString()
+	case c == ':':
+		return p.parseByteSequence()
+	case c == '?':
+		return p.parseBoolean()
+	case isAlpha(c) || c == '*':
+		return p.parseToken(), nil
+	default:
+		return nil, p.error("unexpected character")
+	}
+}
+
+func (p *sfParser) parseNumber() (any, error) {
+	start := p.pos
+
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	isDecimal := false
+
+	for !p.eof() {
+		c := p.peek()
+		if c == '.' && !isDecimal {
+			isDecimal = true
+		} else if !isDigit(c) {
+			break
+		}
+
+		p.pos++
+	}
+
+	if isDecimal {
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, p.error("malformed decimal")
+		}
+
+		return value, nil
+	}
+
+	value, err := strconv.ParseInt(p.input[start:p.pos], 10, 64)
+	if err != nil {
+		return nil, p.error("malformed integer")
+	}
+
+	return value, nil
+}
+
+func (p *sfParser) parseString() (string, error) {
+	var builder strings.Builder
+
+	p.pos++ // consume '"'
+
+	for !p.eof() {
+		c := p.input[p.pos]
+		p.pos++
+
+		switch {
+		case c == '\\':
+			if p.eof() || (p.peek() != '"' && p.peek() != '\\') {
+				return "", p.error("invalid escape sequence")
+			}
+
+			builder.WriteByte(p.input[p.pos])
+			p.pos++
+		case c == '"':
+			return builder.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return "", p.error("invalid character in string")
+		default:
+			builder.WriteByte(c)
+		}
+	}
+
+	return "", p.error("unterminated string")
+}
+
+func (p *sfParser) parseByteSequence() ([]byte, error) {
+	p.pos++ // consume ':'
+
+	end := strings.IndexByte(p.input[p.pos:], ':')
+	if end == -1 {
+		return nil, p.error("unterminated byte sequence")
+	}
+
+	value, err := base64.StdEncoding.DecodeString(p.input[p.pos : p.pos+end])
+	if err != nil {
+		return nil, p.error("malformed byte sequence")
+	}
+
+	p.pos += end + 1
+
+	return value, nil
+}
+
+func (p *sfParser) parseBoolean() (bool, error) {
+	p.pos++ // consume '?'
+
+	switch p.peek() {
+	case '1':
+		p.pos++
+
+		return true, nil
+	case '0':
+		p.pos++
+
+		return false, nil
+	default:
+		return false, p.error("malformed boolean")
+	}
+}
+
+func (p *sfParser) parseToken() Token {
+	start := p.pos
+
+	for !p.eof() {
+		c := p.peek()
+		if !isTChar(c) && c != ':' && c != '/' {
+			break
+		}
+
+		p.pos++
+	}
+
+	return Token(p.input[start:p.pos])
+}
+
+func isLCAlpha(c byte) bool { return c >= 'a' && c <= 'z' }
+func isAlpha(c byte) bool   { return isLCAlpha(c) || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool   { return c >= '0' && c <= '9' }
+
+func isTChar(c byte) bool {
+	return isAlpha(c) || isDigit(c) || strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1
+}
+
+func serializeInnerList(list InnerList) string {
+	var builder strings.Builder
+
+	builder.WriteByte('(')
+
+	for idx, item := range list.Items {
+		if idx != 0 {
+			builder.WriteByte(' ')
+		}
+
+		builder.WriteString(serializeItem(item))
+	}
+
+	builder.WriteByte(')')
+	builder.WriteString(serializeParams(list.Params))
+
+	return builder.String()
+}
+
+func serializeItem(item Item) string {
+	return serializeBareItem(item.Value) + serializeParams(item.Params)
+}
+
+func serializeParams(params Params) string {
+	var builder strings.Builder
+
+	for _, param := range params {
+		builder.WriteByte(';')
+		builder.WriteString(param.Key)
+
+		if value, ok := param.Value.(bool); !ok || !value {
+			builder.WriteByte('=')
+			builder.WriteString(serializeBareItem(param.Value))
+		}
+	}
+
+	return builder.String()
+}
+
+func serializeBareItem(value any) string {
+	switch val := value.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case string:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(val) + `"`
+	case Token:
+		return string(val)
+	case []byte:
+		return ":" + base64.StdEncoding.EncodeToString(val) + ":"
+	case bool:
+		return x.IfThenElse(val, "?1", "?0")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpsig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDictionary(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		value  string
+		assert func(t *testing.T, err error, dict Dictionary)
+	}{
+		{
+			uc:    "empty value",
+			value: "",
+			assert: func(t *testing.T, err error, dict Dictionary) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, dict)
+			},
+		},
+		{
+			uc:    "items of all supported types",
+			value: `a=1, b=-2.5, c="f\"o\\o", d=tok/en:1, e=:aGVsbG8=:, f=?0, g;p=1`,
+			assert: func(t *testing.T, err error, dict Dictionary) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, Dictionary{
+					{Key: "a", Value: Item{Value: int64(1)}},
+					{Key: "b", Value: Item{Value: -2.5}},
+					{Key: "c", Value: Item{Value: `f"o\o`}},
+					{Key: "d", Value: Item{Value: Token("tok/en:1")}},
+					{Key: "e", Value: Item{Value: []byte("hello")}},
+					{Key: "f", Value: Item{Value: false}},
+					{Key: "g", Value: Item{Value: true, Params: Params{{Key: "p", Value: int64(1)}}}},
+				}, dict)
+			},
+		},
+		{
+			uc:    "inner lists with parameters",
+			value: `sig1=("@method" "@query-param";name="foo");created=1618884473;keyid="test", sig2=()`,
+			assert: func(t *testing.T, err error, dict Dictionary) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, Dictionary{
+					{Key: "sig1", Value: InnerList{
+						Items: []Item{
+							{Value: "@method"},
+							{Value: "@query-param", Params: Params{{Key: "name", Value: "foo"}}},
+						},
+						Params: Params{{Key: "created", Value: int64(1618884473)}, {Key: "keyid", Value: "test"}},
+					}},
+					{Key: "sig2", Value: InnerList{}},
+				}, dict)
+			},
+		},
+		{
+			uc:    "duplicate keys",
+			value: `a=1, b=2, a=3`,
+			assert: func(t *testing.T, err error, dict Dictionary) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, Dictionary{
+					{Key: "b", Value: Item{Value: int64(2)}},
+					{Key: "a", Value: Item{Value: int64(3)}},
+				}, dict)
+			},
+		},
+		{
+			uc:    "trailing comma",
+			value: `a=1,`,
+			assert: func(t *testing.T, err error, _ Dictionary) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "trailing ','")
+			},
+		},
+		{
+			uc:    "invalid key",
+			value: `A=1`,
+			assert: func(t *testing.T, err error, _ Dictionary) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "expected key")
+			},
+		},
+		{
+			uc:    "missing separator",
+			value: `a=1 b=2`,
+			assert: func(t *testing.T, err error, _ Dictionary) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "expected ','")
+			},
+		},
+		{
+			uc:    "unterminated string",
+			value: `a="foo`,
+			assert: func(t *testing.T, err error, _ Dictionary) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "unterminated string")
+			},
+		},
+		{
+			uc:    "invalid escape sequence",
+			value: `a="f\oo"`,
+			assert: func(t *testing.T, err error, _ Dictionary) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "invalid escape sequence")
+			},
+		},
+		{
+			uc:    "malformed byte sequence",
+			value: `a=:foo!:`,
+			assert: func(t *testing.T, err error, _ Dictionary) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "malformed byte sequence")
+			},
+		},
+		{
+			uc:    "unterminated inner list",
+			value: `a=("foo" "bar"`,
+			assert: func(t *testing.T, err error, _ Dictionary) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "unterminated inner list")
+			},
+		},
+		{
+			uc:    "malformed boolean",
+			value: `a=?2`,
+			assert: func(t *testing.T, err error, _ Dictionary) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrMalformedStructuredField)
+				assert.Contains(t, err.Error(), "malformed boolean")
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			dict, err := ParseDictionary(tc.value)
+
+			// THEN
+			tc.assert(t, err, dict)
+		})
+	}
+}
+
+func TestSerializeInnerList(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	list := InnerList{
+		Items: []Item{
+			{Value: "@query-param", Params: Params{{Key: "name", Value: `f"oo`}}},
+			{Value: "content-digest"},
+		},
+		Params: Params{
+			{Key: "created", Value: int64(1618884473)},
+			{Key: "tag", Value: Token("app")},
+			{Key: "bin", Value: []byte("hello")},
+			{Key: "flag", Value: true},
+			{Key: "off", Value: false},
+			{Key: "dec", Value: 1.5},
+		},
+	}
+
+	// WHEN
+	result := serializeInnerList(list)
+
+	// THEN
+	assert.Equal(t,
+		`("@query-param";name="f\"oo" "content-digest");created=1618884473;tag=app;bin=:aGVsbG8=:;flag;off=?0;dec=1.5`,
+		result)
+
+	dict, err := ParseDictionary("sig=" + result)
+	require.NoError(t, err)
+	assert.Equal(t, Dictionary{{Key: "sig", Value: list}}, dict)
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	defaultServerMetadataTTL = 10 * time.Minute
+
+	oidcDiscoveryPath    = "/.well-known/openid-configuration"
+	oauth2MetadataPrefix = "/.well-known/oauth-authorization-server"
+)
+
+// ResolvedEndpointSettings holds the settings used to communicate with the endpoints
+// referenced in the server metadata document.
+type ResolvedEndpointSettings struct {
+	Retry            *endpoint.Retry                 `mapstructure:"retry"`
+	AuthStrategy     endpoint.AuthenticationStrategy `mapstructure:"auth"`
+	HTTPCacheEnabled *bool                           `mapstructure:"enable_http_cache"`
+}
+
+type MetadataEndpoint struct {
+	endpoint.Endpoint `mapstructure:",squash"`
+
+	DisableIssuerIdentifierVerification bool                     `mapstructure:"disable_issuer_identifier_verification"` //nolint:lll
+	ResolvedEndpoints                   ResolvedEndpointSettings `mapstructure:"resolved_endpoints"`
+}
+
+func (e *MetadataEndpoint) Get(ctx context.Context) (*ServerMetadata, error) {
+	logger := zerolog.Ctx(ctx)
+	cch := cache.Ctx(ctx)
+
+	cacheKey := e.calculateCacheKey()
+
+	if entry := cch.Get(cacheKey); entry != nil {
+		if metadata, ok := entry.(*ServerMetadata); ok {
+			logger.Debug().Msg("Reusing server metadata from cache")
+
+			return metadata, nil
+		}
+
+		logger.Warn().Msg("Wrong object type from cache")
+		cch.Delete(cacheKey)
+	}
+
+	logger.Debug().Msg("Retrieving server metadata")
+
+	metadata, err := e.fetchMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cch.Set(cacheKey, metadata, defaultServerMetadataTTL)
+
+	return metadata, nil
+}
+
+// ResolvedEndpoint creates an endpoint for the given url, which has been taken from the
+// server metadata document, using the configured settings for resolved endpoints.
+func (e *MetadataEndpoint) ResolvedEndpoint(endpointURL string) endpoint.Endpoint {
+	return endpoint.Endpoint{
+		URL:              endpointURL,
+		Retry:            e.ResolvedEndpoints.Retry,
+		AuthStrategy:     e.ResolvedEndpoints.AuthStrategy,
+		HTTPCacheEnabled: e.ResolvedEndpoints.HTTPCacheEnabled,
+	}
+}
+
+func (e *MetadataEndpoint) fetchMetadata(ctx context.Context) (*ServerMetadata, error) {
+	ept := e.Endpoint
+	ept.Headers = make(map[string]string, len(e.Headers)+1)
+
+	for k, v := range e.Headers {
+		ept.Headers[k] = v
+	}
+
+	if _, ok := ept.Headers["Accept"]; !ok {
+		ept.Headers["Accept"] = "application/json"
+	}
+
+	if len(ept.Method) == 0 {
+		ept.Method = http.MethodGet
+	}
+
+	rawData, err := ept.SendRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata ServerMetadata
+	if err = json.Unmarshal(rawData, &metadata); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrInternal, "failed to unmarshal received server metadata").
+			CausedBy(err)
+	}
+
+	if err = metadata.Validate(e.expectedIssuer()); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrCommunication, "received server metadata is invalid").
+			CausedBy(err)
+	}
+
+	return &metadata, nil
+}
+
+// expectedIssuer derives the issuer identifier from the well-known url of the metadata endpoint
+// as described in OpenID Connect Discovery 1.0 (section 4) and RFC 8414 (section 3). If the url
+// does not follow these conventions, or the verification is disabled, an empty string is returned.
+func (e *MetadataEndpoint) expectedIssuer() string {
+	if e.DisableIssuerIdentifierVerification {
+		return ""
+	}
+
+	metadataURL, err := url.Parse(e.URL)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case strings.HasSuffix(metadataURL.Path, oidcDiscoveryPath):
+		metadataURL.Path = strings.TrimSuffix(metadataURL.Path, oidcDiscoveryPath)
+	case strings.HasPrefix(metadataURL.Path, oauth2MetadataPrefix):
+		metadataURL.Path = strings.TrimPrefix(metadataURL.Path, oauth2MetadataPrefix)
+	default:
+		return ""
+	}
+
+	metadataURL.RawPath = ""
+	metadataURL.RawQuery = ""
+	metadataURL.Fragment = ""
+
+	return metadataURL.String()
+}
+
+func (e *MetadataEndpoint) calculateCacheKey() string {
+	digest := sha256.New()
+	digest.Write(stringx.ToBytes("server_metadata"))
+	digest.Write(e.Hash())
+
+	return hex.EncodeToString(digest.Sum(nil))
+}
@@ -0,0 +1,256 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/mocks"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+)
+
+func TestMetadataEndpointGet(t *testing.T) {
+	t.Parallel()
+
+	var (
+		endpointCalled  bool
+		responseCode    int
+		responseContent string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpointCalled = true
+
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(responseCode)
+		_, err := w.Write([]byte(responseContent))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		uc             string
+		ep             *MetadataEndpoint
+		code           int
+		content        string
+		configureCache func(t *testing.T, cch *mocks.CacheMock, ep *MetadataEndpoint)
+		assert         func(t *testing.T, err error, metadata *ServerMetadata)
+	}{
+		{
+			uc: "metadata taken from cache",
+			ep: &MetadataEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"}},
+			configureCache: func(t *testing.T, cch *mocks.CacheMock, ep *MetadataEndpoint) {
+				t.Helper()
+
+				cch.EXPECT().Get(ep.calculateCacheKey()).Return(&ServerMetadata{Issuer: "foo"})
+			},
+			assert: func(t *testing.T, err error, metadata *ServerMetadata) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.False(t, endpointCalled)
+				assert.Equal(t, "foo", metadata.Issuer)
+			},
+		},
+		{
+			uc:      "wrong object type in cache",
+			ep:      &MetadataEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"}},
+			code:    http.StatusOK,
+			content: `{"issuer": "` + srv.URL + `", "jwks_uri": "` + srv.URL + `/jwks"}`,
+			configureCache: func(t *testing.T, cch *mocks.CacheMock, ep *MetadataEndpoint) {
+				t.Helper()
+
+				cch.EXPECT().Get(ep.calculateCacheKey()).Return("foo")
+				cch.EXPECT().Delete(ep.calculateCacheKey())
+				cch.EXPECT().Set(ep.calculateCacheKey(), mock.Anything, defaultServerMetadataTTL)
+			},
+			assert: func(t *testing.T, err error, metadata *ServerMetadata) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, endpointCalled)
+				assert.Equal(t, srv.URL, metadata.Issuer)
+				assert.Equal(t, srv.URL+"/jwks", metadata.JWKSEndpointURL)
+			},
+		},
+		{
+			uc:   "unexpected response code",
+			ep:   &MetadataEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"}},
+			code: http.StatusNotFound,
+			assert: func(t *testing.T, err error, _ *ServerMetadata) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "unexpected response code")
+			},
+		},
+		{
+			uc:      "not a json document",
+			ep:      &MetadataEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"}},
+			code:    http.StatusOK,
+			content: "foo",
+			assert: func(t *testing.T, err error, _ *ServerMetadata) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrInternal)
+				assert.Contains(t, err.Error(), "failed to unmarshal")
+			},
+		},
+		{
+			uc:      "issuer is missing",
+			ep:      &MetadataEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL + "/metadata"}},
+			code:    http.StatusOK,
+			content: `{"jwks_uri": "` + srv.URL + `/jwks"}`,
+			assert: func(t *testing.T, err error, _ *ServerMetadata) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrServerMetadata)
+				assert.Contains(t, err.Error(), "issuer is missing")
+			},
+		},
+		{
+			uc:      "issuer does not match the openid configuration url",
+			ep:      &MetadataEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"}},
+			code:    http.StatusOK,
+			content: `{"issuer": "https://evil.com"}`,
+			assert: func(t *testing.T, err error, _ *ServerMetadata) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				require.ErrorIs(t, err, ErrServerMetadata)
+				assert.Contains(t, err.Error(), "does not match the expected issuer")
+			},
+		},
+		{
+			uc: "issuer does not match, but verification is disabled",
+			ep: &MetadataEndpoint{
+				Endpoint:                            endpoint.Endpoint{URL: srv.URL + "/.well-known/openid-configuration"},
+				DisableIssuerIdentifierVerification: true,
+			},
+			code:    http.StatusOK,
+			content: `{"issuer": "https://other.com"}`,
+			configureCache: func(t *testing.T, cch *mocks.CacheMock, ep *MetadataEndpoint) {
+				t.Helper()
+
+				cch.EXPECT().Get(ep.calculateCacheKey()).Return(nil)
+				cch.EXPECT().Set(ep.calculateCacheKey(), mock.Anything, defaultServerMetadataTTL)
+			},
+			assert: func(t *testing.T, err error, metadata *ServerMetadata) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "https://other.com", metadata.Issuer)
+			},
+		},
+		{
+			uc: "successful retrieval of authorization server metadata",
+			ep: &MetadataEndpoint{
+				Endpoint: endpoint.Endpoint{URL: srv.URL + "/.well-known/oauth-authorization-server/tenant"},
+			},
+			code: http.StatusOK,
+			content: `{
+"issuer": "` + srv.URL + `/tenant",
+"introspection_endpoint": "` + srv.URL + `/tenant/introspect",
+"id_token_signing_alg_values_supported": ["ES256", "none"]
+}`,
+			configureCache: func(t *testing.T, cch *mocks.CacheMock, ep *MetadataEndpoint) {
+				t.Helper()
+
+				cch.EXPECT().Get(ep.calculateCacheKey()).Return(nil)
+				cch.EXPECT().Set(ep.calculateCacheKey(), mock.Anything, defaultServerMetadataTTL)
+			},
+			assert: func(t *testing.T, err error, metadata *ServerMetadata) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.True(t, endpointCalled)
+				assert.Equal(t, srv.URL+"/tenant", metadata.Issuer)
+				assert.Equal(t, srv.URL+"/tenant/introspect", metadata.IntrospectionEndpointURL)
+				assert.Empty(t, metadata.JWKSEndpointURL)
+				assert.Equal(t, []string{"ES256"}, metadata.AllowedAlgorithms())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			endpointCalled = false
+			responseCode = tc.code
+			responseContent = tc.content
+
+			configureCache := tc.configureCache
+			if configureCache == nil {
+				configureCache = func(t *testing.T, cch *mocks.CacheMock, ep *MetadataEndpoint) {
+					t.Helper()
+
+					cch.EXPECT().Get(ep.calculateCacheKey()).Return(nil)
+				}
+			}
+
+			cch := mocks.NewCacheMock(t)
+			configureCache(t, cch, tc.ep)
+
+			// WHEN
+			metadata, err := tc.ep.Get(cache.WithContext(context.Background(), cch))
+
+			// THEN
+			tc.assert(t, err, metadata)
+		})
+	}
+}
+
+func TestMetadataEndpointResolvedEndpoint(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	enabled := true
+	ep := &MetadataEndpoint{
+		Endpoint: endpoint.Endpoint{
+			URL:     "https://foo.bar/.well-known/openid-configuration",
+			Headers: map[string]string{"X-Foo": "bar"},
+		},
+		ResolvedEndpoints: ResolvedEndpointSettings{
+			Retry:            &endpoint.Retry{MaxDelay: 1},
+			HTTPCacheEnabled: &enabled,
+		},
+	}
+
+	// WHEN
+	resolved := ep.ResolvedEndpoint("https://foo.bar/jwks")
+
+	// THEN
+	assert.Equal(t, "https://foo.bar/jwks", resolved.URL)
+	assert.Equal(t, ep.ResolvedEndpoints.Retry, resolved.Retry)
+	assert.Equal(t, &enabled, resolved.HTTPCacheEnabled)
+	assert.Nil(t, resolved.AuthStrategy)
+	assert.Empty(t, resolved.Headers)
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"errors"
+
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var ErrServerMetadata = errors.New("invalid server metadata")
+
+// ServerMetadata represents the subset of the authorization server metadata (as specified in
+// RFC 8414 and OpenID Connect Discovery 1.0) used by heimdall.
+type ServerMetadata struct {
+	Issuer                   string   `json:"issuer"`
+	JWKSEndpointURL          string   `json:"jwks_uri"`
+	IntrospectionEndpointURL string   `json:"introspection_endpoint"`
+	SigningAlgorithms        []string `json:"id_token_signing_alg_values_supported"`
+}
+
+func (m *ServerMetadata) Validate(expectedIssuer string) error {
+	if len(m.Issuer) == 0 {
+		return errorchain.NewWithMessage(ErrServerMetadata, "issuer is missing")
+	}
+
+	if len(expectedIssuer) != 0 && m.Issuer != expectedIssuer {
+		return errorchain.NewWithMessagef(ErrServerMetadata,
+			"issuer %s does not match the expected issuer %s", m.Issuer, expectedIssuer)
+	}
+
+	return nil
+}
+
+// AllowedAlgorithms returns the signing algorithms advertised by the server, without
+// the "none" algorithm.
+func (m *ServerMetadata) AllowedAlgorithms() []string {
+	algorithms := make([]string, 0, len(m.SigningAlgorithms))
+
+	for _, alg := range m.SigningAlgorithms {
+		if alg != "none" {
+			algorithms = append(algorithms, alg)
+		}
+	}
+
+	return algorithms
+}
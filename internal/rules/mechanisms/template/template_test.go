@@ -42,8 +42,11 @@ func TestTemplateRender(t *testing.T) {
 	ctx.EXPECT().Request().Return(&heimdall.Request{
 		RequestFunctions: reqf,
 		Method:           http.MethodPatch,
-		URL:              &url.URL{Scheme: "http", Host: "foobar.baz", Path: "zab", RawQuery: "my_query_param=query_value"},
-		ClientIP:         []string{"192.168.1.1"},
+		URL: &heimdall.URL{
+			URL:      url.URL{Scheme: "http", Host: "foobar.baz", Path: "zab", RawQuery: "my_query_param=query_value"},
+			Captures: map[string]string{"id": "zab"},
+		},
+		ClientIP: []string{"192.168.1.1"},
 	})
 
 	sub := &subject.Subject{
@@ -65,6 +68,7 @@ func TestTemplateRender(t *testing.T) {
 "my_header": {{ .Request.Header "X-My-Header" | quote }},
 "my_cookie": {{ .Request.Cookie "session_cookie" | quote }},
 "my_query_param": {{ index .Request.URL.Query.my_query_param 0 | quote }},
+"my_capture": {{ quote .Request.URL.Captures.id }},
 "ips": {{ range $i, $el := .Request.ClientIP -}}{{ if $i }} {{ end }}{{ quote $el }}{{ end }},
 "values": [{{ quote .Values.key1 }}, {{ quote .Values.key2 }}]
 }`)
@@ -90,6 +94,7 @@ func TestTemplateRender(t *testing.T) {
 "my_header": "my-value",
 "my_cookie": "session-value",
 "my_query_param": "query_value",
+"my_capture": "zab",
 "ips": "192.168.1.1",
 "values": ["foo", "bar"]
 }`, res)
@@ -47,15 +47,25 @@ var Module = fx.Options(
 				},
 			),
 		),
-		NewRuleFactory,
+		newReloadableRuleFactory,
+		func(f *reloadableRuleFactory) rule.Factory { return f },
 		fx.Annotate(
 			newRepository,
 			fx.OnStart(func(ctx context.Context, o *repository) error { return o.Start(ctx) }),
 			fx.OnStop(func(ctx context.Context, o *repository) error { return o.Stop(ctx) }),
 		),
 		func(r *repository) rule.Repository { return r },
+		func(r *repository) rule.Inventory { return r },
 		newRuleExecutor,
-		NewRuleSetProcessor,
+		newRuleSetProcessor,
+		func(p *ruleSetProcessor) rule.SetProcessor { return p },
+	),
+	fx.Invoke(
+		fx.Annotate(
+			newConfigReloader,
+			fx.OnStart(func(ctx context.Context, r *configReloader) error { return r.Start(ctx) }),
+			fx.OnStop(func(ctx context.Context, r *configReloader) error { return r.Stop(ctx) }),
+		),
 	),
 	provider.Module,
 )
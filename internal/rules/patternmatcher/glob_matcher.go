@@ -36,6 +36,8 @@ func (m *globMatcher) Match(value string) bool {
 	return m.compiled.Match(value)
 }
 
+func (m *globMatcher) Captures(_ string) map[string]string { return nil }
+
 func newGlobMatcher(pattern string) (*globMatcher, error) {
 	if len(pattern) == 0 {
 		return nil, ErrNoGlobPatternDefined
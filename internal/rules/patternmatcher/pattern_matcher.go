@@ -18,12 +18,16 @@ package patternmatcher
 
 import (
 	"errors"
+	"strings"
 )
 
 var ErrUnsupportedPatternMatcher = errors.New("unsupported pattern matcher")
 
 type PatternMatcher interface {
 	Match(value string) bool
+	// Captures returns the values of the named captures of the pattern for the given value.
+	// It returns nil if the pattern does not define named captures or does not match the value.
+	Captures(value string) map[string]string
 }
 
 func NewPatternMatcher(typ, pattern string) (PatternMatcher, error) {
@@ -32,7 +36,46 @@ func NewPatternMatcher(typ, pattern string) (PatternMatcher, error) {
 		return newGlobMatcher(pattern)
 	case "regex":
 		return newRegexMatcher(pattern)
+	case "template":
+		return newTemplateMatcher(pattern)
 	default:
 		return nil, ErrUnsupportedPatternMatcher
 	}
 }
+
+// LiteralPrefix returns the part of the given pattern, which precedes its first wildcard
+// expression (enclosed in '<' and '>' for the glob and regex strategies, and a placeholder
+// for the template strategy). Since the text outside of these expressions is matched
+// literally, every value matched by the pattern starts with the returned prefix.
+func LiteralPrefix(typ, pattern string) string {
+	idx := -1
+
+	switch typ {
+	case "glob", "regex":
+		idx = strings.IndexByte(pattern, '<')
+	case "template":
+		idx = firstPlaceholderIndex(pattern)
+	}
+
+	if idx != -1 {
+		return pattern[:idx]
+	}
+
+	return pattern
+}
+
+// MatchesAllWithPrefix tells whether the given pattern consists of its literal prefix followed
+// by a single wildcard expression matching any value (<**> for the glob and <.*> for the regex
+// strategy). Such a pattern matches every value starting with that prefix.
+func MatchesAllWithPrefix(typ, pattern string) bool {
+	rest := strings.TrimPrefix(pattern, LiteralPrefix(typ, pattern))
+
+	switch typ {
+	case "glob":
+		return rest == "<**>"
+	case "regex":
+		return rest == "<.*>"
+	default:
+		return false
+	}
+}
@@ -18,6 +18,7 @@ package patternmatcher
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/dlclark/regexp2"
 	"github.com/ory/ladon/compiler"
@@ -27,6 +28,7 @@ var ErrNoRegexPatternDefined = errors.New("no glob pattern defined")
 
 type regexpMatcher struct {
 	compiled *regexp2.Regexp
+	names    []string
 }
 
 func newRegexMatcher(pattern string) (*regexpMatcher, error) {
@@ -39,7 +41,20 @@ func newRegexMatcher(pattern string) (*regexpMatcher, error) {
 		return nil, err
 	}
 
-	return &regexpMatcher{compiled: compiled}, nil
+	return newRegexpMatcherFrom(compiled), nil
+}
+
+func newRegexpMatcherFrom(compiled *regexp2.Regexp) *regexpMatcher {
+	var names []string
+
+	for _, name := range compiled.GetGroupNames() {
+		// unnamed groups are named by their numbers
+		if _, err := strconv.Atoi(name); err != nil {
+			names = append(names, name)
+		}
+	}
+
+	return &regexpMatcher{compiled: compiled, names: names}
 }
 
 func (m *regexpMatcher) Match(matchAgainst string) bool {
@@ -48,3 +63,25 @@ func (m *regexpMatcher) Match(matchAgainst string) bool {
 
 	return ok
 }
+
+func (m *regexpMatcher) Captures(matchAgainst string) map[string]string {
+	if len(m.names) == 0 {
+		return nil
+	}
+
+	// ignoring error as it will be set on timeouts, which basically is the same as match miss
+	match, _ := m.compiled.FindStringMatch(matchAgainst)
+	if match == nil {
+		return nil
+	}
+
+	captures := make(map[string]string, len(m.names))
+
+	for _, name := range m.names {
+		if group := match.GroupByName(name); group != nil && len(group.Captures) != 0 {
+			captures[name] = group.String()
+		}
+	}
+
+	return captures
+}
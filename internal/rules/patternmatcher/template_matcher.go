@@ -0,0 +1,135 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package patternmatcher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dlclark/regexp2"
+)
+
+const templateMatchTimeout = 250 * time.Millisecond
+
+var (
+	ErrNoTemplatePatternDefined = errors.New("no template pattern defined")
+	ErrBadPlaceholder           = errors.New("bad placeholder")
+)
+
+// newTemplateMatcher creates a matcher for url templates like /users/:id/orders/{order}.
+// Each placeholder, either in the :name or in the {name} form, matches exactly one
+// non-empty path segment (or a part of it), which is captured under the given name.
+// The :name form is only recognized directly after a '/'. All other characters are
+// matched literally.
+func newTemplateMatcher(pattern string) (*regexpMatcher, error) {
+	if len(pattern) == 0 {
+		return nil, ErrNoTemplatePatternDefined
+	}
+
+	var (
+		buf  strings.Builder
+		seen = make(map[string]bool)
+	)
+
+	buf.WriteByte('^')
+
+	for len(pattern) != 0 {
+		idx := firstPlaceholderIndex(pattern)
+		if idx == -1 {
+			buf.WriteString(regexp2.Escape(pattern))
+
+			break
+		}
+
+		buf.WriteString(regexp2.Escape(pattern[:idx]))
+
+		name, rest, err := cutPlaceholder(pattern[idx:])
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s is used multiple times", ErrBadPlaceholder, name)
+		}
+
+		seen[name] = true
+		pattern = rest
+
+		fmt.Fprintf(&buf, "(?<%s>[^/]+)", name)
+	}
+
+	buf.WriteByte('$')
+
+	compiled, err := regexp2.Compile(buf.String(), regexp2.None)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled.MatchTimeout = templateMatchTimeout
+
+	return newRegexpMatcherFrom(compiled), nil
+}
+
+func firstPlaceholderIndex(pattern string) int {
+	for idx := 0; idx < len(pattern); idx++ {
+		switch pattern[idx] {
+		case '{':
+			return idx
+		case ':':
+			if idx > 0 && pattern[idx-1] == '/' && idx+1 < len(pattern) && isNameStart(pattern[idx+1]) {
+				return idx
+			}
+		}
+	}
+
+	return -1
+}
+
+func cutPlaceholder(pattern string) (string, string, error) {
+	if pattern[0] == ':' {
+		end := 1
+		for end < len(pattern) && isNameChar(pattern[end]) {
+			end++
+		}
+
+		return pattern[1:end], pattern[end:], nil
+	}
+
+	end := strings.IndexByte(pattern, '}')
+	if end == -1 {
+		return "", "", fmt.Errorf("%w: missing closing brace in %s", ErrBadPlaceholder, pattern)
+	}
+
+	name := pattern[1:end]
+	if len(name) == 0 || !isNameStart(name[0]) || strings.IndexFunc(name, func(r rune) bool {
+		return r > 0x7f || !isNameChar(byte(r))
+	}) != -1 {
+		return "", "", fmt.Errorf("%w: invalid name '%s'", ErrBadPlaceholder, name)
+	}
+
+	return name, pattern[end+1:], nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || ('0' <= c && c <= '9')
+}
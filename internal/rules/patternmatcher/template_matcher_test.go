@@ -0,0 +1,199 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package patternmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateMatcherCreation(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc      string
+		pattern string
+		err     error
+	}{
+		{uc: "empty pattern", err: ErrNoTemplatePatternDefined},
+		{uc: "unclosed brace", pattern: "http://foo.bar/{id", err: ErrBadPlaceholder},
+		{uc: "empty name", pattern: "http://foo.bar/{}", err: ErrBadPlaceholder},
+		{uc: "invalid name", pattern: "http://foo.bar/{1d}", err: ErrBadPlaceholder},
+		{uc: "duplicate name", pattern: "http://foo.bar/:id/{id}", err: ErrBadPlaceholder},
+		{uc: "valid pattern", pattern: "http://foo.bar/users/:id/orders/{order}"},
+		{uc: "pattern without placeholders", pattern: "http://foo.bar:8080/users"},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			_, err := newTemplateMatcher(tc.pattern)
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTemplateMatcherMatchAndCaptures(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc           string
+		pattern      string
+		matchAgainst string
+		shouldMatch  bool
+		captures     map[string]string
+	}{
+		{
+			uc:           "both placeholder forms",
+			pattern:      "http://foo.bar/users/:id/orders/{order}",
+			matchAgainst: "http://foo.bar/users/1/orders/abc",
+			shouldMatch:  true,
+			captures:     map[string]string{"id": "1", "order": "abc"},
+		},
+		{
+			uc:           "placeholder matches a single segment only",
+			pattern:      "http://foo.bar/users/:id",
+			matchAgainst: "http://foo.bar/users/1/orders",
+		},
+		{
+			uc:           "placeholder doesn't match empty segment",
+			pattern:      "http://foo.bar/users/{id}",
+			matchAgainst: "http://foo.bar/users/",
+		},
+		{
+			uc:           "placeholder in host and as part of a segment",
+			pattern:      "https://{tenant}.foo.bar/files/{name}.json",
+			matchAgainst: "https://acme.foo.bar/files/report.json",
+			shouldMatch:  true,
+			captures:     map[string]string{"tenant": "acme", "name": "report"},
+		},
+		{
+			uc:           "special characters are matched literally",
+			pattern:      "http://foo.bar:8080/a.b/(c)/:id",
+			matchAgainst: "http://foo.bar:8080/a.b/(c)/1",
+			shouldMatch:  true,
+			captures:     map[string]string{"id": "1"},
+		},
+		{
+			uc:           "special characters are not interpreted",
+			pattern:      "http://foo.bar/a.b/:id",
+			matchAgainst: "http://foo.bar/axb/1",
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			matcher, err := newTemplateMatcher(tc.pattern)
+			require.NoError(t, err)
+
+			// WHEN
+			matched := matcher.Match(tc.matchAgainst)
+			captures := matcher.Captures(tc.matchAgainst)
+
+			// THEN
+			assert.Equal(t, tc.shouldMatch, matched)
+			assert.Equal(t, tc.captures, captures)
+		})
+	}
+}
+
+func TestRegexMatcherCaptures(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc           string
+		pattern      string
+		matchAgainst string
+		captures     map[string]string
+	}{
+		{
+			uc:           "named groups",
+			pattern:      `http://foo.bar/users/<(?<id>[0-9]+)>/<(?<rest>.*)>`,
+			matchAgainst: "http://foo.bar/users/12/foo/bar",
+			captures:     map[string]string{"id": "12", "rest": "foo/bar"},
+		},
+		{
+			uc:           "no named groups",
+			pattern:      `http://foo.bar/users/<[0-9]+>`,
+			matchAgainst: "http://foo.bar/users/12",
+		},
+		{
+			uc:           "not matching value",
+			pattern:      `http://foo.bar/users/<(?<id>[0-9]+)>`,
+			matchAgainst: "http://foo.bar/users/foo",
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			matcher, err := newRegexMatcher(tc.pattern)
+			require.NoError(t, err)
+
+			// WHEN
+			captures := matcher.Captures(tc.matchAgainst)
+
+			// THEN
+			assert.Equal(t, tc.captures, captures)
+		})
+	}
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		strategy string
+		pattern  string
+		prefix   string
+	}{
+		{strategy: "glob", pattern: "http://foo.bar/<**>", prefix: "http://foo.bar/"},
+		{strategy: "glob", pattern: "<{http,https}>://foo.bar/<**>", prefix: ""},
+		{strategy: "regex", pattern: "http://foo.bar/api/<.*>", prefix: "http://foo.bar/api/"},
+		{strategy: "regex", pattern: "http://foo.bar/api", prefix: "http://foo.bar/api"},
+		{strategy: "template", pattern: "http://foo.bar:80/users/:id", prefix: "http://foo.bar:80/users/"},
+		{strategy: "template", pattern: "http://{tenant}.foo.bar/", prefix: "http://"},
+		{strategy: "template", pattern: "http://foo.bar/<id>", prefix: "http://foo.bar/<id>"},
+	} {
+		t.Run(tc.strategy+":"+tc.pattern, func(t *testing.T) {
+			assert.Equal(t, tc.prefix, LiteralPrefix(tc.strategy, tc.pattern))
+		})
+	}
+}
+
+func TestMatchesAllWithPrefix(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		strategy string
+		pattern  string
+		result   bool
+	}{
+		{strategy: "glob", pattern: "http://foo.bar/<**>", result: true},
+		{strategy: "glob", pattern: "http://foo.bar/<*>", result: false},
+		{strategy: "glob", pattern: "http://foo.bar/<**>/baz", result: false},
+		{strategy: "glob", pattern: "http://foo.bar/", result: false},
+		{strategy: "regex", pattern: "http://foo.bar/api/<.*>", result: true},
+		{strategy: "regex", pattern: "http://foo.bar/api/<.+>", result: false},
+		{strategy: "template", pattern: "http://foo.bar/:id", result: false},
+	} {
+		t.Run(tc.strategy+":"+tc.pattern, func(t *testing.T) {
+			assert.Equal(t, tc.result, MatchesAllWithPrefix(tc.strategy, tc.pattern))
+		})
+	}
+}
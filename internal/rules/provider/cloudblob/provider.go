@@ -32,7 +32,9 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	rule_config "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
 	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 	"github.com/dadrus/heimdall/internal/x/slicex"
@@ -42,6 +44,7 @@ type BucketState map[string][]byte
 
 type provider struct {
 	p          rule.SetProcessor
+	t          *syncstatus.Tracker
 	l          zerolog.Logger
 	s          *gocron.Scheduler
 	cancel     context.CancelFunc
@@ -52,6 +55,8 @@ type provider struct {
 func newProvider(
 	conf *config.Configuration,
 	processor rule.SetProcessor,
+	verifier signature.Verifier,
+	registry *syncstatus.Registry,
 	logger zerolog.Logger,
 ) (*provider, error) {
 	rawConf := conf.Providers.CloudBlob
@@ -77,16 +82,30 @@ func newProvider(
 			"no buckets configured for cloud_blob rule provider")
 	}
 
+	sources := make([]string, len(providerConf.Buckets))
+
+	for idx, bucket := range providerConf.Buckets {
+		if bucket.URL == nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"missing url for #%d bucket in cloud_blob rule provider configuration", idx)
+		}
+
+		sources[idx] = bucket.ID()
+	}
+
 	logger = logger.With().Str("_provider_type", "cloud_blob").Logger()
 
 	ctx, cancel := context.WithCancel(context.Background())
-	ctx = logger.With().Logger().WithContext(ctx)
+	ctx = logger.With().Logger().WithContext(signature.WithContext(ctx, verifier))
 
 	scheduler := gocron.NewScheduler(time.UTC)
 	scheduler.SingletonModeAll()
 
+	tracker := registry.Register("cloud_blob", sources...)
+
 	prov := &provider{
-		p:          processor,
+		p:          tracker.Processor(processor),
+		t:          tracker,
 		l:          logger,
 		s:          scheduler,
 		cancel:     cancel,
@@ -94,11 +113,6 @@ func newProvider(
 	}
 
 	for idx, bucket := range providerConf.Buckets {
-		if bucket.URL == nil {
-			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
-				"missing url for #%d bucket in cloud_blob rule provider configuration", idx)
-		}
-
 		if _, err := x.IfThenElseExec(providerConf.WatchInterval != nil && *providerConf.WatchInterval > 0,
 			func() *gocron.Scheduler { return prov.s.Every(*providerConf.WatchInterval) },
 			func() *gocron.Scheduler { return prov.s.Every(1 * time.Second).LimitRunsTo(1) }).
@@ -142,6 +156,8 @@ func (p *provider) Stop(_ context.Context) error {
 func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 	p.l.Debug().Msg("Retrieving rule set")
 
+	synced := true
+
 	ruleSets, err := rsf.FetchRuleSets(ctx)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
@@ -155,6 +171,10 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 			Str("_endpoint", rsf.ID()).
 			Msg("Failed to fetch rule set")
 
+		synced = false
+
+		p.t.Failed(err)
+
 		if errors.Is(err, heimdall.ErrInternal) || errors.Is(err, heimdall.ErrConfiguration) {
 			return err
 		}
@@ -166,11 +186,17 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 	if len(ruleSets) == 0 && len(state) == 0 {
 		p.l.Debug().Str("_endpoint", rsf.ID()).Msg("No updates received")
 
+		if synced {
+			p.t.Synced(rsf.ID())
+		}
+
 		return nil
 	}
 
 	if err = p.ruleSetsUpdated(ruleSets, state, rsf.ID()); err != nil {
 		p.l.Warn().Err(err).Str("_endpoint", rsf.ID()).Msg("Failed to apply rule set changes")
+	} else if synced {
+		p.t.Synced(rsf.ID())
 	}
 
 	return nil
@@ -36,6 +36,7 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 	mock2 "github.com/dadrus/heimdall/internal/x/testsupport/mock"
@@ -138,7 +139,10 @@ buckets:
 			}
 
 			// WHEN
-			prov, err := newProvider(conf, mocks.NewRuleSetProcessorMock(t), log.Logger)
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, mocks.NewRuleSetProcessorMock(t), verifier, nil, log.Logger)
 
 			// THEN
 			tc.assert(t, err, prov)
@@ -520,7 +524,10 @@ rules:
 			}
 
 			logs := &strings.Builder{}
-			prov, err := newProvider(conf, mock, zerolog.New(logs))
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, mock, verifier, nil, zerolog.New(logs))
 			require.NoError(t, err)
 
 			ctx := context.Background()
@@ -17,6 +17,7 @@
 package cloudblob
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -31,9 +32,14 @@ import (
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
+// signatureMetadataKey is the key of the blob metadata entry carrying the detached signature
+// of the rule set.
+const signatureMetadataKey = "signature"
+
 type ruleSetEndpoint struct {
 	URL             *url.URL `mapstructure:"url"`
 	Prefix          string   `mapstructure:"prefix"`
@@ -118,7 +124,19 @@ func (e *ruleSetEndpoint) readRuleSet(ctx context.Context, bucket *blob.Bucket,
 
 	defer reader.Close()
 
-	contents, err := config.ParseRules(attrs.ContentType, reader, false)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, mapError(err, "failed reading blob contents")
+	}
+
+	src := fmt.Sprintf("%s@%s", key, e.ID())
+
+	verified, err := signature.Ctx(ctx).Verify(ctx, src, data, attrs.Metadata[signatureMetadataKey])
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := config.ParseRules(signature.ContentType(attrs.ContentType), bytes.NewReader(verified), false)
 	if err != nil {
 		return nil, errorchain.
 			NewWithMessage(heimdall.ErrInternal, "failed to decode received rule set").
@@ -130,7 +148,7 @@ func (e *ruleSetEndpoint) readRuleSet(ctx context.Context, bucket *blob.Bucket,
 	}
 
 	contents.Hash = attrs.MD5
-	contents.Source = fmt.Sprintf("%s@%s", key, e.ID())
+	contents.Source = src
 	contents.ModTime = attrs.ModTime
 
 	return contents, nil
@@ -0,0 +1,466 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/keystore"
+	rule_config "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/rules/signature"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	ProviderType = "etcd"
+
+	defaultDialTimeout   = 5 * time.Second
+	defaultRetryInterval = 5 * time.Second
+)
+
+var errWatchClosed = errors.New("watch channel closed")
+
+type provider struct {
+	p             rule.SetProcessor
+	t             *syncstatus.Tracker
+	v             signature.Verifier
+	l             zerolog.Logger
+	cl            *clientv3.Client
+	prefix        string
+	pathPrefix    string
+	retryInterval time.Duration
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	state         map[string][]byte
+	configured    bool
+}
+
+func newProvider(
+	conf *config.Configuration,
+	processor rule.SetProcessor,
+	verifier signature.Verifier,
+	registry *syncstatus.Registry,
+	logger zerolog.Logger,
+) (*provider, error) {
+	rawConf := conf.Providers.Etcd
+
+	if rawConf == nil {
+		return &provider{}, nil
+	}
+
+	type Config struct {
+		Endpoints       []string       `mapstructure:"endpoints"`
+		Prefix          string         `mapstructure:"prefix"`
+		Username        string         `mapstructure:"username"`
+		Password        string         `mapstructure:"password"`
+		DialTimeout     *time.Duration `mapstructure:"dial_timeout"`
+		RulesPathPrefix string         `mapstructure:"rule_path_match_prefix"`
+		TLS             *tlsConfig     `mapstructure:"tls"`
+	}
+
+	var providerConf Config
+	if err := decodeConfig(rawConf, &providerConf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed to decode etcd rule provider config").
+			CausedBy(err)
+	}
+
+	if len(providerConf.Endpoints) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"no endpoints configured for etcd rule provider")
+	}
+
+	if len(providerConf.Prefix) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"no key prefix configured for etcd rule provider")
+	}
+
+	useTLS := providerConf.TLS != nil
+	for _, endpoint := range providerConf.Endpoints {
+		useTLS = useTLS || strings.HasPrefix(endpoint, "https://")
+	}
+
+	var tlsConf *tls.Config
+
+	if useTLS {
+		var err error
+
+		if tlsConf, err = newTLSConfig(providerConf.TLS); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints: providerConf.Endpoints,
+		Username:  providerConf.Username,
+		Password:  providerConf.Password,
+		DialTimeout: x.IfThenElseExec(providerConf.DialTimeout != nil,
+			func() time.Duration { return *providerConf.DialTimeout },
+			func() time.Duration { return defaultDialTimeout }),
+		TLS:    tlsConf,
+		Logger: zap.NewNop(),
+	})
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed creating client for connecting to etcd").CausedBy(err)
+	}
+
+	logger = logger.With().Str("_provider_type", ProviderType).Logger()
+
+	logger.Info().Msg("Rule provider configured.")
+
+	tracker := registry.Register(ProviderType, providerConf.Prefix)
+
+	return &provider{
+		p:             tracker.Processor(processor),
+		t:             tracker,
+		v:             verifier,
+		l:             logger,
+		cl:            client,
+		prefix:        providerConf.Prefix,
+		pathPrefix:    providerConf.RulesPathPrefix,
+		retryInterval: defaultRetryInterval,
+		state:         make(map[string][]byte),
+		configured:    true,
+	}, nil
+}
+
+func (p *provider) Start(_ context.Context) error {
+	if !p.configured {
+		return nil
+	}
+
+	p.l.Info().Msg("Starting rule definitions provider")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = p.l.With().Logger().WithContext(ctx)
+
+	p.cancel = cancel
+
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+
+		p.l.Debug().Msg("Starting watch loop")
+
+		p.watchChanges(ctx) //nolint:contextcheck
+
+		p.l.Debug().Msg("Watch loop exited")
+	}()
+
+	return nil
+}
+
+func (p *provider) Stop(ctx context.Context) error {
+	if !p.configured {
+		return nil
+	}
+
+	p.l.Info().Msg("Tearing down rule provider")
+
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		p.l.Warn().Msg("Graceful tearing down aborted (timed out).")
+	}
+
+	return p.cl.Close()
+}
+
+// watchChanges loads all rule sets stored under the configured prefix and watches for changes
+// afterwards. If the watch breaks, e.g. because the connection to etcd is lost or the watched
+// revision has been compacted, all rule sets are synchronized again before watching is resumed.
+func (p *provider) watchChanges(ctx context.Context) {
+	for {
+		revision, err := p.syncRuleSets(ctx)
+		if err == nil {
+			err = p.watchRuleSets(ctx, revision)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.t.Failed(err)
+		p.l.Warn().Err(err).Msgf("Watching rule sets failed. Retrying in %s", p.retryInterval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.retryInterval):
+		}
+	}
+}
+
+func (p *provider) syncRuleSets(ctx context.Context) (int64, error) {
+	p.l.Debug().Str("_prefix", p.prefix).Msg("Retrieving rule sets")
+
+	resp, err := p.cl.Get(ctx, p.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return 0, errorchain.NewWithMessage(heimdall.ErrCommunication, "failed to retrieve rule sets").
+			CausedBy(err)
+	}
+
+	current := make(map[string]bool, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		current[string(kv.Key)] = true
+	}
+
+	for key := range p.state {
+		if !current[key] {
+			p.ruleSetDeleted(key)
+		}
+	}
+
+	for _, kv := range resp.Kvs {
+		p.ruleSetChanged(ctx, kv)
+	}
+
+	if len(resp.Kvs) == 0 {
+		p.l.Debug().Str("_prefix", p.prefix).Msg("No rule sets found")
+	}
+
+	p.t.Synced(p.prefix)
+
+	return resp.Header.Revision, nil
+}
+
+func (p *provider) watchRuleSets(ctx context.Context, revision int64) error {
+	watchChan := p.cl.Watch(clientv3.WithRequireLeader(ctx), p.prefix,
+		clientv3.WithPrefix(), clientv3.WithRev(revision+1))
+
+	for resp := range watchChan {
+		if err := resp.Err(); err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrCommunication, "watching rule sets failed").
+				CausedBy(err)
+		}
+
+		for _, evt := range resp.Events {
+			switch evt.Type {
+			case mvccpb.PUT:
+				p.ruleSetChanged(ctx, evt.Kv)
+			case mvccpb.DELETE:
+				p.ruleSetDeleted(string(evt.Kv.Key))
+			}
+		}
+	}
+
+	return errWatchClosed
+}
+
+func (p *provider) ruleSetChanged(ctx context.Context, kv *mvccpb.KeyValue) {
+	key := string(kv.Key)
+
+	ruleSet, err := p.toRuleSet(ctx, kv)
+	if err != nil {
+		if errors.Is(err, rule_config.ErrEmptyRuleSet) {
+			p.ruleSetDeleted(key)
+
+			return
+		}
+
+		p.l.Warn().Err(err).Str("_key", key).Msg("Ignoring invalid rule set")
+		p.t.Failed(err)
+
+		return
+	}
+
+	oldHash, known := p.state[key]
+	if known && bytes.Equal(oldHash, ruleSet.Hash) {
+		p.l.Debug().Str("_key", key).Msg("No updates received")
+		p.t.Synced(p.prefix)
+
+		return
+	}
+
+	if known {
+		p.l.Info().Str("_key", key).Msg("Rule set update received")
+
+		err = p.p.OnUpdated(ruleSet)
+	} else {
+		p.l.Info().Str("_key", key).Msg("New rule set received")
+
+		err = p.p.OnCreated(ruleSet)
+	}
+
+	if err != nil {
+		p.l.Warn().Err(err).Str("_src", ruleSet.Source).Msg("Failed to apply rule set changes")
+
+		return
+	}
+
+	p.state[key] = ruleSet.Hash
+	p.t.Synced(p.prefix)
+}
+
+func (p *provider) ruleSetDeleted(key string) {
+	if _, known := p.state[key]; !known {
+		return
+	}
+
+	p.l.Info().Str("_key", key).Msg("Rule set deletion received")
+
+	conf := &rule_config.RuleSet{
+		MetaData: rule_config.MetaData{
+			Source:  p.toSource(key),
+			ModTime: time.Now(),
+		},
+	}
+
+	if err := p.p.OnDeleted(conf); err != nil {
+		p.l.Warn().Err(err).Str("_src", conf.Source).Msg("Failed deleting rule set")
+
+		return
+	}
+
+	delete(p.state, key)
+	p.t.Synced(p.prefix)
+}
+
+func (p *provider) toRuleSet(ctx context.Context, kv *mvccpb.KeyValue) (*rule_config.RuleSet, error) {
+	src := p.toSource(string(kv.Key))
+
+	contents, err := p.v.Verify(ctx, src, kv.Value, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet, err := rule_config.ParseRules("application/yaml", bytes.NewReader(contents), false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ruleSet.VerifyPathPrefix(p.pathPrefix); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(kv.Value)
+
+	ruleSet.Hash = hash[:]
+	ruleSet.Source = src
+	ruleSet.ModTime = time.Now()
+	ruleSet.Revision = strconv.FormatInt(kv.ModRevision, 10)
+
+	return ruleSet, nil
+}
+
+func (p *provider) toSource(key string) string {
+	return fmt.Sprintf("%s:%s", ProviderType, key)
+}
+
+// tlsConfig configures the trust anchors used to verify the certificate of the etcd server
+// and the client certificate used to authenticate against it.
+type tlsConfig struct {
+	TrustStore string           `mapstructure:"trust_store"`
+	KeyStore   *config.KeyStore `mapstructure:"key_store"`
+	KeyID      string           `mapstructure:"key_id"`
+}
+
+func newTLSConfig(conf *tlsConfig) (*tls.Config, error) {
+	// without a trust store, the server certificate is verified using the system trust store
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if conf == nil {
+		return cfg, nil
+	}
+
+	if len(conf.TrustStore) != 0 {
+		ts, err := truststore.NewTrustStoreFromPEMFile(conf.TrustStore, false)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"failed loading trust store for etcd rule provider").CausedBy(err)
+		}
+
+		if len(ts) == 0 {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"trust store for etcd rule provider does not contain any certificates")
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		for _, cert := range ts {
+			cfg.RootCAs.AddCert(cert)
+		}
+	}
+
+	if conf.KeyStore != nil {
+		cert, err := clientCertificate(conf.KeyStore, conf.KeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+func clientCertificate(conf *config.KeyStore, keyID string) (tls.Certificate, error) {
+	var entry *keystore.Entry
+
+	ks, err := keystore.NewKeyStoreFromPEMFile(conf.Path, conf.Password)
+	if err != nil {
+		return tls.Certificate{}, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed loading key store for etcd rule provider").CausedBy(err)
+	}
+
+	if len(keyID) != 0 {
+		if entry, err = ks.GetKey(keyID); err != nil {
+			return tls.Certificate{}, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"failed retrieving key from key store for etcd rule provider").CausedBy(err)
+		}
+	} else {
+		entry = ks.Entries()[0]
+	}
+
+	cert, err := keystore.ToTLSCertificate(entry)
+	if err != nil {
+		return tls.Certificate{}, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"key store entry is not suitable for TLS").CausedBy(err)
+	}
+
+	return cert, nil
+}
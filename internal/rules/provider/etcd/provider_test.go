@@ -0,0 +1,452 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package etcd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/rules/signature"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+	mock2 "github.com/dadrus/heimdall/internal/x/testsupport/mock"
+)
+
+func freeURL(t *testing.T) url.URL {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	return url.URL{Scheme: "http", Host: listener.Addr().String()}
+}
+
+func startEtcd(t *testing.T) string {
+	t.Helper()
+
+	clientURL := freeURL(t)
+	peerURL := freeURL(t)
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	cfg.ListenClientUrls = []url.URL{clientURL}
+	cfg.AdvertiseClientUrls = []url.URL{clientURL}
+	cfg.ListenPeerUrls = []url.URL{peerURL}
+	cfg.AdvertisePeerUrls = []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	server, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(server.Close)
+
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("etcd server did not become ready")
+	}
+
+	return clientURL.String()
+}
+
+func TestNewProvider(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	privKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	cert, err := testsupport.NewCertificateBuilder(testsupport.WithValidity(time.Now(), 10*time.Hour),
+		testsupport.WithSerialNumber(big.NewInt(1)),
+		testsupport.WithSubject(pkix.Name{CommonName: "heimdall", Organization: []string{"Test"}}),
+		testsupport.WithSubjectPubKey(&privKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithSelfSigned(),
+		testsupport.WithSignaturePrivKey(privKey)).
+		Build()
+	require.NoError(t, err)
+
+	keyStoreData, err := pemx.BuildPEM(
+		pemx.WithECDSAPrivateKey(privKey, pemx.WithHeader("X-Key-ID", "key1")),
+		pemx.WithX509Certificate(cert),
+	)
+	require.NoError(t, err)
+
+	trustStoreData, err := pemx.BuildPEM(pemx.WithX509Certificate(cert))
+	require.NoError(t, err)
+
+	keyStoreFile := filepath.Join(testDir, "keystore.pem")
+	trustStoreFile := filepath.Join(testDir, "truststore.pem")
+
+	require.NoError(t, os.WriteFile(keyStoreFile, keyStoreData, 0o600))
+	require.NoError(t, os.WriteFile(trustStoreFile, trustStoreData, 0o600))
+
+	for _, tc := range []struct {
+		uc     string
+		conf   []byte
+		assert func(t *testing.T, err error, prov *provider)
+	}{
+		{
+			uc:   "with unknown field",
+			conf: []byte(`foo: bar`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to decode")
+			},
+		},
+		{
+			uc:   "without endpoints",
+			conf: []byte(`prefix: /heimdall/rules`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no endpoints configured")
+			},
+		},
+		{
+			uc: "without prefix",
+			conf: []byte(`
+endpoints:
+  - http://127.0.0.1:2379
+`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no key prefix configured")
+			},
+		},
+		{
+			uc: "with not existing trust store",
+			conf: []byte(`
+endpoints:
+  - https://127.0.0.1:2379
+prefix: /heimdall/rules/
+tls:
+  trust_store: /does/not/exist.pem
+`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed loading trust store")
+			},
+		},
+		{
+			uc: "with unknown key id",
+			conf: []byte(fmt.Sprintf(`
+endpoints:
+  - https://127.0.0.1:2379
+prefix: /heimdall/rules/
+tls:
+  key_store:
+    path: %s
+  key_id: foo
+`, keyStoreFile)),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed retrieving key from key store")
+			},
+		},
+		{
+			uc: "with valid tls configuration",
+			conf: []byte(fmt.Sprintf(`
+endpoints:
+  - https://127.0.0.1:2379
+prefix: /heimdall/rules/
+tls:
+  trust_store: %s
+  key_store:
+    path: %s
+  key_id: key1
+`, trustStoreFile, keyStoreFile)),
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, prov)
+				assert.True(t, prov.configured)
+				require.NotNil(t, prov.cl)
+
+				require.NoError(t, prov.cl.Close())
+			},
+		},
+		{
+			uc: "with valid configuration",
+			conf: []byte(`
+endpoints:
+  - http://127.0.0.1:2379
+  - http://127.0.0.1:22379
+prefix: /heimdall/rules/
+dial_timeout: 2s
+rule_path_match_prefix: /foo
+`),
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, prov)
+				assert.True(t, prov.configured)
+				assert.NotNil(t, prov.cl)
+				assert.NotNil(t, prov.p)
+				assert.Equal(t, "/heimdall/rules/", prov.prefix)
+				assert.Equal(t, "/foo", prov.pathPrefix)
+
+				require.NoError(t, prov.cl.Close())
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			providerConf, err := testsupport.DecodeTestConfig(tc.conf)
+			require.NoError(t, err)
+
+			conf := &config.Configuration{
+				Providers: config.RuleProviders{Etcd: providerConf},
+			}
+
+			// WHEN
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, mocks.NewRuleSetProcessorMock(t), verifier, nil, log.Logger)
+
+			// THEN
+			tc.assert(t, err, prov)
+		})
+	}
+}
+
+func TestProviderLifecycle(t *testing.T) {
+	t.Parallel()
+
+	endpoint := startEtcd(t)
+
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{endpoint}, DialTimeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	ruleSet := func(id string) string {
+		return `
+version: "1alpha2"
+name: test
+rules:
+- id: ` + id + `
+  match: http://foo.bar/<**>
+`
+	}
+
+	put := func(t *testing.T, key, value string) {
+		t.Helper()
+
+		_, err := client.Put(context.Background(), key, value)
+		require.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		uc             string
+		prefix         string
+		setupEtcd      func(t *testing.T, prefix string)
+		setupProcessor func(t *testing.T, processor *mocks.RuleSetProcessorMock)
+		assert         func(t *testing.T, prefix string, logs fmt.Stringer, processor *mocks.RuleSetProcessorMock)
+	}{
+		{
+			uc:     "without rule sets",
+			prefix: "/empty/",
+			assert: func(t *testing.T, _ string, logs fmt.Stringer, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(500 * time.Millisecond)
+
+				messages := logs.String()
+				assert.NotContains(t, messages, "failed")
+				assert.Contains(t, messages, "No rule sets found")
+			},
+		},
+		{
+			uc:     "with existing rule sets",
+			prefix: "/existing/",
+			setupEtcd: func(t *testing.T, prefix string) {
+				t.Helper()
+
+				put(t, prefix+"foo", ruleSet("foo"))
+				put(t, prefix+"empty", "")
+				put(t, "/other/bar", ruleSet("bar"))
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+			},
+			assert: func(t *testing.T, prefix string, _ fmt.Stringer, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(500 * time.Millisecond)
+
+				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Equal(t, "etcd:"+prefix+"foo", ruleSet.Source)
+				assert.NotEmpty(t, ruleSet.Revision)
+				assert.NotEmpty(t, ruleSet.Hash)
+				assert.Len(t, ruleSet.Rules, 1)
+				assert.Equal(t, "foo", ruleSet.Rules[0].ID)
+			},
+		},
+		{
+			uc:     "with rule sets created, updated and deleted while watching",
+			prefix: "/watched/",
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "created").Capture).
+					Return(nil).Once()
+				processor.EXPECT().OnUpdated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "updated").Capture).
+					Return(nil).Once()
+				processor.EXPECT().OnDeleted(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "deleted").Capture).
+					Return(nil).Once()
+			},
+			assert: func(t *testing.T, prefix string, logs fmt.Stringer, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(250 * time.Millisecond)
+
+				put(t, prefix+"foo", ruleSet("foo"))
+				time.Sleep(250 * time.Millisecond)
+
+				// same contents, no update expected
+				put(t, prefix+"foo", ruleSet("foo"))
+				time.Sleep(250 * time.Millisecond)
+
+				put(t, prefix+"foo", ruleSet("bar"))
+				time.Sleep(250 * time.Millisecond)
+
+				// invalid contents are ignored
+				put(t, prefix+"foo", "foo: [")
+				time.Sleep(250 * time.Millisecond)
+
+				_, err := client.Delete(context.Background(), prefix+"foo")
+				require.NoError(t, err)
+				time.Sleep(250 * time.Millisecond)
+
+				assert.Contains(t, logs.String(), "Ignoring invalid rule set")
+
+				created := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "created").Value()
+				assert.Equal(t, "etcd:"+prefix+"foo", created.Source)
+				assert.Equal(t, "foo", created.Rules[0].ID)
+
+				updated := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "updated").Value()
+				assert.Equal(t, "etcd:"+prefix+"foo", updated.Source)
+				assert.Equal(t, "bar", updated.Rules[0].ID)
+				assert.NotEqual(t, created.Revision, updated.Revision)
+
+				deleted := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "deleted").Value()
+				assert.Equal(t, "etcd:"+prefix+"foo", deleted.Source)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			setupEtcd := x.IfThenElse(
+				tc.setupEtcd != nil,
+				tc.setupEtcd,
+				func(t *testing.T, _ string) { t.Helper() },
+			)
+			setupProcessor := x.IfThenElse(
+				tc.setupProcessor != nil,
+				tc.setupProcessor,
+				func(t *testing.T, _ *mocks.RuleSetProcessorMock) { t.Helper() },
+			)
+
+			setupEtcd(t, tc.prefix)
+
+			providerConf, err := testsupport.DecodeTestConfig([]byte(
+				"endpoints:\n- " + endpoint + "\nprefix: " + tc.prefix + "\n"))
+			require.NoError(t, err)
+
+			processor := mocks.NewRuleSetProcessorMock(t)
+			setupProcessor(t, processor)
+
+			conf := &config.Configuration{
+				Providers: config.RuleProviders{Etcd: providerConf},
+			}
+
+			logs := &strings.Builder{}
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, processor, verifier, nil, zerolog.New(logs).Level(zerolog.DebugLevel))
+			require.NoError(t, err)
+
+			ctx := context.Background()
+
+			// WHEN
+			err = prov.Start(ctx)
+
+			defer prov.Stop(ctx) //nolint:errcheck
+
+			// THEN
+			require.NoError(t, err)
+			tc.assert(t, tc.prefix, logs, processor)
+		})
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -34,7 +33,9 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
 	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
@@ -42,13 +43,21 @@ type Provider struct {
 	src            string
 	w              *fsnotify.Watcher
 	p              rule.SetProcessor
+	t              *syncstatus.Tracker
+	v              signature.Verifier
 	l              zerolog.Logger
 	states         sync.Map
 	envVarsEnabled bool
 	configured     bool
 }
 
-func NewProvider(conf *config.Configuration, processor rule.SetProcessor, logger zerolog.Logger) (*Provider, error) {
+func NewProvider(
+	conf *config.Configuration,
+	processor rule.SetProcessor,
+	verifier signature.Verifier,
+	registry *syncstatus.Registry,
+	logger zerolog.Logger,
+) (*Provider, error) {
 	rawConf := conf.Providers.FileSystem
 
 	if conf.Providers.FileSystem == nil {
@@ -101,10 +110,14 @@ func NewProvider(conf *config.Configuration, processor rule.SetProcessor, logger
 	logger = logger.With().Str("_provider_type", "file_system").Logger()
 	logger.Info().Msg("Rule provider configured.")
 
+	tracker := registry.Register("file_system", absPath)
+
 	return &Provider{
 		src:            absPath,
 		w:              watcher,
-		p:              processor,
+		p:              tracker.Processor(processor),
+		t:              tracker,
+		v:              verifier,
 		l:              logger,
 		configured:     true,
 		envVarsEnabled: providerConf.EnvVarsEnabled,
@@ -120,10 +133,13 @@ func (p *Provider) Start(_ context.Context) error {
 
 	if err := p.loadInitialRuleSet(); err != nil {
 		p.l.Error().Err(err).Msg("Failed loading initial rule sets")
+		p.t.Failed(err)
 
 		return err
 	}
 
+	p.t.Synced(p.src)
+
 	if p.w == nil {
 		p.l.Warn().
 			Msg("Watcher for file_system provider is not configured. Updates to rules will have no effects.")
@@ -170,6 +186,9 @@ func (p *Provider) watchFiles() {
 
 			if err := p.ruleSetsChanged(evt); err != nil {
 				p.l.Warn().Err(err).Str("_src", evt.Name).Msg("Failed to apply rule set changes")
+				p.t.Failed(err)
+			} else {
+				p.t.Synced(p.src)
 			}
 		case err, ok := <-p.w.Errors:
 			if !ok {
@@ -179,6 +198,7 @@ func (p *Provider) watchFiles() {
 			}
 
 			p.l.Warn().Err(err).Msg("Watcher error received")
+			p.t.Failed(err)
 		}
 	}
 }
@@ -258,15 +278,20 @@ func (p *Provider) ruleSetDeleted(fileName string) error {
 }
 
 func (p *Provider) loadRuleSet(fileName string) (*config2.RuleSet, error) {
-	file, err := os.Open(fileName)
+	data, err := os.ReadFile(fileName)
 	if err != nil {
 		return nil, errorchain.NewWithMessagef(heimdall.ErrInternal,
-			"failed opening file %s", fileName).CausedBy(err)
+			"failed reading file %s", fileName).CausedBy(err)
 	}
 
-	md := sha256.New()
+	src := fmt.Sprintf("file_system:%s", fileName)
 
-	ruleSet, err := config2.ParseRules("application/yaml", io.TeeReader(file, md), p.envVarsEnabled)
+	contents, err := p.v.Verify(p.l.WithContext(context.Background()), src, data, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet, err := config2.ParseRules("application/yaml", bytes.NewReader(contents), p.envVarsEnabled)
 	if err != nil {
 		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to parse received rule set").
 			CausedBy(err)
@@ -274,8 +299,10 @@ func (p *Provider) loadRuleSet(fileName string) (*config2.RuleSet, error) {
 
 	stat, _ := os.Stat(fileName)
 
-	ruleSet.Hash = md.Sum(nil)
-	ruleSet.Source = fmt.Sprintf("file_system:%s", fileName)
+	hash := sha256.Sum256(data)
+
+	ruleSet.Hash = hash[:]
+	ruleSet.Source = src
 	ruleSet.ModTime = stat.ModTime()
 
 	return ruleSet, nil
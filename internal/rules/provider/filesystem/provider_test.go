@@ -32,6 +32,7 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 	"github.com/dadrus/heimdall/internal/x"
 	mock2 "github.com/dadrus/heimdall/internal/x/testsupport/mock"
 )
@@ -139,7 +140,10 @@ func TestNewProvider(t *testing.T) {
 			// GIVEN
 			conf := &config.Configuration{Providers: config.RuleProviders{FileSystem: tc.conf}}
 
-			prov, err := NewProvider(conf, nil, log.Logger)
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := NewProvider(conf, nil, verifier, nil, log.Logger)
 
 			tc.assert(t, err, prov)
 		})
@@ -481,9 +485,13 @@ rules:
 			}
 
 			// GIVEN
+			verifier, err := signature.NewVerifier(&config.Configuration{}, log.Logger)
+			require.NoError(t, err)
+
 			prov := &Provider{
 				src:        setupContents(t, tmpFile, tmpDir),
 				p:          processor,
+				v:          verifier,
 				l:          log.Logger,
 				w:          watcher,
 				configured: true,
@@ -0,0 +1,35 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+)
+
+// Module is used on app bootstrap.
+// nolint: gochecknoglobals
+var Module = fx.Options(
+	fx.Invoke(
+		fx.Annotate(
+			newProvider,
+			fx.OnStart(func(ctx context.Context, p *provider) error { return p.Start(ctx) }),
+			fx.OnStop(func(ctx context.Context, p *provider) error { return p.Stop(ctx) }),
+		),
+	),
+)
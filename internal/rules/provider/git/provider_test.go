@@ -0,0 +1,300 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/rules/signature"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+	mock2 "github.com/dadrus/heimdall/internal/x/testsupport/mock"
+)
+
+func TestNewProvider(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		conf   []byte
+		assert func(t *testing.T, err error, prov *provider)
+	}{
+		{
+			uc:   "with unknown field",
+			conf: []byte(`foo: bar`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to decode")
+			},
+		},
+		{
+			uc:   "without repositories",
+			conf: []byte(`watch_interval: 5s`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no repositories configured")
+			},
+		},
+		{
+			uc: "without url in one of the configured repositories",
+			conf: []byte(`
+repositories:
+  - url: https://example.com/foo.git
+  - ref: main
+`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "missing url for #1")
+			},
+		},
+		{
+			uc: "with unsupported url scheme",
+			conf: []byte(`
+repositories:
+  - url: git@example.com:foo.git
+`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "bad configuration for #0")
+			},
+		},
+		{
+			uc: "with watch interval and two repositories configured",
+			conf: []byte(`
+watch_interval: 5s
+repositories:
+  - url: https://example.com/foo.git
+  - url: file:///tmp/rules
+    ref: v1.0.0
+    paths:
+      - rules/**.yaml
+    rule_path_match_prefix: /foo
+`),
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, prov)
+				assert.NotNil(t, prov.s)
+				assert.NotNil(t, prov.p)
+				assert.NotNil(t, prov.cancel)
+				assert.True(t, prov.configured)
+				assert.False(t, prov.s.IsRunning())
+				assert.Len(t, prov.s.Jobs(), 2)
+				assert.Len(t, prov.repositories, 2)
+				assert.Equal(t, "v1.0.0", prov.repositories[1].Ref)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			providerConf, err := testsupport.DecodeTestConfig(tc.conf)
+			require.NoError(t, err)
+
+			conf := &config.Configuration{
+				Providers: config.RuleProviders{Git: providerConf},
+			}
+
+			// WHEN
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, mocks.NewRuleSetProcessorMock(t), verifier, nil, log.Logger)
+
+			// THEN
+			tc.assert(t, err, prov)
+		})
+	}
+}
+
+func TestProviderLifecycle(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		uc             string
+		watchInterval  string
+		setupRepo      func(t *testing.T, repo *testRepository)
+		setupProcessor func(t *testing.T, processor *mocks.RuleSetProcessorMock)
+		assert         func(t *testing.T, repo *testRepository, logs fmt.Stringer, processor *mocks.RuleSetProcessorMock)
+	}
+
+	for _, tc := range []testCase{
+		{
+			uc: "without rule sets in the repository",
+			setupRepo: func(t *testing.T, repo *testRepository) {
+				t.Helper()
+
+				repo.commit(map[string]string{"README.md": "# rules"})
+			},
+			assert: func(t *testing.T, _ *testRepository, logs fmt.Stringer, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(1500 * time.Millisecond)
+
+				messages := logs.String()
+				assert.NotContains(t, messages, "Failed")
+				assert.Contains(t, messages, "No updates received")
+			},
+		},
+		{
+			uc: "with a rule set and without watch interval",
+			setupRepo: func(t *testing.T, repo *testRepository) {
+				t.Helper()
+
+				repo.commit(map[string]string{"rules.yaml": ruleSetWithRule("foo")})
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+			},
+			assert: func(t *testing.T, repo *testRepository, _ fmt.Stringer, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(1500 * time.Millisecond)
+
+				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Equal(t, "git:rules.yaml@"+repo.URL(), ruleSet.Source)
+				assert.Equal(t, repo.head(), ruleSet.Revision)
+				assert.Len(t, ruleSet.Rules, 1)
+				assert.Equal(t, "foo", ruleSet.Rules[0].ID)
+			},
+		},
+		{
+			uc:            "with rule sets created, updated and deleted between polls",
+			watchInterval: "250ms",
+			setupRepo: func(t *testing.T, repo *testRepository) {
+				t.Helper()
+
+				repo.commit(map[string]string{
+					"a.yaml": ruleSetWithRule("foo"),
+					"b.yaml": ruleSetWithRule("bar"),
+				})
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "created").Capture).
+					Return(nil).Twice()
+				processor.EXPECT().OnUpdated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "updated").Capture).
+					Return(nil).Once()
+				processor.EXPECT().OnDeleted(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "deleted").Capture).
+					Return(nil).Once()
+			},
+			assert: func(t *testing.T, repo *testRepository, logs fmt.Stringer, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(1500 * time.Millisecond)
+
+				revision := repo.commit(map[string]string{"a.yaml": ruleSetWithRule("baz")}, "b.yaml")
+
+				time.Sleep(1 * time.Second)
+
+				assert.NotContains(t, logs.String(), "Failed")
+
+				created := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "created").Values()
+				assert.Len(t, created, 2)
+
+				updated := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "updated").Value()
+				assert.Equal(t, "git:a.yaml@"+repo.URL(), updated.Source)
+				assert.Equal(t, revision, updated.Revision)
+				assert.Equal(t, "baz", updated.Rules[0].ID)
+
+				deleted := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "deleted").Value()
+				assert.Equal(t, "git:b.yaml@"+repo.URL(), deleted.Source)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			repo := newTestRepository(t)
+
+			setupProcessor := x.IfThenElse(
+				tc.setupProcessor != nil,
+				tc.setupProcessor,
+				func(t *testing.T, _ *mocks.RuleSetProcessorMock) { t.Helper() },
+			)
+
+			tc.setupRepo(t, repo)
+
+			rawConf := "repositories:\n- url: " + repo.URL() + "\n"
+			if len(tc.watchInterval) != 0 {
+				rawConf += "watch_interval: " + tc.watchInterval + "\n"
+			}
+
+			providerConf, err := testsupport.DecodeTestConfig([]byte(rawConf))
+			require.NoError(t, err)
+
+			processor := mocks.NewRuleSetProcessorMock(t)
+			setupProcessor(t, processor)
+
+			conf := &config.Configuration{
+				Providers: config.RuleProviders{Git: providerConf},
+			}
+
+			logs := &strings.Builder{}
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, processor, verifier, nil, zerolog.New(logs))
+			require.NoError(t, err)
+
+			ctx := context.Background()
+
+			// WHEN
+			err = prov.Start(ctx)
+
+			defer prov.Stop(ctx) //nolint:errcheck
+
+			// THEN
+			require.NoError(t, err)
+			tc.assert(t, repo, logs, processor)
+		})
+	}
+}
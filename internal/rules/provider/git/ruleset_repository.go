@@ -0,0 +1,390 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	gitcfg "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/gobwas/glob"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/signature"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+// nolint: gochecknoglobals
+var defaultPaths = []string{"**.yaml", "**.yml"}
+
+// fetchedRef is the local reference the fetched commit is stored under.
+const fetchedRef = "refs/heimdall/fetched"
+
+//nolint:gochecknoinits
+func init() {
+	// file:// repositories are served in-process, so that no git executable is required.
+	client.InstallProtocol("file", server.NewServer(fileLoader{}))
+}
+
+// fileLoader loads local repositories, regardless of whether these are bare or not.
+type fileLoader struct{}
+
+func (fileLoader) Load(ep *transport.Endpoint) (storer.Storer, error) {
+	repo, err := git.PlainOpen(ep.Path)
+	if err != nil {
+		return nil, transport.ErrRepositoryNotFound
+	}
+
+	return repo.Storer, nil
+}
+
+type ruleSetRepository struct {
+	URL             string   `mapstructure:"url"`
+	Ref             string   `mapstructure:"ref"`
+	Paths           []string `mapstructure:"paths"`
+	RulesPathPrefix string   `mapstructure:"rule_path_match_prefix"`
+
+	id    string
+	dir   string
+	repo  *git.Repository
+	globs []glob.Glob
+}
+
+func (r *ruleSetRepository) validate() error {
+	repoURL, err := url.Parse(r.URL)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "failed to parse repository url").
+			CausedBy(err)
+	}
+
+	if repoURL.Scheme != "https" && repoURL.Scheme != "file" {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unsupported '%s' scheme in repository url", repoURL.Scheme)
+	}
+
+	for _, path := range x.IfThenElse(len(r.Paths) != 0, r.Paths, defaultPaths) {
+		pattern, err := glob.Compile(path, '/')
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration, "bad path glob '%s'", path).
+				CausedBy(err)
+		}
+
+		r.globs = append(r.globs, pattern)
+	}
+
+	// the credentials, if present in the url, must not appear in logs and rule set sources
+	r.id = repoURL.Redacted()
+
+	return nil
+}
+
+func (r *ruleSetRepository) ID() string {
+	return x.IfThenElse(len(r.Ref) != 0, fmt.Sprintf("%s#%s", r.id, r.Ref), r.id)
+}
+
+func (r *ruleSetRepository) FetchRuleSets(ctx context.Context) ([]*config.RuleSet, error) {
+	commit, err := r.checkout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := commit.Hash.String()
+	commitTime := commit.Committer.When.UTC()
+
+	var ruleSets []*config.RuleSet
+
+	err = filepath.WalkDir(r.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return x.IfThenElse(entry.Name() == ".git", fs.SkipDir, nil)
+		}
+
+		relPath, _ := filepath.Rel(r.dir, path)
+		relPath = filepath.ToSlash(relPath)
+
+		if !r.matches(relPath) {
+			return nil
+		}
+
+		ruleSet, err := r.readRuleSet(ctx, path, relPath)
+		if err != nil {
+			if errors.Is(err, config.ErrEmptyRuleSet) {
+				return nil
+			}
+
+			return err
+		}
+
+		ruleSet.Revision = revision
+		ruleSet.ModTime = commitTime
+		ruleSets = append(ruleSets, ruleSet)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleSets, nil
+}
+
+func (r *ruleSetRepository) matches(path string) bool {
+	for _, pattern := range r.globs {
+		if pattern.Match(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *ruleSetRepository) readRuleSet(ctx context.Context, path, relPath string) (*config.RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrInternal, "failed reading file %s", relPath).
+			CausedBy(err)
+	}
+
+	// a detached signature, if present, is expected in a file with the same name and the .jws suffix
+	detached, _ := os.ReadFile(path + ".jws")
+	src := fmt.Sprintf("git:%s@%s", relPath, r.ID())
+
+	contents, err := signature.Ctx(ctx).Verify(ctx, src, data, string(bytes.TrimSpace(detached)))
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet, err := config.ParseRules("application/yaml", bytes.NewReader(contents), false)
+	if err != nil {
+		if errors.Is(err, config.ErrEmptyRuleSet) {
+			return nil, err
+		}
+
+		return nil, errorchain.NewWithMessagef(heimdall.ErrInternal, "failed to parse rule set %s", relPath).
+			CausedBy(err)
+	}
+
+	if err = ruleSet.VerifyPathPrefix(r.RulesPathPrefix); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(data)
+
+	ruleSet.Hash = hash[:]
+	ruleSet.Source = src
+
+	return ruleSet, nil
+}
+
+// checkout fetches the configured ref, or the HEAD of the remote repository if no ref is
+// configured, and checks it out. Only the referenced commit is fetched if the transport
+// supports shallow fetches.
+func (r *ruleSetRepository) checkout(ctx context.Context) (*object.Commit, error) {
+	if r.repo == nil {
+		if err := r.initWorkingDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	name, hash, err := r.resolveRef(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.fetch(ctx, fmt.Sprintf("+%s:%s", x.IfThenElse(len(name) != 0, name.String(), hash.String()), fetchedRef))
+	if errors.Is(err, git.ErrExactSHA1NotSupported) {
+		// the remote does not allow fetching single commits, so the referenced commit
+		// can only be fetched together with the history of all refs
+		err = r.fetch(ctx, "+refs/*:refs/heimdall/remote/*")
+	}
+
+	if err != nil {
+		return nil, r.communicationError(ctx, "git fetch", err)
+	}
+
+	commit, err := r.commitFor(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	worktree, err := r.repo.Worktree()
+	if err == nil {
+		err = worktree.Checkout(&git.CheckoutOptions{Hash: commit.Hash, Force: true})
+	}
+
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrInternal, "failed to check out %s", commit.Hash).
+			CausedBy(err)
+	}
+
+	return commit, nil
+}
+
+func (r *ruleSetRepository) fetch(ctx context.Context, refSpec string) error {
+	err := r.repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []gitcfg.RefSpec{gitcfg.RefSpec(refSpec)},
+		// the in-process file transport does not support shallow fetches
+		Depth: x.IfThenElse(strings.HasPrefix(r.URL, "file:"), 0, 1),
+		Tags:  git.NoTags,
+		Force: true,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+
+	return err
+}
+
+// resolveRef resolves the configured ref to the name and the hash it references on the remote.
+// A ref is either a commit hash, a branch, a tag or a full reference name. The returned name
+// is empty if the ref is a commit hash.
+func (r *ruleSetRepository) resolveRef(ctx context.Context) (plumbing.ReferenceName, plumbing.Hash, error) {
+	if plumbing.IsHash(r.Ref) {
+		return "", plumbing.NewHash(r.Ref), nil
+	}
+
+	remote, err := r.repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return "", plumbing.ZeroHash, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to get remote").
+			CausedBy(err)
+	}
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		return "", plumbing.ZeroHash, r.communicationError(ctx, "git ls-remote", err)
+	}
+
+	hashes := make(map[plumbing.ReferenceName]plumbing.Hash, len(refs))
+	for _, ref := range refs {
+		if ref.Type() == plumbing.HashReference {
+			hashes[ref.Name()] = ref.Hash()
+		}
+	}
+
+	candidates := []plumbing.ReferenceName{plumbing.HEAD}
+	if len(r.Ref) != 0 {
+		candidates = []plumbing.ReferenceName{
+			plumbing.ReferenceName(r.Ref), plumbing.NewBranchReferenceName(r.Ref), plumbing.NewTagReferenceName(r.Ref),
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, ref := range refs {
+			if ref.Name() != candidate {
+				continue
+			}
+
+			if ref.Type() == plumbing.SymbolicReference {
+				if hash, ok := hashes[ref.Target()]; ok {
+					return ref.Target(), hash, nil
+				}
+
+				continue
+			}
+
+			return ref.Name(), ref.Hash(), nil
+		}
+	}
+
+	return "", plumbing.ZeroHash, errorchain.NewWithMessagef(heimdall.ErrCommunication,
+		"ref '%s' not found in repository %s", x.IfThenElse(len(r.Ref) != 0, r.Ref, "HEAD"), r.id)
+}
+
+// commitFor returns the commit for the given hash, which references either a commit, or an annotated tag.
+func (r *ruleSetRepository) commitFor(hash plumbing.Hash) (*object.Commit, error) {
+	commit, err := r.repo.CommitObject(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		var tag *object.Tag
+
+		if tag, err = r.repo.TagObject(hash); err == nil {
+			commit, err = tag.Commit()
+		}
+	}
+
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrInternal, "failed to resolve commit %s", hash).
+			CausedBy(err)
+	}
+
+	return commit, nil
+}
+
+func (r *ruleSetRepository) initWorkingDir() error {
+	dir, err := os.MkdirTemp("", "heimdall-git-")
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to create working directory").
+			CausedBy(err)
+	}
+
+	r.dir = dir
+
+	repo, err := git.PlainInit(dir, false)
+	if err == nil {
+		_, err = repo.CreateRemote(&gitcfg.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{r.URL}})
+	}
+
+	if err != nil {
+		_ = r.close()
+		r.dir = ""
+
+		return errorchain.NewWithMessage(heimdall.ErrInternal, "failed to initialize working directory").
+			CausedBy(err)
+	}
+
+	r.repo = repo
+
+	return nil
+}
+
+func (r *ruleSetRepository) communicationError(ctx context.Context, op string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// the credentials, if present in the url, must not leak through the error message
+	return errorchain.NewWithMessagef(heimdall.ErrCommunication, "%s failed: %s", op,
+		strings.ReplaceAll(err.Error(), r.URL, r.id))
+}
+
+func (r *ruleSetRepository) close() error {
+	if len(r.dir) == 0 {
+		return nil
+	}
+
+	return os.RemoveAll(r.dir)
+}
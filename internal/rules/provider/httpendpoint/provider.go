@@ -31,7 +31,9 @@ import (
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
 	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 	"github.com/dadrus/heimdall/internal/validation"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
@@ -39,10 +41,15 @@ import (
 
 type provider struct {
 	p          rule.SetProcessor
+	t          *syncstatus.Tracker
 	l          zerolog.Logger
 	s          *gocron.Scheduler
+	ctx        context.Context //nolint:containedctx
 	cancel     context.CancelFunc
 	states     sync.Map
+	locks      sync.Map
+	notifiers  []*ruleSetEndpoint
+	wg         sync.WaitGroup
 	configured bool
 }
 
@@ -50,6 +57,8 @@ func newProvider(
 	conf *config.Configuration,
 	cch cache.Cache,
 	processor rule.SetProcessor,
+	verifier signature.Verifier,
+	registry *syncstatus.Registry,
 	logger zerolog.Logger,
 ) (*provider, error) {
 	rawConf := conf.Providers.HTTPEndpoint
@@ -75,21 +84,29 @@ func newProvider(
 			"failed validating http_endpoint rule provider config").CausedBy(err)
 	}
 
-	for _, ep := range providerConf.Endpoints {
+	sources := make([]string, len(providerConf.Endpoints))
+
+	for idx, ep := range providerConf.Endpoints {
 		ep.init()
+
+		sources[idx] = ep.ID()
 	}
 
 	logger = logger.With().Str("_provider_type", "http_endpoint").Logger()
 	ctx, cancel := context.WithCancel(context.Background())
-	ctx = logger.WithContext(cache.WithContext(ctx, cch))
+	ctx = logger.WithContext(signature.WithContext(cache.WithContext(ctx, cch), verifier))
 
 	scheduler := gocron.NewScheduler(time.UTC)
 	scheduler.SingletonModeAll()
 
+	tracker := registry.Register("http_endpoint", sources...)
+
 	prov := &provider{
-		p:          processor,
+		p:          tracker.Processor(processor),
+		t:          tracker,
 		l:          logger,
 		s:          scheduler,
+		ctx:        ctx,
 		cancel:     cancel,
 		configured: true,
 	}
@@ -103,6 +120,10 @@ func newProvider(
 				"failed to create a rule provider worker to fetch rules sets from #%d http_endpoint", idx).
 				CausedBy(err)
 		}
+
+		if ep.Notifications != nil {
+			prov.notifiers = append(prov.notifiers, ep)
+		}
 	}
 
 	logger.Info().Msg("Rule provider configured.")
@@ -119,6 +140,16 @@ func (p *provider) Start(_ context.Context) error {
 
 	p.s.StartAsync() //nolint:contextcheck
 
+	for _, ep := range p.notifiers {
+		p.wg.Add(1)
+
+		go func(ep *ruleSetEndpoint) {
+			defer p.wg.Done()
+
+			ep.Notifications.watch(p.ctx, func() { _ = p.watchChanges(p.ctx, uncachedFetcher{ep}) })
+		}(ep)
+	}
+
 	return nil
 }
 
@@ -131,15 +162,26 @@ func (p *provider) Stop(_ context.Context) error {
 
 	p.s.Stop()
 	p.cancel()
+	p.wg.Wait()
 
 	return nil
 }
 
 func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
+	// scheduled runs and pushed notifications must not interleave for the same endpoint
+	lock, _ := p.locks.LoadOrStore(rsf.ID(), &sync.Mutex{})
+	mut := lock.(*sync.Mutex) // nolint: forcetypeassert
+
+	mut.Lock()
+	defer mut.Unlock()
+
 	p.l.Debug().
 		Str("_endpoint", rsf.ID()).
 		Msg("Retrieving rule set")
 
+	// an empty rule set is a valid state and counts as successful synchronization
+	synced := true
+
 	ruleSet, err := rsf.FetchRuleSet(ctx)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
@@ -152,6 +194,12 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 			Str("_endpoint", rsf.ID()).
 			Msg("Failed to fetch rule set")
 
+		if !errors.Is(err, config2.ErrEmptyRuleSet) {
+			synced = false
+
+			p.t.Failed(err)
+		}
+
 		if !errors.Is(err, config2.ErrEmptyRuleSet) &&
 			(errors.Is(err, heimdall.ErrInternal) || errors.Is(err, heimdall.ErrConfiguration)) {
 			return err
@@ -169,6 +217,8 @@ func (p *provider) watchChanges(ctx context.Context, rsf RuleSetFetcher) error {
 		p.l.Warn().Err(err).
 			Str("_src", rsf.ID()).
 			Msg("Failed to apply rule set changes")
+	} else if synced {
+		p.t.Synced(rsf.ID())
 	}
 
 	return nil
@@ -37,6 +37,7 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
 	mock2 "github.com/dadrus/heimdall/internal/x/testsupport/mock"
@@ -154,7 +155,10 @@ endpoints:
 			}
 
 			// WHEN
-			prov, err := newProvider(conf, memory.New(), mocks.NewRuleSetProcessorMock(t), log.Logger)
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, memory.New(), mocks.NewRuleSetProcessorMock(t), verifier, nil, log.Logger)
 
 			// THEN
 			tc.assert(t, err, prov)
@@ -727,7 +731,10 @@ rules:
 			setupProcessor(t, processor)
 
 			logs := &strings.Builder{}
-			prov, err := newProvider(conf, memory.New(), processor, zerolog.New(logs))
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, memory.New(), processor, verifier, nil, zerolog.New(logs))
 			require.NoError(t, err)
 
 			ctx := context.Background()
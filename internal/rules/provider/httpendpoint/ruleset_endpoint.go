@@ -17,6 +17,7 @@
 package httpendpoint
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"errors"
@@ -26,21 +27,38 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/rs/zerolog"
+
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
 )
 
+// signatureHeader is the response header carrying the detached signature of the rule set.
+const signatureHeader = "X-Rule-Set-Signature"
+
 type ruleSetEndpoint struct {
 	endpoint.Endpoint `mapstructure:",squash"`
 
-	RulesPathPrefix string `mapstructure:"rule_path_match_prefix"`
+	RulesPathPrefix string         `mapstructure:"rule_path_match_prefix"`
+	Notifications   *notifications `mapstructure:"notifications"`
+
+	// validators of the last successfully fetched rule set used for conditional requests.
+	// FetchRuleSet is not expected to be called concurrently for the same endpoint.
+	etag         string
+	lastModified string
+	ruleSet      *config.RuleSet
 }
 
 func (e *ruleSetEndpoint) ID() string { return e.URL }
 
 func (e *ruleSetEndpoint) FetchRuleSet(ctx context.Context) (*config.RuleSet, error) {
+	return e.fetchRuleSet(ctx, false)
+}
+
+func (e *ruleSetEndpoint) fetchRuleSet(ctx context.Context, bypassCache bool) (*config.RuleSet, error) {
 	req, err := e.CreateRequest(ctx, nil, nil)
 	if err != nil {
 		return nil, errorchain.
@@ -48,6 +66,20 @@ func (e *ruleSetEndpoint) FetchRuleSet(ctx context.Context) (*config.RuleSet, er
 			CausedBy(err)
 	}
 
+	if bypassCache {
+		req.Header.Set("Cache-Control", "no-cache")
+	}
+
+	if e.ruleSet != nil {
+		if len(e.etag) != 0 {
+			req.Header.Set("If-None-Match", e.etag)
+		}
+
+		if len(e.lastModified) != 0 {
+			req.Header.Set("If-Modified-Since", e.lastModified)
+		}
+	}
+
 	client := e.CreateClient(req.URL.Hostname())
 
 	resp, err := client.Do(req)
@@ -66,14 +98,34 @@ func (e *ruleSetEndpoint) FetchRuleSet(ctx context.Context) (*config.RuleSet, er
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && e.ruleSet != nil {
+		zerolog.Ctx(ctx).Debug().Str("_endpoint", e.ID()).Msg("Rule set not modified")
+
+		return e.ruleSet, nil
+	}
+
+	e.forget()
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, errorchain.NewWithMessagef(heimdall.ErrCommunication,
 			"unexpected response code: %v", resp.StatusCode)
 	}
 
-	md := sha256.New()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrCommunication, "failed to read received rule set").
+			CausedBy(err)
+	}
+
+	src := fmt.Sprintf("http_endpoint:%s", e.ID())
 
-	ruleSet, err := config.ParseRules(resp.Header.Get("Content-Type"), io.TeeReader(resp.Body, md), false)
+	contents, err := signature.Ctx(ctx).Verify(ctx, src, data, resp.Header.Get(signatureHeader))
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet, err := config.ParseRules(
+		signature.ContentType(resp.Header.Get("Content-Type")), bytes.NewReader(contents), false)
 	if err != nil {
 		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to parse received rule set").
 			CausedBy(err)
@@ -83,13 +135,30 @@ func (e *ruleSetEndpoint) FetchRuleSet(ctx context.Context) (*config.RuleSet, er
 		return nil, err
 	}
 
-	ruleSet.Hash = md.Sum(nil)
-	ruleSet.Source = fmt.Sprintf("http_endpoint:%s", e.ID())
+	hash := sha256.Sum256(data)
+
+	ruleSet.Hash = hash[:]
+	ruleSet.Source = src
 	ruleSet.ModTime = time.Now()
 
+	lastModified := resp.Header.Get("Last-Modified")
+	if modTime, err := http.ParseTime(lastModified); err == nil {
+		ruleSet.ModTime = modTime
+	}
+
+	e.etag = resp.Header.Get("ETag")
+	e.lastModified = lastModified
+	e.ruleSet = ruleSet
+
 	return ruleSet, nil
 }
 
+func (e *ruleSetEndpoint) forget() {
+	e.etag = ""
+	e.lastModified = ""
+	e.ruleSet = nil
+}
+
 func (e *ruleSetEndpoint) init() {
 	e.Method = http.MethodGet
 
@@ -97,4 +166,19 @@ func (e *ruleSetEndpoint) init() {
 		cacheEnabled := true
 		e.HTTPCacheEnabled = &cacheEnabled
 	}
+
+	if e.Notifications != nil {
+		e.Notifications.init(e.Endpoint)
+	}
+}
+
+// uncachedFetcher fetches the rule set of the wrapped endpoint bypassing the http cache.
+// It is used for fetches triggered by change notifications, which would otherwise be
+// answered with a stale cached rule set.
+type uncachedFetcher struct {
+	*ruleSetEndpoint
+}
+
+func (f uncachedFetcher) FetchRuleSet(ctx context.Context) (*config.RuleSet, error) {
+	return f.fetchRuleSet(ctx, true)
 }
@@ -18,9 +18,11 @@ package httpendpoint
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
@@ -29,6 +31,7 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 
 	"github.com/dadrus/heimdall/internal/cache"
+	"github.com/dadrus/heimdall/internal/cache/memory"
 	"github.com/dadrus/heimdall/internal/cache/mocks"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
@@ -346,3 +349,99 @@ rules:
 		})
 	}
 }
+
+func TestRuleSetEndpointFetchRuleSetUsingConditionalRequests(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	lastModified := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	requests := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests > 1 {
+			assert.Equal(t, `"v1"`, r.Header.Get("If-None-Match"))
+			assert.Equal(t, lastModified.Format(http.TimeFormat), r.Header.Get("If-Modified-Since"))
+
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		assert.Empty(t, r.Header.Get("If-None-Match"))
+		assert.Empty(t, r.Header.Get("If-Modified-Since"))
+
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		_, err := w.Write([]byte(`
+version: "1"
+name: test
+rules:
+- id: foo
+`))
+		require.NoError(t, err)
+	}))
+
+	defer srv.Close()
+
+	ep := &ruleSetEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL, Method: http.MethodGet}}
+	ctx := context.Background()
+
+	// WHEN
+	first, err1 := ep.FetchRuleSet(ctx)
+	second, err2 := ep.FetchRuleSet(ctx)
+
+	// THEN
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, lastModified, first.ModTime)
+	assert.Equal(t, first, second)
+}
+
+func TestUncachedFetcherFetchRuleSet(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	version := 1
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Expires", time.Now().Add(1*time.Minute).UTC().Format(http.TimeFormat))
+		_, err := fmt.Fprintf(w, `
+version: "1"
+name: test-%d
+rules:
+- id: foo
+`, version)
+		require.NoError(t, err)
+	}))
+
+	defer srv.Close()
+
+	ep := &ruleSetEndpoint{Endpoint: endpoint.Endpoint{URL: srv.URL}}
+	ep.init()
+
+	ctx := cache.WithContext(context.Background(), memory.New())
+
+	// WHEN
+	initial, err1 := ep.FetchRuleSet(ctx)
+
+	version = 2
+
+	cached, err2 := ep.FetchRuleSet(ctx)
+	refreshed, err3 := uncachedFetcher{ep}.FetchRuleSet(ctx)
+	afterRefresh, err4 := ep.FetchRuleSet(ctx)
+
+	// THEN
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	require.NoError(t, err3)
+	require.NoError(t, err4)
+	assert.Equal(t, "test-1", initial.Name)
+	assert.Equal(t, "test-1", cached.Name)
+	assert.Equal(t, "test-2", refreshed.Name)
+	assert.Equal(t, "test-2", afterRefresh.Name)
+}
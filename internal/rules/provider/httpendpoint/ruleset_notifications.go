@@ -0,0 +1,229 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpendpoint
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	notificationModeSSE      = "sse"
+	notificationModeLongPoll = "long_poll"
+
+	defaultReconnectDelay = 5 * time.Second
+	defaultMinPollDelay   = 1 * time.Second
+)
+
+var errNotificationStreamClosed = errors.New("notification stream closed")
+
+// notifications configures a channel, the rule server can use to push change notifications
+// to heimdall. Each received notification results in an immediate fetch of the rule set.
+type notifications struct {
+	Mode           string        `mapstructure:"mode"            validate:"required,oneof=sse long_poll"`
+	URL            string        `mapstructure:"url"             validate:"required,url"`
+	ReconnectDelay time.Duration `mapstructure:"reconnect_delay"`
+
+	ep endpoint.Endpoint
+	// minPollDelay is the time to wait between two regularly completed long poll requests to
+	// avoid a hot loop if the server responds immediately.
+	minPollDelay time.Duration
+}
+
+func (n *notifications) init(parent endpoint.Endpoint) {
+	if n.ReconnectDelay <= 0 {
+		n.ReconnectDelay = defaultReconnectDelay
+	}
+
+	n.minPollDelay = defaultMinPollDelay
+
+	// the notification endpoint shares authentication, retry and header settings with
+	// the rule set endpoint, but responses must never be cached.
+	n.ep = endpoint.Endpoint{
+		URL:          n.URL,
+		Method:       http.MethodGet,
+		Retry:        parent.Retry,
+		AuthStrategy: parent.AuthStrategy,
+		Headers:      parent.Headers,
+	}
+}
+
+// watch listens for change notifications until the given context is canceled and calls
+// onChange for each received notification. Broken connections are reestablished after
+// the configured reconnect delay.
+func (n *notifications) watch(ctx context.Context, onChange func()) {
+	logger := zerolog.Ctx(ctx)
+
+	listen := n.listenSSE
+	if n.Mode == notificationModeLongPoll {
+		listen = n.pollOnce
+	}
+
+	// a broken connection may have lost notifications. So, the rule set is refetched
+	// as soon as the connection is reestablished.
+	resync := false
+
+	for {
+		err := listen(ctx, func() {
+			if resync {
+				resync = false
+
+				onChange()
+			}
+		}, onChange)
+		if ctx.Err() != nil {
+			logger.Debug().Str("_endpoint", n.URL).Msg("Notification listener closed")
+
+			return
+		}
+
+		delay := n.ReconnectDelay
+
+		if err != nil {
+			logger.Warn().Err(err).Str("_endpoint", n.URL).Msg("Failed receiving change notifications")
+
+			resync = true
+		} else {
+			// a long poll request completed regularly
+			resync = false
+			delay = n.minPollDelay
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (n *notifications) listenSSE(ctx context.Context, onConnected, onChange func()) error {
+	resp, err := n.send(ctx, "text/event-stream")
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"unexpected response code: %v", resp.StatusCode)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"unexpected content type: %s", resp.Header.Get("Content-Type"))
+	}
+
+	onConnected()
+
+	scanner := bufio.NewScanner(resp.Body)
+	pending := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if len(line) == 0 {
+			// an empty line dispatches the event
+			if pending {
+				onChange()
+			}
+
+			pending = false
+
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "": // comment, typically used as keep alive
+		case "data", "event":
+			pending = true
+		case "retry":
+			if millis, err := strconv.ParseUint(value, 10, 32); err == nil {
+				n.ReconnectDelay = time.Duration(millis) * time.Millisecond
+			}
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrCommunication,
+			"failed reading notification stream").CausedBy(err)
+	}
+
+	return errNotificationStreamClosed
+}
+
+func (n *notifications) pollOnce(ctx context.Context, onConnected, onChange func()) error {
+	resp, err := n.send(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		onChange()
+
+		return nil
+	case http.StatusNoContent, http.StatusNotModified:
+		onConnected()
+
+		return nil
+	default:
+		return errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"unexpected response code: %v", resp.StatusCode)
+	}
+}
+
+func (n *notifications) send(ctx context.Context, accept string) (*http.Response, error) {
+	req, err := n.ep.CreateRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accept) != 0 {
+		req.Header.Set("Accept", accept)
+		req.Header.Set("Cache-Control", "no-cache")
+	}
+
+	resp, err := n.ep.CreateClient(req.URL.Hostname()).Do(req)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrCommunication,
+			"request to the notification endpoint failed").CausedBy(err)
+	}
+
+	return resp, nil
+}
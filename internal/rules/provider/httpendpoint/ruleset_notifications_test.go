@@ -0,0 +1,152 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpendpoint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+)
+
+func TestNotificationsWatch(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc      string
+		mode    string
+		handler func(t *testing.T, call int, w http.ResponseWriter, r *http.Request)
+		changes int
+	}{
+		{
+			uc:   "sse events trigger a change and a reconnect triggers a resync",
+			mode: notificationModeSSE,
+			handler: func(t *testing.T, call int, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+
+				assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+				assert.Equal(t, "bar", r.Header.Get("X-Foo"))
+
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.WriteHeader(http.StatusOK)
+
+				if call == 1 {
+					_, err := w.Write([]byte(": keep alive\n\nretry: 10\n\nevent: changed\ndata: {}\n\n"))
+					require.NoError(t, err)
+
+					// closing the stream
+					return
+				}
+
+				w.(http.Flusher).Flush()
+
+				<-r.Context().Done()
+			},
+			// one event and one resync after reconnect
+			changes: 2,
+		},
+		{
+			uc:   "sse endpoint responding with unexpected content type",
+			mode: notificationModeSSE,
+			handler: func(t *testing.T, call int, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+
+				if call == 1 {
+					w.Header().Set("Content-Type", "text/plain")
+					w.WriteHeader(http.StatusOK)
+
+					return
+				}
+
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+
+				<-r.Context().Done()
+			},
+			// resync after the failure
+			changes: 1,
+		},
+		{
+			uc:   "long poll",
+			mode: notificationModeLongPoll,
+			handler: func(t *testing.T, call int, w http.ResponseWriter, r *http.Request) {
+				t.Helper()
+
+				assert.Equal(t, http.MethodGet, r.Method)
+
+				switch call {
+				case 1:
+					w.WriteHeader(http.StatusNoContent)
+				case 2:
+					w.WriteHeader(http.StatusOK)
+				case 3:
+					w.WriteHeader(http.StatusBadGateway)
+				case 4:
+					w.WriteHeader(http.StatusNotModified)
+				default:
+					<-r.Context().Done()
+				}
+			},
+			// one notification and one resync after the failure
+			changes: 2,
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			var (
+				calls   atomic.Int32
+				changes atomic.Int32
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tc.handler(t, int(calls.Add(1)), w, r)
+			}))
+			defer srv.Close()
+
+			ntf := &notifications{Mode: tc.mode, URL: srv.URL, ReconnectDelay: 10 * time.Millisecond}
+			ntf.init(endpoint.Endpoint{URL: "http://foo.bar", Headers: map[string]string{"X-Foo": "bar"}})
+			ntf.minPollDelay = 10 * time.Millisecond
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+
+			// WHEN
+			go func() {
+				defer close(done)
+
+				ntf.watch(ctx, func() { changes.Add(1) })
+			}()
+
+			// THEN
+			assert.Eventually(t, func() bool { return int(changes.Load()) == tc.changes },
+				2*time.Second, 10*time.Millisecond)
+
+			cancel()
+			<-done
+
+			assert.Equal(t, tc.changes, int(changes.Load()))
+		})
+	}
+}
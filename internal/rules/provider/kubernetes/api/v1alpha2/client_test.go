@@ -29,6 +29,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
 )
 
 const watchResponse = `{
@@ -136,12 +138,12 @@ func verifyRuleSetList(t *testing.T, rls *RuleSetList) {
 	assert.Equal(t, "http://127.0.0.1:9090/foobar/<{foos*}>", rule.RuleMatcher.URL)
 	assert.Empty(t, rule.Methods)
 	assert.Empty(t, rule.ErrorHandler)
-	assert.Equal(t, "https://foo.bar/baz/bar?foo=bar", rule.Backend.CreateURL(&url.URL{
+	assert.Equal(t, "https://foo.bar/baz/bar?foo=bar", rule.Backend.CreateURL(&heimdall.URL{URL: url.URL{
 		Scheme:   "http",
 		Host:     "bar.foo:8888",
 		Path:     "/foo/bar",
 		RawQuery: url.Values{"boo": []string{"foo"}, "foo": []string{"bar"}}.Encode(),
-	}).String())
+	}}).String())
 	assert.Len(t, rule.Execute, 2)
 	assert.Equal(t, "test_authn", rule.Execute[0]["authenticator"])
 	assert.Equal(t, "test_authz", rule.Execute[1]["authorizer"])
@@ -29,6 +29,7 @@ type ConditionReason string
 const (
 	ConditionRuleSetActive           ConditionReason = "RuleSetActive"
 	ConditionRuleSetActivationFailed ConditionReason = "RuleSetActivationFailed"
+	ConditionRuleSetRulesShadowed    ConditionReason = "RuleSetRulesShadowed"
 	ConditionRuleSetUnloaded         ConditionReason = "RuleSetUnloaded"
 	ConditionRuleSetUnloadingFailed  ConditionReason = "RuleSetUnloadingFailed"
 	ConditionControllerStopped       ConditionReason = "ControllerStopped"
@@ -0,0 +1,205 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+func (p *provider) newConfigMapController(ctx context.Context) (cache.Store, cache.Controller) {
+	repository := p.k8s.CoreV1().ConfigMaps("")
+	selector := p.cms.String()
+
+	return cache.NewInformer(
+		&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				opts.LabelSelector = selector
+
+				return repository.List(ctx, opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				opts.LabelSelector = selector
+
+				return repository.Watch(ctx, opts)
+			},
+		},
+		&corev1.ConfigMap{},
+		0,
+		cache.FilteringResourceEventHandler{
+			FilterFunc: p.filterConfigMap,
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc:    p.addConfigMap,
+				DeleteFunc: p.deleteConfigMap,
+				UpdateFunc: p.updateConfigMap,
+			},
+		},
+	)
+}
+
+func (p *provider) filterConfigMap(obj any) bool {
+	cm, ok := toConfigMap(obj)
+	if !ok {
+		return false
+	}
+
+	authClass := cm.Annotations[AuthClassAnnotation]
+
+	return p.cms.Matches(labels.Set(cm.Labels)) &&
+		x.IfThenElse(len(authClass) != 0, authClass, DefaultClass) == p.ac
+}
+
+func (p *provider) addConfigMap(obj any) {
+	if p.stopped {
+		return
+	}
+
+	cm, _ := toConfigMap(obj)
+
+	p.l.Info().Str("_config_map", cm.Namespace+"/"+cm.Name).Msg("New rule set ConfigMap received")
+
+	for _, key := range sortedKeys(cm.Data) {
+		p.configMapRuleSetChanged(cm, key)
+	}
+}
+
+func (p *provider) updateConfigMap(oldObj, newObj any) {
+	if p.stopped {
+		return
+	}
+
+	oldCM, _ := toConfigMap(oldObj)
+	newCM, _ := toConfigMap(newObj)
+
+	if oldCM.ResourceVersion == newCM.ResourceVersion {
+		// periodic resync. Nothing has changed
+		return
+	}
+
+	p.l.Info().Str("_config_map", newCM.Namespace+"/"+newCM.Name).Msg("Rule set ConfigMap update received")
+
+	for _, key := range sortedKeys(newCM.Data) {
+		p.configMapRuleSetChanged(newCM, key)
+	}
+
+	for _, key := range sortedKeys(oldCM.Data) {
+		if _, present := newCM.Data[key]; !present {
+			p.configMapRuleSetDeleted(newCM, key)
+		}
+	}
+}
+
+func (p *provider) deleteConfigMap(obj any) {
+	if p.stopped {
+		return
+	}
+
+	cm, _ := toConfigMap(obj)
+
+	p.l.Info().Str("_config_map", cm.Namespace+"/"+cm.Name).Msg("Rule set ConfigMap deletion received")
+
+	for _, key := range sortedKeys(cm.Data) {
+		p.configMapRuleSetDeleted(cm, key)
+	}
+}
+
+func (p *provider) configMapRuleSetChanged(cm *corev1.ConfigMap, key string) {
+	origin := "key " + key
+
+	ruleSet, err := p.toConfigMapRuleSet(cm, key)
+	if err != nil {
+		if errors.Is(err, config2.ErrEmptyRuleSet) {
+			p.unloadRuleSet(cm, p.cmStates, configMapRuleSetSource(cm, key), origin)
+
+			return
+		}
+
+		p.l.Warn().Err(err).Str("_src", configMapRuleSetSource(cm, key)).Msg("Ignoring invalid rule set")
+		p.reportLoadingFailure(cm, origin, err)
+
+		return
+	}
+
+	p.loadRuleSet(cm, p.cmStates, ruleSet, origin)
+}
+
+func (p *provider) configMapRuleSetDeleted(cm *corev1.ConfigMap, key string) {
+	p.unloadRuleSet(cm, p.cmStates, configMapRuleSetSource(cm, key), "key "+key)
+}
+
+func (p *provider) toConfigMapRuleSet(cm *corev1.ConfigMap, key string) (*config2.RuleSet, error) {
+	data := cm.Data[key]
+
+	ruleSet, err := config2.ParseRules("application/yaml", strings.NewReader(data), false)
+	if err != nil {
+		if errors.Is(err, config2.ErrEmptyRuleSet) {
+			return nil, err
+		}
+
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to parse rule set").CausedBy(err)
+	}
+
+	hash := sha256.Sum256([]byte(data))
+
+	ruleSet.Hash = hash[:]
+	ruleSet.Source = configMapRuleSetSource(cm, key)
+	ruleSet.ModTime = cm.CreationTimestamp.Time
+	ruleSet.Revision = cm.ResourceVersion
+	ruleSet.Name = x.IfThenElse(len(ruleSet.Name) != 0, ruleSet.Name, cm.Name+"/"+key)
+
+	return ruleSet, nil
+}
+
+func configMapRuleSetSource(cm *corev1.ConfigMap, key string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", ProviderType, cm.Namespace, cm.UID, key)
+}
+
+func toConfigMap(obj any) (*corev1.ConfigMap, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	cm, ok := obj.(*corev1.ConfigMap)
+
+	return cm, ok
+}
+
+func sortedKeys(data map[string]string) []string {
+	keys := maps.Keys(data)
+
+	slices.Sort(keys)
+
+	return keys
+}
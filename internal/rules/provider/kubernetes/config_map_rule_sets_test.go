@@ -0,0 +1,405 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+	mock2 "github.com/dadrus/heimdall/internal/x/testsupport/mock"
+)
+
+const configMapRuleSet = `
+version: "1alpha2"
+name: test-rules
+rules:
+- id: test
+  match: http://foo.bar/<**>
+  forward_to:
+    host: baz
+  execute:
+  - authenticator: authn
+`
+
+const otherConfigMapRuleSet = `
+version: "1alpha2"
+rules:
+- id: other
+  match: http://bar.foo/<**>
+  execute:
+  - authenticator: authn
+`
+
+func newRuleSetConfigMap(name string, data map[string]string, annotations map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "foo",
+			UID:         "dfb2a2f1-1ad2-4d8c-8456-516fc94abb86",
+			Labels:      map[string]string{DefaultConfigMapSelector: "true"},
+			Annotations: annotations,
+		},
+		Data: data,
+	}
+}
+
+func TestConfigMapRuleSetsLifecycle(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc             string
+		authClass      string
+		steps          func(t *testing.T, ctx context.Context, cl *fake.Clientset)
+		setupProcessor func(t *testing.T, processor *mocks.RuleSetProcessorMock)
+		assert         func(t *testing.T, events []string, processor *mocks.RuleSetProcessorMock)
+	}{
+		{
+			uc:        "config map with rule set added",
+			authClass: DefaultClass,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx,
+					newRuleSetConfigMap("test", map[string]string{"rules.yaml": configMapRuleSet}, nil),
+					metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Once()
+			},
+			assert: func(t *testing.T, events []string, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86:rules.yaml", ruleSet.Source)
+				assert.Equal(t, "1alpha2", ruleSet.Version)
+				assert.Equal(t, "test-rules", ruleSet.Name)
+				assert.NotEmpty(t, ruleSet.Hash)
+				require.Len(t, ruleSet.Rules, 1)
+				assert.Equal(t, "test", ruleSet.Rules[0].ID)
+
+				require.Len(t, events, 1)
+				assert.Contains(t, events[0], "Normal RuleSetActive")
+				assert.Contains(t, events[0], "successfully loaded RuleSet from key rules.yaml")
+			},
+		},
+		{
+			uc:        "config map with rule set without name added",
+			authClass: "bar",
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx,
+					newRuleSetConfigMap("test",
+						map[string]string{"rules.yaml": otherConfigMapRuleSet},
+						map[string]string{AuthClassAnnotation: "bar"}),
+					metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Once()
+			},
+			assert: func(t *testing.T, events []string, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Equal(t, "test/rules.yaml", ruleSet.Name)
+
+				require.Len(t, events, 1)
+				assert.Contains(t, events[0], "Normal RuleSetActive")
+			},
+		},
+		{
+			uc:        "config map with mismatching auth class is ignored",
+			authClass: DefaultClass,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx,
+					newRuleSetConfigMap("test",
+						map[string]string{"rules.yaml": configMapRuleSet},
+						map[string]string{AuthClassAnnotation: "bar"}),
+					metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, events []string, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				assert.Empty(t, events)
+			},
+		},
+		{
+			uc:        "config map without the required label is ignored",
+			authClass: DefaultClass,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				cm := newRuleSetConfigMap("test", map[string]string{"rules.yaml": configMapRuleSet}, nil)
+				cm.Labels = nil
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx, cm, metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, events []string, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				assert.Empty(t, events)
+			},
+		},
+		{
+			uc:        "config map with invalid rule set added",
+			authClass: DefaultClass,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx,
+					newRuleSetConfigMap("test", map[string]string{"rules.yaml": "foo: bar"}, nil),
+					metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, events []string, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				require.Len(t, events, 1)
+				assert.Contains(t, events[0], "Warning RuleSetActivationFailed")
+				assert.Contains(t, events[0], "failed loading rule set from key rules.yaml")
+			},
+		},
+		{
+			uc:        "adding rule set from config map fails",
+			authClass: DefaultClass,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx,
+					newRuleSetConfigMap("test", map[string]string{"rules.yaml": configMapRuleSet}, nil),
+					metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).Return(testsupport.ErrTestPurpose).Once()
+			},
+			assert: func(t *testing.T, events []string, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				require.Len(t, events, 1)
+				assert.Contains(t, events[0], "Warning RuleSetActivationFailed")
+				assert.Contains(t, events[0], testsupport.ErrTestPurpose.Error())
+			},
+		},
+		{
+			uc:        "config map is added and then updated",
+			authClass: DefaultClass,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				cm := newRuleSetConfigMap("test", map[string]string{
+					"a.yaml": configMapRuleSet,
+					"b.yaml": otherConfigMapRuleSet,
+					"c.yaml": otherConfigMapRuleSet,
+				}, nil)
+				cm.ResourceVersion = "1"
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx, cm, metav1.CreateOptions{})
+				require.NoError(t, err)
+
+				time.Sleep(100 * time.Millisecond)
+
+				cm = cm.DeepCopy()
+				cm.ResourceVersion = "2"
+				cm.Data = map[string]string{
+					"a.yaml": configMapRuleSet,
+					"b.yaml": configMapRuleSet,
+					"d.yaml": otherConfigMapRuleSet,
+				}
+
+				_, err = cl.CoreV1().ConfigMaps("foo").Update(ctx, cm, metav1.UpdateOptions{})
+				require.NoError(t, err)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).Return(nil).Times(4)
+				processor.EXPECT().OnUpdated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+				processor.EXPECT().OnDeleted(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor2").Capture).
+					Return(nil).Once()
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Times(5)
+			},
+			assert: func(t *testing.T, events []string, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				updated := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86:b.yaml", updated.Source)
+				assert.Equal(t, "test-rules", updated.Name)
+
+				deleted := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor2").Value()
+				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86:c.yaml", deleted.Source)
+
+				require.Len(t, events, 6)
+				assert.Contains(t, events[3], "Normal RuleSetActive")
+				assert.Contains(t, events[3], "successfully reloaded RuleSet from key b.yaml")
+				assert.Contains(t, events[4], "Normal RuleSetActive")
+				assert.Contains(t, events[4], "successfully loaded RuleSet from key d.yaml")
+				assert.Contains(t, events[5], "Normal RuleSetUnloaded")
+				assert.Contains(t, events[5], "dropped rule set from key c.yaml")
+			},
+		},
+		{
+			uc:        "config map is added and then removed",
+			authClass: DefaultClass,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx,
+					newRuleSetConfigMap("test", map[string]string{"rules.yaml": configMapRuleSet}, nil),
+					metav1.CreateOptions{})
+				require.NoError(t, err)
+
+				time.Sleep(100 * time.Millisecond)
+
+				err = cl.CoreV1().ConfigMaps("foo").Delete(ctx, "test", metav1.DeleteOptions{})
+				require.NoError(t, err)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).Return(nil).Once()
+				processor.EXPECT().OnDeleted(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Once()
+			},
+			assert: func(t *testing.T, events []string, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				deleted := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Equal(t, "kubernetes:foo:dfb2a2f1-1ad2-4d8c-8456-516fc94abb86:rules.yaml", deleted.Source)
+
+				require.Len(t, events, 2)
+				assert.Contains(t, events[1], "Normal RuleSetUnloaded")
+			},
+		},
+		{
+			uc:        "removing rule set from config map fails",
+			authClass: DefaultClass,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset) {
+				t.Helper()
+
+				_, err := cl.CoreV1().ConfigMaps("foo").Create(ctx,
+					newRuleSetConfigMap("test", map[string]string{"rules.yaml": configMapRuleSet}, nil),
+					metav1.CreateOptions{})
+				require.NoError(t, err)
+
+				time.Sleep(100 * time.Millisecond)
+
+				err = cl.CoreV1().ConfigMaps("foo").Delete(ctx, "test", metav1.DeleteOptions{})
+				require.NoError(t, err)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).Return(nil).Once()
+				processor.EXPECT().OnDeleted(mock.Anything).Return(testsupport.ErrTestPurpose).Once()
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Once()
+			},
+			assert: func(t *testing.T, events []string, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				require.Len(t, events, 2)
+				assert.Contains(t, events[1], "Warning RuleSetUnloadingFailed")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := fake.NewSimpleClientset()
+			recorder := record.NewFakeRecorder(10)
+
+			selector, err := labels.Parse(DefaultConfigMapSelector)
+			require.NoError(t, err)
+
+			processor := mocks.NewRuleSetProcessorMock(t)
+			if tc.setupProcessor != nil {
+				tc.setupProcessor(t, processor)
+			}
+
+			prov := &provider{
+				p:        processor,
+				l:        log.Logger,
+				ac:       tc.authClass,
+				id:       "test",
+				k8s:      client,
+				cms:      selector,
+				er:       recorder,
+				cmStates: make(map[string][]byte),
+			}
+
+			_, controller := prov.newConfigMapController(ctx)
+
+			go controller.Run(ctx.Done())
+
+			require.Eventually(t, controller.HasSynced, 1*time.Second, 10*time.Millisecond)
+
+			// WHEN
+			tc.steps(t, ctx, client)
+
+			time.Sleep(250 * time.Millisecond)
+			cancel()
+
+			// THEN
+			var events []string
+
+			for len(recorder.Events) != 0 {
+				events = append(events, <-recorder.Events)
+			}
+
+			tc.assert(t, events, processor)
+		})
+	}
+}
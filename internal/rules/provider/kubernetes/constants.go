@@ -19,4 +19,14 @@ package kubernetes
 const (
 	DefaultClass = "default"
 	ProviderType = "kubernetes"
+
+	// DefaultConfigMapSelector is the label selector used to select ConfigMaps with rule sets if
+	// no other selector is configured.
+	DefaultConfigMapSelector = "heimdall.dadrus.github.com/rule-set"
+	// AuthClassAnnotation is the annotation of a ConfigMap defining the heimdall setup, which
+	// should use the rule sets from that ConfigMap.
+	AuthClassAnnotation = "heimdall.dadrus.github.com/authClassName"
+	// RuleTemplateAnnotation is the annotation of an Ingress or an HTTPRoute referencing the rule
+	// template, the rules for the routes defined by that resource should be generated from.
+	RuleTemplateAnnotation = "heimdall.dadrus.github.com/rule-template"
 )
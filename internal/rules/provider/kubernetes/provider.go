@@ -17,6 +17,7 @@
 package kubernetes
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -30,14 +31,21 @@ import (
 
 	"github.com/go-logr/zerologr"
 	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
 	errors2 "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
 	"github.com/dadrus/heimdall/internal/config"
@@ -45,6 +53,7 @@ import (
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/provider/kubernetes/admissioncontroller"
 	"github.com/dadrus/heimdall/internal/rules/provider/kubernetes/api/v1alpha2"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
@@ -55,6 +64,7 @@ type ConfigFactory func() (*rest.Config, error)
 
 type provider struct {
 	p          rule.SetProcessor
+	t          *syncstatus.Tracker
 	l          zerolog.Logger
 	cl         v1alpha2.Client
 	adc        admissioncontroller.AdmissionController
@@ -65,6 +75,17 @@ type provider struct {
 	ac         string
 	id         string
 	store      cache.Store
+
+	// used only if rule sets should be loaded from ConfigMaps, or be
+	// generated from annotated route resources as well
+	k8s       kubernetes.Interface
+	eb        record.EventBroadcaster
+	er        record.EventRecorder
+	cms       labels.Selector
+	cmStates  map[string][]byte
+	dyn       dynamic.Interface
+	routes    []string
+	templates map[string]*ruleTemplate
 }
 
 func newProvider(
@@ -73,6 +94,7 @@ func newProvider(
 	k8sCF ConfigFactory,
 	processor rule.SetProcessor,
 	factory rule.Factory,
+	registry *syncstatus.Registry,
 ) (*provider, error) {
 	rawConf := conf.Providers.Kubernetes
 
@@ -86,9 +108,20 @@ func newProvider(
 			"failed to create kubernetes provider").CausedBy(err)
 	}
 
+	type ConfigMaps struct {
+		Selector string `mapstructure:"selector"`
+	}
+
+	type RouteRules struct {
+		Resources []string       `mapstructure:"resources"`
+		Templates map[string]any `mapstructure:"templates"`
+	}
+
 	type Config struct {
-		AuthClass string      `mapstructure:"auth_class"`
-		TLS       *config.TLS `mapstructure:"tls"`
+		AuthClass  string      `mapstructure:"auth_class"`
+		TLS        *config.TLS `mapstructure:"tls"`
+		ConfigMaps *ConfigMaps `mapstructure:"config_maps"`
+		RouteRules *RouteRules `mapstructure:"route_rules"`
 	}
 
 	client, err := v1alpha2.NewClient(k8sConf)
@@ -108,17 +141,74 @@ func newProvider(
 	adc := admissioncontroller.New(providerConf.TLS, logger, authClass, factory)
 	instanceID, _ := os.Hostname()
 
-	logger.Info().Msg("Rule provider configured.")
-
-	return &provider{
-		p:          processor,
+	prov := &provider{
 		l:          logger,
 		cl:         client,
 		ac:         authClass,
 		adc:        adc,
 		id:         x.IfThenElse(len(instanceID) == 0, "unknown", instanceID),
 		configured: true,
-	}, nil
+	}
+
+	if providerConf.ConfigMaps != nil || providerConf.RouteRules != nil {
+		if err = prov.configureEventRecording(k8sConf); err != nil {
+			return nil, err
+		}
+	}
+
+	if providerConf.ConfigMaps != nil {
+		if err = prov.configureConfigMapSupport(providerConf.ConfigMaps.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	if providerConf.RouteRules != nil {
+		if err = prov.configureRouteSupport(
+			k8sConf, providerConf.RouteRules.Resources, providerConf.RouteRules.Templates,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	// each informer is a source, which is synchronized as soon as its cache is
+	sources := []string{"RuleSet"}
+	if prov.cms != nil {
+		sources = append(sources, "ConfigMap")
+	}
+
+	prov.t = registry.Register(ProviderType, append(sources, prov.routes...)...)
+	prov.p = prov.t.Processor(processor)
+
+	logger.Info().Msg("Rule provider configured.")
+
+	return prov, nil
+}
+
+func (p *provider) configureEventRecording(k8sConf *rest.Config) error {
+	clientSet, err := kubernetes.NewForConfig(k8sConf)
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed creating client for connecting to kubernetes cluster").CausedBy(err)
+	}
+
+	p.k8s = clientSet
+	p.eb = record.NewBroadcaster()
+	p.er = p.eb.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "heimdall", Host: p.id})
+
+	return nil
+}
+
+func (p *provider) configureConfigMapSupport(selector string) error {
+	cms, err := labels.Parse(x.IfThenElse(len(selector) != 0, selector, DefaultConfigMapSelector))
+	if err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"invalid ConfigMap selector").CausedBy(err)
+	}
+
+	p.cms = cms
+	p.cmStates = make(map[string][]byte)
+
+	return nil
 }
 
 func (p *provider) newController(ctx context.Context, namespace string) (cache.Store, cache.Controller) {
@@ -173,9 +263,52 @@ func (p *provider) Start(ctx context.Context) error {
 		p.l.Debug().Msg("Reconciliation loop exited")
 	}()
 
+	if p.eb != nil {
+		p.eb.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: p.k8s.CoreV1().Events("")})
+	}
+
+	p.awaitSync(newCtx, "RuleSet", controller)
+
+	if p.cms != nil {
+		_, cmController := p.newConfigMapController(newCtx) //nolint:contextcheck
+
+		p.runController(newCtx, "ConfigMap", cmController)
+	}
+
+	for _, resource := range p.routes {
+		_, routeController := p.newRouteController(newCtx, resource) //nolint:contextcheck
+
+		p.runController(newCtx, resource, routeController)
+	}
+
 	return p.adc.Start(ctx)
 }
 
+func (p *provider) runController(ctx context.Context, name string, controller cache.Controller) {
+	p.awaitSync(ctx, name, controller)
+
+	p.wg.Add(1)
+
+	go func() {
+		p.l.Debug().Msgf("Starting %s reconciliation loop", name)
+
+		controller.Run(ctx.Done())
+		p.wg.Done()
+
+		p.l.Debug().Msgf("%s reconciliation loop exited", name)
+	}()
+}
+
+// awaitSync reports the source represented by the given controller to be synchronized as soon as
+// the initial synchronization of its cache, that is the processing of all existing resources, is completed.
+func (p *provider) awaitSync(ctx context.Context, name string, controller cache.Controller) {
+	go func() {
+		if cache.WaitForCacheSync(ctx.Done(), controller.HasSynced) {
+			p.t.Synced(name)
+		}
+	}()
+}
+
 func (p *provider) Stop(ctx context.Context) error {
 	if !p.configured || p.stopped {
 		return nil
@@ -195,6 +328,10 @@ func (p *provider) Stop(ctx context.Context) error {
 
 	p.finalize(ctx)
 
+	if p.eb != nil {
+		p.eb.Shutdown()
+	}
+
 	select {
 	case <-done:
 		return nil
@@ -236,15 +373,10 @@ func (p *provider) addRuleSet(obj any) {
 			fmt.Sprintf("%s instance failed loading RuleSet, reason: %s", p.id, err.Error()),
 		)
 	} else {
-		p.updateStatus(
-			context.Background(),
-			rs,
-			metav1.ConditionTrue,
-			v1alpha2.ConditionRuleSetActive,
-			1,
-			1,
-			fmt.Sprintf("%s instance successfully loaded RuleSet", p.id),
-		)
+		reason, msg := p.activationResult(conf.Source, "loaded")
+
+		p.updateStatus(context.Background(), rs, metav1.ConditionTrue, reason, 1, 1, msg)
+		p.t.Synced(conf.Source)
 	}
 }
 
@@ -279,15 +411,10 @@ func (p *provider) updateRuleSet(oldObj, newObj any) {
 			fmt.Sprintf("%s instance failed updating RuleSet, reason: %s", p.id, err.Error()),
 		)
 	} else {
-		p.updateStatus(
-			context.Background(),
-			newRS,
-			metav1.ConditionTrue,
-			v1alpha2.ConditionRuleSetActive,
-			0,
-			0,
-			fmt.Sprintf("%s instance successfully reloaded RuleSet", p.id),
-		)
+		reason, msg := p.activationResult(conf.Source, "reloaded")
+
+		p.updateStatus(context.Background(), newRS, metav1.ConditionTrue, reason, 0, 0, msg)
+		p.t.Synced(conf.Source)
 	}
 }
 
@@ -324,6 +451,7 @@ func (p *provider) deleteRuleSet(obj any) {
 			-1,
 			fmt.Sprintf("%s instance dropped RuleSet", p.id),
 		)
+		p.t.Synced(conf.Source)
 	}
 }
 
@@ -339,6 +467,91 @@ func (p *provider) toRuleSetConfiguration(rs *v1alpha2.RuleSet) *config2.RuleSet
 	}
 }
 
+// activationResult returns the reason and the message for the condition of a successfully
+// (re)loaded rule set. If some of its rules are shadowed by other rules, or shadow other
+// rules, the condition reflects that.
+func (p *provider) activationResult(srcID, action string) (v1alpha2.ConditionReason, string) {
+	conflicts := p.p.Conflicts(srcID)
+	if len(conflicts) == 0 {
+		return v1alpha2.ConditionRuleSetActive, fmt.Sprintf("%s instance successfully %s RuleSet", p.id, action)
+	}
+
+	return v1alpha2.ConditionRuleSetRulesShadowed,
+		fmt.Sprintf("%s instance %s RuleSet, but detected shadowed rules: %s",
+			p.id, action, strings.Join(slicex.Map(conflicts, rule.Conflict.String), "; "))
+}
+
+// loadRuleSet creates or updates the given rule set retrieved from the given object, if it has been
+// changed, and reports the result via an event. The states hold the hashes of the loaded rule sets.
+func (p *provider) loadRuleSet(
+	obj runtime.Object,
+	states map[string][]byte,
+	ruleSet *config2.RuleSet,
+	origin string,
+) {
+	hash, known := states[ruleSet.Source]
+	if known && bytes.Equal(hash, ruleSet.Hash) {
+		// nothing has changed
+		return
+	}
+
+	var err error
+
+	if known {
+		err = p.p.OnUpdated(ruleSet)
+	} else {
+		err = p.p.OnCreated(ruleSet)
+	}
+
+	if err != nil {
+		p.l.Warn().Err(err).Str("_src", ruleSet.Source).Msg("Failed to apply rule set changes")
+		p.reportLoadingFailure(obj, origin, err)
+
+		return
+	}
+
+	states[ruleSet.Source] = ruleSet.Hash
+
+	p.t.Synced(ruleSet.Source)
+
+	reason, msg := p.activationResult(ruleSet.Source, x.IfThenElse(known, "reloaded", "loaded"))
+
+	p.er.Eventf(obj,
+		x.IfThenElse(reason == v1alpha2.ConditionRuleSetActive, corev1.EventTypeNormal, corev1.EventTypeWarning),
+		string(reason), "%s from %s", msg, origin)
+}
+
+// unloadRuleSet deletes the rule set with the given source, if it has been loaded, and reports the
+// result via an event for the given object.
+func (p *provider) unloadRuleSet(obj runtime.Object, states map[string][]byte, src, origin string) {
+	if _, known := states[src]; !known {
+		return
+	}
+
+	if err := p.p.OnDeleted(&config2.RuleSet{MetaData: config2.MetaData{Source: src}}); err != nil {
+		p.l.Warn().Err(err).Str("_src", src).Msg("Failed deleting rule set")
+
+		p.er.Eventf(obj, corev1.EventTypeWarning, string(v1alpha2.ConditionRuleSetUnloadingFailed),
+			"%s instance failed unloading rule set from %s, reason: %s", p.id, origin, err.Error())
+
+		return
+	}
+
+	delete(states, src)
+
+	p.t.Synced(src)
+
+	p.er.Eventf(obj, corev1.EventTypeNormal, string(v1alpha2.ConditionRuleSetUnloaded),
+		"%s instance dropped rule set from %s", p.id, origin)
+}
+
+func (p *provider) reportLoadingFailure(obj runtime.Object, origin string, err error) {
+	p.t.Failed(err)
+
+	p.er.Eventf(obj, corev1.EventTypeWarning, string(v1alpha2.ConditionRuleSetActivationFailed),
+		"%s instance failed loading rule set from %s, reason: %s", p.id, origin, err.Error())
+}
+
 func (p *provider) mapVersion(_ string) string {
 	// currently the only possible version is v1alpha2, which is mapped to the version "1alpha2" used internally
 	return "1alpha2"
@@ -42,6 +42,7 @@ import (
 	"github.com/dadrus/heimdall/internal/heimdall"
 	config2 "github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/provider/kubernetes/api/v1alpha2"
+	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
@@ -93,6 +94,80 @@ func TestNewProvider(t *testing.T) {
 				assert.Equal(t, "foo", prov.ac)
 				assert.Nil(t, prov.cancel)
 				assert.NotNil(t, prov.cl)
+				assert.Nil(t, prov.k8s)
+			},
+		},
+		{
+			uc:   "with config_maps support enabled using default selector",
+			conf: []byte(`config_maps: {}`),
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, prov)
+				assert.NotNil(t, prov.k8s)
+				assert.NotNil(t, prov.eb)
+				assert.NotNil(t, prov.er)
+				assert.Equal(t, DefaultConfigMapSelector, prov.cms.String())
+			},
+		},
+		{
+			uc: "with config_maps support enabled using custom selector",
+			conf: []byte(`
+config_maps:
+  selector: app.kubernetes.io/part-of=heimdall,tier in (edge)
+`),
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, prov)
+				assert.NotNil(t, prov.k8s)
+				assert.Equal(t, "app.kubernetes.io/part-of=heimdall,tier in (edge)", prov.cms.String())
+			},
+		},
+		{
+			uc:   "with config_maps support enabled using invalid selector",
+			conf: []byte(`config_maps: { selector: "foo in bar" }`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "invalid ConfigMap selector")
+			},
+		},
+		{
+			uc: "with route_rules configured",
+			conf: []byte(`
+route_rules:
+  resources: [ ingresses ]
+  templates:
+    authenticated:
+      execute:
+        - authenticator: foo
+`),
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, prov)
+				assert.NotNil(t, prov.k8s)
+				assert.NotNil(t, prov.er)
+				assert.Nil(t, prov.cms)
+				assert.Equal(t, []string{"ingresses"}, prov.routes)
+				assert.Contains(t, prov.templates, "authenticated")
+			},
+		},
+		{
+			uc:   "with route_rules without templates",
+			conf: []byte(`route_rules: { resources: [ httproutes ] }`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no rule templates")
 			},
 		},
 	} {
@@ -107,7 +182,7 @@ func TestNewProvider(t *testing.T) {
 			k8sCF := func() (*rest.Config, error) { return &rest.Config{Host: "http://localhost:80001"}, nil }
 
 			// WHEN
-			prov, err := newProvider(log.Logger, conf, k8sCF, mocks.NewRuleSetProcessorMock(t), mocks.NewFactoryMock(t))
+			prov, err := newProvider(log.Logger, conf, k8sCF, mocks.NewRuleSetProcessorMock(t), mocks.NewFactoryMock(t), nil)
 
 			// THEN
 			tc.assert(t, err, prov)
@@ -353,6 +428,8 @@ func TestProviderLifecycle(t *testing.T) {
 				processor.EXPECT().OnCreated(mock.Anything).
 					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
 					Return(nil).Once()
+
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Maybe()
 			},
 			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, processor *mocks.RuleSetProcessorMock) {
 				t.Helper()
@@ -386,6 +463,40 @@ func TestProviderLifecycle(t *testing.T) {
 				assert.Equal(t, v1alpha2.ConditionRuleSetActive, v1alpha2.ConditionReason(condition.Reason))
 			},
 		},
+		{
+			uc:   "rule set with shadowed rules added",
+			conf: []byte("auth_class: bar"),
+			watchEvent: func(rs v1alpha2.RuleSet, callIdx int) (watch.Event, error) {
+				switch callIdx {
+				case 1:
+					return watch.Event{Type: watch.Modified, Object: &rs}, nil
+				default:
+					return watch.Event{Type: watch.Bookmark, Object: &rs}, nil
+				}
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).Return(nil).Once()
+				processor.EXPECT().Conflicts(mock.Anything).Return([]rule.Conflict{
+					{RuleID: "test", SrcID: "foo", ShadowedByID: "bar", ShadowedBySrcID: "baz"},
+				}).Once()
+			},
+			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(250 * time.Millisecond)
+
+				assert.Len(t, *statusList, 1)
+				assert.Equal(t, "1/1", (*statusList)[0].ActiveIn)
+
+				assert.Len(t, (*statusList)[0].Conditions, 1)
+				condition := (*statusList)[0].Conditions[0]
+				assert.Equal(t, metav1.ConditionTrue, condition.Status)
+				assert.Equal(t, v1alpha2.ConditionRuleSetRulesShadowed, v1alpha2.ConditionReason(condition.Reason))
+				assert.Contains(t, condition.Message, "rule test from foo is fully shadowed by rule bar from baz")
+			},
+		},
 		{
 			uc:   "adding rule set fails",
 			conf: []byte("auth_class: bar"),
@@ -452,6 +563,8 @@ func TestProviderLifecycle(t *testing.T) {
 				processor.EXPECT().OnDeleted(mock.Anything).
 					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor2").Capture).
 					Return(nil).Once()
+
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Maybe()
 			},
 			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, processor *mocks.RuleSetProcessorMock) {
 				t.Helper()
@@ -515,6 +628,8 @@ func TestProviderLifecycle(t *testing.T) {
 				processor.EXPECT().OnCreated(mock.Anything).
 					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
 					Return(nil).Once()
+
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Maybe()
 			},
 			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, processor *mocks.RuleSetProcessorMock) {
 				t.Helper()
@@ -582,6 +697,8 @@ func TestProviderLifecycle(t *testing.T) {
 				processor.EXPECT().OnCreated(mock.Anything).
 					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
 					Return(nil).Once()
+
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Maybe()
 			},
 			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, processor *mocks.RuleSetProcessorMock) {
 				t.Helper()
@@ -634,6 +751,8 @@ func TestProviderLifecycle(t *testing.T) {
 
 				processor.EXPECT().OnCreated(mock.Anything).Return(nil).Once()
 				processor.EXPECT().OnDeleted(mock.Anything).Return(testsupport.ErrTestPurpose).Once()
+
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Maybe()
 			},
 			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, processor *mocks.RuleSetProcessorMock) {
 				t.Helper()
@@ -710,6 +829,8 @@ func TestProviderLifecycle(t *testing.T) {
 				processor.EXPECT().OnUpdated(mock.Anything).
 					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor2").Capture).
 					Return(nil).Once()
+
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Maybe()
 			},
 			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, processor *mocks.RuleSetProcessorMock) {
 				t.Helper()
@@ -799,6 +920,8 @@ func TestProviderLifecycle(t *testing.T) {
 				processor.EXPECT().OnDeleted(mock.Anything).
 					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor2").Capture).
 					Return(nil).Once()
+
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Maybe()
 			},
 			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, processor *mocks.RuleSetProcessorMock) {
 				t.Helper()
@@ -900,6 +1023,8 @@ func TestProviderLifecycle(t *testing.T) {
 
 				processor.EXPECT().OnCreated(mock.Anything).Return(nil).Once()
 				processor.EXPECT().OnUpdated(mock.Anything).Return(testsupport.ErrTestPurpose).Once()
+
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Maybe()
 			},
 			assert: func(t *testing.T, statusList *[]*v1alpha2.RuleSetStatus, processor *mocks.RuleSetProcessorMock) {
 				t.Helper()
@@ -952,7 +1077,7 @@ func TestProviderLifecycle(t *testing.T) {
 			processor := mocks.NewRuleSetProcessorMock(t)
 			setupProcessor(t, processor)
 
-			prov, err := newProvider(log.Logger, conf, k8sCF, processor, mocks.NewFactoryMock(t))
+			prov, err := newProvider(log.Logger, conf, k8sCF, processor, mocks.NewFactoryMock(t), nil)
 			require.NoError(t, err)
 
 			ctx := context.Background()
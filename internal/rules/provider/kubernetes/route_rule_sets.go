@@ -0,0 +1,496 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-json"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	routeResourceIngresses  = "ingresses"
+	routeResourceHTTPRoutes = "httproutes"
+
+	pathMatchExact  = "Exact"
+	pathMatchPrefix = "PathPrefix"
+	pathMatchRegex  = "RegularExpression"
+
+	// routeRulePriorityBase places the generated rules below the explicitly defined ones,
+	// which have a priority of 0 by default.
+	routeRulePriorityBase = -100000
+)
+
+var (
+	ErrNoRulesGenerated = errors.New("no rules could be generated")
+
+	// nolint: gochecknoglobals
+	httpRouteResource = schema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1",
+		Resource: routeResourceHTTPRoutes,
+	}
+)
+
+// ruleTemplate defines the parts of a rule, which are not derived from the route resources.
+type ruleTemplate struct {
+	Methods      []string                 `json:"methods"`
+	Backend      *config2.Backend         `json:"forward_to"`
+	Execute      []config.MechanismConfig `json:"execute"`
+	ErrorHandler []config.MechanismConfig `json:"on_error"`
+}
+
+// route is a protocol agnostic representation of a single route defined by an Ingress, or
+// an HTTPRoute resource.
+type route struct {
+	hosts     []string
+	path      string
+	matchType string
+	methods   []string
+	upstreams []config2.Upstream
+}
+
+// httpRoute contains the parts of the Gateway API HTTPRoute resource relevant for rule generation.
+type httpRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec struct {
+		Hostnames []string `json:"hostnames"`
+		Rules     []struct {
+			Matches []struct {
+				Path *struct {
+					Type  string `json:"type"`
+					Value string `json:"value"`
+				} `json:"path"`
+				Method string `json:"method"`
+			} `json:"matches"`
+			BackendRefs []struct {
+				Group     *string `json:"group"`
+				Kind      *string `json:"kind"`
+				Name      string  `json:"name"`
+				Namespace *string `json:"namespace"`
+				Port      *int32  `json:"port"`
+				Weight    *int32  `json:"weight"`
+			} `json:"backendRefs"` // nolint: tagliatelle
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+func (p *provider) configureRouteSupport(k8sConf *rest.Config, resources []string, rawTemplates map[string]any) error {
+	if len(rawTemplates) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrConfiguration, "no rule templates configured")
+	}
+
+	templates := make(map[string]*ruleTemplate, len(rawTemplates))
+
+	for name, rawTemplate := range rawTemplates {
+		var tmpl ruleTemplate
+
+		if err := config2.DecodeConfig(rawTemplate, &tmpl); err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to decode rule template %s", name).CausedBy(err)
+		}
+
+		templates[name] = &tmpl
+	}
+
+	if len(resources) == 0 {
+		resources = []string{routeResourceIngresses, routeResourceHTTPRoutes}
+	}
+
+	for _, resource := range resources {
+		if resource != routeResourceIngresses && resource != routeResourceHTTPRoutes {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"unsupported route resource %s", resource)
+		}
+	}
+
+	if slices.Contains(resources, routeResourceHTTPRoutes) {
+		dyn, err := dynamic.NewForConfig(k8sConf)
+		if err != nil {
+			return errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"failed creating client for connecting to kubernetes cluster").CausedBy(err)
+		}
+
+		p.dyn = dyn
+	}
+
+	p.routes = resources
+	p.templates = templates
+
+	return nil
+}
+
+func (p *provider) newRouteController(ctx context.Context, resource string) (cache.Store, cache.Controller) {
+	var (
+		lw      *cache.ListWatch
+		objType runtime.Object
+	)
+
+	if resource == routeResourceIngresses {
+		repository := p.k8s.NetworkingV1().Ingresses("")
+		lw = &cache.ListWatch{
+			ListFunc:  func(opts metav1.ListOptions) (runtime.Object, error) { return repository.List(ctx, opts) },
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) { return repository.Watch(ctx, opts) },
+		}
+		objType = &networkingv1.Ingress{}
+	} else {
+		repository := p.dyn.Resource(httpRouteResource)
+		lw = &cache.ListWatch{
+			ListFunc:  func(opts metav1.ListOptions) (runtime.Object, error) { return repository.List(ctx, opts) },
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) { return repository.Watch(ctx, opts) },
+		}
+		objType = &unstructured.Unstructured{}
+	}
+
+	// each controller runs in its own goroutine, so each gets its own states to avoid concurrent map access
+	states := make(map[string][]byte)
+
+	return cache.NewInformer(
+		lw,
+		objType,
+		0,
+		cache.FilteringResourceEventHandler{
+			FilterFunc: p.filterRoute,
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj any) { p.routeChanged(states, obj) },
+				UpdateFunc: func(_, newObj any) { p.routeChanged(states, newObj) },
+				DeleteFunc: func(obj any) { p.routeDeleted(states, obj) },
+			},
+		},
+	)
+}
+
+func (p *provider) filterRoute(obj any) bool {
+	_, accessor, ok := toRoute(obj)
+	if !ok {
+		return false
+	}
+
+	annotations := accessor.GetAnnotations()
+	authClass := annotations[AuthClassAnnotation]
+
+	return len(annotations[RuleTemplateAnnotation]) != 0 &&
+		x.IfThenElse(len(authClass) != 0, authClass, DefaultClass) == p.ac
+}
+
+func (p *provider) routeChanged(states map[string][]byte, obj any) {
+	if p.stopped {
+		return
+	}
+
+	rObj, accessor, _ := toRoute(obj)
+	templateName := accessor.GetAnnotations()[RuleTemplateAnnotation]
+	origin := "template " + templateName
+
+	p.l.Info().
+		Str("_route", fmt.Sprintf("%s/%s", accessor.GetNamespace(), accessor.GetName())).
+		Msg("Annotated route resource received")
+
+	ruleSet, err := p.toRouteRuleSet(rObj, accessor, templateName)
+	if err != nil {
+		p.l.Warn().Err(err).Str("_src", routeRuleSetSource(accessor)).Msg("Failed generating rules")
+		p.reportLoadingFailure(rObj, origin, err)
+
+		return
+	}
+
+	p.loadRuleSet(rObj, states, ruleSet, origin)
+}
+
+func (p *provider) routeDeleted(states map[string][]byte, obj any) {
+	if p.stopped {
+		return
+	}
+
+	rObj, accessor, _ := toRoute(obj)
+
+	p.l.Info().
+		Str("_route", fmt.Sprintf("%s/%s", accessor.GetNamespace(), accessor.GetName())).
+		Msg("Annotated route resource deletion received")
+
+	p.unloadRuleSet(rObj, states, routeRuleSetSource(accessor),
+		"template "+accessor.GetAnnotations()[RuleTemplateAnnotation])
+}
+
+func (p *provider) toRouteRuleSet(
+	obj runtime.Object,
+	accessor metav1.Object,
+	templateName string,
+) (*config2.RuleSet, error) {
+	tmpl, found := p.templates[templateName]
+	if !found {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"unknown rule template %s", templateName)
+	}
+
+	var (
+		kind   string
+		routes []route
+		err    error
+	)
+
+	switch resource := obj.(type) {
+	case *networkingv1.Ingress:
+		kind, routes = "ingress", ingressRoutes(resource)
+	case *unstructured.Unstructured:
+		kind = "httproute"
+
+		var hr httpRoute
+		if err = runtime.DefaultUnstructuredConverter.FromUnstructured(resource.Object, &hr); err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"failed to decode HTTPRoute").CausedBy(err)
+		}
+
+		routes = httpRouteRoutes(&hr)
+	}
+
+	var rules []config2.Rule
+
+	for _, rt := range routes {
+		for _, host := range rt.hosts {
+			rules = append(rules,
+				newRouteRule(fmt.Sprintf("%s:%s:%s:%d", kind, accessor.GetNamespace(), accessor.GetName(), len(rules)),
+					host, rt, tmpl))
+		}
+	}
+
+	if len(rules) == 0 {
+		return nil, errorchain.New(heimdall.ErrConfiguration).CausedBy(ErrNoRulesGenerated)
+	}
+
+	rawRules, err := json.Marshal(rules)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to marshal generated rules").CausedBy(err)
+	}
+
+	hash := sha256.Sum256(rawRules)
+
+	return &config2.RuleSet{
+		MetaData: config2.MetaData{
+			Source:   routeRuleSetSource(accessor),
+			Hash:     hash[:],
+			ModTime:  accessor.GetCreationTimestamp().Time,
+			Revision: accessor.GetResourceVersion(),
+		},
+		Version: config2.CurrentRuleSetVersion,
+		Name:    fmt.Sprintf("%s:%s/%s", kind, accessor.GetNamespace(), accessor.GetName()),
+		Rules:   rules,
+	}, nil
+}
+
+func newRouteRule(id, host string, rt route, tmpl *ruleTemplate) config2.Rule {
+	rul := config2.Rule{
+		ID: id,
+		RuleMatcher: config2.Matcher{
+			URL:      "<https?>://" + hostPattern(host) + pathPattern(rt.path, rt.matchType),
+			Strategy: "regex",
+		},
+		Methods: x.IfThenElse(len(rt.methods) != 0, rt.methods, slices.Clone(tmpl.Methods)),
+		// emulates the longest match semantics of Ingress and HTTPRoute resources
+		Priority: routeRulePriorityBase + len(rt.path) + x.IfThenElse(rt.matchType == pathMatchExact, 1, 0),
+	}
+
+	if tmpl.Backend != nil {
+		rul.Backend = &config2.Backend{}
+		tmpl.Backend.DeepCopyInto(rul.Backend)
+	}
+
+	if len(rt.upstreams) != 0 &&
+		(rul.Backend == nil || (len(rul.Backend.Host) == 0 && len(rul.Backend.Upstreams) == 0)) {
+		rul.Backend = x.IfThenElse(rul.Backend != nil, rul.Backend, &config2.Backend{})
+
+		if len(rt.upstreams) == 1 {
+			rul.Backend.Host = rt.upstreams[0].Host
+		} else {
+			rul.Backend.Upstreams = rt.upstreams
+		}
+	}
+
+	for _, mc := range tmpl.Execute {
+		var cp config.MechanismConfig
+
+		mc.DeepCopyInto(&cp)
+		rul.Execute = append(rul.Execute, cp)
+	}
+
+	for _, mc := range tmpl.ErrorHandler {
+		var cp config.MechanismConfig
+
+		mc.DeepCopyInto(&cp)
+		rul.ErrorHandler = append(rul.ErrorHandler, cp)
+	}
+
+	return rul
+}
+
+func ingressRoutes(ingress *networkingv1.Ingress) []route {
+	var routes []route
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			rt := route{
+				hosts:     []string{rule.Host},
+				path:      path.Path,
+				matchType: pathMatchPrefix,
+			}
+
+			if path.PathType != nil && *path.PathType == networkingv1.PathTypeExact {
+				rt.matchType = pathMatchExact
+			}
+
+			if svc := path.Backend.Service; svc != nil && svc.Port.Number != 0 {
+				rt.upstreams = []config2.Upstream{
+					{Host: fmt.Sprintf("%s.%s.svc:%d", svc.Name, ingress.Namespace, svc.Port.Number)},
+				}
+			}
+
+			routes = append(routes, rt)
+		}
+	}
+
+	return routes
+}
+
+func httpRouteRoutes(hr *httpRoute) []route {
+	var routes []route
+
+	hosts := x.IfThenElse(len(hr.Spec.Hostnames) != 0, hr.Spec.Hostnames, []string{""})
+
+	for _, rule := range hr.Spec.Rules {
+		var upstreams []config2.Upstream
+
+		for _, ref := range rule.BackendRefs {
+			if (ref.Group != nil && len(*ref.Group) != 0) || (ref.Kind != nil && *ref.Kind != "Service") ||
+				ref.Port == nil {
+				continue
+			}
+
+			upstreams = append(upstreams, config2.Upstream{
+				Host: fmt.Sprintf("%s.%s.svc:%d",
+					ref.Name, x.IfThenElseExec(ref.Namespace != nil,
+						func() string { return *ref.Namespace },
+						func() string { return hr.Namespace }),
+					*ref.Port),
+				Weight: x.IfThenElseExec(ref.Weight != nil,
+					func() *int { weight := int(*ref.Weight); return &weight },
+					func() *int { return nil }),
+			})
+		}
+
+		if len(rule.Matches) == 0 {
+			routes = append(routes, route{hosts: hosts, path: "/", matchType: pathMatchPrefix, upstreams: upstreams})
+
+			continue
+		}
+
+		for _, match := range rule.Matches {
+			rt := route{hosts: hosts, path: "/", matchType: pathMatchPrefix, upstreams: upstreams}
+
+			if match.Path != nil {
+				rt.path = x.IfThenElse(len(match.Path.Value) != 0, match.Path.Value, "/")
+				rt.matchType = x.IfThenElse(len(match.Path.Type) != 0, match.Path.Type, pathMatchPrefix)
+			}
+
+			if len(match.Method) != 0 {
+				rt.methods = []string{match.Method}
+			}
+
+			routes = append(routes, rt)
+		}
+	}
+
+	return routes
+}
+
+func hostPattern(host string) string {
+	switch {
+	case len(host) == 0:
+		return "<[^/]+>"
+	case strings.HasPrefix(host, "*."):
+		// a wildcard matches exactly one dns label
+		return "<[^./]+>" + host[1:] + "<(:[0-9]+)?>"
+	default:
+		return host + "<(:[0-9]+)?>"
+	}
+}
+
+func pathPattern(path, matchType string) string {
+	switch matchType {
+	case pathMatchExact:
+		return path
+	case pathMatchRegex:
+		return "<" + path + ">"
+	default:
+		// prefix matching is done on path elements
+		prefix := strings.TrimSuffix(path, "/")
+		if len(prefix) == 0 {
+			return "/<.*>"
+		}
+
+		return prefix + "<(/.*)?>"
+	}
+}
+
+func routeRuleSetSource(accessor metav1.Object) string {
+	return fmt.Sprintf("%s:%s:%s", ProviderType, accessor.GetNamespace(), accessor.GetUID())
+}
+
+func toRoute(obj any) (runtime.Object, metav1.Object, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	rObj, ok := obj.(runtime.Object)
+	if !ok {
+		return nil, nil, false
+	}
+
+	accessor, err := meta.Accessor(rObj)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	return rObj, accessor, true
+}
@@ -0,0 +1,642 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	mock2 "github.com/dadrus/heimdall/internal/x/testsupport/mock"
+)
+
+func newTestIngress(annotations map[string]string) *networkingv1.Ingress {
+	exact := networkingv1.PathTypeExact
+	prefix := networkingv1.PathTypePrefix
+
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test",
+			Namespace:       "foo",
+			UID:             "4b0b5b3b-6f0e-4a58-9c7a-cd1d2b1d9e11",
+			ResourceVersion: "1",
+			Annotations:     annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "foo.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/api/",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "api",
+											Port: networkingv1.ServiceBackendPort{Number: 8080},
+										},
+									},
+								},
+								{
+									Path:     "/health",
+									PathType: &exact,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "api",
+											Port: networkingv1.ServiceBackendPort{Name: "http"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Host: "*.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "web",
+											Port: networkingv1.ServiceBackendPort{Number: 80},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestHTTPRoute(annotations map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata": map[string]any{
+			"name":            "test",
+			"namespace":       "foo",
+			"uid":             "9a1c4f0e-8d5b-4c3e-b2a1-0f9e8d7c6b5a",
+			"resourceVersion": "1",
+			"annotations":     annotations,
+		},
+		"spec": map[string]any{
+			"hostnames": []any{"bar.example.com"},
+			"rules": []any{
+				map[string]any{
+					"matches": []any{
+						map[string]any{
+							"path":   map[string]any{"type": "PathPrefix", "value": "/users"},
+							"method": "GET",
+						},
+						map[string]any{
+							"path": map[string]any{"type": "RegularExpression", "value": "/items/[0-9]+"},
+						},
+					},
+					"backendRefs": []any{
+						map[string]any{"name": "users-v1", "port": int64(8080), "weight": int64(90)},
+						map[string]any{"name": "users-v2", "namespace": "bar", "port": int64(8080), "weight": int64(10)},
+						map[string]any{"name": "bucket", "group": "storage.example.com", "kind": "Bucket"},
+					},
+				},
+				map[string]any{
+					"backendRefs": []any{
+						map[string]any{"name": "web", "port": int64(80)},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func newTestTemplates(t *testing.T) map[string]*ruleTemplate {
+	t.Helper()
+
+	var tmpl ruleTemplate
+
+	require.NoError(t, config2.DecodeConfig(map[string]any{
+		"methods": []any{"GET", "POST"},
+		"forward_to": map[string]any{
+			"rewrite": map[string]any{"scheme": "http"},
+		},
+		"execute": []any{
+			map[string]any{"authenticator": "jwt"},
+			map[string]any{"finalizer": "id_token"},
+		},
+		"on_error": []any{
+			map[string]any{"error_handler": "redirect"},
+		},
+	}, &tmpl))
+
+	return map[string]*ruleTemplate{"authenticated": &tmpl}
+}
+
+func assertURLMatching(t *testing.T, rul config2.Rule, matching []string, notMatching []string) {
+	t.Helper()
+
+	matcher, err := patternmatcher.NewPatternMatcher(rul.RuleMatcher.Strategy, rul.RuleMatcher.URL)
+	require.NoError(t, err)
+
+	for _, value := range matching {
+		assert.True(t, matcher.Match(value), "%s should match %s", rul.RuleMatcher.URL, value)
+	}
+
+	for _, value := range notMatching {
+		assert.False(t, matcher.Match(value), "%s should not match %s", rul.RuleMatcher.URL, value)
+	}
+}
+
+func TestToRouteRuleSet(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		obj      runtime.Object
+		template string
+		assert   func(t *testing.T, err error, ruleSet *config2.RuleSet)
+	}{
+		{
+			uc:       "unknown template",
+			obj:      newTestIngress(nil),
+			template: "foo",
+			assert: func(t *testing.T, err error, _ *config2.RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unknown rule template foo")
+			},
+		},
+		{
+			uc: "ingress without rules",
+			obj: &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "foo"},
+				Spec: networkingv1.IngressSpec{
+					DefaultBackend: &networkingv1.IngressBackend{
+						Service: &networkingv1.IngressServiceBackend{Name: "foo"},
+					},
+				},
+			},
+			template: "authenticated",
+			assert: func(t *testing.T, err error, _ *config2.RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrNoRulesGenerated)
+			},
+		},
+		{
+			uc:       "ingress",
+			obj:      newTestIngress(nil),
+			template: "authenticated",
+			assert: func(t *testing.T, err error, ruleSet *config2.RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "kubernetes:foo:4b0b5b3b-6f0e-4a58-9c7a-cd1d2b1d9e11", ruleSet.Source)
+				assert.Equal(t, "ingress:foo/test", ruleSet.Name)
+				assert.Equal(t, "1", ruleSet.Revision)
+				assert.Equal(t, config2.CurrentRuleSetVersion, ruleSet.Version)
+				assert.NotEmpty(t, ruleSet.Hash)
+				require.Len(t, ruleSet.Rules, 3)
+
+				rul := ruleSet.Rules[0]
+				assert.Equal(t, "ingress:foo:test:0", rul.ID)
+				assert.Equal(t, "regex", rul.RuleMatcher.Strategy)
+				assert.Equal(t, []string{"GET", "POST"}, rul.Methods)
+				assert.Equal(t, routeRulePriorityBase+5, rul.Priority)
+				require.NotNil(t, rul.Backend)
+				assert.Equal(t, "api.foo.svc:8080", rul.Backend.Host)
+				require.NotNil(t, rul.Backend.URLRewriter)
+				assert.Equal(t, "http", rul.Backend.URLRewriter.Scheme)
+				assert.Len(t, rul.Execute, 2)
+				assert.Equal(t, "jwt", rul.Execute[0]["authenticator"])
+				assert.Len(t, rul.ErrorHandler, 1)
+				assertURLMatching(t, rul,
+					[]string{
+						"https://foo.example.com/api",
+						"https://foo.example.com/api/",
+						"http://foo.example.com:8080/api/users/1",
+					},
+					[]string{
+						"https://foo.example.com/apis",
+						"https://bar.example.com/api",
+						"https://foo.example.com/foo/api",
+						"ftp://foo.example.com/api",
+					})
+
+				rul = ruleSet.Rules[1]
+				assert.Equal(t, "ingress:foo:test:1", rul.ID)
+				assert.Equal(t, routeRulePriorityBase+8, rul.Priority)
+				require.NotNil(t, rul.Backend)
+				assert.Empty(t, rul.Backend.Host)
+				assertURLMatching(t, rul,
+					[]string{"https://foo.example.com/health"},
+					[]string{"https://foo.example.com/health/foo", "https://foo.example.com/healthz"})
+
+				rul = ruleSet.Rules[2]
+				assert.Equal(t, "ingress:foo:test:2", rul.ID)
+				assert.Equal(t, "web.foo.svc:80", rul.Backend.Host)
+				assertURLMatching(t, rul,
+					[]string{"https://foo.example.com/", "https://bar.example.com/foo/bar"},
+					[]string{"https://example.com/", "https://foo.bar.example.com/"})
+			},
+		},
+		{
+			uc:       "httproute",
+			obj:      newTestHTTPRoute(nil),
+			template: "authenticated",
+			assert: func(t *testing.T, err error, ruleSet *config2.RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, "kubernetes:foo:9a1c4f0e-8d5b-4c3e-b2a1-0f9e8d7c6b5a", ruleSet.Source)
+				assert.Equal(t, "httproute:foo/test", ruleSet.Name)
+				require.Len(t, ruleSet.Rules, 3)
+
+				rul := ruleSet.Rules[0]
+				assert.Equal(t, "httproute:foo:test:0", rul.ID)
+				assert.Equal(t, []string{"GET"}, rul.Methods)
+				require.NotNil(t, rul.Backend)
+				assert.Empty(t, rul.Backend.Host)
+				assert.Equal(t, []config2.Upstream{
+					{Host: "users-v1.foo.svc:8080", Weight: func() *int { weight := 90; return &weight }()},
+					{Host: "users-v2.bar.svc:8080", Weight: func() *int { weight := 10; return &weight }()},
+				}, rul.Backend.Upstreams)
+				assertURLMatching(t, rul,
+					[]string{"https://bar.example.com/users", "https://bar.example.com/users/1"},
+					[]string{"https://bar.example.com/usersx", "https://foo.example.com/users"})
+
+				rul = ruleSet.Rules[1]
+				assert.Equal(t, []string{"GET", "POST"}, rul.Methods)
+				assertURLMatching(t, rul,
+					[]string{"https://bar.example.com/items/12"},
+					[]string{"https://bar.example.com/items/foo", "https://bar.example.com/items/12/foo"})
+
+				rul = ruleSet.Rules[2]
+				assert.Equal(t, "web.foo.svc:80", rul.Backend.Host)
+				assertURLMatching(t, rul,
+					[]string{"https://bar.example.com/", "https://bar.example.com/foo"},
+					[]string{"https://foo.example.com/"})
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			prov := &provider{templates: newTestTemplates(t)}
+
+			accessor, err := meta.Accessor(tc.obj)
+			require.NoError(t, err)
+
+			// WHEN
+			ruleSet, err := prov.toRouteRuleSet(tc.obj, accessor, tc.template)
+
+			// THEN
+			tc.assert(t, err, ruleSet)
+		})
+	}
+}
+
+func TestRouteRuleSetsLifecycle(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc             string
+		resource       string
+		steps          func(t *testing.T, ctx context.Context, cl *fake.Clientset, dyn *dynamicfake.FakeDynamicClient)
+		setupProcessor func(t *testing.T, processor *mocks.RuleSetProcessorMock)
+		assert         func(t *testing.T, events []string, processor *mocks.RuleSetProcessorMock)
+	}{
+		{
+			uc:       "ingress without annotation is ignored",
+			resource: routeResourceIngresses,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset, _ *dynamicfake.FakeDynamicClient) {
+				t.Helper()
+
+				_, err := cl.NetworkingV1().Ingresses("foo").Create(ctx, newTestIngress(nil), metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, events []string, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				assert.Empty(t, events)
+			},
+		},
+		{
+			uc:       "ingress with mismatching auth class is ignored",
+			resource: routeResourceIngresses,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset, _ *dynamicfake.FakeDynamicClient) {
+				t.Helper()
+
+				_, err := cl.NetworkingV1().Ingresses("foo").Create(ctx, newTestIngress(map[string]string{
+					RuleTemplateAnnotation: "authenticated",
+					AuthClassAnnotation:    "bar",
+				}), metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, events []string, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				assert.Empty(t, events)
+			},
+		},
+		{
+			uc:       "ingress referencing unknown template",
+			resource: routeResourceIngresses,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset, _ *dynamicfake.FakeDynamicClient) {
+				t.Helper()
+
+				_, err := cl.NetworkingV1().Ingresses("foo").Create(ctx, newTestIngress(map[string]string{
+					RuleTemplateAnnotation: "foo",
+				}), metav1.CreateOptions{})
+				require.NoError(t, err)
+			},
+			assert: func(t *testing.T, events []string, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				require.Len(t, events, 1)
+				assert.Contains(t, events[0], "Warning RuleSetActivationFailed")
+				assert.Contains(t, events[0], "unknown rule template foo")
+			},
+		},
+		{
+			uc:       "ingress annotated, updated and the annotation removed",
+			resource: routeResourceIngresses,
+			steps: func(t *testing.T, ctx context.Context, cl *fake.Clientset, _ *dynamicfake.FakeDynamicClient) {
+				t.Helper()
+
+				ingress := newTestIngress(map[string]string{RuleTemplateAnnotation: "authenticated"})
+
+				_, err := cl.NetworkingV1().Ingresses("foo").Create(ctx, ingress, metav1.CreateOptions{})
+				require.NoError(t, err)
+
+				time.Sleep(100 * time.Millisecond)
+
+				// metadata only update, which does not affect the rules
+				ingress = ingress.DeepCopy()
+				ingress.ResourceVersion = "2"
+				ingress.Labels = map[string]string{"foo": "bar"}
+
+				_, err = cl.NetworkingV1().Ingresses("foo").Update(ctx, ingress, metav1.UpdateOptions{})
+				require.NoError(t, err)
+
+				time.Sleep(100 * time.Millisecond)
+
+				ingress = ingress.DeepCopy()
+				ingress.ResourceVersion = "3"
+				ingress.Spec.Rules = ingress.Spec.Rules[:1]
+
+				_, err = cl.NetworkingV1().Ingresses("foo").Update(ctx, ingress, metav1.UpdateOptions{})
+				require.NoError(t, err)
+
+				time.Sleep(100 * time.Millisecond)
+
+				ingress = ingress.DeepCopy()
+				ingress.ResourceVersion = "4"
+				ingress.Annotations = nil
+
+				_, err = cl.NetworkingV1().Ingresses("foo").Update(ctx, ingress, metav1.UpdateOptions{})
+				require.NoError(t, err)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+				processor.EXPECT().OnUpdated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor2").Capture).
+					Return(nil).Once()
+				processor.EXPECT().OnDeleted(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor3").Capture).
+					Return(nil).Once()
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Twice()
+			},
+			assert: func(t *testing.T, events []string, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				created := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Len(t, created.Rules, 3)
+
+				updated := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor2").Value()
+				assert.Len(t, updated.Rules, 2)
+				assert.Equal(t, "3", updated.Revision)
+
+				deleted := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor3").Value()
+				assert.Equal(t, created.Source, deleted.Source)
+
+				require.Len(t, events, 3)
+				assert.Contains(t, events[0], "Normal RuleSetActive")
+				assert.Contains(t, events[0], "successfully loaded RuleSet from template authenticated")
+				assert.Contains(t, events[1], "successfully reloaded RuleSet from template authenticated")
+				assert.Contains(t, events[2], "Normal RuleSetUnloaded")
+			},
+		},
+		{
+			uc:       "httproute annotated and deleted",
+			resource: routeResourceHTTPRoutes,
+			steps: func(t *testing.T, ctx context.Context, _ *fake.Clientset, dyn *dynamicfake.FakeDynamicClient) {
+				t.Helper()
+
+				_, err := dyn.Resource(httpRouteResource).Namespace("foo").Create(ctx,
+					newTestHTTPRoute(map[string]any{RuleTemplateAnnotation: "authenticated"}),
+					metav1.CreateOptions{})
+				require.NoError(t, err)
+
+				time.Sleep(100 * time.Millisecond)
+
+				err = dyn.Resource(httpRouteResource).Namespace("foo").Delete(ctx, "test", metav1.DeleteOptions{})
+				require.NoError(t, err)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+				processor.EXPECT().OnDeleted(mock.Anything).Return(nil).Once()
+				processor.EXPECT().Conflicts(mock.Anything).Return(nil).Once()
+			},
+			assert: func(t *testing.T, events []string, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				created := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Equal(t, "httproute:foo/test", created.Name)
+				assert.Len(t, created.Rules, 3)
+
+				require.Len(t, events, 2)
+				assert.Contains(t, events[0], "Normal RuleSetActive")
+				assert.Contains(t, events[1], "Normal RuleSetUnloaded")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := fake.NewSimpleClientset()
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{httpRouteResource: "HTTPRouteList"})
+			recorder := record.NewFakeRecorder(10)
+
+			processor := mocks.NewRuleSetProcessorMock(t)
+			if tc.setupProcessor != nil {
+				tc.setupProcessor(t, processor)
+			}
+
+			prov := &provider{
+				p:         processor,
+				l:         log.Logger,
+				ac:        DefaultClass,
+				id:        "test",
+				k8s:       client,
+				dyn:       dyn,
+				er:        recorder,
+				templates: newTestTemplates(t),
+			}
+
+			_, controller := prov.newRouteController(ctx, tc.resource)
+
+			go controller.Run(ctx.Done())
+
+			require.Eventually(t, controller.HasSynced, 1*time.Second, 10*time.Millisecond)
+
+			// WHEN
+			tc.steps(t, ctx, client, dyn)
+
+			time.Sleep(250 * time.Millisecond)
+			cancel()
+
+			// THEN
+			var events []string
+
+			for len(recorder.Events) != 0 {
+				events = append(events, <-recorder.Events)
+			}
+
+			tc.assert(t, events, processor)
+		})
+	}
+}
+
+func TestConfigureRouteSupport(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc        string
+		resources []string
+		templates map[string]any
+		assert    func(t *testing.T, err error, prov *provider)
+	}{
+		{
+			uc: "without templates",
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no rule templates")
+			},
+		},
+		{
+			uc:        "with invalid template",
+			templates: map[string]any{"foo": map[string]any{"bar": "baz"}},
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed to decode rule template foo")
+			},
+		},
+		{
+			uc:        "with unsupported resource",
+			resources: []string{"services"},
+			templates: map[string]any{"foo": map[string]any{"execute": []any{}}},
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "unsupported route resource services")
+			},
+		},
+		{
+			uc:        "with ingresses only",
+			resources: []string{"ingresses"},
+			templates: map[string]any{"foo": map[string]any{"execute": []any{}}},
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []string{"ingresses"}, prov.routes)
+				assert.Nil(t, prov.dyn)
+				assert.Contains(t, prov.templates, "foo")
+			},
+		},
+		{
+			uc:        "with default resources",
+			templates: map[string]any{"foo": map[string]any{"execute": []any{}}},
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []string{"ingresses", "httproutes"}, prov.routes)
+				assert.NotNil(t, prov.dyn)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			prov := &provider{}
+
+			// WHEN
+			err := prov.configureRouteSupport(&rest.Config{Host: "http://localhost:80001"}, tc.resources, tc.templates)
+
+			// THEN
+			tc.assert(t, err, prov)
+		})
+	}
+}
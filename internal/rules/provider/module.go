@@ -22,19 +22,29 @@ import (
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/rules/provider/cloudblob"
+	"github.com/dadrus/heimdall/internal/rules/provider/etcd"
 	"github.com/dadrus/heimdall/internal/rules/provider/filesystem"
+	"github.com/dadrus/heimdall/internal/rules/provider/git"
 	"github.com/dadrus/heimdall/internal/rules/provider/httpendpoint"
 	"github.com/dadrus/heimdall/internal/rules/provider/kubernetes"
+	"github.com/dadrus/heimdall/internal/rules/provider/oci"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
+	"github.com/dadrus/heimdall/internal/rules/signature"
 )
 
 // Module is used on app bootstrap.
 // nolint: gochecknoglobals
 var Module = fx.Options(
 	fx.Invoke(checkRuleProvider),
+	signature.Module,
+	syncstatus.Module,
 	filesystem.Module,
 	httpendpoint.Module,
 	cloudblob.Module,
 	kubernetes.Module,
+	git.Module,
+	etcd.Module,
+	oci.Module,
 )
 
 func checkRuleProvider(logger zerolog.Logger, conf *config.Configuration) {
@@ -49,6 +59,12 @@ func checkRuleProvider(logger zerolog.Logger, conf *config.Configuration) {
 		ruleProviderConfigured = true
 	case conf.Providers.Kubernetes != nil:
 		ruleProviderConfigured = true
+	case conf.Providers.Git != nil:
+		ruleProviderConfigured = true
+	case conf.Providers.Etcd != nil:
+		ruleProviderConfigured = true
+	case conf.Providers.OCI != nil:
+		ruleProviderConfigured = true
 	}
 
 	if !ruleProviderConfigured {
@@ -0,0 +1,267 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"github.com/rs/zerolog"
+	"golang.org/x/exp/maps"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	rule_config "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/provider/syncstatus"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/rules/signature"
+	"github.com/dadrus/heimdall/internal/validation"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/slicex"
+)
+
+type ArtifactState map[string][]byte
+
+type provider struct {
+	p          rule.SetProcessor
+	t          *syncstatus.Tracker
+	l          zerolog.Logger
+	s          *gocron.Scheduler
+	cancel     context.CancelFunc
+	states     sync.Map
+	configured bool
+}
+
+func newProvider(
+	conf *config.Configuration,
+	processor rule.SetProcessor,
+	verifier signature.Verifier,
+	registry *syncstatus.Registry,
+	logger zerolog.Logger,
+) (*provider, error) {
+	rawConf := conf.Providers.OCI
+
+	if rawConf == nil {
+		return &provider{}, nil
+	}
+
+	type Config struct {
+		Artifacts     []*ruleSetArtifact `mapstructure:"artifacts"      validate:"required,gt=0,dive"`
+		WatchInterval *time.Duration     `mapstructure:"watch_interval"`
+	}
+
+	var providerConf Config
+	if err := decodeConfig(rawConf, &providerConf); err != nil {
+		return nil, errorchain.
+			NewWithMessage(heimdall.ErrConfiguration, "failed decoding oci rule provider config").
+			CausedBy(err)
+	}
+
+	if err := validation.ValidateStruct(&providerConf); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed validating oci rule provider config").CausedBy(err)
+	}
+
+	sources := make([]string, len(providerConf.Artifacts))
+
+	for idx, artifact := range providerConf.Artifacts {
+		if err := artifact.init(); err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"bad configuration for #%d artifact in oci rule provider configuration", idx).CausedBy(err)
+		}
+
+		sources[idx] = artifact.ID()
+	}
+
+	logger = logger.With().Str("_provider_type", "oci").Logger()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = logger.With().Logger().WithContext(signature.WithContext(ctx, verifier))
+
+	scheduler := gocron.NewScheduler(time.UTC)
+	scheduler.SingletonModeAll()
+
+	tracker := registry.Register("oci", sources...)
+
+	prov := &provider{
+		p:          tracker.Processor(processor),
+		t:          tracker,
+		l:          logger,
+		s:          scheduler,
+		cancel:     cancel,
+		configured: true,
+	}
+
+	for idx, artifact := range providerConf.Artifacts {
+		if _, err := x.IfThenElseExec(providerConf.WatchInterval != nil && *providerConf.WatchInterval > 0,
+			func() *gocron.Scheduler { return prov.s.Every(*providerConf.WatchInterval) },
+			func() *gocron.Scheduler { return prov.s.Every(1 * time.Second).LimitRunsTo(1) }).
+			Do(prov.watchChanges, ctx, artifact); err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrInternal,
+				"failed to create a rule provider worker to fetch rules sets from #%d oci artifact", idx).
+				CausedBy(err)
+		}
+	}
+
+	logger.Info().Msg("Rule provider configured.")
+
+	return prov, nil
+}
+
+func (p *provider) Start(_ context.Context) error {
+	if !p.configured {
+		return nil
+	}
+
+	p.l.Info().Msg("Starting rule definitions provider")
+
+	p.s.StartAsync() //nolint:contextcheck
+
+	return nil
+}
+
+func (p *provider) Stop(_ context.Context) error {
+	if !p.configured {
+		return nil
+	}
+
+	p.l.Info().Msg("Tearing down rule provider")
+
+	p.s.Stop()
+	p.cancel()
+
+	return nil
+}
+
+func (p *provider) watchChanges(ctx context.Context, artifact *ruleSetArtifact) error {
+	p.l.Debug().Str("_artifact", artifact.ID()).Msg("Retrieving rule sets")
+
+	ruleSets, err := artifact.FetchRuleSets(ctx)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			p.l.Debug().Msg("Watcher closed")
+
+			return nil
+		}
+
+		p.l.Warn().
+			Err(err).
+			Str("_artifact", artifact.ID()).
+			Msg("Failed to fetch rule sets")
+
+		p.t.Failed(err)
+
+		// keep the rule sets loaded so far. E.g. the registry might be unreachable just temporarily
+		return nil
+	}
+
+	state := p.getArtifactState(artifact.ID())
+
+	// if no rule sets are available and no rule sets were known from the past
+	if len(ruleSets) == 0 && len(state) == 0 {
+		p.l.Debug().Str("_artifact", artifact.ID()).Msg("No updates received")
+		p.t.Synced(artifact.ID())
+
+		return nil
+	}
+
+	if err = p.ruleSetsUpdated(ruleSets, state, artifact.ID()); err != nil {
+		p.l.Warn().Err(err).Str("_artifact", artifact.ID()).Msg("Failed to apply rule set changes")
+	} else {
+		p.t.Synced(artifact.ID())
+	}
+
+	return nil
+}
+
+func (p *provider) ruleSetsUpdated(ruleSets []*rule_config.RuleSet, state ArtifactState, artifactID string) error {
+	// check which were present in the past and are not present now
+	// and which are new
+	currentIDs := toRuleSetIDs(ruleSets)
+	oldIDs := maps.Keys(state)
+
+	removedIDs := slicex.Subtract(oldIDs, currentIDs)
+	newIDs := slicex.Subtract(currentIDs, oldIDs)
+
+	for _, ID := range removedIDs {
+		conf := &rule_config.RuleSet{
+			MetaData: rule_config.MetaData{
+				Source:  ID,
+				ModTime: time.Now(),
+			},
+		}
+
+		if err := p.p.OnDeleted(conf); err != nil {
+			return err
+		}
+
+		delete(state, ID)
+	}
+
+	// check which rule sets are new and which are modified
+	for _, ruleSet := range ruleSets {
+		isNew := slices.Contains(newIDs, ruleSet.Source)
+		hasChanged := !isNew && !bytes.Equal(state[ruleSet.Source], ruleSet.Hash)
+
+		if !isNew && !hasChanged {
+			p.l.Debug().
+				Str("_artifact", artifactID).
+				Str("_rule_set", ruleSet.Source).
+				Msg("No updates received")
+
+			continue
+		}
+
+		var err error
+
+		if isNew {
+			err = p.p.OnCreated(ruleSet)
+		} else if hasChanged {
+			err = p.p.OnUpdated(ruleSet)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		state[ruleSet.Source] = ruleSet.Hash
+	}
+
+	return nil
+}
+
+func (p *provider) getArtifactState(key string) ArtifactState {
+	value, _ := p.states.LoadOrStore(key, make(ArtifactState))
+
+	return value.(ArtifactState) // nolint: forcetypeassert
+}
+
+func toRuleSetIDs(ruleSets []*rule_config.RuleSet) []string {
+	currentIDs := make([]string, len(ruleSets))
+
+	for idx, ruleSet := range ruleSets {
+		currentIDs[idx] = ruleSet.Source
+	}
+
+	return currentIDs
+}
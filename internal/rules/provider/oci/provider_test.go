@@ -0,0 +1,310 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/rules/signature"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+	mock2 "github.com/dadrus/heimdall/internal/x/testsupport/mock"
+)
+
+func TestNewProvider(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		conf   []byte
+		assert func(t *testing.T, err error, prov *provider)
+	}{
+		{
+			uc:   "with unknown field",
+			conf: []byte(`foo: bar`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc:   "without artifacts",
+			conf: []byte(`watch_interval: 5s`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed validating")
+			},
+		},
+		{
+			uc: "without reference in one of the configured artifacts",
+			conf: []byte(`
+artifacts:
+  - reference: ghcr.io/acme/rules:v1
+  - plain_http: true
+`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed validating")
+			},
+		},
+		{
+			uc: "with invalid reference",
+			conf: []byte(`
+artifacts:
+  - reference: rules
+`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "bad configuration for #0")
+			},
+		},
+		{
+			uc: "with unsupported authentication strategy",
+			conf: []byte(`
+artifacts:
+  - reference: ghcr.io/acme/rules:v1
+    auth:
+      type: foo
+`),
+			assert: func(t *testing.T, err error, _ *provider) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed decoding")
+			},
+		},
+		{
+			uc: "with watch interval and two artifacts configured",
+			conf: []byte(`
+watch_interval: 5s
+artifacts:
+  - reference: ghcr.io/acme/rules:v1
+    auth:
+      type: basic_auth
+      config:
+        user: foo
+        password: bar
+  - reference: localhost:5000/rules@sha256:0123
+    plain_http: true
+    rule_path_match_prefix: /foo
+`),
+			assert: func(t *testing.T, err error, prov *provider) {
+				t.Helper()
+
+				require.NoError(t, err)
+
+				require.NotNil(t, prov)
+				assert.NotNil(t, prov.s)
+				assert.NotNil(t, prov.p)
+				assert.NotNil(t, prov.cancel)
+				assert.True(t, prov.configured)
+				assert.False(t, prov.s.IsRunning())
+				assert.Len(t, prov.s.Jobs(), 2)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			providerConf, err := testsupport.DecodeTestConfig(tc.conf)
+			require.NoError(t, err)
+
+			conf := &config.Configuration{
+				Providers: config.RuleProviders{OCI: providerConf},
+			}
+
+			// WHEN
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, mocks.NewRuleSetProcessorMock(t), verifier, nil, log.Logger)
+
+			// THEN
+			tc.assert(t, err, prov)
+		})
+	}
+}
+
+func TestProviderLifecycle(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		uc             string
+		watchInterval  string
+		setupRegistry  func(t *testing.T, reg *testRegistry)
+		setupProcessor func(t *testing.T, processor *mocks.RuleSetProcessorMock)
+		assert         func(t *testing.T, reg *testRegistry, logs fmt.Stringer, processor *mocks.RuleSetProcessorMock)
+	}
+
+	for _, tc := range []testCase{
+		{
+			uc: "with not existing tag",
+			assert: func(t *testing.T, _ *testRegistry, logs fmt.Stringer, _ *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(1500 * time.Millisecond)
+
+				assert.Contains(t, logs.String(), "Failed to fetch rule sets")
+			},
+		},
+		{
+			uc: "with rule set and without watch interval",
+			setupRegistry: func(t *testing.T, reg *testRegistry) {
+				t.Helper()
+
+				reg.push(t, "v1", reg.layer(mediaTypeRuleSetYAML, "rules.yaml", ruleSetWithRule("foo")))
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "captor1").Capture).
+					Return(nil).Once()
+			},
+			assert: func(t *testing.T, reg *testRegistry, _ fmt.Stringer, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(1500 * time.Millisecond)
+
+				ruleSet := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "captor1").Value()
+				assert.Equal(t, "oci:rules.yaml@"+reg.host()+"/rules:v1", ruleSet.Source)
+				assert.Contains(t, ruleSet.Revision, "sha256:")
+				assert.Len(t, ruleSet.Rules, 1)
+				assert.Equal(t, "foo", ruleSet.Rules[0].ID)
+			},
+		},
+		{
+			uc:            "with tag moved to a new manifest",
+			watchInterval: "250ms",
+			setupRegistry: func(t *testing.T, reg *testRegistry) {
+				t.Helper()
+
+				reg.push(t, "v1",
+					reg.layer(mediaTypeRuleSetYAML, "a.yaml", ruleSetWithRule("foo")),
+					reg.layer(mediaTypeRuleSetYAML, "b.yaml", ruleSetWithRule("bar")),
+				)
+			},
+			setupProcessor: func(t *testing.T, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				processor.EXPECT().OnCreated(mock.Anything).Return(nil).Twice()
+				processor.EXPECT().OnUpdated(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "updated").Capture).
+					Return(nil).Once()
+				processor.EXPECT().OnDeleted(mock.Anything).
+					Run(mock2.NewArgumentCaptor[*config2.RuleSet](&processor.Mock, "deleted").Capture).
+					Return(nil).Once()
+			},
+			assert: func(t *testing.T, reg *testRegistry, logs fmt.Stringer, processor *mocks.RuleSetProcessorMock) {
+				t.Helper()
+
+				time.Sleep(1500 * time.Millisecond)
+
+				digest := reg.push(t, "v1", reg.layer(mediaTypeRuleSetYAML, "a.yaml", ruleSetWithRule("baz")))
+
+				time.Sleep(1 * time.Second)
+
+				assert.NotContains(t, logs.String(), "Failed")
+
+				updated := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "updated").Value()
+				assert.Equal(t, "oci:a.yaml@"+reg.host()+"/rules:v1", updated.Source)
+				assert.Equal(t, digest, updated.Revision)
+				assert.Equal(t, "baz", updated.Rules[0].ID)
+
+				deleted := mock2.ArgumentCaptorFrom[*config2.RuleSet](&processor.Mock, "deleted").Value()
+				assert.Equal(t, "oci:b.yaml@"+reg.host()+"/rules:v1", deleted.Source)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			reg := newTestRegistry(t)
+
+			setupRegistry := x.IfThenElse(
+				tc.setupRegistry != nil,
+				tc.setupRegistry,
+				func(t *testing.T, _ *testRegistry) { t.Helper() },
+			)
+			setupProcessor := x.IfThenElse(
+				tc.setupProcessor != nil,
+				tc.setupProcessor,
+				func(t *testing.T, _ *mocks.RuleSetProcessorMock) { t.Helper() },
+			)
+
+			setupRegistry(t, reg)
+
+			rawConf := "artifacts:\n- reference: " + reg.host() + "/rules:v1\n  plain_http: true\n"
+			if len(tc.watchInterval) != 0 {
+				rawConf += "watch_interval: " + tc.watchInterval + "\n"
+			}
+
+			providerConf, err := testsupport.DecodeTestConfig([]byte(rawConf))
+			require.NoError(t, err)
+
+			processor := mocks.NewRuleSetProcessorMock(t)
+			setupProcessor(t, processor)
+
+			conf := &config.Configuration{
+				Providers: config.RuleProviders{OCI: providerConf},
+			}
+
+			logs := &strings.Builder{}
+			verifier, err := signature.NewVerifier(conf, log.Logger)
+			require.NoError(t, err)
+
+			prov, err := newProvider(conf, processor, verifier, nil, zerolog.New(logs))
+			require.NoError(t, err)
+
+			ctx := context.Background()
+
+			// WHEN
+			err = prov.Start(ctx)
+
+			defer prov.Stop(ctx) //nolint:errcheck
+
+			// THEN
+			require.NoError(t, err)
+			tc.assert(t, reg, logs, processor)
+		})
+	}
+}
@@ -0,0 +1,391 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/endpoint"
+	"github.com/dadrus/heimdall/internal/rules/signature"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+const (
+	mediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeRuleSetYAML   = "application/vnd.heimdall.ruleset.v1+yaml"
+	mediaTypeRuleSetJSON   = "application/vnd.heimdall.ruleset.v1+json"
+
+	annotationTitle   = "org.opencontainers.image.title"
+	annotationCreated = "org.opencontainers.image.created"
+
+	// annotationSignature is the layer annotation carrying the detached signature of the rule set.
+	annotationSignature = "io.github.dadrus.heimdall.ruleset.signature"
+
+	maxContentSize = 4 << 20
+)
+
+// nolint: gochecknoglobals
+var challengeParamPattern = regexp.MustCompile(`(\w+)=(?:"([^"]*)"|([^\s,]*))`)
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type manifest struct {
+	MediaType   string            `json:"mediaType"`
+	Layers      []descriptor      `json:"layers"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ruleSetArtifact struct {
+	Reference       string                          `mapstructure:"reference"              validate:"required"`
+	AuthStrategy    endpoint.AuthenticationStrategy `mapstructure:"auth"`
+	PlainHTTP       bool                            `mapstructure:"plain_http"`
+	RulesPathPrefix string                          `mapstructure:"rule_path_match_prefix"`
+
+	registry   string
+	repository string
+	ref        string
+	token      string
+	digest     string
+	ruleSets   []*config.RuleSet
+}
+
+// init splits the reference into the registry host, the repository name and the tag or digest.
+// If neither a tag, nor a digest is specified, the "latest" tag is used.
+func (a *ruleSetArtifact) init() error {
+	registry, remainder, found := strings.Cut(a.Reference, "/")
+	if !found || len(registry) == 0 || len(remainder) == 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"invalid reference '%s': registry and repository are required", a.Reference)
+	}
+
+	repository, ref := remainder, "latest"
+
+	if idx := strings.Index(remainder, "@"); idx != -1 {
+		repository, ref = remainder[:idx], remainder[idx+1:]
+	} else if idx = strings.LastIndex(remainder, ":"); idx != -1 {
+		repository, ref = remainder[:idx], remainder[idx+1:]
+	}
+
+	if len(repository) == 0 || len(ref) == 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration, "invalid reference '%s'", a.Reference)
+	}
+
+	a.registry = registry
+	a.repository = repository
+	a.ref = ref
+
+	return nil
+}
+
+func (a *ruleSetArtifact) ID() string { return a.Reference }
+
+func (a *ruleSetArtifact) isDigest() bool { return strings.HasPrefix(a.ref, "sha256:") }
+
+// FetchRuleSets resolves the reference to a manifest and loads the rule sets from its layers.
+// The layers are only downloaded if the manifest has changed since the last invocation.
+func (a *ruleSetArtifact) FetchRuleSets(ctx context.Context) ([]*config.RuleSet, error) {
+	if a.isDigest() && a.digest == a.ref {
+		// content addressed references never change
+		return a.ruleSets, nil
+	}
+
+	data, err := a.fetch(ctx, "manifests", a.ref, mediaTypeImageManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := computeDigest(data)
+	if a.isDigest() && digest != a.ref {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"digest of the received manifest %s does not match the reference", digest)
+	}
+
+	if digest == a.digest {
+		return a.ruleSets, nil
+	}
+
+	var mf manifest
+	if err = json.Unmarshal(data, &mf); err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed to decode manifest").
+			CausedBy(err)
+	}
+
+	modTime := time.Now()
+	if created, err := time.Parse(time.RFC3339, mf.Annotations[annotationCreated]); err == nil {
+		modTime = created
+	}
+
+	var ruleSets []*config.RuleSet
+
+	for _, layer := range mf.Layers {
+		contentType := toContentType(layer.MediaType)
+		if len(contentType) == 0 {
+			continue
+		}
+
+		ruleSet, err := a.fetchRuleSet(ctx, layer, contentType)
+		if err != nil {
+			if errors.Is(err, config.ErrEmptyRuleSet) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		ruleSet.Revision = digest
+		ruleSet.ModTime = modTime
+		ruleSets = append(ruleSets, ruleSet)
+	}
+
+	a.digest = digest
+	a.ruleSets = ruleSets
+
+	return ruleSets, nil
+}
+
+func (a *ruleSetArtifact) fetchRuleSet(
+	ctx context.Context, layer descriptor, contentType string,
+) (*config.RuleSet, error) {
+	data, err := a.fetch(ctx, "blobs", layer.Digest, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if digest := computeDigest(data); digest != layer.Digest {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"digest of the received layer %s does not match the expected one %s", digest, layer.Digest)
+	}
+
+	src := fmt.Sprintf("oci:%s@%s",
+		x.IfThenElse(len(layer.Annotations[annotationTitle]) != 0, layer.Annotations[annotationTitle], layer.Digest),
+		a.ID())
+
+	contents, err := signature.Ctx(ctx).Verify(ctx, src, data, layer.Annotations[annotationSignature])
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet, err := config.ParseRules(contentType, bytes.NewReader(contents), false)
+	if err != nil {
+		if errors.Is(err, config.ErrEmptyRuleSet) {
+			return nil, err
+		}
+
+		return nil, errorchain.NewWithMessagef(heimdall.ErrInternal,
+			"failed to parse rule set from layer %s", layer.Digest).CausedBy(err)
+	}
+
+	if err = ruleSet.VerifyPathPrefix(a.RulesPathPrefix); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(data)
+
+	ruleSet.Hash = hash[:]
+	ruleSet.Source = src
+
+	return ruleSet, nil
+}
+
+func (a *ruleSetArtifact) fetch(ctx context.Context, kind, ref, accept string) ([]byte, error) {
+	resp, err := a.send(ctx, kind, ref, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, errorchain.NewWithMessage(heimdall.ErrCommunication, "registry rejected the credentials")
+		}
+
+		if err = a.obtainToken(ctx, challenge); err != nil {
+			return nil, err
+		}
+
+		if resp, err = a.send(ctx, kind, ref, accept); err != nil {
+			return nil, err
+		}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"unexpected response code while fetching %s %s: %v", kind, ref, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxContentSize+1))
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrCommunication, "failed to read %s %s", kind, ref).
+			CausedBy(err)
+	}
+
+	if len(data) > maxContentSize {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrInternal, "%s %s exceeds the allowed size", kind, ref)
+	}
+
+	return data, nil
+}
+
+func (a *ruleSetArtifact) send(ctx context.Context, kind, ref, accept string) (*http.Response, error) {
+	ep := endpoint.Endpoint{
+		URL: fmt.Sprintf("%s://%s/v2/%s/%s/%s",
+			x.IfThenElse(a.PlainHTTP, "http", "https"), a.registry, a.repository, kind, ref),
+		Method:  http.MethodGet,
+		Headers: map[string]string{},
+	}
+
+	if len(accept) != 0 {
+		ep.Headers["Accept"] = accept
+	}
+
+	if len(a.token) != 0 {
+		ep.Headers["Authorization"] = "Bearer " + a.token
+	} else {
+		ep.AuthStrategy = a.AuthStrategy
+	}
+
+	return a.do(ctx, ep)
+}
+
+// obtainToken implements the token authentication flow of the distribution spec. The configured
+// authentication strategy is used to authenticate against the token service.
+func (a *ruleSetArtifact) obtainToken(ctx context.Context, challenge string) error {
+	params := parseChallenge(challenge[len("bearer "):])
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || len(realm.Host) == 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"invalid realm in authentication challenge: '%s'", params["realm"])
+	}
+
+	query := realm.Query()
+
+	for _, name := range []string{"service", "scope"} {
+		if len(params[name]) != 0 {
+			query.Set(name, params[name])
+		}
+	}
+
+	realm.RawQuery = query.Encode()
+
+	resp, err := a.do(ctx, endpoint.Endpoint{
+		URL:          realm.String(),
+		Method:       http.MethodGet,
+		AuthStrategy: a.AuthStrategy,
+	})
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errorchain.NewWithMessagef(heimdall.ErrCommunication,
+			"unexpected response code from the token service: %v", resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxContentSize)).Decode(&tokenResp); err != nil {
+		return errorchain.NewWithMessage(heimdall.ErrCommunication, "failed to decode token response").
+			CausedBy(err)
+	}
+
+	a.token = x.IfThenElse(len(tokenResp.Token) != 0, tokenResp.Token, tokenResp.AccessToken)
+	if len(a.token) == 0 {
+		return errorchain.NewWithMessage(heimdall.ErrCommunication, "token service did not issue a token")
+	}
+
+	return nil
+}
+
+func (a *ruleSetArtifact) do(ctx context.Context, ep endpoint.Endpoint) (*http.Response, error) {
+	req, err := ep.CreateRequest(ctx, nil, nil)
+	if err != nil {
+		return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed creating request").
+			CausedBy(err)
+	}
+
+	resp, err := ep.CreateClient(req.URL.Hostname()).Do(req)
+	if err != nil {
+		var clientErr *url.Error
+		if errors.As(err, &clientErr) && clientErr.Timeout() {
+			return nil, errorchain.NewWithMessage(heimdall.ErrCommunicationTimeout, "request to registry timed out").
+				CausedBy(err)
+		}
+
+		return nil, errorchain.NewWithMessage(heimdall.ErrCommunication, "request to registry failed").
+			CausedBy(err)
+	}
+
+	return resp, nil
+}
+
+func parseChallenge(value string) map[string]string {
+	params := make(map[string]string)
+
+	for _, match := range challengeParamPattern.FindAllStringSubmatch(value, -1) {
+		params[strings.ToLower(match[1])] = x.IfThenElse(len(match[2]) != 0, match[2], match[3])
+	}
+
+	return params
+}
+
+func toContentType(mediaType string) string {
+	switch mediaType {
+	case mediaTypeRuleSetYAML, "application/yaml", signature.ContentTypeJOSE:
+		return "application/yaml"
+	case mediaTypeRuleSetJSON, "application/json":
+		return "application/json"
+	default:
+		return ""
+	}
+}
+
+func computeDigest(data []byte) string {
+	hash := sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
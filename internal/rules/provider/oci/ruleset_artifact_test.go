@@ -0,0 +1,361 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/endpoint/authstrategy"
+)
+
+type testRegistry struct {
+	mu        sync.Mutex
+	srv       *httptest.Server
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	requests  []string
+
+	// if set, the registry requires a bearer token issued by its token endpoint
+	// for the given basic auth credentials
+	user, password string
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+
+	reg := &testRegistry{manifests: map[string][]byte{}, blobs: map[string][]byte{}}
+	reg.srv = httptest.NewServer(http.HandlerFunc(reg.serve))
+
+	t.Cleanup(reg.srv.Close)
+
+	return reg
+}
+
+func (r *testRegistry) host() string { return strings.TrimPrefix(r.srv.URL, "http://") }
+
+// push stores the given layers and a manifest referencing them under the given tag
+// and returns the digest of the manifest.
+func (r *testRegistry) push(t *testing.T, tag string, layers ...descriptor) string {
+	t.Helper()
+
+	mf := manifest{MediaType: mediaTypeImageManifest, Layers: layers}
+
+	data, err := json.Marshal(mf)
+	require.NoError(t, err)
+
+	digest := computeDigest(data)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.manifests[tag] = data
+	r.manifests[digest] = data
+
+	return digest
+}
+
+func (r *testRegistry) layer(mediaType, title, content string) descriptor {
+	digest := computeDigest([]byte(content))
+
+	r.mu.Lock()
+	r.blobs[digest] = []byte(content)
+	r.mu.Unlock()
+
+	desc := descriptor{MediaType: mediaType, Digest: digest, Size: int64(len(content))}
+	if len(title) != 0 {
+		desc.Annotations = map[string]string{annotationTitle: title}
+	}
+
+	return desc
+}
+
+func (r *testRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, req.URL.Path)
+
+	if req.URL.Path == "/token" {
+		user, password, _ := req.BasicAuth()
+		if user != r.user || password != r.password || req.URL.Query().Get("scope") != "repository:rules:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"token":"test-token"}`))
+
+		return
+	}
+
+	if len(r.user) != 0 && req.Header.Get("Authorization") != "Bearer test-token" {
+		w.Header().Set("WWW-Authenticate",
+			`Bearer realm="`+r.srv.URL+`/token",service="test",scope="repository:rules:pull"`)
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	var (
+		data  []byte
+		found bool
+	)
+
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/v2/rules/manifests/"):
+		data, found = r.manifests[strings.TrimPrefix(req.URL.Path, "/v2/rules/manifests/")]
+		w.Header().Set("Content-Type", mediaTypeImageManifest)
+	case strings.HasPrefix(req.URL.Path, "/v2/rules/blobs/"):
+		data, found = r.blobs[strings.TrimPrefix(req.URL.Path, "/v2/rules/blobs/")]
+	}
+
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	_, _ = w.Write(data)
+}
+
+func ruleSetWithRule(id string) string {
+	return `
+version: "1alpha2"
+name: test
+rules:
+- id: ` + id + `
+  match: http://foo.bar/<**>
+`
+}
+
+func TestRuleSetArtifactInit(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc         string
+		reference  string
+		registry   string
+		repository string
+		ref        string
+		err        bool
+	}{
+		{uc: "without repository", reference: "ghcr.io", err: true},
+		{uc: "with empty tag", reference: "ghcr.io/acme/rules:", err: true},
+		{uc: "without tag", reference: "ghcr.io/acme/rules", registry: "ghcr.io", repository: "acme/rules", ref: "latest"},
+		{uc: "with tag", reference: "localhost:5000/rules:v1", registry: "localhost:5000", repository: "rules", ref: "v1"},
+		{
+			uc:         "with digest",
+			reference:  "ghcr.io/acme/rules@sha256:0123",
+			registry:   "ghcr.io",
+			repository: "acme/rules",
+			ref:        "sha256:0123",
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			artifact := &ruleSetArtifact{Reference: tc.reference}
+
+			// WHEN
+			err := artifact.init()
+
+			// THEN
+			if tc.err {
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.registry, artifact.registry)
+			assert.Equal(t, tc.repository, artifact.repository)
+			assert.Equal(t, tc.ref, artifact.ref)
+		})
+	}
+}
+
+func TestRuleSetArtifactFetchRuleSets(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		setup  func(t *testing.T, reg *testRegistry) *ruleSetArtifact
+		assert func(t *testing.T, reg *testRegistry, artifact *ruleSetArtifact, err error, ruleSets []*config.RuleSet)
+	}{
+		{
+			uc: "not existing tag",
+			setup: func(t *testing.T, reg *testRegistry) *ruleSetArtifact {
+				t.Helper()
+
+				return &ruleSetArtifact{Reference: reg.host() + "/rules:v1", PlainHTTP: true}
+			},
+			assert: func(t *testing.T, _ *testRegistry, _ *ruleSetArtifact, err error, _ []*config.RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "404")
+			},
+		},
+		{
+			uc: "tag with rule set and unrelated layers",
+			setup: func(t *testing.T, reg *testRegistry) *ruleSetArtifact {
+				t.Helper()
+
+				reg.push(t, "v1",
+					reg.layer(mediaTypeRuleSetYAML, "rules.yaml", ruleSetWithRule("foo")),
+					reg.layer("application/json", "", `{"version":"1alpha2","name":"bar","rules":[{"id":"bar","match":"http://bar/<**>"}]}`),
+					reg.layer("application/vnd.oci.image.layer.v1.tar", "", "binary"),
+					reg.layer(mediaTypeRuleSetYAML, "empty.yaml", ""),
+				)
+
+				return &ruleSetArtifact{Reference: reg.host() + "/rules:v1", PlainHTTP: true}
+			},
+			assert: func(t *testing.T, reg *testRegistry, artifact *ruleSetArtifact, err error, ruleSets []*config.RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, ruleSets, 2)
+
+				assert.Equal(t, "oci:rules.yaml@"+reg.host()+"/rules:v1", ruleSets[0].Source)
+				assert.Equal(t, "foo", ruleSets[0].Rules[0].ID)
+				assert.Equal(t, "bar", ruleSets[1].Rules[0].ID)
+				assert.Contains(t, ruleSets[1].Source, "oci:sha256:")
+				assert.Equal(t, artifact.digest, ruleSets[0].Revision)
+
+				// unchanged manifest does not result in downloading the layers again
+				count := len(reg.requests)
+				again, err := artifact.FetchRuleSets(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, ruleSets, again)
+				assert.Len(t, reg.requests, count+1)
+			},
+		},
+		{
+			uc: "digest reference",
+			setup: func(t *testing.T, reg *testRegistry) *ruleSetArtifact {
+				t.Helper()
+
+				digest := reg.push(t, "v1", reg.layer(mediaTypeRuleSetYAML, "rules.yaml", ruleSetWithRule("foo")))
+				reg.push(t, "v1", reg.layer(mediaTypeRuleSetYAML, "rules.yaml", ruleSetWithRule("bar")))
+
+				return &ruleSetArtifact{Reference: reg.host() + "/rules@" + digest, PlainHTTP: true}
+			},
+			assert: func(t *testing.T, reg *testRegistry, artifact *ruleSetArtifact, err error, ruleSets []*config.RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, ruleSets, 1)
+				assert.Equal(t, "foo", ruleSets[0].Rules[0].ID)
+				assert.Equal(t, artifact.ref, ruleSets[0].Revision)
+
+				// digest references are not resolved again
+				count := len(reg.requests)
+				_, err = artifact.FetchRuleSets(context.Background())
+				require.NoError(t, err)
+				assert.Len(t, reg.requests, count)
+			},
+		},
+		{
+			uc: "layer with digest mismatch",
+			setup: func(t *testing.T, reg *testRegistry) *ruleSetArtifact {
+				t.Helper()
+
+				layer := reg.layer(mediaTypeRuleSetYAML, "rules.yaml", ruleSetWithRule("foo"))
+				reg.blobs[layer.Digest] = []byte(ruleSetWithRule("bar"))
+				reg.push(t, "v1", layer)
+
+				return &ruleSetArtifact{Reference: reg.host() + "/rules:v1", PlainHTTP: true}
+			},
+			assert: func(t *testing.T, _ *testRegistry, _ *ruleSetArtifact, err error, _ []*config.RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "does not match")
+			},
+		},
+		{
+			uc: "registry requiring token authentication",
+			setup: func(t *testing.T, reg *testRegistry) *ruleSetArtifact {
+				t.Helper()
+
+				reg.user, reg.password = "foo", "bar"
+				reg.push(t, "v1", reg.layer(mediaTypeRuleSetYAML, "rules.yaml", ruleSetWithRule("foo")))
+
+				return &ruleSetArtifact{
+					Reference:    reg.host() + "/rules:v1",
+					PlainHTTP:    true,
+					AuthStrategy: &authstrategy.BasicAuth{User: "foo", Password: "bar"},
+				}
+			},
+			assert: func(t *testing.T, _ *testRegistry, artifact *ruleSetArtifact, err error, ruleSets []*config.RuleSet) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, ruleSets, 1)
+				assert.Equal(t, "test-token", artifact.token)
+			},
+		},
+		{
+			uc: "registry requiring token authentication with wrong credentials",
+			setup: func(t *testing.T, reg *testRegistry) *ruleSetArtifact {
+				t.Helper()
+
+				reg.user, reg.password = "foo", "bar"
+				reg.push(t, "v1", reg.layer(mediaTypeRuleSetYAML, "rules.yaml", ruleSetWithRule("foo")))
+
+				return &ruleSetArtifact{
+					Reference:    reg.host() + "/rules:v1",
+					PlainHTTP:    true,
+					AuthStrategy: &authstrategy.BasicAuth{User: "foo", Password: "baz"},
+				}
+			},
+			assert: func(t *testing.T, _ *testRegistry, _ *ruleSetArtifact, err error, _ []*config.RuleSet) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrCommunication)
+				assert.Contains(t, err.Error(), "token service")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			reg := newTestRegistry(t)
+			artifact := tc.setup(t, reg)
+			require.NoError(t, artifact.init())
+
+			// WHEN
+			ruleSets, err := artifact.FetchRuleSets(context.Background())
+
+			// THEN
+			tc.assert(t, reg, artifact, err, ruleSets)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syncstatus
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/dadrus/heimdall/version"
+)
+
+const providerAttrKey = attribute.Key("provider")
+
+// Status describes the synchronization state of a rule provider.
+type Status struct {
+	Provider     string     `json:"provider"`
+	Ready        bool       `json:"ready"`
+	RuleSets     int        `json:"rule_sets"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	LastErrorAt  *time.Time `json:"last_error_at,omitempty"`
+}
+
+// Registry keeps track of the synchronization state of all configured rule providers.
+type Registry struct {
+	mut      sync.Mutex
+	trackers []*Tracker
+}
+
+func NewRegistry(logger zerolog.Logger) *Registry {
+	reg := &Registry{}
+
+	if err := reg.registerMetrics(otel.GetMeterProvider()); err != nil {
+		logger.Warn().Err(err).Msg("Failed registering rule provider metrics")
+	}
+
+	return reg
+}
+
+// Register creates a tracker for the given provider. The provider is ready as soon as each of the
+// given sources has been synchronized successfully at least once, or, if no sources are given, on
+// its first successful synchronization. Is nil safe and returns a nil tracker, which ignores all
+// reports, if the registry is nil.
+func (r *Registry) Register(provider string, sources ...string) *Tracker {
+	if r == nil {
+		return nil
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	tracker := &Tracker{
+		provider: provider,
+		ruleSets: make(map[string]struct{}),
+		pending:  make(map[string]struct{}, len(sources)),
+	}
+
+	for _, src := range sources {
+		tracker.pending[src] = struct{}{}
+	}
+
+	r.trackers = append(r.trackers, tracker)
+
+	return tracker
+}
+
+// Status returns the state of all registered providers.
+func (r *Registry) Status() []Status {
+	r.mut.Lock()
+	trackers := slices.Clone(r.trackers)
+	r.mut.Unlock()
+
+	states := make([]Status, len(trackers))
+	for idx, tracker := range trackers {
+		states[idx] = tracker.Status()
+	}
+
+	return states
+}
+
+// Ready returns true if all registered providers have completed their first successful synchronization.
+func (r *Registry) Ready() bool {
+	for _, status := range r.Status() {
+		if !status.Ready {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Registry) registerMetrics(provider metric.MeterProvider) error {
+	meter := provider.Meter(
+		"github.com/dadrus/heimdall/internal/rules/provider",
+		metric.WithInstrumentationVersion(version.Version),
+	)
+
+	ruleSets, err := meter.Int64ObservableGauge(
+		"rules.provider.rule_sets",
+		metric.WithDescription("Number of rule sets loaded by a rule provider"),
+	)
+	if err != nil {
+		return err
+	}
+
+	lastSync, err := meter.Int64ObservableGauge(
+		"rules.provider.last_sync",
+		metric.WithDescription("Time of the last successful synchronization of a rule provider"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+
+	lastError, err := meter.Int64ObservableGauge(
+		"rules.provider.last_error",
+		metric.WithDescription("Time of the last failed synchronization of a rule provider"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(
+		func(_ context.Context, observer metric.Observer) error {
+			for _, status := range r.Status() {
+				attrs := metric.WithAttributes(providerAttrKey.String(status.Provider))
+
+				observer.ObserveInt64(ruleSets, int64(status.RuleSets), attrs)
+
+				if status.LastSyncedAt != nil {
+					observer.ObserveInt64(lastSync, status.LastSyncedAt.Unix(), attrs)
+				}
+
+				if status.LastErrorAt != nil {
+					observer.ObserveInt64(lastError, status.LastErrorAt.Unix(), attrs)
+				}
+			}
+
+			return nil
+		},
+		ruleSets, lastSync, lastError,
+	)
+
+	return err
+}
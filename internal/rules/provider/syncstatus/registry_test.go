@@ -0,0 +1,222 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syncstatus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
+)
+
+func TestTrackerStatus(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	for _, tc := range []struct {
+		uc     string
+		track  func(t *testing.T, tracker *Tracker)
+		assert func(t *testing.T, status Status)
+	}{
+		{
+			uc:    "nothing reported",
+			track: func(t *testing.T, _ *Tracker) { t.Helper() },
+			assert: func(t *testing.T, status Status) {
+				t.Helper()
+
+				assert.Equal(t, "test", status.Provider)
+				assert.False(t, status.Ready)
+				assert.Zero(t, status.RuleSets)
+				assert.Nil(t, status.LastSyncedAt)
+				assert.Empty(t, status.LastError)
+				assert.Nil(t, status.LastErrorAt)
+			},
+		},
+		{
+			uc: "only failures reported",
+			track: func(t *testing.T, tracker *Tracker) {
+				t.Helper()
+
+				tracker.Failed(errTest)
+			},
+			assert: func(t *testing.T, status Status) {
+				t.Helper()
+
+				assert.False(t, status.Ready)
+				assert.Nil(t, status.LastSyncedAt)
+				assert.Equal(t, "test error", status.LastError)
+				assert.NotNil(t, status.LastErrorAt)
+			},
+		},
+		{
+			uc: "failure after successful synchronization",
+			track: func(t *testing.T, tracker *Tracker) {
+				t.Helper()
+
+				tracker.Synced("foo")
+				tracker.Failed(errTest)
+			},
+			assert: func(t *testing.T, status Status) {
+				t.Helper()
+
+				assert.True(t, status.Ready)
+				assert.NotNil(t, status.LastSyncedAt)
+				assert.Equal(t, "test error", status.LastError)
+				assert.NotNil(t, status.LastErrorAt)
+			},
+		},
+		{
+			uc: "rule sets loaded and dropped via the processor",
+			track: func(t *testing.T, tracker *Tracker) {
+				t.Helper()
+
+				processor := mocks.NewRuleSetProcessorMock(t)
+				processor.EXPECT().OnCreated(&config.RuleSet{MetaData: config.MetaData{Source: "foo"}}).Return(nil)
+				processor.EXPECT().OnCreated(&config.RuleSet{MetaData: config.MetaData{Source: "bar"}}).Return(nil)
+				processor.EXPECT().OnUpdated(&config.RuleSet{MetaData: config.MetaData{Source: "foo"}}).Return(nil)
+				processor.EXPECT().OnCreated(&config.RuleSet{MetaData: config.MetaData{Source: "baz"}}).
+					Return(errTest)
+				processor.EXPECT().OnDeleted(&config.RuleSet{MetaData: config.MetaData{Source: "bar"}}).Return(nil)
+
+				tp := tracker.Processor(processor)
+
+				require.NoError(t, tp.OnCreated(&config.RuleSet{MetaData: config.MetaData{Source: "foo"}}))
+				require.NoError(t, tp.OnCreated(&config.RuleSet{MetaData: config.MetaData{Source: "bar"}}))
+				require.NoError(t, tp.OnUpdated(&config.RuleSet{MetaData: config.MetaData{Source: "foo"}}))
+				require.ErrorIs(t, tp.OnCreated(&config.RuleSet{MetaData: config.MetaData{Source: "baz"}}), errTest)
+				require.NoError(t, tp.OnDeleted(&config.RuleSet{MetaData: config.MetaData{Source: "bar"}}))
+			},
+			assert: func(t *testing.T, status Status) {
+				t.Helper()
+
+				assert.Equal(t, 1, status.RuleSets)
+				assert.False(t, status.Ready)
+				assert.Equal(t, "test error", status.LastError)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			tracker := NewRegistry(zerolog.Nop()).Register("test")
+
+			// WHEN
+			tc.track(t, tracker)
+
+			// THEN
+			tc.assert(t, tracker.Status())
+		})
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var registry *Registry
+
+	processor := mocks.NewRuleSetProcessorMock(t)
+
+	// WHEN
+	tracker := registry.Register("test")
+
+	// THEN
+	require.Nil(t, tracker)
+	assert.NotPanics(t, func() { tracker.Synced("foo") })
+	assert.NotPanics(t, func() { tracker.Failed(errors.New("test error")) })
+	assert.Equal(t, processor, tracker.Processor(processor))
+}
+
+func TestRegistryReady(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	registry := NewRegistry(zerolog.Nop())
+
+	// WHEN & THEN
+	assert.True(t, registry.Ready())
+
+	foo := registry.Register("foo")
+	bar := registry.Register("bar", "src1", "src2")
+
+	assert.False(t, registry.Ready())
+
+	foo.Synced("foo")
+	assert.False(t, registry.Ready())
+
+	bar.Failed(errors.New("test error"))
+	assert.False(t, registry.Ready())
+
+	bar.Synced("src1")
+	bar.Synced("src1")
+	assert.False(t, registry.Ready())
+
+	bar.Synced("src2")
+	assert.True(t, registry.Ready())
+
+	status := registry.Status()
+	require.Len(t, status, 2)
+	assert.Equal(t, "foo", status[0].Provider)
+	assert.Equal(t, "bar", status[1].Provider)
+}
+
+func TestRegistryMetrics(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	exp := metric.NewManualReader()
+	registry := &Registry{}
+
+	require.NoError(t, registry.registerMetrics(metric.NewMeterProvider(metric.WithReader(exp))))
+
+	foo := registry.Register("foo")
+	foo.Synced("foo")
+	foo.Failed(errors.New("test error"))
+
+	registry.Register("bar")
+
+	// WHEN
+	var rm metricdata.ResourceMetrics
+
+	err := exp.Collect(context.TODO(), &rm)
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	metrics := map[string]metricdata.Gauge[int64]{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data.(metricdata.Gauge[int64]) // nolint: forcetypeassert
+	}
+
+	require.Len(t, metrics, 3)
+	assert.Len(t, metrics["rules.provider.rule_sets"].DataPoints, 2)
+	assert.Len(t, metrics["rules.provider.last_sync"].DataPoints, 1)
+	assert.Len(t, metrics["rules.provider.last_error"].DataPoints, 1)
+
+	provider, ok := metrics["rules.provider.last_sync"].DataPoints[0].Attributes.Value(providerAttrKey)
+	require.True(t, ok)
+	assert.Equal(t, "foo", provider.AsString())
+}
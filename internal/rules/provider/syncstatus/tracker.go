@@ -0,0 +1,149 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syncstatus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+// Tracker records the synchronization state of a single rule provider. All methods are nil safe.
+type Tracker struct {
+	provider string
+
+	mut       sync.Mutex
+	lastSync  time.Time
+	lastErr   error
+	lastErrAt time.Time
+	ruleSets  map[string]struct{}
+	pending   map[string]struct{}
+}
+
+// Synced records a successful synchronization of the given source, even if it did not result
+// in any changes.
+func (t *Tracker) Synced(src string) {
+	if t == nil {
+		return
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.lastSync = time.Now()
+	delete(t.pending, src)
+}
+
+// Failed records a failed attempt to fetch, parse or load a rule set.
+func (t *Tracker) Failed(err error) {
+	if t == nil || err == nil {
+		return
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.lastErr = err
+	t.lastErrAt = time.Now()
+}
+
+// Status returns the current state of the tracked provider.
+func (t *Tracker) Status() Status {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	status := Status{
+		Provider: t.provider,
+		Ready:    !t.lastSync.IsZero() && len(t.pending) == 0,
+		RuleSets: len(t.ruleSets),
+	}
+
+	if !t.lastSync.IsZero() {
+		lastSync := t.lastSync
+		status.LastSyncedAt = &lastSync
+	}
+
+	if t.lastErr != nil {
+		lastErrAt := t.lastErrAt
+		status.LastError = t.lastErr.Error()
+		status.LastErrorAt = &lastErrAt
+	}
+
+	return status
+}
+
+// Processor decorates the given processor to count the rule sets loaded by the tracked provider
+// and to record failures while applying changes. Successful synchronizations must be reported
+// by the provider via Synced.
+func (t *Tracker) Processor(processor rule.SetProcessor) rule.SetProcessor {
+	if t == nil {
+		return processor
+	}
+
+	return &trackingProcessor{p: processor, t: t}
+}
+
+func (t *Tracker) loaded(src string, err error) error {
+	if err != nil {
+		t.Failed(err)
+
+		return err
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.ruleSets[src] = struct{}{}
+
+	return nil
+}
+
+func (t *Tracker) dropped(src string, err error) error {
+	if err != nil {
+		t.Failed(err)
+
+		return err
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	delete(t.ruleSets, src)
+
+	return nil
+}
+
+type trackingProcessor struct {
+	p rule.SetProcessor
+	t *Tracker
+}
+
+func (p *trackingProcessor) OnCreated(ruleSet *config.RuleSet) error {
+	return p.t.loaded(ruleSet.Source, p.p.OnCreated(ruleSet))
+}
+
+func (p *trackingProcessor) OnUpdated(ruleSet *config.RuleSet) error {
+	return p.t.loaded(ruleSet.Source, p.p.OnUpdated(ruleSet))
+}
+
+func (p *trackingProcessor) OnDeleted(ruleSet *config.RuleSet) error {
+	return p.t.dropped(ruleSet.Source, p.p.OnDeleted(ruleSet))
+}
+
+func (p *trackingProcessor) Conflicts(srcID string) []rule.Conflict { return p.p.Conflicts(srcID) }
@@ -0,0 +1,73 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/dadrus/heimdall/internal/config"
+	config2 "github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+// reloadableRuleFactory delegates to the rule factory created from the current configuration.
+// It is shared by all components creating rules, like the rule set processor and the admission
+// controller of the kubernetes provider, so that all of them make use of the same mechanisms
+// after the config file has been reloaded.
+type reloadableRuleFactory struct {
+	mut sync.RWMutex
+	f   rule.Factory
+}
+
+func newReloadableRuleFactory(
+	hf mechanisms.Factory,
+	conf *config.Configuration,
+	mode config.OperationMode,
+	logger zerolog.Logger,
+) (*reloadableRuleFactory, error) {
+	factory, err := NewRuleFactory(hf, conf, mode, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reloadableRuleFactory{f: factory}, nil
+}
+
+func (r *reloadableRuleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule) (rule.Rule, error) {
+	return r.current().CreateRule(version, srcID, ruleConfig)
+}
+
+func (r *reloadableRuleFactory) DefaultRule() rule.Rule { return r.current().DefaultRule() }
+
+func (r *reloadableRuleFactory) HasDefaultRule() bool { return r.current().HasDefaultRule() }
+
+func (r *reloadableRuleFactory) current() rule.Factory {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	return r.f
+}
+
+func (r *reloadableRuleFactory) set(factory rule.Factory) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.f = factory
+}
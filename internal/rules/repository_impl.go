@@ -19,8 +19,12 @@ package rules
 import (
 	"bytes"
 	"context"
-	"net/url"
+	"encoding/hex"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -37,43 +41,71 @@ func newRepository(
 	ruleFactory rule.Factory,
 	logger zerolog.Logger,
 ) *repository {
-	return &repository{
-		dr: x.IfThenElseExec(ruleFactory.HasDefaultRule(),
-			func() rule.Rule { return ruleFactory.DefaultRule() },
-			func() rule.Rule { return nil }),
+	repo := &repository{
 		logger: logger,
 		queue:  queue,
 		quit:   make(chan bool),
 	}
+
+	repo.setDefaultRule(x.IfThenElseExec(ruleFactory.HasDefaultRule(),
+		func() rule.Rule { return ruleFactory.DefaultRule() },
+		func() rule.Rule { return nil }))
+
+	return repo
 }
 
 type repository struct {
-	dr     rule.Rule
+	dr     atomic.Pointer[rule.Rule]
 	logger zerolog.Logger
 
-	rules []rule.Rule
-	mutex sync.RWMutex
+	rules    []rule.Rule
+	ruleSets map[string]rule.SetInfo
+	index    atomic.Pointer[ruleIndex]
+	mutex    sync.Mutex
 
 	queue event.RuleSetChangedEventQueue
 	quit  chan bool
 }
 
-func (r *repository) FindRule(requestURL *url.URL) (rule.Rule, error) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+func (r *repository) FindRule(request *heimdall.Request) (rule.Rule, error) {
+	dr := r.defaultRule()
 
-	for _, rul := range r.rules {
-		if rul.MatchesURL(requestURL) {
-			return rul, nil
-		}
+	rul, shadows, urlMatched := r.index.Load().find(request)
+	if rul != nil {
+		return withShadowRules(rul, shadows), nil
+	}
+
+	rul, err := r.findFallback(dr, request, urlMatched)
+	if err != nil && len(shadows) != 0 {
+		// rules operated in shadow mode never handle a request themselves. These are executed
+		// to record their decisions nevertheless, before the request is rejected
+		return nil, &unhandledRequestError{error: err, shadows: shadows}
 	}
 
-	if r.dr != nil {
-		return r.dr, nil
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, errorchain.NewWithMessagef(heimdall.ErrNoRuleFound,
-		"no applicable rule found for %s", requestURL.String())
+	return withShadowRules(rul, shadows), nil
+}
+
+func (r *repository) findFallback(dr rule.Rule, request *heimdall.Request, urlMatched bool) (rule.Rule, error) {
+	if urlMatched {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrMethodNotAllowed,
+			"no rule matching %s method found for %s", request.Method, request.URL.String())
+	}
+
+	if dr == nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrNoRuleFound,
+			"no applicable rule found for %s", request.URL.String())
+	}
+
+	if !dr.MatchesMethod(request.Method) {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrMethodNotAllowed,
+			"default rule doesn't match %s method", request.Method)
+	}
+
+	return dr, nil
 }
 
 func (r *repository) Start(_ context.Context) error {
@@ -109,7 +141,11 @@ func (r *repository) watchRuleSetChanges() {
 				r.updateRuleSet(evt.Source, evt.Rules)
 			case event.Remove:
 				r.deleteRuleSet(evt.Source)
+			case event.Reload:
+				r.reloadRules(evt.Rules, evt.Default)
 			}
+
+			r.updateRuleSetInfo(evt)
 		case <-r.quit:
 			r.logger.Info().Msg("Rule definition loader stopped")
 
@@ -127,6 +163,8 @@ func (r *repository) addRuleSet(srcID string, rules []rule.Rule) {
 
 	// add them
 	r.addRules(rules)
+
+	r.rebuildIndex()
 }
 
 func (r *repository) updateRuleSet(srcID string, rules []rule.Rule) {
@@ -201,6 +239,8 @@ func (r *repository) updateRuleSet(srcID string, rules []rule.Rule) {
 
 		// add new rules
 		r.addRules(newRules)
+
+		r.rebuildIndex()
 	}()
 }
 
@@ -215,6 +255,115 @@ func (r *repository) deleteRuleSet(srcID string) {
 
 	// remove them
 	r.removeRules(applicable)
+
+	r.rebuildIndex()
+}
+
+func (r *repository) reloadRules(rules []rule.Rule, dr rule.Rule) {
+	r.logger.Info().Msg("Replacing all rules")
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.rules = slices.Clone(rules)
+	r.setDefaultRule(dr)
+
+	r.rebuildIndex()
+}
+
+func (r *repository) defaultRule() rule.Rule {
+	if dr := r.dr.Load(); dr != nil {
+		return *dr
+	}
+
+	return nil
+}
+
+func (r *repository) setDefaultRule(dr rule.Rule) { r.dr.Store(&dr) }
+
+func (r *repository) updateRuleSetInfo(evt event.RuleSetChanged) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	switch evt.ChangeType {
+	case event.Remove:
+		delete(r.ruleSets, evt.Source)
+
+		return
+	case event.Reload:
+		now := time.Now()
+
+		for src, info := range r.ruleSets {
+			info.LoadedAt = now
+			r.ruleSets[src] = info
+		}
+
+		return
+	}
+
+	if r.ruleSets == nil {
+		r.ruleSets = make(map[string]rule.SetInfo)
+	}
+
+	r.ruleSets[evt.Source] = rule.SetInfo{
+		Source:   evt.Source,
+		Name:     evt.Name,
+		Hash:     hex.EncodeToString(evt.Hash),
+		ModTime:  evt.ModTime,
+		Revision: evt.Revision,
+		LoadedAt: time.Now(),
+		Rules:    slicex.Map(evt.Rules, func(r rule.Rule) string { return r.ID() }),
+	}
+}
+
+func (r *repository) RuleSets() []rule.SetInfo {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	sets := make([]rule.SetInfo, 0, len(r.ruleSets))
+	for _, info := range r.ruleSets {
+		sets = append(sets, info)
+	}
+
+	slices.SortFunc(sets, func(a, b rule.SetInfo) int { return strings.Compare(a.Source, b.Source) })
+
+	return sets
+}
+
+func (r *repository) Rules() []rule.Description {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rules := make([]rule.Description, 0, len(r.rules)+1)
+	for _, rul := range r.rules {
+		rules = append(rules, describeRule(rul))
+	}
+
+	slices.SortFunc(rules, func(a, b rule.Description) int {
+		return x.IfThenElseExec(a.Source == b.Source,
+			func() int { return strings.Compare(a.ID, b.ID) },
+			func() int { return strings.Compare(a.Source, b.Source) })
+	})
+
+	if dr := r.defaultRule(); dr != nil {
+		rules = append(rules, describeRule(dr))
+	}
+
+	return rules
+}
+
+func describeRule(rul rule.Rule) rule.Description {
+	if impl, ok := rul.(*ruleImpl); ok {
+		return impl.describe()
+	}
+
+	return rule.Description{ID: rul.ID(), Source: rul.SrcID()}
+}
+
+// rebuildIndex replaces the index used by FindRule with one reflecting the current rules.
+// It must be called with the mutex being held.
+func (r *repository) rebuildIndex() {
+	r.index.Store(newRuleIndex(r.rules))
 }
 
 func (r *repository) addRules(rules []rule.Rule) {
@@ -18,6 +18,7 @@ package rules
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 	"testing"
 	"time"
@@ -28,7 +29,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/event"
+	rulemocks "github.com/dadrus/heimdall/internal/rules/mocks"
 	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
 	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
@@ -64,14 +67,17 @@ func TestRepositoryFindRule(t *testing.T) {
 
 	for _, tc := range []struct {
 		uc               string
-		requestURL       *url.URL
+		request          *heimdall.Request
 		addRules         func(t *testing.T, repo *repository)
 		configureFactory func(t *testing.T, factory *mocks.FactoryMock)
 		assert           func(t *testing.T, err error, rul rule.Rule)
 	}{
 		{
-			uc:         "no matching rule without default rule",
-			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
+			uc: "no matching rule without default rule",
+			request: &heimdall.Request{
+				Method: http.MethodGet,
+				URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"}},
+			},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
 
@@ -85,24 +91,51 @@ func TestRepositoryFindRule(t *testing.T) {
 			},
 		},
 		{
-			uc:         "no matching rule with default rule",
-			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
+			uc: "no matching rule with default rule",
+			request: &heimdall.Request{
+				Method: http.MethodGet,
+				URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"}},
+			},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
 
 				factory.EXPECT().HasDefaultRule().Return(true)
-				factory.EXPECT().DefaultRule().Return(&ruleImpl{id: "test", isDefault: true})
+				factory.EXPECT().DefaultRule().Return(
+					&ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodGet}})
 			},
 			assert: func(t *testing.T, err error, rul rule.Rule) {
 				t.Helper()
 
 				require.NoError(t, err)
-				require.Equal(t, &ruleImpl{id: "test", isDefault: true}, rul)
+				require.Equal(t, &ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodGet}}, rul)
+			},
+		},
+		{
+			uc: "no matching rule with default rule not matching the method",
+			request: &heimdall.Request{
+				Method: http.MethodPost,
+				URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"}},
+			},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(true)
+				factory.EXPECT().DefaultRule().Return(
+					&ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodGet}})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrMethodNotAllowed)
 			},
 		},
 		{
-			uc:         "matching rule",
-			requestURL: &url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"},
+			uc: "matching rule",
+			request: &heimdall.Request{
+				Method: http.MethodGet,
+				URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"}},
+			},
 			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
 				t.Helper()
 
@@ -111,10 +144,12 @@ func TestRepositoryFindRule(t *testing.T) {
 			addRules: func(t *testing.T, repo *repository) {
 				t.Helper()
 
-				repo.rules = append(repo.rules,
+				repo.addRuleSet("bar", []rule.Rule{
 					&ruleImpl{
-						id:    "test1",
-						srcID: "bar",
+						id:        "test1",
+						srcID:     "bar",
+						methods:   []string{http.MethodGet},
+						urlPrefix: "http://heimdall.test.local/baz",
 						urlMatcher: func() patternmatcher.PatternMatcher {
 							matcher, _ := patternmatcher.NewPatternMatcher("glob",
 								"http://heimdall.test.local/baz")
@@ -123,8 +158,10 @@ func TestRepositoryFindRule(t *testing.T) {
 						}(),
 					},
 					&ruleImpl{
-						id:    "test2",
-						srcID: "baz",
+						id:        "test2",
+						srcID:     "baz",
+						methods:   []string{http.MethodGet},
+						urlPrefix: "http://foo.bar/baz",
 						urlMatcher: func() patternmatcher.PatternMatcher {
 							matcher, _ := patternmatcher.NewPatternMatcher("glob",
 								"http://foo.bar/baz")
@@ -132,7 +169,7 @@ func TestRepositoryFindRule(t *testing.T) {
 							return matcher
 						}(),
 					},
-				)
+				})
 			},
 			assert: func(t *testing.T, err error, rul rule.Rule) {
 				t.Helper()
@@ -146,6 +183,83 @@ func TestRepositoryFindRule(t *testing.T) {
 				require.Equal(t, "baz", impl.srcID)
 			},
 		},
+		{
+			uc: "matching rule selected by method",
+			request: &heimdall.Request{
+				Method: http.MethodPost,
+				URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/orders/1"}},
+			},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(false)
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				matcher, err := patternmatcher.NewPatternMatcher("glob", "http://foo.bar/orders/<**>")
+				require.NoError(t, err)
+
+				repo.addRuleSet("bar", []rule.Rule{
+					&ruleImpl{
+						id:         "get",
+						srcID:      "bar",
+						methods:    []string{http.MethodGet},
+						urlPrefix:  "http://foo.bar/orders/",
+						urlMatcher: matcher,
+					},
+					&ruleImpl{
+						id:         "post",
+						srcID:      "bar",
+						methods:    []string{http.MethodPost},
+						urlPrefix:  "http://foo.bar/orders/",
+						urlMatcher: matcher,
+					},
+				})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Equal(t, "post", rul.ID())
+			},
+		},
+		{
+			uc: "matching url, but no rule matching the method",
+			request: &heimdall.Request{
+				Method: http.MethodDelete,
+				URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/orders/1"}},
+			},
+			configureFactory: func(t *testing.T, factory *mocks.FactoryMock) {
+				t.Helper()
+
+				factory.EXPECT().HasDefaultRule().Return(true)
+				factory.EXPECT().DefaultRule().Return(
+					&ruleImpl{id: "test", isDefault: true, methods: []string{http.MethodDelete}})
+			},
+			addRules: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				matcher, err := patternmatcher.NewPatternMatcher("glob", "http://foo.bar/orders/<**>")
+				require.NoError(t, err)
+
+				repo.addRuleSet("bar", []rule.Rule{
+					&ruleImpl{
+						id:         "get",
+						srcID:      "bar",
+						methods:    []string{http.MethodGet},
+						urlPrefix:  "http://foo.bar/orders/",
+						urlMatcher: matcher,
+					},
+				})
+			},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrMethodNotAllowed)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -161,7 +275,7 @@ func TestRepositoryFindRule(t *testing.T) {
 			addRules(t, repo)
 
 			// WHEN
-			rul, err := repo.FindRule(tc.requestURL)
+			rul, err := repo.FindRule(tc.request)
 
 			// THEN
 			tc.assert(t, err, rul)
@@ -333,6 +447,39 @@ func TestRepositoryRuleSetLifecycleManagement(t *testing.T) {
 				assert.Equal(t, &ruleImpl{id: "rule:foo4", srcID: "test2", hash: []byte{4}}, repo.rules[3])
 			},
 		},
+		{
+			uc: "rules and default rule reloaded",
+			events: []event.RuleSetChanged{
+				{
+					Source:     "test1",
+					ChangeType: event.Create,
+					Rules:      []rule.Rule{&ruleImpl{id: "rule:bar", srcID: "test1", hash: []byte{1}}},
+				},
+				{
+					Source:     "test2",
+					ChangeType: event.Create,
+					Rules:      []rule.Rule{&ruleImpl{id: "rule:foo", srcID: "test2", hash: []byte{2}}},
+				},
+				{
+					Source: "config",
+					Rules: []rule.Rule{
+						&ruleImpl{id: "rule:bar", srcID: "test1", hash: []byte{1}, priority: 1},
+						&ruleImpl{id: "rule:foo", srcID: "test2", hash: []byte{2}, priority: 2},
+					},
+					Default:    &ruleImpl{id: "default", srcID: "config", isDefault: true},
+					ChangeType: event.Reload,
+				},
+			},
+			assert: func(t *testing.T, repo *repository) {
+				t.Helper()
+
+				require.Len(t, repo.rules, 2)
+				assert.Equal(t, &ruleImpl{id: "rule:bar", srcID: "test1", hash: []byte{1}, priority: 1}, repo.rules[0])
+				assert.Equal(t, &ruleImpl{id: "rule:foo", srcID: "test2", hash: []byte{2}, priority: 2}, repo.rules[1])
+				assert.Equal(t, &ruleImpl{id: "default", srcID: "config", isDefault: true}, repo.defaultRule())
+				assert.Len(t, repo.RuleSets(), 2)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -358,3 +505,112 @@ func TestRepositoryRuleSetLifecycleManagement(t *testing.T) {
 		})
 	}
 }
+
+func TestRepositoryInventory(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := context.Background()
+	modTime := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	authn := rulemocks.NewSubjectCreatorMock(t)
+	authn.EXPECT().ID().Return("jwt")
+
+	authz := rulemocks.NewSubjectHandlerMock(t)
+	authz.EXPECT().ID().Return("allow_all")
+
+	finalizer := rulemocks.NewSubjectHandlerMock(t)
+	finalizer.EXPECT().ID().Return("header")
+
+	errHandler := rulemocks.NewErrorHandlerMock(t)
+	errHandler.EXPECT().ID().Return("redirect")
+
+	backend := &config.Backend{Host: "foo.bar"}
+	rul := &ruleImpl{
+		id:       "rule:foo",
+		srcID:    "test2",
+		priority: 10,
+		hash:     []byte{1, 2},
+		matcher:  config.Matcher{URL: "http://foo.bar/<**>", Strategy: "glob"},
+		methods:  []string{http.MethodGet},
+		backend:  backend,
+		sc:       compositeSubjectCreator{authn},
+		sh: compositeSubjectHandler{
+			&conditionalSubjectHandler{
+				typ: "authorizer",
+				h:   &shadowSubjectHandler{h: authz},
+				c:   defaultExecutionCondition{},
+			},
+		},
+		fi: compositeSubjectHandler{
+			&conditionalSubjectHandler{typ: "finalizer", h: finalizer, c: defaultExecutionCondition{}},
+		},
+		eh: compositeErrorHandler{errHandler},
+	}
+
+	queue := make(event.RuleSetChangedEventQueue, 10)
+	defer close(queue)
+
+	repo := newRepository(queue, &ruleFactory{}, log.Logger)
+	repo.setDefaultRule(&ruleImpl{id: "default", srcID: "config", isDefault: true, methods: []string{http.MethodGet}})
+	require.NoError(t, repo.Start(ctx))
+
+	defer repo.Stop(ctx)
+
+	// WHEN
+	queue <- event.RuleSetChanged{
+		Source:     "test2",
+		Name:       "foo",
+		Hash:       []byte{0xab, 0xcd},
+		ModTime:    modTime,
+		ChangeType: event.Create,
+		Rules:      []rule.Rule{rul},
+	}
+	queue <- event.RuleSetChanged{
+		Source:     "test1",
+		ChangeType: event.Create,
+		Rules:      []rule.Rule{&ruleImpl{id: "rule:bar", srcID: "test1"}},
+	}
+	queue <- event.RuleSetChanged{
+		Source:     "test3",
+		ChangeType: event.Create,
+		Rules:      []rule.Rule{&ruleImpl{id: "rule:baz", srcID: "test3"}},
+	}
+	queue <- event.RuleSetChanged{Source: "test3", ChangeType: event.Remove}
+
+	time.Sleep(100 * time.Millisecond)
+
+	// THEN
+	sets := repo.RuleSets()
+	require.Len(t, sets, 2)
+	assert.Equal(t, "test1", sets[0].Source)
+	assert.Equal(t, []string{"rule:bar"}, sets[0].Rules)
+	assert.Equal(t, "test2", sets[1].Source)
+	assert.Equal(t, "foo", sets[1].Name)
+	assert.Equal(t, "abcd", sets[1].Hash)
+	assert.Equal(t, modTime, sets[1].ModTime)
+	assert.False(t, sets[1].LoadedAt.IsZero())
+	assert.Equal(t, []string{"rule:foo"}, sets[1].Rules)
+
+	rules := repo.Rules()
+	require.Len(t, rules, 3)
+	assert.Equal(t, "rule:bar", rules[0].ID)
+	assert.Equal(t, "test1", rules[0].Source)
+	assert.Equal(t, rule.Description{
+		ID:       "rule:foo",
+		Source:   "test2",
+		Hash:     "0102",
+		Priority: 10,
+		Match:    config.Matcher{URL: "http://foo.bar/<**>", Strategy: "glob"},
+		Methods:  []string{http.MethodGet},
+		Backend:  backend,
+		Execute: []rule.MechanismDescription{
+			{Type: "authenticator", ID: "jwt"},
+			{Type: "authorizer", ID: "allow_all", Shadow: true},
+			{Type: "finalizer", ID: "header"},
+		},
+		OnError: []rule.MechanismDescription{{Type: "error_handler", ID: "redirect"}},
+	}, rules[1])
+	assert.Equal(t, "default", rules[2].ID)
+	assert.Equal(t, "config", rules[2].Source)
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gobwas/glob"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+type requestMatcher interface {
+	Matches(req *heimdall.Request) bool
+}
+
+// compositeRequestMatcher matches a request only if all of its matchers match it.
+type compositeRequestMatcher []requestMatcher
+
+func (c compositeRequestMatcher) Matches(req *heimdall.Request) bool {
+	for _, matcher := range c {
+		if !matcher.Matches(req) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type valuesMatcher []glob.Glob
+
+func (m valuesMatcher) matchesAny(values ...string) bool {
+	for _, value := range values {
+		for _, pattern := range m {
+			if pattern.Match(value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type headerMatcher struct {
+	name     string
+	patterns valuesMatcher
+}
+
+func (m *headerMatcher) Matches(req *heimdall.Request) bool {
+	value := req.Header(m.name)
+
+	return len(value) != 0 && m.patterns.matchesAny(value)
+}
+
+type queryParamMatcher struct {
+	name     string
+	patterns valuesMatcher
+}
+
+func (m *queryParamMatcher) Matches(req *heimdall.Request) bool {
+	return m.patterns.matchesAny(req.URL.Query()[m.name]...)
+}
+
+type clientIPMatcher []*net.IPNet
+
+func (m clientIPMatcher) Matches(req *heimdall.Request) bool {
+	// the first entry is the ultimate client
+	if len(req.ClientIP) == 0 {
+		return false
+	}
+
+	ip := net.ParseIP(req.ClientIP[0])
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range m {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newRequestMatcher(conf config.Matcher) (compositeRequestMatcher, error) {
+	var matchers compositeRequestMatcher
+
+	for name, values := range conf.Headers {
+		patterns, err := compileValuePatterns(values)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"bad value pattern for header %s", name).CausedBy(err)
+		}
+
+		matchers = append(matchers, &headerMatcher{name: name, patterns: patterns})
+	}
+
+	for name, values := range conf.QueryParams {
+		patterns, err := compileValuePatterns(values)
+		if err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"bad value pattern for query parameter %s", name).CausedBy(err)
+		}
+
+		matchers = append(matchers, &queryParamMatcher{name: name, patterns: patterns})
+	}
+
+	if len(conf.ClientIPs) != 0 {
+		ipMatcher := make(clientIPMatcher, len(conf.ClientIPs))
+
+		for idx, value := range conf.ClientIPs {
+			ipNet, err := parseCIDR(value)
+			if err != nil {
+				return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+					"bad client ip definition %s", value).CausedBy(err)
+			}
+
+			ipMatcher[idx] = ipNet
+		}
+
+		matchers = append(matchers, ipMatcher)
+	}
+
+	return matchers, nil
+}
+
+func compileValuePatterns(values []string) (valuesMatcher, error) {
+	if len(values) == 0 {
+		return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration, "no values defined")
+	}
+
+	patterns := make(valuesMatcher, len(values))
+
+	for idx, value := range values {
+		pattern, err := glob.Compile(value)
+		if err != nil {
+			return nil, err
+		}
+
+		patterns[idx] = pattern
+	}
+
+	return patterns, nil
+}
+
+func parseCIDR(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, &net.ParseError{Type: "IP address", Text: value}
+		}
+
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(value)
+
+	return ipNet, err
+}
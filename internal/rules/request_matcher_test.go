@@ -0,0 +1,194 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+func TestNewRequestMatcher(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		conf   config.Matcher
+		assert func(t *testing.T, err error, matcher compositeRequestMatcher)
+	}{
+		{
+			uc: "without any conditions",
+			assert: func(t *testing.T, err error, matcher compositeRequestMatcher) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Empty(t, matcher)
+			},
+		},
+		{
+			uc:   "header without values",
+			conf: config.Matcher{Headers: map[string][]string{"X-Foo": {}}},
+			assert: func(t *testing.T, err error, _ compositeRequestMatcher) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "X-Foo")
+			},
+		},
+		{
+			uc:   "query parameter with bad pattern",
+			conf: config.Matcher{QueryParams: map[string][]string{"foo": {"[a"}}},
+			assert: func(t *testing.T, err error, _ compositeRequestMatcher) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "foo")
+			},
+		},
+		{
+			uc:   "bad client ip",
+			conf: config.Matcher{ClientIPs: []string{"10.0.0.0/33"}},
+			assert: func(t *testing.T, err error, _ compositeRequestMatcher) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			},
+		},
+		{
+			uc: "all conditions",
+			conf: config.Matcher{
+				Headers:     map[string][]string{"X-Foo": {"bar"}},
+				QueryParams: map[string][]string{"foo": {"bar"}},
+				ClientIPs:   []string{"10.0.0.0/8", "192.168.1.1", "::1"},
+			},
+			assert: func(t *testing.T, err error, matcher compositeRequestMatcher) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Len(t, matcher, 3)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			matcher, err := newRequestMatcher(tc.conf)
+
+			tc.assert(t, err, matcher)
+		})
+	}
+}
+
+func TestRequestMatcherMatches(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := newRequestMatcher(config.Matcher{
+		Headers: map[string][]string{
+			"Content-Type":  {"application/json*", "application/yaml"},
+			"X-Api-Version": {"2"},
+		},
+		QueryParams: map[string][]string{"format": {"full", "short"}},
+		ClientIPs:   []string{"10.0.0.0/8", "192.168.1.1"},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc          string
+		contentType string
+		apiVersion  string
+		query       string
+		clientIP    []string
+		matches     bool
+	}{
+		{
+			uc:          "all conditions satisfied",
+			contentType: "application/json; charset=utf-8",
+			apiVersion:  "2",
+			query:       "format=full",
+			clientIP:    []string{"10.1.2.3", "192.168.2.1"},
+			matches:     true,
+		},
+		{
+			uc:          "all conditions satisfied using other values",
+			contentType: "application/yaml",
+			apiVersion:  "2",
+			query:       "format=foo&format=short",
+			clientIP:    []string{"192.168.1.1"},
+			matches:     true,
+		},
+		{
+			uc:          "header not matching",
+			contentType: "text/plain",
+			apiVersion:  "2",
+			query:       "format=full",
+			clientIP:    []string{"10.1.2.3"},
+		},
+		{
+			uc:          "header missing",
+			contentType: "application/json",
+			query:       "format=full",
+			clientIP:    []string{"10.1.2.3"},
+		},
+		{
+			uc:          "query parameter missing",
+			contentType: "application/json",
+			apiVersion:  "2",
+			clientIP:    []string{"10.1.2.3"},
+		},
+		{
+			uc:          "client ip not matching",
+			contentType: "application/json",
+			apiVersion:  "2",
+			query:       "format=full",
+			clientIP:    []string{"192.168.1.2", "10.1.2.3"},
+		},
+		{
+			uc:          "client ip not known",
+			contentType: "application/json",
+			apiVersion:  "2",
+			query:       "format=full",
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			reqf := mocks.NewRequestFunctionsMock(t)
+			reqf.EXPECT().Header("Content-Type").Return(tc.contentType).Maybe()
+			reqf.EXPECT().Header("X-Api-Version").Return(tc.apiVersion).Maybe()
+
+			req := &heimdall.Request{
+				RequestFunctions: reqf,
+				URL: &heimdall.URL{URL: url.URL{
+					Scheme: "http", Host: "foo.bar", Path: "/baz", RawQuery: tc.query,
+				}},
+				ClientIP: tc.clientIP,
+			}
+
+			// WHEN
+			matches := matcher.Matches(req)
+
+			// THEN
+			assert.Equal(t, tc.matches, matches)
+		})
+	}
+}
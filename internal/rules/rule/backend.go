@@ -23,5 +23,13 @@ import (
 //go:generate mockery --name Backend --structname BackendMock
 
 type Backend interface {
+	// URL returns the url to forward the request to. If multiple upstreams are configured,
+	// the first call selects the upstream to use.
 	URL() *url.URL
+	// HostHeader returns the value of the Host header to be used while forwarding the request.
+	// If empty, the host of the url returned by URL is used.
+	HostHeader() string
+	// Done reports the outcome of forwarding the request to the selected upstream. A
+	// non nil error marks the communication with that upstream as failed.
+	Done(err error)
 }
@@ -0,0 +1,39 @@
+// Copyright 2022 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rule
+
+import (
+	"fmt"
+
+	"github.com/dadrus/heimdall/internal/x"
+)
+
+// Conflict describes a rule shadowed by another rule taking precedence over it. A fully
+// shadowed rule is unreachable. A partially shadowed rule is used only for those requests,
+// which are not matched by the shadowing rule (e.g. due to its methods or request conditions).
+type Conflict struct {
+	RuleID          string
+	SrcID           string
+	ShadowedByID    string
+	ShadowedBySrcID string
+	Partial         bool
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("rule %s from %s is %s shadowed by rule %s from %s",
+		c.RuleID, c.SrcID, x.IfThenElse(c.Partial, "partially", "fully"), c.ShadowedByID, c.ShadowedBySrcID)
+}
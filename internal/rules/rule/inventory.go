@@ -0,0 +1,63 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rule
+
+import (
+	"time"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+)
+
+//go:generate mockery --name Inventory --structname InventoryMock
+
+// Inventory provides read access to the currently loaded rule sets and rules.
+type Inventory interface {
+	RuleSets() []SetInfo
+	Rules() []Description
+}
+
+// SetInfo describes a loaded rule set.
+type SetInfo struct {
+	Source   string    `json:"source"`
+	Name     string    `json:"name,omitempty"`
+	Hash     string    `json:"hash,omitempty"`
+	ModTime  time.Time `json:"mod_time"`
+	Revision string    `json:"revision,omitempty"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Rules    []string  `json:"rules"`
+}
+
+// MechanismDescription describes a mechanism used in a pipeline of a rule.
+type MechanismDescription struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Shadow bool   `json:"shadow,omitempty"`
+}
+
+// Description describes a loaded rule.
+type Description struct {
+	ID       string                 `json:"id"`
+	Source   string                 `json:"source"`
+	Hash     string                 `json:"hash,omitempty"`
+	Priority int                    `json:"priority,omitempty"`
+	Shadow   bool                   `json:"shadow,omitempty"`
+	Match    config.Matcher         `json:"match"`
+	Methods  []string               `json:"methods"`
+	Backend  *config.Backend        `json:"forward_to,omitempty"`
+	Execute  []MechanismDescription `json:"execute"`
+	OnError  []MechanismDescription `json:"on_error,omitempty"`
+}
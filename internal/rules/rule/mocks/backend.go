@@ -21,6 +21,80 @@ func (_m *BackendMock) EXPECT() *BackendMock_Expecter {
 	return &BackendMock_Expecter{mock: &_m.Mock}
 }
 
+// Done provides a mock function with given fields: err
+func (_m *BackendMock) Done(err error) {
+	_m.Called(err)
+}
+
+// BackendMock_Done_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Done'
+type BackendMock_Done_Call struct {
+	*mock.Call
+}
+
+// Done is a helper method to define mock.On call
+//   - err error
+func (_e *BackendMock_Expecter) Done(err interface{}) *BackendMock_Done_Call {
+	return &BackendMock_Done_Call{Call: _e.mock.On("Done", err)}
+}
+
+func (_c *BackendMock_Done_Call) Run(run func(err error)) *BackendMock_Done_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(error))
+	})
+	return _c
+}
+
+func (_c *BackendMock_Done_Call) Return() *BackendMock_Done_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *BackendMock_Done_Call) RunAndReturn(run func(error)) *BackendMock_Done_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HostHeader provides a mock function with given fields:
+func (_m *BackendMock) HostHeader() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// BackendMock_HostHeader_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HostHeader'
+type BackendMock_HostHeader_Call struct {
+	*mock.Call
+}
+
+// HostHeader is a helper method to define mock.On call
+func (_e *BackendMock_Expecter) HostHeader() *BackendMock_HostHeader_Call {
+	return &BackendMock_HostHeader_Call{Call: _e.mock.On("HostHeader")}
+}
+
+func (_c *BackendMock_HostHeader_Call) Run(run func()) *BackendMock_HostHeader_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *BackendMock_HostHeader_Call) Return(_a0 string) *BackendMock_HostHeader_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BackendMock_HostHeader_Call) RunAndReturn(run func() string) *BackendMock_HostHeader_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // URL provides a mock function with given fields:
 func (_m *BackendMock) URL() *url.URL {
 	ret := _m.Called()
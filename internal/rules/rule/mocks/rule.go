@@ -161,6 +161,48 @@ func (_c *RuleMock_MatchesMethod_Call) RunAndReturn(run func(string) bool) *Rule
 	return _c
 }
 
+// MatchesRequest provides a mock function with given fields: _a0
+func (_m *RuleMock) MatchesRequest(_a0 *heimdall.Request) bool {
+	ret := _m.Called(_a0)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*heimdall.Request) bool); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// RuleMock_MatchesRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MatchesRequest'
+type RuleMock_MatchesRequest_Call struct {
+	*mock.Call
+}
+
+// MatchesRequest is a helper method to define mock.On call
+//   - _a0 *heimdall.Request
+func (_e *RuleMock_Expecter) MatchesRequest(_a0 interface{}) *RuleMock_MatchesRequest_Call {
+	return &RuleMock_MatchesRequest_Call{Call: _e.mock.On("MatchesRequest", _a0)}
+}
+
+func (_c *RuleMock_MatchesRequest_Call) Run(run func(_a0 *heimdall.Request)) *RuleMock_MatchesRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*heimdall.Request))
+	})
+	return _c
+}
+
+func (_c *RuleMock_MatchesRequest_Call) Return(_a0 bool) *RuleMock_MatchesRequest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *RuleMock_MatchesRequest_Call) RunAndReturn(run func(*heimdall.Request) bool) *RuleMock_MatchesRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // MatchesURL provides a mock function with given fields: _a0
 func (_m *RuleMock) MatchesURL(_a0 *url.URL) bool {
 	ret := _m.Called(_a0)
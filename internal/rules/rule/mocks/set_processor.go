@@ -5,6 +5,8 @@ package mocks
 import (
 	config "github.com/dadrus/heimdall/internal/rules/config"
 	mock "github.com/stretchr/testify/mock"
+
+	rule "github.com/dadrus/heimdall/internal/rules/rule"
 )
 
 // RuleSetProcessorMock is an autogenerated mock type for the SetProcessor type
@@ -20,6 +22,50 @@ func (_m *RuleSetProcessorMock) EXPECT() *RuleSetProcessorMock_Expecter {
 	return &RuleSetProcessorMock_Expecter{mock: &_m.Mock}
 }
 
+// Conflicts provides a mock function with given fields: srcID
+func (_m *RuleSetProcessorMock) Conflicts(srcID string) []rule.Conflict {
+	ret := _m.Called(srcID)
+
+	var r0 []rule.Conflict
+	if rf, ok := ret.Get(0).(func(string) []rule.Conflict); ok {
+		r0 = rf(srcID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]rule.Conflict)
+		}
+	}
+
+	return r0
+}
+
+// RuleSetProcessorMock_Conflicts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Conflicts'
+type RuleSetProcessorMock_Conflicts_Call struct {
+	*mock.Call
+}
+
+// Conflicts is a helper method to define mock.On call
+//   - srcID string
+func (_e *RuleSetProcessorMock_Expecter) Conflicts(srcID interface{}) *RuleSetProcessorMock_Conflicts_Call {
+	return &RuleSetProcessorMock_Conflicts_Call{Call: _e.mock.On("Conflicts", srcID)}
+}
+
+func (_c *RuleSetProcessorMock_Conflicts_Call) Run(run func(srcID string)) *RuleSetProcessorMock_Conflicts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *RuleSetProcessorMock_Conflicts_Call) Return(_a0 []rule.Conflict) *RuleSetProcessorMock_Conflicts_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *RuleSetProcessorMock_Conflicts_Call) RunAndReturn(run func(string) []rule.Conflict) *RuleSetProcessorMock_Conflicts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // OnCreated provides a mock function with given fields: ruleSet
 func (_m *RuleSetProcessorMock) OnCreated(ruleSet *config.RuleSet) error {
 	ret := _m.Called(ruleSet)
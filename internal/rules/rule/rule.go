@@ -30,4 +30,5 @@ type Rule interface {
 	Execute(ctx heimdall.Context) (Backend, error)
 	MatchesURL(match *url.URL) bool
 	MatchesMethod(method string) bool
+	MatchesRequest(request *heimdall.Request) bool
 }
@@ -24,4 +24,7 @@ type SetProcessor interface {
 	OnCreated(ruleSet *config.RuleSet) error
 	OnUpdated(ruleSet *config.RuleSet) error
 	OnDeleted(ruleSet *config.RuleSet) error
+	// Conflicts returns the conflicts detected while the rule set with the given source id
+	// has been created or updated the last time.
+	Conflicts(srcID string) []Conflict
 }
@@ -0,0 +1,120 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+// detectConflicts returns the conflicts between the given rules, which involve at least one
+// rule from the given source. The precedence of the rules is determined the same way it is
+// done by the rule index. Per shadowed rule only one conflict is reported, with a rule
+// shadowing it fully being preferred over one shadowing it partially.
+func detectConflicts(rules []rule.Rule, srcID string) []rule.Conflict {
+	var conflicts []rule.Conflict
+
+	ordered := sortByPriority(rules)
+
+	for pos, shadowed := range ordered {
+		var found *rule.Conflict
+
+		for _, shadowing := range ordered[:pos] {
+			if shadowed.SrcID() != srcID && shadowing.SrcID() != srcID {
+				continue
+			}
+
+			fully, partially := shadows(shadowing, shadowed)
+			if !fully && !partially {
+				continue
+			}
+
+			if found == nil || fully {
+				found = &rule.Conflict{
+					RuleID:          shadowed.ID(),
+					SrcID:           shadowed.SrcID(),
+					ShadowedByID:    shadowing.ID(),
+					ShadowedBySrcID: shadowing.SrcID(),
+					Partial:         !fully,
+				}
+			}
+
+			if fully {
+				break
+			}
+		}
+
+		if found != nil {
+			conflicts = append(conflicts, *found)
+		}
+	}
+
+	return conflicts
+}
+
+// shadows tells whether the first rule, taking precedence over the second one, shadows it fully
+// or partially. The second rule is shadowed fully if the first one matches all its requests.
+// It is shadowed partially if the first one matches at least some of its requests, that is if
+// the first rule serves only some of the methods of the second one, or defines further request
+// matching conditions.
+func shadows(first, second rule.Rule) (bool, bool) {
+	firstImpl, ok := first.(*ruleImpl)
+	if !ok || firstImpl.shadow {
+		// rules operated in shadow mode never prevent other rules from being executed
+		return false, false
+	}
+
+	secondImpl, ok := second.(*ruleImpl)
+	if !ok || !firstImpl.matchesAllURLsOf(secondImpl) {
+		return false, false
+	}
+
+	var sharedMethods int
+
+	for _, method := range secondImpl.methods {
+		if slices.Contains(firstImpl.methods, method) {
+			sharedMethods++
+		}
+	}
+
+	if sharedMethods == 0 {
+		return false, false
+	}
+
+	fully := sharedMethods == len(secondImpl.methods) && len(firstImpl.reqMatcher) == 0
+
+	return fully, !fully
+}
+
+// matchesAllURLsOf tells whether the url pattern of the rule matches all urls matched by the
+// url pattern of the other rule. Since patterns can't be compared in general, only the obvious
+// cases are considered: equal patterns, a pattern matching everything starting with a prefix
+// of the literal prefix of the other pattern, and a matching pattern without any wildcards.
+func (r *ruleImpl) matchesAllURLsOf(other *ruleImpl) bool {
+	switch {
+	case r.urlPattern == other.urlPattern:
+		return true
+	case r.urlCatchAll:
+		return strings.HasPrefix(other.urlPrefix, r.urlPrefix)
+	case other.urlPrefix == other.urlPattern:
+		return r.urlMatcher.Match(other.urlPattern)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+func TestDetectConflicts(t *testing.T) {
+	t.Parallel()
+
+	withSrc := func(rul *ruleImpl, srcID string) *ruleImpl {
+		rul.srcID = srcID
+
+		return rul
+	}
+
+	withMethods := func(rul *ruleImpl, methods ...string) *ruleImpl {
+		rul.methods = methods
+
+		return rul
+	}
+
+	withPriority := func(rul *ruleImpl, priority int) *ruleImpl {
+		rul.priority = priority
+
+		return rul
+	}
+
+	withConditions := func(rul *ruleImpl) *ruleImpl {
+		matcher, err := newRequestMatcher(config.Matcher{ClientIPs: []string{"10.0.0.0/8"}})
+		require.NoError(t, err)
+
+		rul.reqMatcher = matcher
+
+		return rul
+	}
+
+	for _, tc := range []struct {
+		uc        string
+		rules     []rule.Rule
+		conflicts []rule.Conflict
+	}{
+		{
+			uc: "catch all rule preceding a more specific one",
+			rules: []rule.Rule{
+				newTestRule(t, "1", "glob", "http://foo.bar/api/<**>"),
+				newTestRule(t, "2", "glob", "http://foo.bar/api/users/<*>"),
+			},
+			conflicts: []rule.Conflict{
+				{RuleID: "2", SrcID: "test", ShadowedByID: "1", ShadowedBySrcID: "test"},
+			},
+		},
+		{
+			uc: "specific rule preceding a catch all one",
+			rules: []rule.Rule{
+				newTestRule(t, "1", "glob", "http://foo.bar/api/users/<*>"),
+				newTestRule(t, "2", "glob", "http://foo.bar/api/<**>"),
+			},
+		},
+		{
+			uc: "catch all rule with an unrelated prefix",
+			rules: []rule.Rule{
+				newTestRule(t, "1", "regex", "http://foo.bar/api/<.*>"),
+				newTestRule(t, "2", "glob", "http://foo.bar/static/<*>"),
+			},
+		},
+		{
+			uc: "rule matching a literal pattern of the following rule",
+			rules: []rule.Rule{
+				newTestRule(t, "1", "template", "http://foo.bar/users/:id"),
+				newTestRule(t, "2", "glob", "http://foo.bar/users/admin"),
+			},
+			conflicts: []rule.Conflict{
+				{RuleID: "2", SrcID: "test", ShadowedByID: "1", ShadowedBySrcID: "test"},
+			},
+		},
+		{
+			uc: "same patterns with disjoint methods",
+			rules: []rule.Rule{
+				withMethods(newTestRule(t, "1", "glob", "http://foo.bar/<*>"), http.MethodGet),
+				withMethods(newTestRule(t, "2", "glob", "http://foo.bar/<*>"), http.MethodPost),
+			},
+		},
+		{
+			uc: "same patterns with overlapping methods",
+			rules: []rule.Rule{
+				withMethods(newTestRule(t, "1", "glob", "http://foo.bar/<*>"), http.MethodGet),
+				withMethods(newTestRule(t, "2", "glob", "http://foo.bar/<*>"), http.MethodGet, http.MethodPost),
+			},
+			conflicts: []rule.Conflict{
+				{RuleID: "2", SrcID: "test", ShadowedByID: "1", ShadowedBySrcID: "test", Partial: true},
+			},
+		},
+		{
+			uc: "preceding rule with further request conditions",
+			rules: []rule.Rule{
+				withConditions(newTestRule(t, "1", "glob", "http://foo.bar/<*>")),
+				newTestRule(t, "2", "glob", "http://foo.bar/<*>"),
+			},
+			conflicts: []rule.Conflict{
+				{RuleID: "2", SrcID: "test", ShadowedByID: "1", ShadowedBySrcID: "test", Partial: true},
+			},
+		},
+		{
+			uc: "full shadowing is preferred over partial one",
+			rules: []rule.Rule{
+				withConditions(newTestRule(t, "1", "glob", "http://foo.bar/<**>")),
+				newTestRule(t, "2", "glob", "http://foo.bar/<**>"),
+				newTestRule(t, "3", "glob", "http://foo.bar/<*>"),
+			},
+			conflicts: []rule.Conflict{
+				{RuleID: "2", SrcID: "test", ShadowedByID: "1", ShadowedBySrcID: "test", Partial: true},
+				{RuleID: "3", SrcID: "test", ShadowedByID: "2", ShadowedBySrcID: "test"},
+			},
+		},
+		{
+			uc: "priority resolves the conflict",
+			rules: []rule.Rule{
+				newTestRule(t, "1", "glob", "http://foo.bar/api/<**>"),
+				withPriority(newTestRule(t, "2", "glob", "http://foo.bar/api/users/<*>"), 10),
+			},
+		},
+		{
+			uc: "rule with higher priority from another source shadows a rule",
+			rules: []rule.Rule{
+				newTestRule(t, "1", "glob", "http://foo.bar/api/users/<*>"),
+				withPriority(withSrc(newTestRule(t, "2", "glob", "http://foo.bar/<**>"), "other"), 1),
+			},
+			conflicts: []rule.Conflict{
+				{RuleID: "1", SrcID: "test", ShadowedByID: "2", ShadowedBySrcID: "other"},
+			},
+		},
+		{
+			uc: "conflicts not involving the source are ignored",
+			rules: []rule.Rule{
+				withSrc(newTestRule(t, "1", "glob", "http://foo.bar/<**>"), "other"),
+				withSrc(newTestRule(t, "2", "glob", "http://foo.bar/<*>"), "other"),
+				newTestRule(t, "3", "glob", "http://foo.bar/baz"),
+			},
+			conflicts: []rule.Conflict{
+				{RuleID: "3", SrcID: "test", ShadowedByID: "1", ShadowedBySrcID: "other"},
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			conflicts := detectConflicts(tc.rules, "test")
+
+			// THEN
+			assert.Equal(t, tc.conflicts, conflicts)
+		})
+	}
+}
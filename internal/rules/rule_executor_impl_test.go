@@ -26,6 +26,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	mocks2 "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	rule2 "github.com/dadrus/heimdall/internal/rules/rule"
 	mocks4 "github.com/dadrus/heimdall/internal/rules/rule/mocks"
 )
 
@@ -48,23 +49,42 @@ func TestRuleExecutorExecute(t *testing.T) {
 			configureMocks: func(t *testing.T, ctx *mocks2.ContextMock, repo *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
+				req := &heimdall.Request{Method: http.MethodPost, URL: &heimdall.URL{URL: *matchingURL}}
+
 				ctx.EXPECT().AppContext().Return(context.Background())
-				ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodPost, URL: matchingURL})
-				repo.EXPECT().FindRule(matchingURL).Return(nil, heimdall.ErrNoRuleFound)
+				ctx.EXPECT().Request().Return(req)
+				repo.EXPECT().FindRule(req).Return(nil, heimdall.ErrNoRuleFound)
 			},
 		},
 		{
-			uc:     "rule doesn't match method",
+			uc:     "no rule matches method",
 			expErr: heimdall.ErrMethodNotAllowed,
 			configureMocks: func(t *testing.T, ctx *mocks2.ContextMock, repo *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
+				req := &heimdall.Request{Method: http.MethodPost, URL: &heimdall.URL{URL: *matchingURL}}
+
 				ctx.EXPECT().AppContext().Return(context.Background())
-				ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodPost, URL: matchingURL})
-				rule.EXPECT().MatchesMethod(http.MethodPost).Return(false)
-				rule.EXPECT().ID().Return("test_id")
-				rule.EXPECT().SrcID().Return("test_src")
-				repo.EXPECT().FindRule(matchingURL).Return(rule, nil)
+				ctx.EXPECT().Request().Return(req)
+				repo.EXPECT().FindRule(req).Return(nil, heimdall.ErrMethodNotAllowed)
+			},
+		},
+		{
+			uc:     "only rule in shadow mode matches",
+			expErr: heimdall.ErrNoRuleFound,
+			configureMocks: func(t *testing.T, ctx *mocks2.ContextMock, repo *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
+				t.Helper()
+
+				req := &heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *matchingURL}}
+
+				ctx.EXPECT().AppContext().Return(context.Background())
+				ctx.EXPECT().Request().Return(req)
+				// the shadow rule is executed to record its decision, but its result is ignored
+				rule.EXPECT().Execute(ctx).Return(mocks4.NewBackendMock(t), nil)
+				repo.EXPECT().FindRule(req).Return(nil, &unhandledRequestError{
+					error:   heimdall.ErrNoRuleFound,
+					shadows: []rule2.Rule{rule},
+				})
 			},
 		},
 		{
@@ -73,11 +93,12 @@ func TestRuleExecutorExecute(t *testing.T) {
 			configureMocks: func(t *testing.T, ctx *mocks2.ContextMock, repo *mocks4.RepositoryMock, rule *mocks4.RuleMock) {
 				t.Helper()
 
+				req := &heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *matchingURL}}
+
 				ctx.EXPECT().AppContext().Return(context.Background())
-				ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodGet, URL: matchingURL})
-				rule.EXPECT().MatchesMethod(http.MethodGet).Return(true)
+				ctx.EXPECT().Request().Return(req)
 				rule.EXPECT().Execute(ctx).Return(nil, heimdall.ErrAuthentication)
-				repo.EXPECT().FindRule(matchingURL).Return(rule, nil)
+				repo.EXPECT().FindRule(req).Return(rule, nil)
 			},
 		},
 		{
@@ -87,11 +108,12 @@ func TestRuleExecutorExecute(t *testing.T) {
 
 				upstream := mocks4.NewBackendMock(t)
 
+				req := &heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *matchingURL}}
+
 				ctx.EXPECT().AppContext().Return(context.Background())
-				ctx.EXPECT().Request().Return(&heimdall.Request{Method: http.MethodGet, URL: matchingURL})
-				rule.EXPECT().MatchesMethod(http.MethodGet).Return(true)
+				ctx.EXPECT().Request().Return(req)
 				rule.EXPECT().Execute(ctx).Return(upstream, nil)
-				repo.EXPECT().FindRule(matchingURL).Return(rule, nil)
+				repo.EXPECT().FindRule(req).Return(rule, nil)
 			},
 		},
 	} {
@@ -26,6 +26,7 @@ import (
 
 	"github.com/goccy/go-json"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
 
 	"github.com/dadrus/heimdall/internal/config"
 	"github.com/dadrus/heimdall/internal/heimdall"
@@ -46,7 +47,12 @@ func NewRuleFactory(
 ) (rule.Factory, error) {
 	logger.Debug().Msg("Creating rule factory")
 
-	rf := &ruleFactory{hf: hf, hasDefaultRule: false, logger: logger, mode: mode}
+	sr, err := newShadowRecorder(otel.GetMeterProvider())
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed creating shadow mode metrics")
+	}
+
+	rf := &ruleFactory{hf: hf, hasDefaultRule: false, logger: logger, mode: mode, sr: sr}
 
 	if err := rf.initWithDefaultRule(conf.Default, logger); err != nil {
 		logger.Error().Err(err).Msg("Loading default rule failed")
@@ -63,6 +69,7 @@ type ruleFactory struct {
 	defaultRule    *ruleImpl
 	hasDefaultRule bool
 	mode           config.OperationMode
+	sr             *shadowRecorder
 }
 
 //nolint:funlen,gocognit,cyclop
@@ -104,6 +111,14 @@ func (f *ruleFactory) createExecutePipeline(
 					"an authenticator is defined after some other non authenticator type")
 			}
 
+			shadow, err := isShadowMode(pipelineStep["mode"])
+			if err != nil {
+				return nil, nil, nil, err
+			} else if shadow {
+				return nil, nil, nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+					"shadow mode is not supported for authenticators")
+			}
+
 			authenticator, err := f.hf.CreateAuthenticator(version, id.(string), getConfig(pipelineStep["config"]))
 			if err != nil {
 				return nil, nil, nil, err
@@ -114,7 +129,7 @@ func (f *ruleFactory) createExecutePipeline(
 			continue
 		}
 
-		handler, err := createHandler(version, "authorizer", pipelineStep, authorizersCheck,
+		handler, err := createHandler(version, "authorizer", pipelineStep, f.sr, authorizersCheck,
 			f.hf.CreateAuthorizer)
 		if err != nil && !errors.Is(err, errHandlerNotFound) {
 			return nil, nil, nil, err
@@ -124,7 +139,7 @@ func (f *ruleFactory) createExecutePipeline(
 			continue
 		}
 
-		handler, err = createHandler(version, "contextualizer", pipelineStep, contextualizersCheck,
+		handler, err = createHandler(version, "contextualizer", pipelineStep, f.sr, contextualizersCheck,
 			f.hf.CreateContextualizer)
 		if err != nil && !errors.Is(err, errHandlerNotFound) {
 			return nil, nil, nil, err
@@ -134,7 +149,7 @@ func (f *ruleFactory) createExecutePipeline(
 			continue
 		}
 
-		handler, err = createHandler(version, "finalizer", pipelineStep, finalizersCheck,
+		handler, err = createHandler(version, "finalizer", pipelineStep, f.sr, finalizersCheck,
 			f.hf.CreateFinalizer)
 		if err != nil && !errors.Is(err, errHandlerNotFound) {
 			return nil, nil, nil, err
@@ -177,6 +192,19 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 			ruleConfig.RuleMatcher.Strategy, ruleConfig.ID, srcID).CausedBy(err)
 	}
 
+	reqMatcher, err := newRequestMatcher(ruleConfig.RuleMatcher)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"bad request matching conditions defined for rule ID=%s from %s",
+			ruleConfig.ID, srcID).CausedBy(err)
+	}
+
+	shadow, err := isShadowMode(ruleConfig.Mode)
+	if err != nil {
+		return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"bad mode defined for rule ID=%s from %s", ruleConfig.ID, srcID).CausedBy(err)
+	}
+
 	authenticators, subHandlers, finalizers, err := f.createExecutePipeline(version, ruleConfig.Execute)
 	if err != nil {
 		return nil, err
@@ -217,18 +245,47 @@ func (f *ruleFactory) CreateRule(version, srcID string, ruleConfig config2.Rule)
 			"failed to create hash for rule ID=%s from %s", ruleConfig.ID, srcID)
 	}
 
+	var (
+		upstreams *upstreamPool
+		rewriter  *urlRewriter
+	)
+
+	if ruleConfig.Backend != nil {
+		if upstreams, err = newUpstreamPool(ruleConfig.Backend); err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"bad forward_to definition in rule ID=%s from %s", ruleConfig.ID, srcID).CausedBy(err)
+		}
+
+		if rewriter, err = newURLRewriter(ruleConfig.Backend.URLRewriter); err != nil {
+			return nil, errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"bad rewrite definition in forward_to in rule ID=%s from %s", ruleConfig.ID, srcID).CausedBy(err)
+		}
+	}
+
+	strategy, pattern := ruleConfig.RuleMatcher.Strategy, ruleConfig.RuleMatcher.URL
+
 	return &ruleImpl{
-		id:         ruleConfig.ID,
-		urlMatcher: matcher,
-		backend:    ruleConfig.Backend,
-		methods:    methods,
-		srcID:      srcID,
-		isDefault:  false,
-		hash:       hash,
-		sc:         authenticators,
-		sh:         subHandlers,
-		fi:         finalizers,
-		eh:         errorHandlers,
+		id:          ruleConfig.ID,
+		priority:    ruleConfig.Priority,
+		urlMatcher:  matcher,
+		urlPattern:  pattern,
+		urlPrefix:   patternmatcher.LiteralPrefix(strategy, pattern),
+		urlCatchAll: patternmatcher.MatchesAllWithPrefix(strategy, pattern),
+		matcher:     ruleConfig.RuleMatcher,
+		reqMatcher:  reqMatcher,
+		backend:     ruleConfig.Backend,
+		upstreams:   upstreams,
+		rewriter:    rewriter,
+		methods:     methods,
+		srcID:       srcID,
+		isDefault:   false,
+		shadow:      shadow,
+		sr:          f.sr,
+		hash:        hash,
+		sc:          authenticators,
+		sh:          subHandlers,
+		fi:          finalizers,
+		eh:          errorHandlers,
 	}, nil
 }
 
@@ -239,21 +296,32 @@ func checkProxyModeApplicability(srcID string, ruleConfig config2.Rule) error {
 			ruleConfig.ID, srcID)
 	}
 
-	if len(ruleConfig.Backend.Host) == 0 {
+	if len(ruleConfig.Backend.Host) == 0 && len(ruleConfig.Backend.Upstreams) == 0 {
 		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
 			"missing host definition in forward_to in rule ID=%s from %s",
 			ruleConfig.ID, srcID)
 	}
 
+	if len(ruleConfig.Backend.Host) != 0 && len(ruleConfig.Backend.Upstreams) != 0 {
+		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+			"host and upstreams are mutually exclusive in forward_to in rule ID=%s from %s",
+			ruleConfig.ID, srcID)
+	}
+
+	for _, us := range ruleConfig.Backend.Upstreams {
+		if len(us.Host) == 0 {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"missing host definition for an upstream in forward_to in rule ID=%s from %s",
+				ruleConfig.ID, srcID)
+		}
+	}
+
 	urlRewriter := ruleConfig.Backend.URLRewriter
 	if urlRewriter == nil {
 		return nil
 	}
 
-	if len(urlRewriter.Scheme) == 0 &&
-		len(urlRewriter.PathPrefixToAdd) == 0 &&
-		len(urlRewriter.PathPrefixToCut) == 0 &&
-		len(urlRewriter.QueryParamsToRemove) == 0 {
+	if urlRewriter.IsEmpty() {
 		return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
 			"rewrite is defined in forward_to in rule ID=%s from %s, but is empty", ruleConfig.ID, srcID)
 	}
@@ -386,6 +454,7 @@ func createHandler[T subjectHandler](
 	version string,
 	handlerType string,
 	configMap map[string]any,
+	sr *shadowRecorder,
 	check CheckFunc,
 	creteHandler func(version, id string, conf config.MechanismConfig) (T, error),
 ) (subjectHandler, error) {
@@ -403,12 +472,25 @@ func createHandler[T subjectHandler](
 		return nil, err
 	}
 
+	shadow, err := isShadowMode(configMap["mode"])
+	if err != nil {
+		return nil, err
+	}
+
 	handler, err := creteHandler(version, id.(string), getConfig(configMap["config"]))
 	if err != nil {
 		return nil, err
 	}
 
-	return &conditionalSubjectHandler{h: handler, c: condition}, err
+	if shadow {
+		return &conditionalSubjectHandler{
+			typ: handlerType,
+			h:   &shadowSubjectHandler{h: handler, sr: sr},
+			c:   condition,
+		}, nil
+	}
+
+	return &conditionalSubjectHandler{typ: handlerType, h: handler, c: condition}, err
 }
 
 func getConfig(conf any) config.MechanismConfig {
@@ -596,6 +596,98 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				assert.Contains(t, err.Error(), "rewrite is defined")
 			},
 		},
+		{
+			uc:     "in proxy mode, with id and both forward_to.host and forward_to.upstreams",
+			opMode: config.ProxyMode,
+			config: config2.Rule{
+				ID: "foobar",
+				Backend: &config2.Backend{
+					Host:      "foo.bar",
+					Upstreams: []config2.Upstream{{Host: "bar.foo"}},
+				},
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "mutually exclusive")
+			},
+		},
+		{
+			uc:     "in proxy mode, with id and forward_to.upstreams containing an entry without host",
+			opMode: config.ProxyMode,
+			config: config2.Rule{
+				ID: "foobar",
+				Backend: &config2.Backend{
+					Upstreams: []config2.Upstream{{Host: "bar.foo"}, {Weight: weight(2)}},
+				},
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "missing host definition for an upstream")
+			},
+		},
+		{
+			uc:     "in proxy mode, with unsupported load balancing policy",
+			opMode: config.ProxyMode,
+			config: config2.Rule{
+				ID: "foobar",
+				Backend: &config2.Backend{
+					Upstreams:     []config2.Upstream{{Host: "bar.foo"}, {Host: "foo.bar"}},
+					LoadBalancing: &config2.LoadBalancing{Policy: "foo"},
+				},
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute:     []config.MechanismConfig{{"authenticator": "foo"}},
+				Methods:     []string{"FOO"},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "bad forward_to")
+				assert.Contains(t, err.Error(), "unsupported load balancing policy")
+			},
+		},
+		{
+			uc:     "in proxy mode, with conflicting rewrite definition",
+			opMode: config.ProxyMode,
+			config: config2.Rule{
+				ID: "foobar",
+				Backend: &config2.Backend{
+					Host: "foo.bar",
+					URLRewriter: &config2.URLRewriter{
+						PathPrefixToCut: "/foo",
+						PathTemplate:    "/bar",
+					},
+				},
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute:     []config.MechanismConfig{{"authenticator": "foo"}},
+				Methods:     []string{"FOO"},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "bad rewrite definition")
+				assert.Contains(t, err.Error(), "conflicting rewrite definitions")
+			},
+		},
 		{
 			uc:     "without default rule, with id, but without url",
 			config: config2.Rule{ID: "foobar"},
@@ -858,6 +950,45 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				assert.Len(t, rul.fi, 1)
 				assert.Empty(t, rul.eh)
 				assert.NotNil(t, rul.backend)
+				require.NotNil(t, rul.upstreams)
+				require.Len(t, rul.upstreams.upstreams, 1)
+				assert.Equal(t, "foo.bar", rul.upstreams.upstreams[0].host)
+			},
+		},
+		{
+			uc:     "without default rule but with weighted upstreams in proxy mode",
+			opMode: config.ProxyMode,
+			config: config2.Rule{
+				ID: "foobar",
+				Backend: &config2.Backend{
+					Upstreams: []config2.Upstream{{Host: "foo.bar", Weight: weight(9)}, {Host: "bar.foo"}},
+					LoadBalancing: &config2.LoadBalancing{
+						Policy:      "least_requests",
+						HealthCheck: &config2.PassiveHealthCheck{MaxFailures: 2},
+					},
+				},
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute:     []config.MechanismConfig{{"authenticator": "foo"}},
+				Methods:     []string{"FOO"},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, rul)
+				require.NotNil(t, rul.upstreams)
+
+				assert.Equal(t, lbPolicyLeastRequests, rul.upstreams.policy)
+				assert.Equal(t, 2, rul.upstreams.maxFailures)
+				assert.Equal(t, defaultEjectionTime, rul.upstreams.ejectionTime)
+				require.Len(t, rul.upstreams.upstreams, 2)
+				assert.Equal(t, 9, rul.upstreams.upstreams[0].weight)
+				assert.Equal(t, 1, rul.upstreams.upstreams[1].weight)
 			},
 		},
 		{
@@ -1010,12 +1141,12 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				assert.Equal(t, "foobar", rul.id)
 				assert.NotNil(t, rul.urlMatcher)
 				assert.ElementsMatch(t, rul.methods, []string{"BAR", "BAZ"})
-				assert.Equal(t, "https://bar.foo/baz/bar?foo=bar", rul.backend.CreateURL(&url.URL{
+				assert.Equal(t, "https://bar.foo/baz/bar?foo=bar", rul.backend.CreateURL(&heimdall.URL{URL: url.URL{
 					Scheme:   "http",
 					Host:     "foo.bar:8888",
 					Path:     "/foo/bar",
 					RawQuery: url.Values{"bar": []string{"foo"}, "foo": []string{"bar"}}.Encode(),
-				}).String())
+				}}).String())
 
 				// nil checks above mean the responses from the mockHandlerFactory are used
 				// and not the values from the default rule
@@ -1145,6 +1276,114 @@ func TestRuleFactoryCreateRule(t *testing.T) {
 				require.Empty(t, rul.eh)
 			},
 		},
+		{
+			uc: "with unsupported rule mode",
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Mode:        "foo",
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "bad mode")
+			},
+		},
+		{
+			uc: "with authenticator in shadow mode",
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo", "mode": "shadow"},
+				},
+				Methods: []string{"FOO"},
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "not supported for authenticators")
+			},
+		},
+		{
+			uc: "with unsupported mechanism mode",
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authorizer": "bar", "mode": "foo"},
+				},
+				Methods: []string{"FOO"},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).Return(&mocks2.AuthenticatorMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorContains(t, err, "unsupported mode")
+			},
+		},
+		{
+			uc: "with rule and some mechanisms in shadow mode",
+			config: config2.Rule{
+				ID:          "foobar",
+				RuleMatcher: config2.Matcher{URL: "http://foo.bar", Strategy: "glob"},
+				Mode:        "shadow",
+				Execute: []config.MechanismConfig{
+					{"authenticator": "foo"},
+					{"authorizer": "bar", "mode": "shadow"},
+					{"contextualizer": "bar", "mode": "enforce"},
+					{"finalizer": "bar", "mode": "shadow", "if": "true"},
+				},
+				Methods: []string{"FOO"},
+			},
+			configureMocks: func(t *testing.T, mhf *mocks3.FactoryMock) {
+				t.Helper()
+
+				mhf.EXPECT().CreateAuthenticator("test", "foo", mock.Anything).
+					Return(&mocks2.AuthenticatorMock{}, nil)
+				mhf.EXPECT().CreateAuthorizer("test", "bar", mock.Anything).
+					Return(&mocks4.AuthorizerMock{}, nil)
+				mhf.EXPECT().CreateContextualizer("test", "bar", mock.Anything).
+					Return(&mocks5.ContextualizerMock{}, nil)
+				mhf.EXPECT().CreateFinalizer("test", "bar", mock.Anything).
+					Return(&mocks7.FinalizerMock{}, nil)
+			},
+			assert: func(t *testing.T, err error, rul *ruleImpl) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, rul)
+
+				assert.True(t, rul.shadow)
+
+				require.Len(t, rul.sh, 2)
+
+				sh, ok := rul.sh[0].(*conditionalSubjectHandler)
+				require.True(t, ok)
+				assert.IsType(t, &shadowSubjectHandler{}, sh.h)
+
+				sh, ok = rul.sh[1].(*conditionalSubjectHandler)
+				require.True(t, ok)
+				assert.IsType(t, &mocks5.ContextualizerMock{}, sh.h)
+
+				require.Len(t, rul.fi, 1)
+				sh, ok = rul.fi[0].(*conditionalSubjectHandler)
+				require.True(t, ok)
+				assert.IsType(t, &shadowSubjectHandler{}, sh.h)
+				assert.IsType(t, &celExecutionCondition{}, sh.c)
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
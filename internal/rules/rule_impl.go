@@ -17,91 +17,227 @@
 package rules
 
 import (
+	"encoding/hex"
 	"fmt"
 	"net/url"
 	"slices"
 
 	"github.com/rs/zerolog"
 
+	"github.com/dadrus/heimdall/internal/explain"
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
 	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
 	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/x"
 )
 
 type ruleImpl struct {
-	id         string
-	urlMatcher patternmatcher.PatternMatcher
-	backend    *config.Backend
-	methods    []string
-	srcID      string
-	isDefault  bool
-	hash       []byte
-	sc         compositeSubjectCreator
-	sh         compositeSubjectHandler
-	fi         compositeSubjectHandler
-	eh         compositeErrorHandler
+	id          string
+	priority    int
+	urlMatcher  patternmatcher.PatternMatcher
+	urlPattern  string
+	urlPrefix   string
+	urlCatchAll bool
+	matcher     config.Matcher
+	reqMatcher  compositeRequestMatcher
+	backend     *config.Backend
+	upstreams   *upstreamPool
+	rewriter    *urlRewriter
+	methods     []string
+	srcID       string
+	isDefault   bool
+	shadow      bool
+	sr          *shadowRecorder
+	hash        []byte
+	sc          compositeSubjectCreator
+	sh          compositeSubjectHandler
+	fi          compositeSubjectHandler
+	eh          compositeErrorHandler
 }
 
 func (r *ruleImpl) Execute(ctx heimdall.Context) (rule.Backend, error) {
 	logger := zerolog.Ctx(ctx.AppContext())
 
+	explain.SetRule(ctx.AppContext(), r.id)
+
 	if r.isDefault {
 		logger.Info().Msg("Executing default rule")
 	} else {
-		logger.Info().Str("_src", r.srcID).Str("_id", r.id).Msg("Executing rule")
+		logger.Info().Str("_src", r.srcID).Str("_id", r.id).
+			Msg(x.IfThenElse(r.shadow, "Executing rule in shadow mode", "Executing rule"))
+	}
+
+	if r.urlMatcher != nil {
+		reqURL := ctx.Request().URL
+		reqURL.Captures = r.urlMatcher.Captures(matchableURL(&reqURL.URL))
+	}
+
+	if r.shadow {
+		sub, err := r.executePipeline(shadowContext{Context: ctx})
+
+		be, rewriteErr := r.upstream(ctx, sub, r.rewriter)
+		r.sr.record(ctx, "rule", r.id, x.IfThenElse(err != nil, err, rewriteErr))
+
+		if rewriteErr != nil {
+			// the rewrites may depend on the subject, which is not available if the pipeline failed.
+			// Since rules in shadow mode never reject requests, the backend url is used as is.
+			return r.upstream(ctx, sub, nil)
+		}
+
+		return be, nil
+	}
+
+	sub, err := r.executePipeline(ctx)
+	if err == nil {
+		var be rule.Backend
+
+		if be, err = r.upstream(ctx, sub, r.rewriter); err == nil {
+			return be, nil
+		}
 	}
 
+	_, err = r.eh.Execute(ctx, err)
+
+	return nil, err
+}
+
+func (r *ruleImpl) executePipeline(ctx heimdall.Context) (*subject.Subject, error) {
 	// authenticators
 	sub, err := r.sc.Execute(ctx)
 	if err != nil {
-		_, err := r.eh.Execute(ctx, err)
-
 		return nil, err
 	}
 
 	// authorizers & contextualizer
 	if err = r.sh.Execute(ctx, sub); err != nil {
-		_, err := r.eh.Execute(ctx, err)
-
-		return nil, err
+		return sub, err
 	}
 
 	// finalizers
-	if err = r.fi.Execute(ctx, sub); err != nil {
-		_, err := r.eh.Execute(ctx, err)
+	return sub, r.fi.Execute(ctx, sub)
+}
 
+func (r *ruleImpl) upstream(
+	ctx heimdall.Context,
+	sub *subject.Subject,
+	rewriter *urlRewriter,
+) (rule.Backend, error) {
+	if r.backend == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	req := ctx.Request()
+	targetURL := r.backend.CreateURL(req.URL)
+
+	hostHeader, err := rewriter.rewrite(targetURL, req, sub)
+	if err != nil {
 		return nil, err
 	}
 
-	var upstream rule.Backend
+	return &backend{
+		pool:       r.upstreams,
+		baseURL:    targetURL,
+		hostHeader: hostHeader,
+		hashKey:    x.IfThenElseExec(sub != nil, func() string { return sub.ID }, func() string { return "" }),
+	}, nil
+}
+
+func (r *ruleImpl) MatchesURL(requestURL *url.URL) bool {
+	return r.urlMatcher.Match(matchableURL(requestURL))
+}
+
+func (r *ruleImpl) MatchesMethod(method string) bool { return slices.Contains(r.methods, method) }
+
+func (r *ruleImpl) MatchesRequest(req *heimdall.Request) bool { return r.reqMatcher.Matches(req) }
+
+func (r *ruleImpl) ID() string { return r.id }
+
+func (r *ruleImpl) SrcID() string { return r.srcID }
+
+// describe returns the description of the rule used by the rule inventory.
+func (r *ruleImpl) describe() rule.Description {
+	desc := rule.Description{
+		ID:       r.id,
+		Source:   r.srcID,
+		Hash:     hex.EncodeToString(r.hash),
+		Priority: r.priority,
+		Shadow:   r.shadow,
+		Match:    r.matcher,
+		Methods:  r.methods,
+		Backend:  r.backend,
+		Execute:  []rule.MechanismDescription{},
+	}
+
+	for _, sc := range r.sc {
+		desc.Execute = append(desc.Execute, rule.MechanismDescription{Type: "authenticator", ID: sc.ID()})
+	}
 
-	if r.backend != nil {
-		upstream = &backend{
-			targetURL: r.backend.CreateURL(ctx.Request().URL),
+	for _, handlers := range []compositeSubjectHandler{r.sh, r.fi} {
+		for _, sh := range handlers {
+			desc.Execute = append(desc.Execute, describeSubjectHandler(sh))
 		}
 	}
 
-	return upstream, nil
+	for _, eh := range r.eh {
+		desc.OnError = append(desc.OnError, rule.MechanismDescription{Type: "error_handler", ID: eh.ID()})
+	}
+
+	return desc
 }
 
-func (r *ruleImpl) MatchesURL(requestURL *url.URL) bool {
+func describeSubjectHandler(sh subjectHandler) rule.MechanismDescription {
+	csh, ok := sh.(*conditionalSubjectHandler)
+	if !ok {
+		return rule.MechanismDescription{ID: sh.ID()}
+	}
+
+	_, shadow := csh.h.(*shadowSubjectHandler)
+
+	return rule.MechanismDescription{Type: csh.typ, ID: csh.ID(), Shadow: shadow}
+}
+
+func matchableURL(requestURL *url.URL) string {
 	toBeMatched := url.URL{
 		Scheme: requestURL.Scheme,
 		Opaque: fmt.Sprintf("//%s%s", requestURL.Host, requestURL.Path),
 	}
 
-	return r.urlMatcher.Match(toBeMatched.String())
+	return toBeMatched.String()
 }
 
-func (r *ruleImpl) MatchesMethod(method string) bool { return slices.Contains(r.methods, method) }
+type backend struct {
+	pool       *upstreamPool
+	baseURL    *url.URL
+	hostHeader string
+	hashKey    string
 
-func (r *ruleImpl) ID() string { return r.id }
+	selected  *upstream
+	targetURL *url.URL
+}
 
-func (r *ruleImpl) SrcID() string { return r.srcID }
+func (b *backend) URL() *url.URL {
+	if b.targetURL != nil || b.pool == nil {
+		return x.IfThenElse(b.targetURL != nil, b.targetURL, b.baseURL)
+	}
 
-type backend struct {
-	targetURL *url.URL
+	b.selected = b.pool.next(b.hashKey)
+
+	targetURL := *b.baseURL
+	targetURL.Host = b.selected.host
+	b.targetURL = &targetURL
+
+	return b.targetURL
 }
 
-func (b *backend) URL() *url.URL { return b.targetURL }
+func (b *backend) HostHeader() string { return b.hostHeader }
+
+func (b *backend) Done(err error) {
+	if b.selected == nil {
+		return
+	}
+
+	b.pool.done(b.selected, err)
+	b.selected = nil
+}
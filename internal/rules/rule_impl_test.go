@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
@@ -176,7 +177,9 @@ func TestRuleExecute(t *testing.T) {
 
 	for _, tc := range []struct {
 		uc             string
+		urlMatcher     patternmatcher.PatternMatcher
 		backend        *config.Backend
+		shadow         bool
 		configureMocks func(
 			t *testing.T,
 			ctx *heimdallmocks.ContextMock,
@@ -335,7 +338,9 @@ func TestRuleExecute(t *testing.T) {
 				authorizer.EXPECT().Execute(ctx, sub).Return(nil)
 				finalizer.EXPECT().Execute(ctx, sub).Return(nil)
 
-				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &url.URL{Scheme: "http", Host: "foo.local", Path: "/api/v1/foo"}})
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					URL: &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.local", Path: "/api/v1/foo"}},
+				})
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend) {
 				t.Helper()
@@ -362,7 +367,9 @@ func TestRuleExecute(t *testing.T) {
 				authorizer.EXPECT().Execute(ctx, sub).Return(nil)
 				finalizer.EXPECT().Execute(ctx, sub).Return(nil)
 
-				ctx.EXPECT().Request().Return(&heimdall.Request{URL: &url.URL{Scheme: "http", Host: "foo.local", Path: "/api/v1/foo"}})
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					URL: &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.local", Path: "/api/v1/foo"}},
+				})
 			},
 			assert: func(t *testing.T, err error, backend rule.Backend) {
 				t.Helper()
@@ -371,6 +378,127 @@ func TestRuleExecute(t *testing.T) {
 				assert.Equal(t, &url.URL{Scheme: "http", Host: "foo.bar", Path: "/foo"}, backend.URL())
 			},
 		},
+		{
+			uc: "captures are made available to the pipeline and the url rewriter",
+			urlMatcher: func() patternmatcher.PatternMatcher {
+				matcher, err := patternmatcher.NewPatternMatcher("template", "http://foo.local/:tenant/users/:id")
+				require.NoError(t, err)
+
+				return matcher
+			}(),
+			backend: &config.Backend{
+				Host:        "foo.bar",
+				URLRewriter: &config.URLRewriter{PathPrefixToCut: "/{tenant}"},
+			},
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, authenticator *mocks.SubjectCreatorMock,
+				authorizer *mocks.SubjectHandlerMock, finalizer *mocks.SubjectHandlerMock,
+				_ *mocks.ErrorHandlerMock,
+			) {
+				t.Helper()
+
+				sub := &subject.Subject{ID: "Foo"}
+				req := &heimdall.Request{
+					URL: &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.local", Path: "/acme/users/1"}},
+				}
+				capturesSet := func(_ heimdall.Context) bool {
+					return assert.Equal(t, map[string]string{"tenant": "acme", "id": "1"}, req.URL.Captures)
+				}
+
+				ctx.EXPECT().Request().Return(req)
+				authenticator.EXPECT().Execute(mock.MatchedBy(capturesSet)).Return(sub, nil)
+				authorizer.EXPECT().Execute(ctx, sub).Return(nil)
+				finalizer.EXPECT().Execute(ctx, sub).Return(nil)
+			},
+			assert: func(t *testing.T, err error, backend rule.Backend) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &url.URL{Scheme: "http", Host: "foo.bar", Path: "/users/1"}, backend.URL())
+			},
+		},
+		{
+			uc:      "rule in shadow mode lets the request through although authorizer fails",
+			backend: &config.Backend{Host: "foo.bar"},
+			shadow:  true,
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, authenticator *mocks.SubjectCreatorMock,
+				authorizer *mocks.SubjectHandlerMock, _ *mocks.SubjectHandlerMock,
+				_ *mocks.ErrorHandlerMock,
+			) {
+				t.Helper()
+
+				sub := &subject.Subject{ID: "Foo"}
+				authenticator.EXPECT().Execute(mock.MatchedBy(inShadowMode)).Return(sub, nil)
+				authorizer.EXPECT().Execute(mock.MatchedBy(inShadowMode), sub).Return(testsupport.ErrTestPurpose)
+				authorizer.EXPECT().ContinueOnError().Return(false)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					URL: &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.local", Path: "/api/v1/foo"}},
+				})
+			},
+			assert: func(t *testing.T, err error, backend rule.Backend) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, backend)
+				assert.Equal(t, &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/v1/foo"}, backend.URL())
+			},
+		},
+		{
+			uc: "rule in shadow mode uses not rewritten url if rewrite depends on subject and authenticator fails",
+			backend: &config.Backend{
+				Host:        "foo.bar",
+				URLRewriter: &config.URLRewriter{PathTemplate: "/users/{{ .Subject.ID }}"},
+			},
+			shadow: true,
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, authenticator *mocks.SubjectCreatorMock,
+				_ *mocks.SubjectHandlerMock, _ *mocks.SubjectHandlerMock,
+				_ *mocks.ErrorHandlerMock,
+			) {
+				t.Helper()
+
+				authenticator.EXPECT().Execute(mock.Anything).Return(nil, testsupport.ErrTestPurpose)
+				authenticator.EXPECT().IsFallbackOnErrorAllowed().Return(false)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					URL: &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.local", Path: "/api/v1/foo"}},
+				})
+			},
+			assert: func(t *testing.T, err error, backend rule.Backend) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, backend)
+				assert.Equal(t, &url.URL{Scheme: "http", Host: "foo.bar", Path: "/api/v1/foo"}, backend.URL())
+			},
+		},
+		{
+			uc: "rule in shadow mode applies subject dependent rewrite if pipeline succeeds",
+			backend: &config.Backend{
+				Host:        "foo.bar",
+				URLRewriter: &config.URLRewriter{PathTemplate: "/users/{{ .Subject.ID }}"},
+			},
+			shadow: true,
+			configureMocks: func(t *testing.T, ctx *heimdallmocks.ContextMock, authenticator *mocks.SubjectCreatorMock,
+				authorizer *mocks.SubjectHandlerMock, finalizer *mocks.SubjectHandlerMock,
+				_ *mocks.ErrorHandlerMock,
+			) {
+				t.Helper()
+
+				sub := &subject.Subject{ID: "Foo"}
+
+				authenticator.EXPECT().Execute(mock.Anything).Return(sub, nil)
+				authorizer.EXPECT().Execute(mock.Anything, sub).Return(nil)
+				finalizer.EXPECT().Execute(mock.Anything, sub).Return(nil)
+				ctx.EXPECT().Request().Return(&heimdall.Request{
+					URL: &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.local", Path: "/api/v1/foo"}},
+				})
+			},
+			assert: func(t *testing.T, err error, backend rule.Backend) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, backend)
+				assert.Equal(t, &url.URL{Scheme: "http", Host: "foo.bar", Path: "/users/Foo"}, backend.URL())
+			},
+		},
 	} {
 		t.Run("case="+tc.uc, func(t *testing.T) {
 			// GIVEN
@@ -382,12 +510,24 @@ func TestRuleExecute(t *testing.T) {
 			finalizer := mocks.NewSubjectHandlerMock(t)
 			errHandler := mocks.NewErrorHandlerMock(t)
 
+			var rewriter *urlRewriter
+
+			if tc.backend != nil {
+				var err error
+
+				rewriter, err = newURLRewriter(tc.backend.URLRewriter)
+				require.NoError(t, err)
+			}
+
 			rul := &ruleImpl{
-				backend: tc.backend,
-				sc:      compositeSubjectCreator{authenticator},
-				sh:      compositeSubjectHandler{authorizer},
-				fi:      compositeSubjectHandler{finalizer},
-				eh:      compositeErrorHandler{errHandler},
+				urlMatcher: tc.urlMatcher,
+				backend:    tc.backend,
+				rewriter:   rewriter,
+				shadow:     tc.shadow,
+				sc:         compositeSubjectCreator{authenticator},
+				sh:         compositeSubjectHandler{authorizer},
+				fi:         compositeSubjectHandler{finalizer},
+				eh:         compositeErrorHandler{errHandler},
 			}
 
 			tc.configureMocks(t, ctx, authenticator, authorizer, finalizer, errHandler)
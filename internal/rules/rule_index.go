@@ -0,0 +1,228 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+// ruleIndex is an immutable radix tree over the literal prefixes (scheme, host and path
+// parts preceding the first wildcard) of the url patterns of the indexed rules. A lookup
+// walks the tree along the request url and evaluates the actual pattern matchers only
+// for the rules found on that path. The precedence of a rule is defined by its priority
+// and, for rules having the same priority, by its position in the slice the index has
+// been built from, so the first match semantics of a linear scan over that slice ordered
+// by priority is retained. Since the method and the further request conditions are
+// part of the lookup, rules sharing the same url pattern, but serving different methods or
+// requests can coexist.
+type ruleIndex struct {
+	root  indexNode
+	rules []rule.Rule
+}
+
+type indexNode struct {
+	label    string
+	children []*indexNode
+	entries  []int
+}
+
+func newRuleIndex(rules []rule.Rule) *ruleIndex {
+	idx := &ruleIndex{rules: sortByPriority(rules)}
+
+	for pos, rul := range idx.rules {
+		idx.root.insert(literalPrefixOf(rul), pos)
+	}
+
+	return idx
+}
+
+// find returns the first rule matching the given request, which is not operated in shadow
+// mode, as well as the matching rules operated in shadow mode preceding it. The returned flag
+// tells whether at least one rule matched the url and the further request conditions
+// regardless of the method.
+func (idx *ruleIndex) find(req *heimdall.Request) (rule.Rule, []rule.Rule, bool) {
+	if idx == nil || len(idx.rules) == 0 {
+		return nil, nil, false
+	}
+
+	var (
+		buf        [16]int
+		shadows    []rule.Rule
+		urlMatched bool
+	)
+
+	requestURL := &req.URL.URL
+
+	candidates := idx.root.collect(matchableURL(requestURL), buf[:0])
+	slices.Sort(candidates)
+
+	for _, pos := range candidates {
+		rul := idx.rules[pos]
+		if !rul.MatchesURL(requestURL) || !rul.MatchesRequest(req) {
+			continue
+		}
+
+		if !rul.MatchesMethod(req.Method) {
+			// rules operated in shadow mode never handle a request
+			urlMatched = urlMatched || !isShadow(rul)
+
+			continue
+		}
+
+		if isShadow(rul) {
+			shadows = append(shadows, rul)
+
+			continue
+		}
+
+		return rul, shadows, true
+	}
+
+	return nil, shadows, urlMatched
+}
+
+func (n *indexNode) insert(key string, pos int) {
+	node := n
+
+	for len(key) != 0 {
+		child := node.child(key[0])
+		if child == nil {
+			node.addChild(&indexNode{label: key, entries: []int{pos}})
+
+			return
+		}
+
+		common := commonPrefixLength(key, child.label)
+		if common < len(child.label) {
+			// split the edge to make the common prefix a node on its own
+			child.split(common)
+		}
+
+		node = child
+		key = key[common:]
+	}
+
+	node.entries = append(node.entries, pos)
+}
+
+func (n *indexNode) split(at int) {
+	tail := &indexNode{
+		label:    n.label[at:],
+		children: n.children,
+		entries:  n.entries,
+	}
+
+	n.label = n.label[:at]
+	n.children = []*indexNode{tail}
+	n.entries = nil
+}
+
+func (n *indexNode) child(first byte) *indexNode {
+	idx, found := slices.BinarySearchFunc(n.children, first, func(node *indexNode, b byte) int {
+		return int(node.label[0]) - int(b)
+	})
+	if !found {
+		return nil
+	}
+
+	return n.children[idx]
+}
+
+func (n *indexNode) addChild(child *indexNode) {
+	idx, _ := slices.BinarySearchFunc(n.children, child.label[0], func(node *indexNode, b byte) int {
+		return int(node.label[0]) - int(b)
+	})
+
+	n.children = slices.Insert(n.children, idx, child)
+}
+
+// collect appends the positions of all rules, whose literal prefix is a prefix of the given value.
+func (n *indexNode) collect(value string, candidates []int) []int {
+	node := n
+
+	for {
+		candidates = append(candidates, node.entries...)
+
+		if len(value) == 0 {
+			return candidates
+		}
+
+		child := node.child(value[0])
+		if child == nil || len(value) < len(child.label) || value[:len(child.label)] != child.label {
+			return candidates
+		}
+
+		node = child
+		value = value[len(child.label):]
+	}
+}
+
+func commonPrefixLength(a, b string) int {
+	length := min(len(a), len(b))
+
+	for i := 0; i < length; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return length
+}
+
+// literalPrefixOf returns the literal prefix of the url pattern of the given rule. Rules,
+// which do not expose it (e.g. the default rule), are indexed with an empty prefix and
+// thus considered for every lookup.
+func literalPrefixOf(rul rule.Rule) string {
+	if impl, ok := rul.(*ruleImpl); ok {
+		return impl.urlPrefix
+	}
+
+	return ""
+}
+
+// priorityOf returns the priority of the given rule. Rules, which do not expose it, have
+// the default priority 0.
+func priorityOf(rul rule.Rule) int {
+	if impl, ok := rul.(*ruleImpl); ok {
+		return impl.priority
+	}
+
+	return 0
+}
+
+// isShadow tells whether the given rule is operated in shadow mode.
+func isShadow(rul rule.Rule) bool {
+	impl, ok := rul.(*ruleImpl)
+
+	return ok && impl.shadow
+}
+
+// sortByPriority returns a copy of the given rules ordered by descending priority. Rules
+// having the same priority retain their relative order.
+func sortByPriority(rules []rule.Rule) []rule.Rule {
+	sorted := slices.Clone(rules)
+
+	slices.SortStableFunc(sorted, func(a, b rule.Rule) int {
+		return cmp.Compare(priorityOf(b), priorityOf(a))
+	})
+
+	return sorted
+}
@@ -0,0 +1,274 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/patternmatcher"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+)
+
+func newTestRule(t testing.TB, id, strategy, pattern string) *ruleImpl {
+	t.Helper()
+
+	matcher, err := patternmatcher.NewPatternMatcher(strategy, pattern)
+	require.NoError(t, err)
+
+	return &ruleImpl{
+		id:          id,
+		srcID:       "test",
+		methods:     []string{http.MethodGet},
+		urlMatcher:  matcher,
+		urlPattern:  pattern,
+		urlPrefix:   patternmatcher.LiteralPrefix(strategy, pattern),
+		urlCatchAll: patternmatcher.MatchesAllWithPrefix(strategy, pattern),
+	}
+}
+
+func TestRuleIndexFind(t *testing.T) {
+	t.Parallel()
+
+	rules := []rule.Rule{
+		newTestRule(t, "1", "glob", "http://foo.bar/api/v1/users/<*>"),
+		newTestRule(t, "2", "glob", "http://foo.bar/api/<**>"),
+		newTestRule(t, "3", "glob", "http://foo.bar/api/v1/users/admin"),
+		newTestRule(t, "4", "regex", "http://foo.bar/svc/<(users|groups)>/<.+>"),
+		newTestRule(t, "5", "glob", "<{http,https}>://foo.bar/static/<**>"),
+		newTestRule(t, "6", "glob", "http://foo.bar/a"),
+		newTestRule(t, "7", "glob", "http://foo.bar/ab"),
+		newTestRule(t, "8", "glob", "http://foo.bar/<**>"),
+	}
+
+	idx := newRuleIndex(rules)
+
+	for _, tc := range []struct {
+		uc       string
+		url      string
+		expected string
+	}{
+		{uc: "first matching rule wins over more specific one", url: "http://foo.bar/api/v1/users/admin", expected: "1"},
+		{uc: "rule with shorter prefix matches", url: "http://foo.bar/api/v1/users/admin/foo", expected: "2"},
+		{uc: "regex rule", url: "http://foo.bar/svc/groups/foo", expected: "4"},
+		{uc: "rule with wildcard scheme", url: "https://foo.bar/static/app.js", expected: "5"},
+		{uc: "rule, which prefix is a prefix of another rule", url: "http://foo.bar/a", expected: "6"},
+		{uc: "rule sharing prefix with another rule", url: "http://foo.bar/ab", expected: "7"},
+		{uc: "fallback rule", url: "http://foo.bar/abc", expected: "8"},
+		{uc: "no matching rule", url: "http://bar.foo/abc"},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			reqURL, err := url.Parse(tc.url)
+			require.NoError(t, err)
+
+			rul, _, _ := idx.find(&heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *reqURL}})
+
+			if len(tc.expected) == 0 {
+				assert.Nil(t, rul)
+			} else {
+				require.NotNil(t, rul)
+				assert.Equal(t, tc.expected, rul.ID())
+			}
+		})
+	}
+}
+
+func TestRuleIndexFindBehavesLikeLinearScan(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var rules []rule.Rule
+
+	for i := 0; i < 50; i++ {
+		rules = append(rules,
+			newTestRule(t, fmt.Sprintf("a%d", i), "glob", fmt.Sprintf("http://host%d.local/<**>", i%7)),
+			newTestRule(t, fmt.Sprintf("b%d", i), "glob", fmt.Sprintf("http://host%d.local/api/%d", i%5, i)),
+			newTestRule(t, fmt.Sprintf("c%d", i), "regex", fmt.Sprintf("http://host%d.local/api/<[0-9]+>", i%3)),
+			newTestRule(t, fmt.Sprintf("d%d", i), "glob", fmt.Sprintf("<*>://host%d.local/other/<*>", i%11)),
+		)
+	}
+
+	idx := newRuleIndex(rules)
+
+	for host := 0; host < 12; host++ {
+		for _, path := range []string{"/", "/api/1", "/api/42", "/api/foo", "/other/bar", "/other/bar/baz"} {
+			reqURL := &url.URL{Scheme: "http", Host: fmt.Sprintf("host%d.local", host), Path: path}
+
+			var expected rule.Rule
+
+			for _, rul := range rules {
+				if rul.MatchesURL(reqURL) {
+					expected = rul
+
+					break
+				}
+			}
+
+			// WHEN
+			rul, _, _ := idx.find(&heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *reqURL}})
+
+			// THEN
+			assert.Equal(t, expected, rul, reqURL.String())
+		}
+	}
+}
+
+func TestRuleIndexFindConsidersRequestConditions(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	internal := newTestRule(t, "internal", "glob", "http://foo.bar/<**>")
+	internal.methods = []string{http.MethodGet, http.MethodPost}
+	internal.reqMatcher = compositeRequestMatcher{clientIPMatcher{
+		&net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+	}}
+
+	external := newTestRule(t, "external", "glob", "http://foo.bar/<**>")
+
+	idx := newRuleIndex([]rule.Rule{internal, external})
+
+	for _, tc := range []struct {
+		uc         string
+		method     string
+		clientIP   string
+		expected   string
+		urlMatched bool
+	}{
+		{uc: "internal client", method: http.MethodGet, clientIP: "10.1.1.1", expected: "internal", urlMatched: true},
+		{uc: "external client", method: http.MethodGet, clientIP: "192.168.1.1", expected: "external", urlMatched: true},
+		{uc: "internal client with method only allowed for internal rule", method: http.MethodPost, clientIP: "10.1.1.1",
+			expected: "internal", urlMatched: true},
+		{uc: "external client with method only allowed for internal rule", method: http.MethodPost, clientIP: "192.168.1.1",
+			urlMatched: true},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			rul, _, urlMatched := idx.find(&heimdall.Request{
+				Method:   tc.method,
+				URL:      &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: "/baz"}},
+				ClientIP: []string{tc.clientIP},
+			})
+
+			// THEN
+			assert.Equal(t, tc.urlMatched, urlMatched)
+
+			if len(tc.expected) == 0 {
+				assert.Nil(t, rul)
+			} else {
+				require.NotNil(t, rul)
+				assert.Equal(t, tc.expected, rul.ID())
+			}
+		})
+	}
+}
+
+func TestRuleIndexFindConsidersPriority(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	generic := newTestRule(t, "generic", "glob", "http://foo.bar/<**>")
+	specific := newTestRule(t, "specific", "glob", "http://foo.bar/users/<*>")
+	specific.priority = 1
+	fallback := newTestRule(t, "fallback", "glob", "http://foo.bar/<**>")
+	fallback.priority = -1
+
+	idx := newRuleIndex([]rule.Rule{fallback, generic, specific})
+
+	for path, expected := range map[string]string{
+		"/users/foo": "specific",
+		"/foo":       "generic",
+	} {
+		// WHEN
+		rul, _, _ := idx.find(&heimdall.Request{
+			Method: http.MethodGet,
+			URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: path}},
+		})
+
+		// THEN
+		require.NotNil(t, rul)
+		assert.Equal(t, expected, rul.ID())
+	}
+}
+
+func TestRuleIndexOfEmptyRuleSet(t *testing.T) {
+	t.Parallel()
+
+	var idx *ruleIndex
+
+	req := &heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar"}}}
+
+	rul, _, urlMatched := idx.find(req)
+	assert.Nil(t, rul)
+	assert.False(t, urlMatched)
+
+	rul, _, urlMatched = newRuleIndex(nil).find(req)
+	assert.Nil(t, rul)
+	assert.False(t, urlMatched)
+}
+
+func BenchmarkRepositoryFindRule(b *testing.B) {
+	for _, count := range []int{100, 1000, 10000} {
+		var rules []rule.Rule
+
+		for i := 0; i < count; i++ {
+			rules = append(rules, newTestRule(b, fmt.Sprintf("rule-%d", i), "glob",
+				fmt.Sprintf("https://service-%d.example.com/api/v1/resources-%d/<**>", i%100, i)))
+		}
+
+		repo := newRepository(nil, &ruleFactory{}, *zerolog.Ctx(context.Background()))
+		repo.addRuleSet("test", rules)
+
+		// the rule defined last is the worst case for a linear scan
+		reqURL := &url.URL{
+			Scheme: "https",
+			Host:   fmt.Sprintf("service-%d.example.com", (count-1)%100),
+			Path:   fmt.Sprintf("/api/v1/resources-%d/foo/bar", count-1),
+		}
+		req := &heimdall.Request{Method: http.MethodGet, URL: &heimdall.URL{URL: *reqURL}}
+
+		b.Run(fmt.Sprintf("indexed/rules=%d", count), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.FindRule(req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("linear/rules=%d", count), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				for _, rul := range rules {
+					if rul.MatchesURL(&req.URL.URL) && rul.MatchesRequest(req) && rul.MatchesMethod(req.Method) {
+						break
+					}
+				}
+			}
+		})
+	}
+}
@@ -17,44 +17,121 @@
 package rules
 
 import (
+	"context"
 	"errors"
+	"slices"
+	"sync"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 
 	"github.com/dadrus/heimdall/internal/heimdall"
 	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/event"
 	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/slicex"
+	"github.com/dadrus/heimdall/version"
 )
 
+const shadowingAttrKey = attribute.Key("shadowing")
+
 var ErrUnsupportedRuleSetVersion = errors.New("unsupported rule set version")
 
 type ruleSetProcessor struct {
 	q event.RuleSetChangedEventQueue
 	f rule.Factory
 	l zerolog.Logger
+
+	// serializes the processing of rule set changes and reloads, so that the events are sent
+	// in the order the rules have been created in. Guards f and ruleSets as well.
+	lock     sync.Mutex
+	ruleSets map[string]*config.RuleSet
+
+	// the rules of all known rule sets in the order these sets have been created, and the
+	// conflicts detected between them. Used to detect shadowed rules.
+	mutex     sync.Mutex
+	srcIDs    []string
+	rules     map[string][]rule.Rule
+	conflicts []rule.Conflict
 }
 
 func NewRuleSetProcessor(
 	queue event.RuleSetChangedEventQueue, factory rule.Factory, logger zerolog.Logger,
 ) rule.SetProcessor {
-	return &ruleSetProcessor{
-		q: queue,
-		f: factory,
-		l: logger,
+	return newRuleSetProcessor(queue, factory, logger)
+}
+
+func newRuleSetProcessor(
+	queue event.RuleSetChangedEventQueue, factory rule.Factory, logger zerolog.Logger,
+) *ruleSetProcessor {
+	processor := &ruleSetProcessor{
+		q:        queue,
+		f:        factory,
+		l:        logger,
+		ruleSets: make(map[string]*config.RuleSet),
+		rules:    make(map[string][]rule.Rule),
+	}
+
+	if err := processor.registerMetrics(otel.GetMeterProvider()); err != nil {
+		logger.Warn().Err(err).Msg("Failed registering shadowed rules metric")
 	}
+
+	return processor
+}
+
+func (p *ruleSetProcessor) registerMetrics(provider metric.MeterProvider) error {
+	meter := provider.Meter(
+		"github.com/dadrus/heimdall/internal/rules",
+		metric.WithInstrumentationVersion(version.Version),
+	)
+
+	shadowedRules, err := meter.Int64ObservableGauge(
+		"rules.shadowed",
+		metric.WithDescription("Number of rules shadowed by rules taking precedence over them"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(
+		func(_ context.Context, observer metric.Observer) error {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+
+			var partially int64
+
+			for _, conflict := range p.conflicts {
+				if conflict.Partial {
+					partially++
+				}
+			}
+
+			observer.ObserveInt64(shadowedRules, int64(len(p.conflicts))-partially,
+				metric.WithAttributes(shadowingAttrKey.String("full")))
+			observer.ObserveInt64(shadowedRules, partially,
+				metric.WithAttributes(shadowingAttrKey.String("partial")))
+
+			return nil
+		},
+		shadowedRules,
+	)
+
+	return err
 }
 
 func (p *ruleSetProcessor) isVersionSupported(version string) bool {
 	return version == config.CurrentRuleSetVersion
 }
 
-func (p *ruleSetProcessor) loadRules(ruleSet *config.RuleSet) ([]rule.Rule, error) {
+func (p *ruleSetProcessor) loadRules(factory rule.Factory, ruleSet *config.RuleSet) ([]rule.Rule, error) {
 	rules := make([]rule.Rule, len(ruleSet.Rules))
 
 	for idx, rc := range ruleSet.Rules {
-		rul, err := p.f.CreateRule(ruleSet.Version, ruleSet.Source, rc)
+		rul, err := factory.CreateRule(ruleSet.Version, ruleSet.Source, rc)
 		if err != nil {
 			return nil, errorchain.NewWithMessage(heimdall.ErrInternal, "failed loading rule").CausedBy(err)
 		}
@@ -70,14 +147,23 @@ func (p *ruleSetProcessor) OnCreated(ruleSet *config.RuleSet) error {
 		return errorchain.NewWithMessage(ErrUnsupportedRuleSetVersion, ruleSet.Version)
 	}
 
-	rules, err := p.loadRules(ruleSet)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rules, err := p.loadRules(p.f, ruleSet)
 	if err != nil {
 		return err
 	}
 
+	p.ruleSets[ruleSet.Source] = ruleSet
+	p.detectConflicts(ruleSet.Source, rules)
+
 	evt := event.RuleSetChanged{
 		Source:     ruleSet.Source,
 		Name:       ruleSet.Name,
+		Hash:       ruleSet.Hash,
+		ModTime:    ruleSet.ModTime,
+		Revision:   ruleSet.Revision,
 		Rules:      rules,
 		ChangeType: event.Create,
 	}
@@ -92,14 +178,23 @@ func (p *ruleSetProcessor) OnUpdated(ruleSet *config.RuleSet) error {
 		return errorchain.NewWithMessage(ErrUnsupportedRuleSetVersion, ruleSet.Version)
 	}
 
-	rules, err := p.loadRules(ruleSet)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	rules, err := p.loadRules(p.f, ruleSet)
 	if err != nil {
 		return err
 	}
 
+	p.ruleSets[ruleSet.Source] = ruleSet
+	p.detectConflicts(ruleSet.Source, rules)
+
 	evt := event.RuleSetChanged{
 		Source:     ruleSet.Source,
 		Name:       ruleSet.Name,
+		Hash:       ruleSet.Hash,
+		ModTime:    ruleSet.ModTime,
+		Revision:   ruleSet.Revision,
 		Rules:      rules,
 		ChangeType: event.Update,
 	}
@@ -110,6 +205,12 @@ func (p *ruleSetProcessor) OnUpdated(ruleSet *config.RuleSet) error {
 }
 
 func (p *ruleSetProcessor) OnDeleted(ruleSet *config.RuleSet) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.ruleSets, ruleSet.Source)
+	p.forgetRuleSet(ruleSet.Source)
+
 	evt := event.RuleSetChanged{
 		Source:     ruleSet.Source,
 		Name:       ruleSet.Name,
@@ -121,6 +222,107 @@ func (p *ruleSetProcessor) OnDeleted(ruleSet *config.RuleSet) error {
 	return nil
 }
 
+// reload re-creates the rules of all known rule sets using the given factory. If the creation of
+// any rule fails, nothing is changed and the error is returned. Otherwise, the given factory is
+// used for all subsequent changes and the re-created rules replace all existing ones at once.
+func (p *ruleSetProcessor) reload(factory rule.Factory) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	srcIDs := func() []string {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		return slices.Clone(p.srcIDs)
+	}()
+
+	var all []rule.Rule
+
+	reloaded := make(map[string][]rule.Rule, len(srcIDs))
+
+	for _, srcID := range srcIDs {
+		rules, err := p.loadRules(factory, p.ruleSets[srcID])
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrConfiguration,
+				"failed to re-create rules from %s", srcID).CausedBy(err)
+		}
+
+		reloaded[srcID] = rules
+		all = append(all, rules...)
+	}
+
+	p.f = factory
+
+	func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		p.rules = reloaded
+	}()
+
+	p.sendEvent(event.RuleSetChanged{
+		Source:     "config",
+		Rules:      all,
+		Default:    x.IfThenElseExec(factory.HasDefaultRule(), factory.DefaultRule, func() rule.Rule { return nil }),
+		ChangeType: event.Reload,
+	})
+
+	return nil
+}
+
+func (p *ruleSetProcessor) Conflicts(srcID string) []rule.Conflict {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return slicex.Filter(p.conflicts, func(conflict rule.Conflict) bool { return involves(conflict, srcID) })
+}
+
+func (p *ruleSetProcessor) detectConflicts(srcID string, rules []rule.Rule) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, known := p.rules[srcID]; !known {
+		p.srcIDs = append(p.srcIDs, srcID)
+	}
+
+	p.rules[srcID] = rules
+
+	var all []rule.Rule
+	for _, id := range p.srcIDs {
+		all = append(all, p.rules[id]...)
+	}
+
+	conflicts := detectConflicts(all, srcID)
+
+	for _, conflict := range conflicts {
+		p.l.Warn().
+			Str("_src", conflict.SrcID).
+			Str("_id", conflict.RuleID).
+			Str("_shadowed_by_src", conflict.ShadowedBySrcID).
+			Str("_shadowed_by_id", conflict.ShadowedByID).
+			Msg(x.IfThenElse(conflict.Partial, "Rule partially shadowed", "Rule shadowed and unreachable"))
+	}
+
+	p.conflicts = append(
+		slicex.Filter(p.conflicts, func(conflict rule.Conflict) bool { return !involves(conflict, srcID) }),
+		conflicts...,
+	)
+}
+
+func (p *ruleSetProcessor) forgetRuleSet(srcID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.rules, srcID)
+
+	p.srcIDs = slicex.Filter(p.srcIDs, func(id string) bool { return id != srcID })
+	p.conflicts = slicex.Filter(p.conflicts, func(conflict rule.Conflict) bool { return !involves(conflict, srcID) })
+}
+
+func involves(conflict rule.Conflict, srcID string) bool {
+	return conflict.SrcID == srcID || conflict.ShadowedBySrcID == srcID
+}
+
 func (p *ruleSetProcessor) sendEvent(evt event.RuleSetChanged) {
 	p.l.Info().
 		Str("_src", evt.Source).
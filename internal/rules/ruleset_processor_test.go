@@ -18,6 +18,7 @@ package rules
 
 import (
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/dadrus/heimdall/internal/rules/config"
 	"github.com/dadrus/heimdall/internal/rules/event"
+	"github.com/dadrus/heimdall/internal/rules/rule"
 	"github.com/dadrus/heimdall/internal/rules/rule/mocks"
 	"github.com/dadrus/heimdall/internal/x"
 	"github.com/dadrus/heimdall/internal/x/testsupport"
@@ -70,7 +72,7 @@ func TestRuleSetProcessorOnCreated(t *testing.T) {
 		{
 			uc: "successful",
 			ruleset: &config.RuleSet{
-				MetaData: config.MetaData{Source: "test"},
+				MetaData: config.MetaData{Source: "test", Hash: []byte{1}, ModTime: time.Unix(100, 0)},
 				Version:  config.CurrentRuleSetVersion,
 				Name:     "foobar",
 				Rules:    []config.Rule{{ID: "foo"}},
@@ -91,6 +93,8 @@ func TestRuleSetProcessorOnCreated(t *testing.T) {
 				assert.Equal(t, event.Create, evt.ChangeType)
 				assert.Equal(t, "test", evt.Source)
 				assert.Equal(t, "foobar", evt.Name)
+				assert.Equal(t, []byte{1}, evt.Hash)
+				assert.Equal(t, time.Unix(100, 0), evt.ModTime)
 
 				assert.Equal(t, &mocks.RuleMock{}, evt.Rules[0])
 			},
@@ -157,7 +161,7 @@ func TestRuleSetProcessorOnUpdated(t *testing.T) {
 		{
 			uc: "successful",
 			ruleset: &config.RuleSet{
-				MetaData: config.MetaData{Source: "test"},
+				MetaData: config.MetaData{Source: "test", Hash: []byte{1}, ModTime: time.Unix(100, 0)},
 				Version:  config.CurrentRuleSetVersion,
 				Name:     "foobar",
 				Rules:    []config.Rule{{ID: "foo"}},
@@ -179,6 +183,8 @@ func TestRuleSetProcessorOnUpdated(t *testing.T) {
 				assert.Equal(t, event.Update, evt.ChangeType)
 				assert.Equal(t, "test", evt.Source)
 				assert.Equal(t, "foobar", evt.Name)
+				assert.Equal(t, []byte{1}, evt.Hash)
+				assert.Equal(t, time.Unix(100, 0), evt.ModTime)
 
 				assert.Equal(t, &mocks.RuleMock{}, evt.Rules[0])
 			},
@@ -247,3 +253,54 @@ func TestRuleSetProcessorOnDeleted(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleSetProcessorConflicts(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	rules := map[string]rule.Rule{
+		"generic":  newTestRule(t, "generic", "glob", "http://foo.bar/<**>"),
+		"specific": newTestRule(t, "specific", "glob", "http://foo.bar/users/<*>"),
+	}
+
+	factory := mocks.NewFactoryMock(t)
+	factory.EXPECT().CreateRule(config.CurrentRuleSetVersion, mock.Anything, mock.Anything).
+		RunAndReturn(func(_, srcID string, conf config.Rule) (rule.Rule, error) {
+			rul := rules[conf.ID].(*ruleImpl) // nolint: forcetypeassert
+			rul.srcID = srcID
+
+			return rul, nil
+		})
+
+	queue := make(event.RuleSetChangedEventQueue, 10)
+	processor := NewRuleSetProcessor(queue, factory, log.Logger)
+
+	first := &config.RuleSet{
+		MetaData: config.MetaData{Source: "first"},
+		Version:  config.CurrentRuleSetVersion,
+		Rules:    []config.Rule{{ID: "generic"}},
+	}
+	second := &config.RuleSet{
+		MetaData: config.MetaData{Source: "second"},
+		Version:  config.CurrentRuleSetVersion,
+		Rules:    []config.Rule{{ID: "specific"}},
+	}
+
+	// WHEN
+	require.NoError(t, processor.OnCreated(first))
+	require.NoError(t, processor.OnCreated(second))
+
+	// THEN
+	expected := []rule.Conflict{
+		{RuleID: "specific", SrcID: "second", ShadowedByID: "generic", ShadowedBySrcID: "first"},
+	}
+
+	assert.Equal(t, expected, processor.Conflicts("first"))
+	assert.Equal(t, expected, processor.Conflicts("second"))
+
+	// WHEN
+	require.NoError(t, processor.OnDeleted(first))
+
+	// THEN
+	assert.Empty(t, processor.Conflicts("second"))
+}
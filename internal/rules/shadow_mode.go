@@ -0,0 +1,210 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"maps"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dadrus/heimdall/internal/explain"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/version"
+)
+
+const (
+	modeEnforce = "enforce"
+	modeShadow  = "shadow"
+
+	shadowTypeAttrKey     = attribute.Key("type")
+	shadowIDAttrKey       = attribute.Key("id")
+	shadowDecisionAttrKey = attribute.Key("decision")
+)
+
+// isShadowMode returns whether the given mode value requests the shadow mode. An empty
+// value stands for the enforce mode.
+func isShadowMode(mode any) (bool, error) {
+	if mode == nil {
+		return false, nil
+	}
+
+	value, ok := mode.(string)
+	if !ok {
+		return false, errorchain.NewWithMessagef(heimdall.ErrConfiguration, "unexpected type '%T' for mode", mode)
+	}
+
+	switch value {
+	case "", modeEnforce:
+		return false, nil
+	case modeShadow:
+		return true, nil
+	default:
+		return false, errorchain.NewWithMessagef(heimdall.ErrConfiguration, "unsupported mode '%s'", value)
+	}
+}
+
+// shadowContext is used to execute pipelines in shadow mode. It shares the request with
+// the actual context, but drops everything, which would affect the request forwarded to
+// the upstream service or the response sent to the client.
+type shadowContext struct {
+	heimdall.Context
+}
+
+func (c shadowContext) AddHeaderForUpstream(_, _ string) {}
+
+func (c shadowContext) AddCookieForUpstream(_, _ string) {}
+
+func (c shadowContext) SetPipelineError(_ error) {}
+
+func (c shadowContext) shadowMode() bool { return true }
+
+// shadowModeAware is implemented by contexts knowing whether pipelines are executed in shadow
+// mode. Contexts wrapping other contexts must delegate to the wrapped one.
+type shadowModeAware interface {
+	shadowMode() bool
+}
+
+// inShadowMode returns true if the given context, or a context wrapped by it, is a shadow context.
+func inShadowMode(ctx heimdall.Context) bool {
+	aware, ok := ctx.(shadowModeAware)
+
+	return ok && aware.shadowMode()
+}
+
+// shadowRecorder records the decisions made in shadow mode in logs, metrics and traces.
+type shadowRecorder struct {
+	decisions metric.Int64Counter
+}
+
+func newShadowRecorder(provider metric.MeterProvider) (*shadowRecorder, error) {
+	meter := provider.Meter(
+		"github.com/dadrus/heimdall/internal/rules",
+		metric.WithInstrumentationVersion(version.Version),
+	)
+
+	decisions, err := meter.Int64Counter(
+		"rules.shadow.decisions",
+		metric.WithDescription("Number of decisions made by rules and mechanisms operated in shadow mode"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &shadowRecorder{decisions: decisions}, nil
+}
+
+// record reports the decision made by the rule or mechanism with the given type and id. The
+// decision is "deny" if the given error is not nil and "allow" otherwise.
+func (r *shadowRecorder) record(ctx heimdall.Context, typ, id string, err error) {
+	logger := zerolog.Ctx(ctx.AppContext())
+	decision := x.IfThenElse(err == nil, "allow", "deny")
+	attrs := []attribute.KeyValue{
+		shadowTypeAttrKey.String(typ),
+		shadowIDAttrKey.String(id),
+		shadowDecisionAttrKey.String(decision),
+	}
+
+	if err != nil {
+		logger.Warn().Err(err).Str("_type", typ).Str("_id", id).
+			Msg("Shadow mode: request would have been denied")
+		trace.SpanFromContext(ctx.AppContext()).AddEvent("shadow decision",
+			trace.WithAttributes(append(attrs, attribute.String("error", err.Error()))...))
+	} else {
+		logger.Info().Str("_type", typ).Str("_id", id).
+			Msg("Shadow mode: request would have been allowed")
+		trace.SpanFromContext(ctx.AppContext()).AddEvent("shadow decision", trace.WithAttributes(attrs...))
+	}
+
+	if r != nil {
+		r.decisions.Add(ctx.AppContext(), 1, metric.WithAttributes(attrs...))
+	}
+}
+
+// shadowedRule executes the rules operated in shadow mode, which match the request, before
+// executing the rule actually handling it.
+type shadowedRule struct {
+	rule.Rule
+
+	shadows []rule.Rule
+}
+
+func withShadowRules(rul rule.Rule, shadows []rule.Rule) rule.Rule {
+	if len(shadows) == 0 {
+		return rul
+	}
+
+	return &shadowedRule{Rule: rul, shadows: shadows}
+}
+
+func (r *shadowedRule) Execute(ctx heimdall.Context) (rule.Backend, error) {
+	executeShadows(ctx, r.shadows)
+
+	return r.Rule.Execute(ctx)
+}
+
+func executeShadows(ctx heimdall.Context, shadows []rule.Rule) {
+	for _, shadow := range shadows {
+		// rules operated in shadow mode never fail
+		_, _ = shadow.Execute(ctx)
+	}
+}
+
+// unhandledRequestError is returned if only rules operated in shadow mode match the request.
+// The rule executor executes these to record their decisions before rejecting the request.
+type unhandledRequestError struct {
+	error
+
+	shadows []rule.Rule
+}
+
+func (e *unhandledRequestError) Unwrap() error { return e.error }
+
+// shadowSubjectHandler executes the wrapped handler in shadow mode. The handler operates on
+// a copy of the subject, its failures are recorded, but never reported to the pipeline.
+type shadowSubjectHandler struct {
+	h  subjectHandler
+	sr *shadowRecorder
+}
+
+func (h *shadowSubjectHandler) Execute(ctx heimdall.Context, sub *subject.Subject) error {
+	shadowSub := &subject.Subject{ID: sub.ID, Attributes: maps.Clone(sub.Attributes)}
+
+	err := h.h.Execute(shadowContext{Context: ctx}, shadowSub)
+	h.sr.record(ctx, "mechanism", h.h.ID(), err)
+
+	if step := explainStep(ctx); step != nil {
+		step.Shadow = true
+
+		if err != nil {
+			step.Outcome = explain.OutcomeFailure
+			step.Error = err.Error()
+		}
+	}
+
+	return nil
+}
+
+func (h *shadowSubjectHandler) ID() string { return h.h.ID() }
+
+func (h *shadowSubjectHandler) ContinueOnError() bool { return true }
@@ -0,0 +1,264 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/dadrus/heimdall/internal/explain"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	heimdallmocks "github.com/dadrus/heimdall/internal/heimdall/mocks"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mocks"
+	"github.com/dadrus/heimdall/internal/rules/rule"
+	rulemocks "github.com/dadrus/heimdall/internal/rules/rule/mocks"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func TestIsShadowMode(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		mode   any
+		shadow bool
+		err    bool
+	}{
+		{uc: "not set"},
+		{uc: "empty", mode: ""},
+		{uc: "enforce", mode: "enforce"},
+		{uc: "shadow", mode: "shadow", shadow: true},
+		{uc: "unsupported value", mode: "foo", err: true},
+		{uc: "unexpected type", mode: true, err: true},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			shadow, err := isShadowMode(tc.mode)
+
+			// THEN
+			if tc.err {
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.shadow, shadow)
+			}
+		})
+	}
+}
+
+func TestInShadowMode(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		ctx    func(ctx heimdall.Context) heimdall.Context
+		shadow bool
+	}{
+		{uc: "regular context", ctx: func(ctx heimdall.Context) heimdall.Context { return ctx }},
+		{
+			uc:     "shadow context",
+			ctx:    func(ctx heimdall.Context) heimdall.Context { return shadowContext{Context: ctx} },
+			shadow: true,
+		},
+		{
+			uc: "explain context wrapping a regular context",
+			ctx: func(ctx heimdall.Context) heimdall.Context {
+				return explainContext{Context: ctx, step: &explain.Step{}}
+			},
+		},
+		{
+			uc: "explain context wrapping a shadow context",
+			ctx: func(ctx heimdall.Context) heimdall.Context {
+				return explainContext{Context: shadowContext{Context: ctx}, step: &explain.Step{}}
+			},
+			shadow: true,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			shadow := inShadowMode(tc.ctx(heimdallmocks.NewContextMock(t)))
+
+			// THEN
+			assert.Equal(t, tc.shadow, shadow)
+		})
+	}
+}
+
+func TestShadowSubjectHandlerExecute(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc  string
+		err error
+	}{
+		{uc: "wrapped handler succeeds"},
+		{uc: "wrapped handler fails", err: testsupport.ErrTestPurpose},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			sr, err := newShadowRecorder(noop.NewMeterProvider())
+			require.NoError(t, err)
+
+			sub := &subject.Subject{ID: "foo", Attributes: map[string]any{"bar": "baz"}}
+
+			ctx := heimdallmocks.NewContextMock(t)
+			ctx.EXPECT().AppContext().Return(context.Background())
+
+			handler := mocks.NewSubjectHandlerMock(t)
+			handler.EXPECT().ID().Return("test")
+			handler.EXPECT().Execute(mock.Anything, mock.Anything).
+				Run(func(hctx heimdall.Context, hsub *subject.Subject) {
+					// results must not leak into the actual context and subject
+					hctx.AddHeaderForUpstream("X-Foo", "bar")
+					hctx.AddCookieForUpstream("foo", "bar")
+					hsub.Attributes["bar"] = "changed"
+				}).
+				Return(tc.err)
+
+			shadowHandler := &shadowSubjectHandler{h: handler, sr: sr}
+
+			// WHEN
+			err = shadowHandler.Execute(ctx, sub)
+
+			// THEN
+			require.NoError(t, err)
+			assert.True(t, shadowHandler.ContinueOnError())
+			assert.Equal(t, "test", shadowHandler.ID())
+			assert.Equal(t, map[string]any{"bar": "baz"}, sub.Attributes)
+		})
+	}
+}
+
+func TestShadowedRuleExecute(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var order []string
+
+	ctx := heimdallmocks.NewContextMock(t)
+
+	shadow := rulemocks.NewRuleMock(t)
+	shadow.EXPECT().Execute(ctx).Run(func(_ heimdall.Context) { order = append(order, "shadow") }).
+		Return(nil, nil)
+
+	actual := rulemocks.NewRuleMock(t)
+	actual.EXPECT().Execute(ctx).Run(func(_ heimdall.Context) { order = append(order, "actual") }).
+		Return(nil, testsupport.ErrTestPurpose)
+
+	rul := withShadowRules(actual, []rule.Rule{shadow})
+
+	// WHEN
+	_, err := rul.Execute(ctx)
+
+	// THEN
+	require.ErrorIs(t, err, testsupport.ErrTestPurpose)
+	assert.Equal(t, []string{"shadow", "actual"}, order)
+	assert.Equal(t, actual, withShadowRules(actual, nil))
+}
+
+func TestRepositoryFindRuleWithShadowRules(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	shadow := newTestRule(t, "shadow", "glob", "http://foo.bar/<**>")
+	shadow.shadow = true
+
+	enforced := newTestRule(t, "enforced", "glob", "http://foo.bar/api/<**>")
+
+	for _, tc := range []struct {
+		uc          string
+		path        string
+		defaultRule rule.Rule
+		assert      func(t *testing.T, err error, rul rule.Rule)
+	}{
+		{
+			uc:   "enforced rule with shadow rule",
+			path: "/api/foo",
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, &shadowedRule{Rule: enforced, shadows: []rule.Rule{shadow}}, rul)
+			},
+		},
+		{
+			uc:          "default rule with shadow rule",
+			path:        "/foo",
+			defaultRule: &ruleImpl{id: "default", isDefault: true, methods: []string{http.MethodGet}},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.IsType(t, &shadowedRule{}, rul)
+				assert.Equal(t, "default", rul.ID())
+				assert.Equal(t, []rule.Rule{shadow}, rul.(*shadowedRule).shadows)
+			},
+		},
+		{
+			uc:   "only shadow rule without default rule",
+			path: "/foo",
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrNoRuleFound)
+				assert.Nil(t, rul)
+
+				var unhandled *unhandledRequestError
+				require.ErrorAs(t, err, &unhandled)
+				assert.Equal(t, []rule.Rule{shadow}, unhandled.shadows)
+			},
+		},
+		{
+			uc:          "only shadow rule with default rule not matching the method",
+			path:        "/foo",
+			defaultRule: &ruleImpl{id: "default", isDefault: true, methods: []string{http.MethodPost}},
+			assert: func(t *testing.T, err error, rul rule.Rule) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrMethodNotAllowed)
+				assert.Nil(t, rul)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			repo := newRepository(nil, &ruleFactory{}, log.Logger)
+			repo.setDefaultRule(tc.defaultRule)
+			repo.addRuleSet("test", []rule.Rule{shadow, enforced})
+
+			// WHEN
+			rul, err := repo.FindRule(&heimdall.Request{
+				Method: http.MethodGet,
+				URL:    &heimdall.URL{URL: url.URL{Scheme: "http", Host: "foo.bar", Path: tc.path}},
+			})
+
+			// THEN
+			tc.assert(t, err, rul)
+		})
+	}
+}
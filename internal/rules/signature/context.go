@@ -0,0 +1,36 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import "context"
+
+type ctxKey struct{}
+
+// WithContext returns a copy of ctx with the given verifier associated.
+func WithContext(ctx context.Context, verifier Verifier) context.Context {
+	return context.WithValue(ctx, ctxKey{}, verifier)
+}
+
+// Ctx returns the Verifier associated with the ctx. If no verifier is associated, an instance
+// is returned, which does not verify anything.
+func Ctx(ctx context.Context) Verifier {
+	if v, ok := ctx.Value(ctxKey{}).(Verifier); ok {
+		return v
+	}
+
+	return noopVerifier{}
+}
@@ -0,0 +1,207 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"regexp"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/truststore"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+	"github.com/dadrus/heimdall/internal/x/pkix"
+	"github.com/dadrus/heimdall/version"
+)
+
+// ContentTypeJOSE is the media type of rule sets embedded in a JWS in compact serialization.
+const ContentTypeJOSE = "application/jose"
+
+const resultAttrKey = attribute.Key("result")
+
+var (
+	ErrVerification = errors.New("rule set signature verification failed")
+
+	// nolint: gochecknoglobals
+	compactJWSPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`)
+)
+
+// Verifier verifies the signatures of rule sets loaded by the providers.
+type Verifier interface {
+	// Verify verifies the signature of the rule set with the given contents loaded from the given
+	// source and returns the contents the rule set is to be parsed from. The signature is either
+	// given as a detached JWS in compact serialization, or the contents are a JWS in compact
+	// serialization embedding the rule set. In the latter case the payload of the JWS is returned.
+	Verify(ctx context.Context, src string, contents []byte, detachedSignature string) ([]byte, error)
+}
+
+type verifier struct {
+	ts           truststore.TrustStore
+	required     bool
+	verification metric.Int64Counter
+}
+
+func NewVerifier(conf *config.Configuration, logger zerolog.Logger) (Verifier, error) {
+	sigConf := conf.Providers.Signature
+	ver := &verifier{}
+
+	if sigConf != nil {
+		if len(sigConf.TrustStore) == 0 {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"no trust store configured for rule set signature verification")
+		}
+
+		trustStore, err := truststore.NewTrustStoreFromPEMFile(sigConf.TrustStore, true)
+		if err != nil {
+			return nil, errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"failed loading trust store for rule set signature verification").CausedBy(err)
+		}
+
+		ver.ts = trustStore
+		ver.required = sigConf.Required
+	}
+
+	meter := otel.GetMeterProvider().Meter(
+		"github.com/dadrus/heimdall/internal/rules/signature",
+		metric.WithInstrumentationVersion(version.Version),
+	)
+
+	counter, err := meter.Int64Counter(
+		"rules.signature.verifications",
+		metric.WithDescription("Number of rule set signature verifications by result"),
+	)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed registering rule set signature verification metric")
+	} else {
+		ver.verification = counter
+	}
+
+	return ver, nil
+}
+
+func (v *verifier) Verify(ctx context.Context, src string, contents []byte, detachedSignature string) ([]byte, error) {
+	if len(bytes.TrimSpace(contents)) == 0 && len(detachedSignature) == 0 {
+		// empty rule sets are treated by the providers as removed ones
+		return contents, nil
+	}
+
+	payload, result, err := v.verify(contents, detachedSignature)
+
+	if v.verification != nil {
+		v.verification.Add(ctx, 1, metric.WithAttributes(resultAttrKey.String(result)))
+	}
+
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("_src", src).Msg("Rejecting rule set")
+
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func (v *verifier) verify(contents []byte, detachedSignature string) ([]byte, string, error) {
+	embedded := len(detachedSignature) == 0 && compactJWSPattern.Match(bytes.TrimSpace(contents))
+
+	if len(detachedSignature) == 0 && !embedded {
+		if v.required {
+			return nil, "rejected", errorchain.NewWithMessage(heimdall.ErrConfiguration,
+				"rule set is not signed, but signatures are required").CausedBy(ErrVerification)
+		}
+
+		return contents, "unsigned", nil
+	}
+
+	if len(v.ts) == 0 {
+		return nil, "rejected", errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"rule set is signed, but signature verification is not configured").CausedBy(ErrVerification)
+	}
+
+	var (
+		jws *jose.JSONWebSignature
+		err error
+	)
+
+	if embedded {
+		jws, err = jose.ParseSigned(string(bytes.TrimSpace(contents)))
+	} else {
+		jws, err = jose.ParseDetached(detachedSignature, contents)
+	}
+
+	if err != nil {
+		return nil, "rejected", errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"failed to parse rule set signature").CausedBy(ErrVerification).CausedBy(err)
+	}
+
+	payload, err := v.verifyJWS(jws)
+	if err != nil {
+		return nil, "rejected", errorchain.NewWithMessage(heimdall.ErrConfiguration,
+			"invalid rule set signature").CausedBy(ErrVerification).CausedBy(err)
+	}
+
+	return payload, "verified", nil
+}
+
+func (v *verifier) verifyJWS(jws *jose.JSONWebSignature) ([]byte, error) {
+	if len(jws.Signatures) != 1 {
+		return nil, errors.New("exactly one signature expected")
+	}
+
+	roots := x509.NewCertPool()
+	for _, cert := range v.ts {
+		roots.AddCert(cert)
+	}
+
+	// only the protected header is covered by the signature. So the signer certificate
+	// must be present there.
+	chains, err := jws.Signatures[0].Protected.Certificates(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signer := chains[0][0]
+
+	if err = pkix.ValidateCertificate(signer,
+		pkix.WithIntermediateCACertificates(chains[0][1:]),
+		pkix.WithRootCACertificates(v.ts),
+		pkix.WithKeyUsage(x509.KeyUsageDigitalSignature),
+	); err != nil {
+		return nil, err
+	}
+
+	return jws.Verify(signer.PublicKey)
+}
+
+// ContentType returns the content type the contents returned by Verify are to be parsed with,
+// given the content type reported by the source of the rule set.
+func ContentType(contentType string) string {
+	// the payload of an embedding JWS is either YAML or JSON. The YAML parser can handle both.
+	return x.IfThenElse(contentType == ContentTypeJOSE, "application/yaml", contentType)
+}
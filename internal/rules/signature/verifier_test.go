@@ -0,0 +1,505 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/dadrus/heimdall/internal/config"
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/x/pkix/pemx"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+const testRuleSet = `
+version: "1alpha2"
+rules:
+- id: foo
+  match: http://foo.bar/<**>
+  execute:
+  - authenticator: bar
+`
+
+type testSigner struct {
+	key   *ecdsa.PrivateKey
+	certs []*x509.Certificate
+}
+
+func (s testSigner) sign(t *testing.T, payload []byte) *jose.JSONWebSignature {
+	t.Helper()
+
+	x5c := make([]string, len(s.certs))
+	for idx, cert := range s.certs {
+		x5c[idx] = encodeCertificate(cert)
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES384, Key: s.key},
+		(&jose.SignerOptions{}).WithHeader("x5c", x5c))
+	require.NoError(t, err)
+
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+
+	return jws
+}
+
+func encodeCertificate(cert *x509.Certificate) string {
+	return base64.StdEncoding.EncodeToString(cert.Raw)
+}
+
+func newPKI(t *testing.T) (*testsupport.CA, testSigner, testSigner) {
+	t.Helper()
+
+	rootCA, err := testsupport.NewRootCA("Test Root CA 1", time.Hour*24)
+	require.NoError(t, err)
+
+	intCAPrivKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	intCACert, err := rootCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{
+			CommonName:   "Test Int CA 1",
+			Organization: []string{"Test"},
+			Country:      []string{"EU"},
+		}),
+		testsupport.WithIsCA(),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&intCAPrivKey.PublicKey, x509.ECDSAWithSHA384))
+	require.NoError(t, err)
+
+	intCA := testsupport.NewCA(intCAPrivKey, intCACert)
+
+	signerPrivKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	signerCert, err := intCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{
+			CommonName:   "Rule Set Signer",
+			Organization: []string{"Test"},
+			Country:      []string{"EU"},
+		}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&signerPrivKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature))
+	require.NoError(t, err)
+
+	otherPrivKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	otherCert, err := intCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{
+			CommonName:   "Other",
+			Organization: []string{"Test"},
+			Country:      []string{"EU"},
+		}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&otherPrivKey.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageKeyEncipherment))
+	require.NoError(t, err)
+
+	return rootCA,
+		testSigner{key: signerPrivKey, certs: []*x509.Certificate{signerCert, intCACert}},
+		testSigner{key: otherPrivKey, certs: []*x509.Certificate{otherCert, intCACert}}
+}
+
+func writeTrustStore(t *testing.T, certs ...*x509.Certificate) string {
+	t.Helper()
+
+	opts := make([]pemx.EntryOption, len(certs))
+	for idx, cert := range certs {
+		opts[idx] = pemx.WithX509Certificate(cert)
+	}
+
+	pemBytes, err := pemx.BuildPEM(opts...)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "trust_store.pem")
+	require.NoError(t, os.WriteFile(path, pemBytes, 0o600))
+
+	return path
+}
+
+func TestNewVerifier(t *testing.T) {
+	t.Parallel()
+
+	rootCA, _, _ := newPKI(t)
+	trustStorePath := writeTrustStore(t, rootCA.Certificate)
+
+	for _, tc := range []struct {
+		uc     string
+		conf   *config.RuleSetSignature
+		assert func(t *testing.T, err error, ver Verifier)
+	}{
+		{
+			uc: "without signature configuration",
+			assert: func(t *testing.T, err error, ver Verifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, ver)
+
+				impl, ok := ver.(*verifier)
+				require.True(t, ok)
+				assert.Empty(t, impl.ts)
+				assert.False(t, impl.required)
+			},
+		},
+		{
+			uc:   "without trust store",
+			conf: &config.RuleSetSignature{Required: true},
+			assert: func(t *testing.T, err error, _ Verifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "no trust store")
+			},
+		},
+		{
+			uc:   "with not existing trust store",
+			conf: &config.RuleSetSignature{TrustStore: "/does/not/exist.pem"},
+			assert: func(t *testing.T, err error, _ Verifier) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				assert.Contains(t, err.Error(), "failed loading trust store")
+			},
+		},
+		{
+			uc:   "with valid configuration",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath, Required: true},
+			assert: func(t *testing.T, err error, ver Verifier) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, ver)
+
+				impl, ok := ver.(*verifier)
+				require.True(t, ok)
+				assert.Len(t, impl.ts, 1)
+				assert.True(t, impl.required)
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			conf := &config.Configuration{Providers: config.RuleProviders{Signature: tc.conf}}
+
+			// WHEN
+			ver, err := NewVerifier(conf, log.Logger)
+
+			// THEN
+			tc.assert(t, err, ver)
+		})
+	}
+}
+
+func TestVerifierVerify(t *testing.T) {
+	t.Parallel()
+
+	rootCA, signer, other := newPKI(t)
+	trustStorePath := writeTrustStore(t, rootCA.Certificate)
+
+	untrustedCA, err := testsupport.NewRootCA("Untrusted Root CA", time.Hour*24)
+	require.NoError(t, err)
+
+	untrustedCert, err := untrustedCA.IssueCertificate(
+		testsupport.WithSubject(pkix.Name{CommonName: "Untrusted Signer"}),
+		testsupport.WithValidity(time.Now(), time.Hour*24),
+		testsupport.WithSubjectPubKey(&signer.key.PublicKey, x509.ECDSAWithSHA384),
+		testsupport.WithKeyUsage(x509.KeyUsageDigitalSignature))
+	require.NoError(t, err)
+
+	untrusted := testSigner{key: signer.key, certs: []*x509.Certificate{untrustedCert}}
+
+	for _, tc := range []struct {
+		uc       string
+		conf     *config.RuleSetSignature
+		contents func(t *testing.T) ([]byte, string)
+		assert   func(t *testing.T, err error, payload []byte)
+	}{
+		{
+			uc:   "empty contents are passed through",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath, Required: true},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				return []byte("  \n"), ""
+			},
+			assert: func(t *testing.T, err error, payload []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []byte("  \n"), payload)
+			},
+		},
+		{
+			uc: "unsigned rule set without signature configuration",
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				return []byte(testRuleSet), ""
+			},
+			assert: func(t *testing.T, err error, payload []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []byte(testRuleSet), payload)
+			},
+		},
+		{
+			uc:   "unsigned rule set with optional signatures",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				return []byte(testRuleSet), ""
+			},
+			assert: func(t *testing.T, err error, payload []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []byte(testRuleSet), payload)
+			},
+		},
+		{
+			uc:   "unsigned rule set with required signatures",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath, Required: true},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				return []byte(testRuleSet), ""
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, heimdall.ErrConfiguration)
+				require.ErrorIs(t, err, ErrVerification)
+				assert.Contains(t, err.Error(), "not signed")
+			},
+		},
+		{
+			uc: "signed rule set without signature configuration",
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				jws := signer.sign(t, []byte(testRuleSet))
+				serialized, err := jws.CompactSerialize()
+				require.NoError(t, err)
+
+				return []byte(serialized), ""
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrVerification)
+				assert.Contains(t, err.Error(), "not configured")
+			},
+		},
+		{
+			uc:   "valid embedding signature",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath, Required: true},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				jws := signer.sign(t, []byte(testRuleSet))
+				serialized, err := jws.CompactSerialize()
+				require.NoError(t, err)
+
+				return []byte(serialized + "\n"), ""
+			},
+			assert: func(t *testing.T, err error, payload []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []byte(testRuleSet), payload)
+			},
+		},
+		{
+			uc:   "valid detached signature",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath, Required: true},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				jws := signer.sign(t, []byte(testRuleSet))
+				serialized, err := jws.DetachedCompactSerialize()
+				require.NoError(t, err)
+
+				return []byte(testRuleSet), serialized
+			},
+			assert: func(t *testing.T, err error, payload []byte) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, []byte(testRuleSet), payload)
+			},
+		},
+		{
+			uc:   "detached signature over different contents",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				jws := signer.sign(t, []byte(testRuleSet))
+				serialized, err := jws.DetachedCompactSerialize()
+				require.NoError(t, err)
+
+				return []byte(testRuleSet + "\n- id: bar\n"), serialized
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrVerification)
+				assert.Contains(t, err.Error(), "invalid rule set signature")
+			},
+		},
+		{
+			uc:   "malformed detached signature",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				return []byte(testRuleSet), "foo..bar"
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrVerification)
+				assert.Contains(t, err.Error(), "failed to parse")
+			},
+		},
+		{
+			uc:   "signature by certificate issued by an untrusted CA",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				jws := untrusted.sign(t, []byte(testRuleSet))
+				serialized, err := jws.CompactSerialize()
+				require.NoError(t, err)
+
+				return []byte(serialized), ""
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrVerification)
+				assert.Contains(t, err.Error(), "invalid rule set signature")
+			},
+		},
+		{
+			uc:   "signature by certificate not allowed for digital signatures",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				jws := other.sign(t, []byte(testRuleSet))
+				serialized, err := jws.CompactSerialize()
+				require.NoError(t, err)
+
+				return []byte(serialized), ""
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrVerification)
+				assert.Contains(t, err.Error(), "invalid rule set signature")
+			},
+		},
+		{
+			uc:   "signature without certificate",
+			conf: &config.RuleSetSignature{TrustStore: trustStorePath},
+			contents: func(t *testing.T) ([]byte, string) {
+				t.Helper()
+
+				jws := testSigner{key: signer.key}.sign(t, []byte(testRuleSet))
+				serialized, err := jws.CompactSerialize()
+				require.NoError(t, err)
+
+				return []byte(serialized), ""
+			},
+			assert: func(t *testing.T, err error, _ []byte) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrVerification)
+				assert.Contains(t, err.Error(), "invalid rule set signature")
+			},
+		},
+	} {
+		t.Run("case="+tc.uc, func(t *testing.T) {
+			// GIVEN
+			ver, err := NewVerifier(
+				&config.Configuration{Providers: config.RuleProviders{Signature: tc.conf}},
+				log.Logger,
+			)
+			require.NoError(t, err)
+
+			contents, detached := tc.contents(t)
+
+			// WHEN
+			payload, err := ver.Verify(context.Background(), "test", contents, detached)
+
+			// THEN
+			tc.assert(t, err, payload)
+		})
+	}
+}
+
+func TestContentType(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "application/yaml", ContentType(ContentTypeJOSE))
+	assert.Equal(t, "application/json", ContentType("application/json"))
+	assert.Equal(t, "application/yaml", ContentType("application/yaml"))
+}
+
+func TestVerifierFromContext(t *testing.T) {
+	t.Parallel()
+
+	// no verifier in the context
+	ver := Ctx(context.Background())
+	require.NotNil(t, ver)
+
+	payload, err := ver.Verify(context.Background(), "test", []byte(testRuleSet), "")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(testRuleSet), payload)
+
+	// verifier in the context
+	configured := &verifier{required: true}
+	assert.Equal(t, configured, Ctx(WithContext(context.Background(), configured)))
+}
@@ -0,0 +1,319 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x"
+	"github.com/dadrus/heimdall/internal/x/stringx"
+)
+
+const (
+	lbPolicyRoundRobin     = "round_robin"
+	lbPolicyRandom         = "random"
+	lbPolicyLeastRequests  = "least_requests"
+	lbPolicyConsistentHash = "consistent_hash"
+
+	defaultMaxFailures  = 5
+	defaultEjectionTime = 30 * time.Second
+
+	// number of points each weight unit of an upstream gets on the hash ring
+	hashRingReplicas = 100
+)
+
+var (
+	errUnsupportedLBPolicy = errors.New("unsupported load balancing policy")
+	errBadUpstreamWeight   = errors.New("bad upstream weight")
+	errBadHealthCheck      = errors.New("bad passive health check configuration")
+)
+
+type upstream struct {
+	host   string
+	weight int
+	// number of requests currently forwarded to this upstream
+	active atomic.Int64
+
+	mut          sync.Mutex
+	failures     int
+	ejectedUntil time.Time
+}
+
+func (u *upstream) available(now time.Time) bool {
+	u.mut.Lock()
+	defer u.mut.Unlock()
+
+	return !now.Before(u.ejectedUntil)
+}
+
+type hashRingEntry struct {
+	hash uint64
+	idx  int
+}
+
+// upstreamPool selects the upstream a request should be forwarded to according to the configured
+// load balancing policy and keeps track of failing upstreams. Upstreams, which failed to respond
+// for the configured number of times in a row, are not considered for the configured ejection time.
+// If all upstreams are ejected, all of them are considered again to not fail the request upfront.
+type upstreamPool struct {
+	upstreams    []*upstream
+	policy       string
+	maxFailures  int
+	ejectionTime time.Duration
+
+	mut            sync.Mutex
+	currentWeights []int
+	ring           []hashRingEntry
+}
+
+func newUpstreamPool(conf *config.Backend) (*upstreamPool, error) {
+	pool := &upstreamPool{
+		policy:       lbPolicyRoundRobin,
+		maxFailures:  defaultMaxFailures,
+		ejectionTime: defaultEjectionTime,
+	}
+
+	if len(conf.Upstreams) == 0 {
+		pool.upstreams = []*upstream{{host: conf.Host, weight: 1}}
+	}
+
+	totalWeight := 0
+
+	for _, us := range conf.Upstreams {
+		weight := x.IfThenElseExec(us.Weight != nil, func() int { return *us.Weight }, func() int { return 1 })
+		if weight < 0 {
+			return nil, fmt.Errorf("%w: weight of %s must not be negative", errBadUpstreamWeight, us.Host)
+		}
+
+		totalWeight += weight
+		pool.upstreams = append(pool.upstreams, &upstream{host: us.Host, weight: weight})
+	}
+
+	if len(conf.Upstreams) != 0 && totalWeight == 0 {
+		return nil, fmt.Errorf("%w: at least one upstream must have a weight greater than 0", errBadUpstreamWeight)
+	}
+
+	if lb := conf.LoadBalancing; lb != nil {
+		switch lb.Policy {
+		case "", lbPolicyRoundRobin:
+		case lbPolicyRandom, lbPolicyLeastRequests, lbPolicyConsistentHash:
+			pool.policy = lb.Policy
+		default:
+			return nil, fmt.Errorf("%w: %s", errUnsupportedLBPolicy, lb.Policy)
+		}
+
+		if hc := lb.HealthCheck; hc != nil {
+			if hc.MaxFailures < 0 || hc.EjectionTime < 0 {
+				return nil, fmt.Errorf("%w: values must not be negative", errBadHealthCheck)
+			}
+
+			pool.maxFailures = x.IfThenElse(hc.MaxFailures > 0, hc.MaxFailures, pool.maxFailures)
+			pool.ejectionTime = x.IfThenElse(hc.EjectionTime > 0, hc.EjectionTime, pool.ejectionTime)
+		}
+	}
+
+	pool.currentWeights = make([]int, len(pool.upstreams))
+
+	if pool.policy == lbPolicyConsistentHash {
+		pool.ring = newHashRing(pool.upstreams)
+	}
+
+	return pool, nil
+}
+
+// next selects the upstream to forward the request to and accounts the request as active on it.
+// The key is used by the consistent_hash policy only. If it is empty, round_robin is used instead.
+func (p *upstreamPool) next(key string) *upstream {
+	now := time.Now()
+
+	available := make([]bool, len(p.upstreams))
+	anyAvailable := false
+
+	// upstreams with a weight of 0 never receive any traffic
+	for idx, us := range p.upstreams {
+		available[idx] = us.weight > 0 && us.available(now)
+		anyAvailable = anyAvailable || available[idx]
+	}
+
+	if !anyAvailable {
+		for idx, us := range p.upstreams {
+			available[idx] = us.weight > 0
+		}
+	}
+
+	var selected *upstream
+
+	switch {
+	case p.policy == lbPolicyRandom:
+		selected = p.nextRandom(available)
+	case p.policy == lbPolicyLeastRequests:
+		selected = p.nextLeastRequests(available)
+	case p.policy == lbPolicyConsistentHash && len(key) != 0:
+		selected = p.nextConsistentHash(available, key)
+	default:
+		selected = p.nextRoundRobin(available)
+	}
+
+	selected.active.Add(1)
+
+	return selected
+}
+
+// done releases the request accounted as active on the given upstream and updates its health state.
+func (p *upstreamPool) done(us *upstream, err error) {
+	us.active.Add(-1)
+
+	us.mut.Lock()
+	defer us.mut.Unlock()
+
+	if err == nil {
+		us.failures = 0
+
+		return
+	}
+
+	us.failures++
+	if us.failures >= p.maxFailures {
+		us.failures = 0
+		us.ejectedUntil = time.Now().Add(p.ejectionTime)
+	}
+}
+
+// nextRoundRobin implements the smooth weighted round-robin algorithm, which spreads the
+// requests evenly over the upstreams according to their weights.
+func (p *upstreamPool) nextRoundRobin(available []bool) *upstream {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	var (
+		total    int
+		selected = -1
+	)
+
+	for idx, us := range p.upstreams {
+		if !available[idx] {
+			continue
+		}
+
+		p.currentWeights[idx] += us.weight
+		total += us.weight
+
+		if selected == -1 || p.currentWeights[idx] > p.currentWeights[selected] {
+			selected = idx
+		}
+	}
+
+	p.currentWeights[selected] -= total
+
+	return p.upstreams[selected]
+}
+
+func (p *upstreamPool) nextRandom(available []bool) *upstream {
+	var total int
+
+	for idx, us := range p.upstreams {
+		if available[idx] {
+			total += us.weight
+		}
+	}
+
+	//nolint:gosec
+	// no need for a cryptographically secure random number here
+	pick := rand.Intn(total)
+
+	for idx, us := range p.upstreams {
+		if !available[idx] {
+			continue
+		}
+
+		if pick < us.weight {
+			return us
+		}
+
+		pick -= us.weight
+	}
+
+	// cannot happen, as pick is always less than total
+	return p.upstreams[0]
+}
+
+func (p *upstreamPool) nextLeastRequests(available []bool) *upstream {
+	var selected *upstream
+
+	for idx, us := range p.upstreams {
+		if !available[idx] {
+			continue
+		}
+
+		// compares active/weight ratios without the need for floating point arithmetic
+		if selected == nil ||
+			us.active.Load()*int64(selected.weight) < selected.active.Load()*int64(us.weight) {
+			selected = us
+		}
+	}
+
+	return selected
+}
+
+func (p *upstreamPool) nextConsistentHash(available []bool, key string) *upstream {
+	hash := hashOf(key)
+	start, _ := slices.BinarySearchFunc(p.ring, hash, func(entry hashRingEntry, hash uint64) int {
+		return cmp.Compare(entry.hash, hash)
+	})
+
+	for i := 0; i < len(p.ring); i++ {
+		entry := p.ring[(start+i)%len(p.ring)]
+
+		if available[entry.idx] {
+			return p.upstreams[entry.idx]
+		}
+	}
+
+	// cannot happen, as at least one upstream is always available
+	return p.upstreams[p.ring[start%len(p.ring)].idx]
+}
+
+func newHashRing(upstreams []*upstream) []hashRingEntry {
+	var ring []hashRingEntry
+
+	for idx, us := range upstreams {
+		for i := 0; i < us.weight*hashRingReplicas; i++ {
+			ring = append(ring, hashRingEntry{hash: hashOf(us.host + "#" + strconv.Itoa(i)), idx: idx})
+		}
+	}
+
+	slices.SortFunc(ring, func(a, b hashRingEntry) int { return cmp.Compare(a.hash, b.hash) })
+
+	return ring
+}
+
+func hashOf(value string) uint64 {
+	hash := fnv.New64a()
+	hash.Write(stringx.ToBytes(value))
+
+	return hash.Sum64()
+}
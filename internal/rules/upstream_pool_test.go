@@ -0,0 +1,417 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/x/testsupport"
+)
+
+func weight(value int) *int { return &value }
+
+func TestNewUpstreamPool(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		conf   *config.Backend
+		assert func(t *testing.T, err error, pool *upstreamPool)
+	}{
+		{
+			uc:   "single host",
+			conf: &config.Backend{Host: "foo.bar"},
+			assert: func(t *testing.T, err error, pool *upstreamPool) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, pool.upstreams, 1)
+				assert.Equal(t, "foo.bar", pool.upstreams[0].host)
+				assert.Equal(t, 1, pool.upstreams[0].weight)
+				assert.Equal(t, lbPolicyRoundRobin, pool.policy)
+				assert.Equal(t, defaultMaxFailures, pool.maxFailures)
+				assert.Equal(t, defaultEjectionTime, pool.ejectionTime)
+				assert.Empty(t, pool.ring)
+			},
+		},
+		{
+			uc: "upstreams with and without weights",
+			conf: &config.Backend{Upstreams: []config.Upstream{
+				{Host: "foo.bar", Weight: weight(3)},
+				{Host: "bar.foo"},
+			}},
+			assert: func(t *testing.T, err error, pool *upstreamPool) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, pool.upstreams, 2)
+				assert.Equal(t, "foo.bar", pool.upstreams[0].host)
+				assert.Equal(t, 3, pool.upstreams[0].weight)
+				assert.Equal(t, "bar.foo", pool.upstreams[1].host)
+				assert.Equal(t, 1, pool.upstreams[1].weight)
+			},
+		},
+		{
+			uc:   "upstream with negative weight",
+			conf: &config.Backend{Upstreams: []config.Upstream{{Host: "foo.bar", Weight: weight(-1)}}},
+			assert: func(t *testing.T, err error, _ *upstreamPool) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, errBadUpstreamWeight)
+			},
+		},
+		{
+			uc: "upstream with explicit zero weight",
+			conf: &config.Backend{Upstreams: []config.Upstream{
+				{Host: "foo.bar", Weight: weight(0)},
+				{Host: "bar.foo"},
+			}},
+			assert: func(t *testing.T, err error, pool *upstreamPool) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.Len(t, pool.upstreams, 2)
+				assert.Equal(t, 0, pool.upstreams[0].weight)
+				assert.Equal(t, 1, pool.upstreams[1].weight)
+			},
+		},
+		{
+			uc: "upstreams with total weight of zero",
+			conf: &config.Backend{Upstreams: []config.Upstream{
+				{Host: "foo.bar", Weight: weight(0)},
+				{Host: "bar.foo", Weight: weight(0)},
+			}},
+			assert: func(t *testing.T, err error, _ *upstreamPool) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, errBadUpstreamWeight)
+				require.ErrorContains(t, err, "greater than 0")
+			},
+		},
+		{
+			uc: "unsupported policy",
+			conf: &config.Backend{
+				Host:          "foo.bar",
+				LoadBalancing: &config.LoadBalancing{Policy: "foo"},
+			},
+			assert: func(t *testing.T, err error, _ *upstreamPool) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, errUnsupportedLBPolicy)
+			},
+		},
+		{
+			uc: "negative health check values",
+			conf: &config.Backend{
+				Host: "foo.bar",
+				LoadBalancing: &config.LoadBalancing{
+					HealthCheck: &config.PassiveHealthCheck{MaxFailures: -1},
+				},
+			},
+			assert: func(t *testing.T, err error, _ *upstreamPool) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, errBadHealthCheck)
+			},
+		},
+		{
+			uc: "consistent hash policy with health check",
+			conf: &config.Backend{
+				Upstreams: []config.Upstream{{Host: "foo.bar", Weight: weight(2)}, {Host: "bar.foo"}},
+				LoadBalancing: &config.LoadBalancing{
+					Policy:      lbPolicyConsistentHash,
+					HealthCheck: &config.PassiveHealthCheck{MaxFailures: 1, EjectionTime: time.Minute},
+				},
+			},
+			assert: func(t *testing.T, err error, pool *upstreamPool) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Equal(t, lbPolicyConsistentHash, pool.policy)
+				assert.Equal(t, 1, pool.maxFailures)
+				assert.Equal(t, time.Minute, pool.ejectionTime)
+				assert.Len(t, pool.ring, 3*hashRingReplicas)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			pool, err := newUpstreamPool(tc.conf)
+
+			// THEN
+			tc.assert(t, err, pool)
+		})
+	}
+}
+
+func TestUpstreamPoolRoundRobin(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool, err := newUpstreamPool(&config.Backend{Upstreams: []config.Upstream{
+		{Host: "a", Weight: weight(3)},
+		{Host: "b"},
+	}})
+	require.NoError(t, err)
+
+	// WHEN
+	var hosts []string
+
+	for i := 0; i < 8; i++ {
+		us := pool.next("")
+		hosts = append(hosts, us.host)
+		pool.done(us, nil)
+	}
+
+	// THEN
+	// smooth weighted round-robin does not send all requests for a in a row
+	assert.Equal(t, []string{"a", "a", "b", "a", "a", "a", "b", "a"}, hosts)
+}
+
+func TestUpstreamPoolRandom(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool, err := newUpstreamPool(&config.Backend{
+		Upstreams:     []config.Upstream{{Host: "a", Weight: weight(9)}, {Host: "b"}},
+		LoadBalancing: &config.LoadBalancing{Policy: lbPolicyRandom},
+	})
+	require.NoError(t, err)
+
+	// WHEN
+	counts := map[string]int{}
+
+	for i := 0; i < 1000; i++ {
+		us := pool.next("")
+		counts[us.host]++
+		pool.done(us, nil)
+	}
+
+	// THEN
+	assert.Equal(t, 1000, counts["a"]+counts["b"])
+	assert.Greater(t, counts["a"], counts["b"])
+}
+
+func TestUpstreamPoolLeastRequests(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool, err := newUpstreamPool(&config.Backend{
+		Upstreams:     []config.Upstream{{Host: "a", Weight: weight(2)}, {Host: "b"}},
+		LoadBalancing: &config.LoadBalancing{Policy: lbPolicyLeastRequests},
+	})
+	require.NoError(t, err)
+
+	// WHEN
+	first := pool.next("")
+	second := pool.next("")
+	third := pool.next("")
+
+	pool.done(second, nil)
+
+	fourth := pool.next("")
+
+	// THEN
+	assert.Equal(t, "a", first.host)
+	assert.Equal(t, "b", second.host)
+	// a has 1 active request with weight 2, b has 1 with weight 1
+	assert.Equal(t, "a", third.host)
+	// b has no active requests anymore
+	assert.Equal(t, "b", fourth.host)
+	assert.Equal(t, int64(2), pool.upstreams[0].active.Load())
+	assert.Equal(t, int64(1), pool.upstreams[1].active.Load())
+}
+
+func TestUpstreamPoolConsistentHash(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool, err := newUpstreamPool(&config.Backend{
+		Upstreams: []config.Upstream{{Host: "a"}, {Host: "b"}, {Host: "c"}},
+		LoadBalancing: &config.LoadBalancing{
+			Policy:      lbPolicyConsistentHash,
+			HealthCheck: &config.PassiveHealthCheck{MaxFailures: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	// WHEN
+	selected := map[string]string{}
+	hosts := map[string]bool{}
+
+	for _, key := range []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi"} {
+		us := pool.next(key)
+		selected[key] = us.host
+		hosts[us.host] = true
+		pool.done(us, nil)
+	}
+
+	// THEN
+	// the same key always results in the same upstream
+	for key, host := range selected {
+		us := pool.next(key)
+		assert.Equal(t, host, us.host)
+		pool.done(us, nil)
+	}
+
+	// keys are spread over the upstreams
+	assert.Greater(t, len(hosts), 1)
+
+	// ejecting an upstream moves only the keys it served
+	aliceHost := pool.next("alice")
+	pool.done(aliceHost, testsupport.ErrTestPurpose)
+
+	for key, host := range selected {
+		us := pool.next(key)
+		if host == aliceHost.host {
+			assert.NotEqual(t, host, us.host)
+		} else {
+			assert.Equal(t, host, us.host)
+		}
+
+		pool.done(us, nil)
+	}
+
+	// without a key round-robin is used
+	assert.NotEqual(t, pool.next("").host, pool.next("").host)
+}
+
+func TestUpstreamPoolPassiveHealthTracking(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool, err := newUpstreamPool(&config.Backend{
+		Upstreams: []config.Upstream{{Host: "a"}, {Host: "b"}},
+		LoadBalancing: &config.LoadBalancing{
+			HealthCheck: &config.PassiveHealthCheck{MaxFailures: 2, EjectionTime: time.Hour},
+		},
+	})
+	require.NoError(t, err)
+
+	upstreamA := pool.upstreams[0]
+	upstreamB := pool.upstreams[1]
+
+	// WHEN & THEN
+	// a success resets the failure counter
+	pool.done(pool.next(""), testsupport.ErrTestPurpose)
+	upstreamA.active.Add(1)
+	pool.done(upstreamA, nil)
+	upstreamA.active.Add(1)
+	pool.done(upstreamA, testsupport.ErrTestPurpose)
+	assert.True(t, upstreamA.available(time.Now()))
+
+	// consecutive failures eject the upstream
+	upstreamA.active.Add(1)
+	pool.done(upstreamA, testsupport.ErrTestPurpose)
+	assert.False(t, upstreamA.available(time.Now()))
+	assert.True(t, upstreamA.available(time.Now().Add(time.Hour)))
+
+	for i := 0; i < 4; i++ {
+		us := pool.next("")
+		assert.Equal(t, "b", us.host)
+		pool.done(us, nil)
+	}
+
+	// if all upstreams are ejected, these are used nevertheless
+	for i := 0; i < 2; i++ {
+		upstreamB.active.Add(1)
+		pool.done(upstreamB, testsupport.ErrTestPurpose)
+	}
+
+	hosts := map[string]bool{}
+
+	for i := 0; i < 4; i++ {
+		us := pool.next("")
+		hosts[us.host] = true
+		pool.done(us, nil)
+	}
+
+	assert.Len(t, hosts, 2)
+}
+
+func TestUpstreamPoolSkipsUpstreamsWithZeroWeight(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []string{
+		lbPolicyRoundRobin, lbPolicyRandom, lbPolicyLeastRequests, lbPolicyConsistentHash,
+	} {
+		t.Run(policy, func(t *testing.T) {
+			// GIVEN
+			pool, err := newUpstreamPool(&config.Backend{
+				Upstreams: []config.Upstream{{Host: "a", Weight: weight(0)}, {Host: "b"}},
+				LoadBalancing: &config.LoadBalancing{
+					Policy:      policy,
+					HealthCheck: &config.PassiveHealthCheck{MaxFailures: 1, EjectionTime: time.Hour},
+				},
+			})
+			require.NoError(t, err)
+
+			// WHEN & THEN
+			for i := 0; i < 10; i++ {
+				us := pool.next(strconv.Itoa(i))
+				assert.Equal(t, "b", us.host)
+				pool.done(us, nil)
+			}
+
+			// even if all other upstreams are ejected
+			us := pool.next("foo")
+			pool.done(us, testsupport.ErrTestPurpose)
+
+			assert.Equal(t, "b", pool.next("bar").host)
+		})
+	}
+}
+
+func TestBackendSelectsUpstreamLazily(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	conf := &config.Backend{Upstreams: []config.Upstream{{Host: "a"}, {Host: "b"}}}
+	pool, err := newUpstreamPool(conf)
+	require.NoError(t, err)
+
+	baseURL := &url.URL{Scheme: "http", Host: "foo.bar", Path: "/foo"}
+	be := &backend{pool: pool, baseURL: baseURL}
+
+	// WHEN & THEN
+	assert.Equal(t, int64(0), pool.upstreams[0].active.Load())
+
+	targetURL := be.URL()
+	assert.Equal(t, "http://a/foo", targetURL.String())
+	assert.Same(t, targetURL, be.URL())
+	assert.Equal(t, int64(1), pool.upstreams[0].active.Load())
+
+	be.Done(nil)
+	be.Done(nil)
+	assert.Equal(t, int64(0), pool.upstreams[0].active.Load())
+
+	// a backend, which has not been used, does not affect the pool
+	(&backend{pool: pool, baseURL: baseURL}).Done(testsupport.ErrTestPurpose)
+	assert.Equal(t, 0, pool.upstreams[0].failures)
+	assert.Equal(t, 0, pool.upstreams[1].failures)
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/template"
+	"github.com/dadrus/heimdall/internal/x/errorchain"
+)
+
+var errRewriteConflict = errors.New("conflicting rewrite definitions")
+
+// urlRewriter applies the rewrite middlewares, which require access to the request and the
+// subject, or which are too expensive to be prepared on each request. It complements the
+// middlewares applied by config.URLRewriter.
+type urlRewriter struct {
+	pathRegex       *regexp.Regexp
+	pathReplacement string
+	pathTemplate    template.Template
+	pathPrefix      string
+	queryToRename   map[string]string
+	queryToAdd      map[string]template.Template
+	hostHeader      template.Template
+}
+
+func newURLRewriter(conf *config.URLRewriter) (*urlRewriter, error) {
+	if conf == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	rewriter := &urlRewriter{queryToRename: conf.QueryParamsToRename}
+
+	if len(conf.PathTemplate) != 0 &&
+		(conf.PathRegex != nil || len(conf.PathPrefixToCut) != 0 || len(conf.PathPrefixToAdd) != 0) {
+		return nil, fmt.Errorf("%w: path_template cannot be used together with other path middlewares",
+			errRewriteConflict)
+	}
+
+	if conf.PathRegex != nil {
+		regex, err := regexp.Compile(conf.PathRegex.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		rewriter.pathRegex = regex
+		rewriter.pathReplacement = conf.PathRegex.Replacement
+	}
+
+	var err error
+
+	if rewriter.pathTemplate, err = newOptionalTemplate(conf.PathTemplate); err != nil {
+		return nil, err
+	}
+
+	// the static part of the template up to the last slash before the first action
+	prefix, _, _ := strings.Cut(conf.PathTemplate, "{{")
+	rewriter.pathPrefix = prefix[:strings.LastIndex(prefix, "/")+1]
+
+	if rewriter.hostHeader, err = newOptionalTemplate(conf.HostHeader); err != nil {
+		return nil, err
+	}
+
+	if len(conf.QueryParamsToAdd) != 0 {
+		rewriter.queryToAdd = make(map[string]template.Template, len(conf.QueryParamsToAdd))
+
+		for name, value := range conf.QueryParamsToAdd {
+			if rewriter.queryToAdd[name], err = template.New(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return rewriter, nil
+}
+
+func newOptionalTemplate(value string) (template.Template, error) {
+	if len(value) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	return template.New(value)
+}
+
+// rewrite applies the configured middlewares to the given url and returns the value of the
+// Host header to use while forwarding the request. An empty value means, the Host header is
+// not overridden.
+func (r *urlRewriter) rewrite(value *url.URL, req *heimdall.Request, sub *subject.Subject) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+
+	values := map[string]any{
+		"Request": req,
+		"Subject": sub,
+	}
+
+	if r.pathRegex != nil {
+		value.Path = r.pathRegex.ReplaceAllString(value.Path, r.pathReplacement)
+		value.RawPath = ""
+	}
+
+	if r.pathTemplate != nil {
+		rendered, err := r.renderPath(values)
+		if err != nil {
+			return "", err
+		}
+
+		value.Path = rendered
+		value.RawPath = ""
+	}
+
+	if err := r.rewriteQuery(value, values); err != nil {
+		return "", err
+	}
+
+	if r.hostHeader == nil {
+		return "", nil
+	}
+
+	host, err := r.hostHeader.Render(values)
+	if err != nil {
+		return "", errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to render host_header").CausedBy(err)
+	}
+
+	return host, nil
+}
+
+// renderPath renders the path template. Since the values used by the template, like captures, are
+// decoded, they can contain dot segments. These are resolved and the result must not leave the
+// static prefix of the template.
+func (r *urlRewriter) renderPath(values map[string]any) (string, error) {
+	rendered, err := r.pathTemplate.Render(values)
+	if err != nil {
+		return "", errorchain.NewWithMessage(heimdall.ErrInternal,
+			"failed to render path_template").CausedBy(err)
+	}
+
+	cleaned := path.Clean(rendered)
+	if strings.HasSuffix(rendered, "/") && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") || !strings.HasPrefix(cleaned+"/", r.pathPrefix) {
+		return "", errorchain.NewWithMessagef(heimdall.ErrArgument,
+			"rendered path_template leaves the '%s' path prefix", r.pathPrefix)
+	}
+
+	return cleaned, nil
+}
+
+func (r *urlRewriter) rewriteQuery(value *url.URL, values map[string]any) error {
+	if len(r.queryToRename) == 0 && len(r.queryToAdd) == 0 {
+		return nil
+	}
+
+	query := value.Query()
+
+	for oldName, newName := range r.queryToRename {
+		if params, present := query[oldName]; present {
+			query.Del(oldName)
+			query[newName] = append(query[newName], params...)
+		}
+	}
+
+	for name, tpl := range r.queryToAdd {
+		param, err := tpl.Render(values)
+		if err != nil {
+			return errorchain.NewWithMessagef(heimdall.ErrInternal,
+				"failed to render value for %s query parameter", name).CausedBy(err)
+		}
+
+		query.Set(name, param)
+	}
+
+	value.RawQuery = query.Encode()
+
+	return nil
+}
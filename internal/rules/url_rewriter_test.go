@@ -0,0 +1,269 @@
+// Copyright 2023 Dimitrij Drus <dadrus@gmx.de>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/heimdall/internal/heimdall"
+	"github.com/dadrus/heimdall/internal/rules/config"
+	"github.com/dadrus/heimdall/internal/rules/mechanisms/subject"
+	"github.com/dadrus/heimdall/internal/x"
+)
+
+func TestNewURLRewriter(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		conf   *config.URLRewriter
+		assert func(t *testing.T, err error, rewriter *urlRewriter)
+	}{
+		{
+			uc: "without configuration",
+			assert: func(t *testing.T, err error, rewriter *urlRewriter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				assert.Nil(t, rewriter)
+			},
+		},
+		{
+			uc: "path_template together with strip_path_prefix",
+			conf: &config.URLRewriter{
+				PathTemplate:    "/foo",
+				PathPrefixToCut: "/bar",
+			},
+			assert: func(t *testing.T, err error, _ *urlRewriter) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, errRewriteConflict)
+			},
+		},
+		{
+			uc: "path_template together with path_regex",
+			conf: &config.URLRewriter{
+				PathTemplate: "/foo",
+				PathRegex:    &config.RegexSubstitution{Pattern: "^/bar", Replacement: "/baz"},
+			},
+			assert: func(t *testing.T, err error, _ *urlRewriter) {
+				t.Helper()
+
+				require.Error(t, err)
+				require.ErrorIs(t, err, errRewriteConflict)
+			},
+		},
+		{
+			uc:   "bad path_regex",
+			conf: &config.URLRewriter{PathRegex: &config.RegexSubstitution{Pattern: "(foo"}},
+			assert: func(t *testing.T, err error, _ *urlRewriter) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "missing closing )")
+			},
+		},
+		{
+			uc:   "bad path_template",
+			conf: &config.URLRewriter{PathTemplate: "{{ .foo "},
+			assert: func(t *testing.T, err error, _ *urlRewriter) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		{
+			uc:   "bad add_query_parameters template",
+			conf: &config.URLRewriter{QueryParamsToAdd: map[string]string{"foo": "{{ .foo "}},
+			assert: func(t *testing.T, err error, _ *urlRewriter) {
+				t.Helper()
+
+				require.Error(t, err)
+			},
+		},
+		{
+			uc: "all middlewares",
+			conf: &config.URLRewriter{
+				PathRegex:           &config.RegexSubstitution{Pattern: "^/foo/(.*)$", Replacement: "/bar/$1"},
+				QueryParamsToRename: map[string]string{"foo": "bar"},
+				QueryParamsToAdd:    map[string]string{"baz": "{{ .Subject.ID }}"},
+				HostHeader:          "foo.bar",
+			},
+			assert: func(t *testing.T, err error, rewriter *urlRewriter) {
+				t.Helper()
+
+				require.NoError(t, err)
+				require.NotNil(t, rewriter)
+				assert.NotNil(t, rewriter.pathRegex)
+				assert.Equal(t, "/bar/$1", rewriter.pathReplacement)
+				assert.Nil(t, rewriter.pathTemplate)
+				assert.Equal(t, map[string]string{"foo": "bar"}, rewriter.queryToRename)
+				assert.Len(t, rewriter.queryToAdd, 1)
+				assert.NotNil(t, rewriter.hostHeader)
+			},
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			rewriter, err := newURLRewriter(tc.conf)
+
+			// THEN
+			tc.assert(t, err, rewriter)
+		})
+	}
+}
+
+func TestURLRewriterRewrite(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc       string
+		conf     *config.URLRewriter
+		original string
+		expURL   string
+		expHost  string
+		captures map[string]string
+		expErr   error
+	}{
+		{
+			uc:       "no rewriter",
+			original: "http://foo.bar/foo?bar=baz",
+			expURL:   "http://foo.bar/foo?bar=baz",
+		},
+		{
+			uc: "path regex with capture group references",
+			conf: &config.URLRewriter{
+				PathRegex: &config.RegexSubstitution{
+					Pattern:     "^/users/([^/]+)/profile$",
+					Replacement: "/profiles/$1",
+				},
+			},
+			original: "http://foo.bar/users/alice/profile?bar=baz",
+			expURL:   "http://foo.bar/profiles/alice?bar=baz",
+		},
+		{
+			uc: "path template using captures and subject attributes",
+			conf: &config.URLRewriter{
+				PathTemplate: "/tenants/{{ .Subject.Attributes.tenant }}/items/{{ .Request.URL.Captures.id }}",
+			},
+			original: "http://foo.bar/items/42",
+			expURL:   "http://foo.bar/tenants/acme/items/42",
+		},
+		{
+			uc: "path template with capture containing dot segments inside the static prefix",
+			conf: &config.URLRewriter{
+				PathTemplate: "/api/{{ .Request.URL.Captures.rest }}/",
+			},
+			original: "http://foo.bar/items/foo%2F..%2Fbar",
+			captures: map[string]string{"rest": "foo/../bar"},
+			expURL:   "http://foo.bar/api/bar/",
+		},
+		{
+			uc: "path template with capture traversing out of the static prefix",
+			conf: &config.URLRewriter{
+				PathTemplate: "/api/{{ .Request.URL.Captures.rest }}",
+			},
+			original: "http://foo.bar/items/..%2Fadmin",
+			captures: map[string]string{"rest": "../admin"},
+			expErr:   heimdall.ErrArgument,
+		},
+		{
+			uc: "path template with capture traversing out of the static prefix partially",
+			conf: &config.URLRewriter{
+				PathTemplate: "/api/v{{ .Request.URL.Captures.rest }}",
+			},
+			original: "http://foo.bar/items/1%2F..%2F..%2Fadmin",
+			captures: map[string]string{"rest": "1/../../admin"},
+			expErr:   heimdall.ErrArgument,
+		},
+		{
+			uc:       "path template without static prefix producing a relative traversal",
+			conf:     &config.URLRewriter{PathTemplate: "{{ .Request.URL.Captures.rest }}"},
+			original: "http://foo.bar/items/..%2Fadmin",
+			captures: map[string]string{"rest": "../admin"},
+			expErr:   heimdall.ErrArgument,
+		},
+		{
+			uc: "query parameters renaming and injection",
+			conf: &config.URLRewriter{
+				QueryParamsToRename: map[string]string{"q": "query", "missing": "foo"},
+				QueryParamsToAdd:    map[string]string{"user": "{{ .Subject.ID }}", "page": "1"},
+			},
+			original: "http://foo.bar/search?q=heimdall&page=7",
+			expURL:   "http://foo.bar/search?page=1&query=heimdall&user=alice",
+		},
+		{
+			uc:       "host header override",
+			conf:     &config.URLRewriter{HostHeader: "{{ .Subject.Attributes.tenant }}.internal"},
+			original: "http://foo.bar/foo",
+			expURL:   "http://foo.bar/foo",
+			expHost:  "acme.internal",
+		},
+		{
+			uc:       "failing path template",
+			conf:     &config.URLRewriter{PathTemplate: "{{ fail \"foo\" }}"},
+			original: "http://foo.bar/foo",
+			expErr:   heimdall.ErrInternal,
+		},
+		{
+			uc:       "failing query parameter template",
+			conf:     &config.URLRewriter{QueryParamsToAdd: map[string]string{"foo": "{{ fail \"foo\" }}"}},
+			original: "http://foo.bar/foo",
+			expErr:   heimdall.ErrInternal,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			rewriter, err := newURLRewriter(tc.conf)
+			require.NoError(t, err)
+
+			original, err := url.Parse(tc.original)
+			require.NoError(t, err)
+
+			req := &heimdall.Request{
+				Method: "GET",
+				URL: &heimdall.URL{
+					URL:      *original,
+					Captures: x.IfThenElse(tc.captures != nil, tc.captures, map[string]string{"id": "42"}),
+				},
+			}
+			sub := &subject.Subject{ID: "alice", Attributes: map[string]any{"tenant": "acme"}}
+
+			value := *original
+
+			// WHEN
+			host, err := rewriter.rewrite(&value, req, sub)
+
+			// THEN
+			if tc.expErr != nil {
+				require.Error(t, err)
+				require.ErrorIs(t, err, tc.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expURL, value.String())
+			assert.Equal(t, tc.expHost, host)
+		})
+	}
+}
@@ -32,6 +32,19 @@ func registerTranslations(validate *validator.Validate, trans ut.Translator) err
 				return translation
 			},
 		},
+		{
+			tag:         "excluded_with",
+			translation: "{0} cannot be used together with {1}",
+			override:    false,
+			customTransFunc: func(ut ut.Translator, fe validator.FieldError) string {
+				translation, err := ut.T(fe.Tag(), fe.Field(), strings.ToLower(fe.Param()))
+				if err != nil {
+					return fe.Error()
+				}
+
+				return translation
+			},
+		},
 		{
 			tag: "gt",
 			customRegisFunc: func(ut ut.Translator) error {